@@ -0,0 +1,78 @@
+package zero
+
+import "sort"
+
+// 为一组互斥的键分配节点，组内的键尽量落在不同的节点上
+// 键按字典序依次分配：先取键本来的节点，已被组内其他键占用时沿哈希环顺时针取下一个未被占用的节点
+// 键多于节点时，所有节点用完后再开始新的一轮，此时才会出现重复
+// 返回键到节点的映射，没有节点时返回空映射
+func (h *ConsistentHash) GetWithAntiAffinity(keys []string) map[string]interface{} {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	result := make(map[string]interface{}, len(keys))
+	if len(h.ring) == 0 {
+		return result
+	}
+
+	sorted := append([]string(nil), keys...)
+	sort.Strings(sorted)
+	used := make(map[string]struct{}, len(h.nodes))
+	for _, key := range sorted {
+		if _, ok := result[key]; ok {
+			continue
+		}
+		if len(used) == len(h.nodes) {
+			used = make(map[string]struct{}, len(h.nodes))
+		}
+
+		h.walk(key, func(node string) bool {
+			if _, ok := used[node]; ok {
+				return true
+			}
+			used[node] = struct{}{}
+			result[key] = node
+			return false
+		})
+	}
+	return result
+}
+
+// 依次访问键的节点，第一个节点与 Get 的结果一致，之后从键的位置沿哈希环顺时针访问
+// 键有固定路由时先访问固定的节点，否则先访问过载转移的临时节点或就近选择的节点，不计入热点统计
+// 同一节点可能被访问多次，fn 返回 false 或所有虚拟节点访问完时停止
+// 调用方需持有读锁
+func (h *ConsistentHash) walk(key string, fn func(node string) bool) {
+	if node, ok := h.pinned(key); ok {
+		if !fn(node) {
+			return
+		}
+	} else if to, ok := h.spilledToLocked(key); ok {
+		if !fn(to) {
+			return
+		}
+	} else if h.proximity != nil && !fn(h.nearestLocked(key)) {
+		return
+	}
+	h.walkRing(key, fn)
+}
+
+// 从键的位置沿哈希环顺时针依次访问节点，不考虑固定路由和就近选择
+// 调用方需持有读锁
+func (h *ConsistentHash) walkRing(key string, fn func(node string) bool) {
+	b := []byte(key)
+	hash := h.hashFunc(b)
+	first, _ := h.locate(hash, b)
+	if !fn(first.(string)) {
+		return
+	}
+
+	h.ascendRing(hash, func(point uint64) bool {
+		for _, node := range h.ring[point] {
+			if !fn(node.(string)) {
+				return false
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,68 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetWithAntiAffinity(t *testing.T) {
+	ch := NewConsistentHash()
+	assert.Empty(t, ch.GetWithAntiAffinity([]string{"a"}))
+
+	for i := 0; i < 5; i++ {
+		ch.Add("10.0.0." + strconv.Itoa(i) + ":6379")
+	}
+
+	keys := []string{"replica-0", "replica-1", "replica-2", "replica-3"}
+	result := ch.GetWithAntiAffinity(keys)
+	assert.Equal(t, len(keys), len(result))
+	seen := make(map[interface{}]bool)
+	for _, node := range result {
+		assert.False(t, seen[node])
+		seen[node] = true
+	}
+	// 第一个键总是落在本来的节点上
+	natural, _ := ch.Get("replica-0")
+	assert.Equal(t, natural, result["replica-0"])
+	// 结果与键的顺序无关
+	assert.Equal(t, result, ch.GetWithAntiAffinity([]string{"replica-3", "replica-1", "replica-2", "replica-0", "replica-1"}))
+
+	// 键多于节点时每个节点至多多用一轮
+	keys = nil
+	for i := 0; i < 7; i++ {
+		keys = append(keys, "k"+strconv.Itoa(i))
+	}
+	counts := make(map[interface{}]int)
+	for _, node := range ch.GetWithAntiAffinity(keys) {
+		counts[node]++
+	}
+	assert.Equal(t, 5, len(counts))
+	for _, count := range counts {
+		assert.True(t, count <= 2)
+	}
+}
+
+func TestGetWithAntiAffinityNaturalPositions(t *testing.T) {
+	ch := NewConsistentHash()
+	for i := 0; i < 20; i++ {
+		ch.Add("10.0.0." + strconv.Itoa(i) + ":6379")
+	}
+
+	// 本来就不冲突的键保持不变
+	var keys []string
+	used := make(map[interface{}]bool)
+	for i := 0; len(keys) < 3; i++ {
+		key := "k" + strconv.Itoa(i)
+		node, _ := ch.Get(key)
+		if !used[node] {
+			used[node] = true
+			keys = append(keys, key)
+		}
+	}
+	for key, node := range ch.GetWithAntiAffinity(keys) {
+		natural, _ := ch.Get(key)
+		assert.Equal(t, natural, node)
+	}
+}
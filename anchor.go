@@ -0,0 +1,163 @@
+package zero
+
+import (
+	"sort"
+	"sync"
+)
+
+// AnchorHash 一致性哈希
+// 预先分配容量为 capacity 的桶，每个物理节点占用一个桶
+// 内存与桶数量成正比，查找期望为常数时间，增删节点时只有必要的键发生迁移
+// 桶的分配依赖增删顺序，多个进程需按相同顺序变更才能得到相同的映射
+type AnchorHash struct {
+	// 哈希函数
+	hashFunc Func
+	// A[b] 为0表示桶 b 在工作，否则为 b 被删除时剩余的工作桶数量
+	anchor []int
+	// 删除桶后的替代关系
+	next []int
+	// 工作集合的位置映射，W[L[b]] == b
+	list []int
+	work []int
+	// 已删除的桶，栈顶最先被复用
+	removed []int
+	// 工作桶数量
+	size int
+	// 桶到物理节点的映射
+	buckets []string
+	// 物理节点到桶的映射
+	nodes map[string]int
+	// 读写锁
+	lock sync.RWMutex
+}
+
+func NewAnchorHash(capacity int) *AnchorHash {
+	return NewCustomAnchorHash(capacity, Hash)
+}
+
+func NewCustomAnchorHash(capacity int, fn Func) *AnchorHash {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	if fn == nil {
+		fn = Hash
+	}
+
+	h := &AnchorHash{
+		hashFunc: fn,
+		anchor:   make([]int, capacity),
+		next:     make([]int, capacity),
+		list:     make([]int, capacity),
+		work:     make([]int, capacity),
+		removed:  make([]int, 0, capacity),
+		buckets:  make([]string, capacity),
+		nodes:    make(map[string]int),
+	}
+	// 初始时所有桶都未启用，逆序入栈使得桶按0,1,2...的顺序启用
+	for b := capacity - 1; b >= 0; b-- {
+		h.next[b] = b
+		h.list[b] = b
+		h.work[b] = b
+		h.anchor[b] = b
+		h.removed = append(h.removed, b)
+	}
+
+	return h
+}
+
+// 增加物理节点，支持重复添加
+// 超出容量时添加会被忽略，可通过 Len 判断
+func (h *AnchorHash) Add(node string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if _, ok := h.nodes[node]; ok || len(h.removed) == 0 {
+		return
+	}
+
+	b := h.removed[len(h.removed)-1]
+	h.removed = h.removed[:len(h.removed)-1]
+	h.anchor[b] = 0
+	h.list[h.work[h.size]] = h.size
+	h.work[h.list[b]] = b
+	h.next[b] = b
+	h.size++
+
+	h.buckets[b] = node
+	h.nodes[node] = b
+}
+
+// 删除物理节点
+func (h *AnchorHash) Remove(node string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	b, ok := h.nodes[node]
+	if !ok {
+		return
+	}
+	delete(h.nodes, node)
+	h.buckets[b] = ""
+
+	h.removed = append(h.removed, b)
+	h.size--
+	h.anchor[b] = h.size
+	h.work[h.list[b]] = h.work[h.size]
+	h.next[b] = h.work[h.size]
+	h.list[h.work[h.size]] = h.list[b]
+}
+
+// 查找键所属的物理节点
+func (h *AnchorHash) Get(v string) (interface{}, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if h.size == 0 {
+		return nil, false
+	}
+
+	hash := h.hashFunc([]byte(v))
+	b := int(hash % uint64(len(h.anchor)))
+	// 桶已被删除时，在删除当时的工作集合中重新选择
+	for h.anchor[b] > 0 {
+		next := int(anchorMix(hash, b) % uint64(h.anchor[b]))
+		// 跳过比 b 更早被删除的桶
+		for h.anchor[next] >= h.anchor[b] {
+			next = h.next[next]
+		}
+		b = next
+	}
+
+	return h.buckets[b], true
+}
+
+// 当前物理节点数量
+func (h *AnchorHash) Len() int {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.size
+}
+
+// 当前所有物理节点，按字典序排列
+func (h *AnchorHash) Nodes() []string {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	nodes := make([]string, 0, len(h.nodes))
+	for node := range h.nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// 桶的容量
+func (h *AnchorHash) Capacity() int {
+	return len(h.anchor)
+}
+
+// 以桶编号为种子对键哈希再次混淆
+func anchorMix(hash uint64, b int) uint64 {
+	return mix64(hash + uint64(b+1)*0x9e3779b97f4a7c15)
+}
@@ -0,0 +1,89 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnchorHash(t *testing.T) {
+	h := NewAnchorHash(64)
+	_, ok := h.Get("any")
+	assert.False(t, ok)
+
+	for i := 0; i < keySize; i++ {
+		h.Add("localhost:" + strconv.Itoa(i))
+	}
+	assert.Equal(t, keySize, h.Len())
+
+	const total = requestSize * 10
+	loads := make(map[interface{}]int)
+	for i := 0; i < total; i++ {
+		node, ok := h.Get(strconv.Itoa(i))
+		assert.True(t, ok)
+		loads[node]++
+	}
+	assert.Equal(t, keySize, len(loads))
+	for node, load := range loads {
+		assert.InDelta(t, total/keySize, load, total/keySize/4, node)
+	}
+}
+
+func TestAnchorHashConsistency(t *testing.T) {
+	h := NewAnchorHash(32)
+	for i := 0; i < keySize; i++ {
+		h.Add("localhost:" + strconv.Itoa(i))
+	}
+
+	snapshot := func() map[int]interface{} {
+		keys := make(map[int]interface{}, requestSize)
+		for i := 0; i < requestSize; i++ {
+			keys[i], _ = h.Get(strconv.Itoa(i))
+		}
+		return keys
+	}
+
+	// 依次删除多个节点，只有被删除节点上的键会迁移
+	for _, removed := range []string{"localhost:3", "localhost:11", "localhost:0"} {
+		before := snapshot()
+		h.Remove(removed)
+		after := snapshot()
+		for i, node := range before {
+			assert.NotEqual(t, removed, after[i])
+			if node != removed {
+				assert.Equal(t, node, after[i])
+			}
+		}
+	}
+
+	// 新增节点时，键只会迁移到新节点上
+	before := snapshot()
+	h.Add("new")
+	after := snapshot()
+	var moved int
+	for i, node := range after {
+		if node != before[i] {
+			assert.Equal(t, "new", node)
+			moved++
+		}
+	}
+	assert.True(t, moved > 0)
+}
+
+func TestAnchorHashCapacity(t *testing.T) {
+	h := NewAnchorHash(2)
+	h.Add("a")
+	h.Add("b")
+	h.Add("c")
+	assert.Equal(t, 2, h.Len())
+	assert.Equal(t, 2, h.Capacity())
+
+	h.Remove("a")
+	h.Add("c")
+	for i := 0; i < 100; i++ {
+		node, ok := h.Get(strconv.Itoa(i))
+		assert.True(t, ok)
+		assert.Contains(t, []string{"b", "c"}, node)
+	}
+}
@@ -0,0 +1,149 @@
+package zero
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// 自动调优时虚拟节点放大因子的上限
+const maxAutoReplicas = minReplicas << 6
+
+// 创建自动调优虚拟节点数量的一致性哈希
+// targetStdDev 为各节点实际占有哈希空间与期望占比之比的标准差
+// 每次拓扑变更后都会测量不均衡度，并成倍增减虚拟节点直至满足目标
+func NewAutoTunedConsistentHash(targetStdDev float64) *ConsistentHash {
+	h := New()
+	h.targetStdDev = targetStdDev
+	return h
+}
+
+// 根据目标不均衡度调整虚拟节点数量
+// 调用方需持有写锁
+func (h *ConsistentHash) tune() {
+	if h.targetStdDev <= 0 || len(h.nodes) < 2 {
+		return
+	}
+
+	if h.imbalance() > h.targetStdDev {
+		for h.imbalance() > h.targetStdDev && h.replicas < maxAutoReplicas {
+			h.rescale(h.replicas << 1)
+		}
+		return
+	}
+
+	// 已满足目标时尝试减少虚拟节点，节省内存
+	for h.replicas > minReplicas {
+		prev := h.replicas
+		h.rescale(max(h.replicas>>1, minReplicas))
+		if h.imbalance() > h.targetStdDev {
+			h.rescale(prev)
+			return
+		}
+	}
+}
+
+// 按新的放大因子等比例缩放所有节点的虚拟节点并重建哈希环
+// 调用方需持有写锁
+func (h *ConsistentHash) rescale(replicas int) {
+	nodes := make(map[string]int, len(h.nodes))
+	for node, n := range h.nodes {
+		if n > 0 {
+			n = max(n*replicas/h.replicas, 1)
+		}
+		nodes[node] = n
+	}
+	h.replicas = replicas
+	h.rebuild(nodes)
+}
+
+// 按给定的节点及虚拟节点数量重建哈希环
+// 调用方需持有写锁
+func (h *ConsistentHash) rebuild(nodes map[string]int) {
+	start := time.Now()
+	defer func() {
+		h.lastRebuild = time.Since(start)
+	}()
+
+	names := make([]string, 0, len(nodes))
+	for node := range nodes {
+		names = append(names, node)
+	}
+	// 固定添加顺序，使冲突链的顺序可复现
+	sort.Strings(names)
+
+	h.resetPoints()
+	h.ring = make(map[uint64][]interface{})
+	h.nodes = make(map[string]int, len(nodes))
+	h.points = make(map[string][]uint64, len(nodes))
+	precomputed := h.precomputePoints(nodes)
+	for _, node := range names {
+		points := h.pointsFor(precomputed, node, nodes[node])
+		// 重建时不能丢弃已有节点，冲突时退化为共享位置
+		if err := h.addPointsLocked(node, nodes[node], points); err != nil {
+			h.insertLocked(node, nodes[node], points)
+		}
+	}
+	h.sortKeys()
+}
+
+// 计算每个物理节点拥有的哈希空间占比
+// 冲突的虚拟节点由冲突链上的节点平分
+// 调用方需持有读锁
+func (h *ConsistentHash) ownership() map[string]float64 {
+	owned := make(map[string]float64, len(h.nodes))
+	if len(h.ring) == 0 {
+		return owned
+	}
+
+	// 第一个有效虚拟节点的前驱是最后一个有效虚拟节点
+	prev := h.lastPoint()
+	first := true
+	h.ascend(0, func(hash uint64) bool {
+		nodes := h.ring[hash]
+		// 无符号减法加掩码处理了首个虚拟节点跨越0点的情况
+		arc := h.arcFraction((hash - prev) & h.hashMask())
+		// 只有一个位置时占满整个环
+		if first && hash == prev {
+			arc = 1
+		}
+		first = false
+		prev = hash
+		if arc == 0 {
+			return true
+		}
+
+		for _, node := range nodes {
+			owned[node.(string)] += arc / float64(len(nodes))
+		}
+		return true
+	})
+
+	return owned
+}
+
+// 各节点实际占比与按虚拟节点数量计算的期望占比之比的标准差
+// 调用方需持有读锁
+func (h *ConsistentHash) imbalance() float64 {
+	var total int
+	for _, replicas := range h.nodes {
+		total += replicas
+	}
+	if total == 0 {
+		return 0
+	}
+
+	owned := h.ownership()
+	var sum, count float64
+	for node, replicas := range h.nodes {
+		if replicas == 0 {
+			continue
+		}
+		expected := float64(replicas) / float64(total)
+		diff := owned[node]/expected - 1
+		sum += diff * diff
+		count++
+	}
+
+	return math.Sqrt(sum / count)
+}
@@ -0,0 +1,48 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoTunedConsistentHash(t *testing.T) {
+	const target = .03
+	ch := NewAutoTunedConsistentHash(target)
+	for i := 0; i < keySize; i++ {
+		ch.Add("10.0.0." + strconv.Itoa(i) + ":6379")
+	}
+
+	assert.True(t, ch.replicas > minReplicas)
+	assert.True(t, ch.imbalance() <= target)
+	for node, replicas := range ch.nodes {
+		assert.Equal(t, ch.replicas, replicas, node)
+	}
+
+	for i := 0; i < keySize-2; i++ {
+		ch.Remove("10.0.0." + strconv.Itoa(i) + ":6379")
+		assert.True(t, ch.imbalance() <= target || ch.replicas == maxAutoReplicas)
+	}
+	val, ok := ch.Get("any")
+	assert.True(t, ok)
+	assert.Contains(t, []string{"10.0.0.18:6379", "10.0.0.19:6379"}, val)
+}
+
+func TestAutoTunedConsistentHashLooseTarget(t *testing.T) {
+	ch := NewAutoTunedConsistentHash(10)
+	for i := 0; i < keySize; i++ {
+		ch.Add("localhost:" + strconv.Itoa(i))
+	}
+	assert.Equal(t, minReplicas, ch.replicas)
+}
+
+func TestConsistentHashOwnership(t *testing.T) {
+	ch := NewConsistentHash()
+	ch.Add("first")
+	assert.InDelta(t, 1, ch.ownership()["first"], 1e-9)
+
+	ch.Add("second")
+	owned := ch.ownership()
+	assert.InDelta(t, 1, owned["first"]+owned["second"], 1e-9)
+}
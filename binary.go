@@ -0,0 +1,187 @@
+package zero
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"math"
+	"sort"
+)
+
+const (
+	// 二进制格式的魔数和版本
+	// 版本2在固定路由之后追加节点地址，没有节点地址时仍编码为版本1
+	binaryMagic     = "CHR"
+	binaryVersion   = 1
+	binaryVersionV2 = 2
+	// 末尾 CRC32 校验和的长度
+	checksumSize = 4
+)
+
+var (
+	// 数据不是合法的二进制格式
+	ErrBadEncoding = errors.New("consistenthash: malformed binary encoding")
+	// 校验和不匹配，数据在传输中损坏
+	ErrChecksum = errors.New("consistenthash: checksum mismatch")
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// 把当前成员和固定路由编码为紧凑的二进制格式，适合由控制面向大量 sidecar 推送
+// 节点和固定路由按字典序排列，相同的成员总是得到相同的字节
+// 格式：魔数 "CHR" | 版本 | 放大因子 | 节点数 | (名称, 虚拟节点数)... | 固定路由数 | (键, 节点)... | [地址数 | (ID, 地址)...] | CRC32C
+// 地址部分只在有节点地址时出现，此时版本为2
+// 整数均为 uvarint，字符串为 uvarint 长度加内容，校验和为大端序
+func (h *ConsistentHash) EncodeBinary() []byte {
+	return h.Snapshot().appendBinary(nil)
+}
+
+// 解码 EncodeBinary 的结果并替换当前的成员和固定路由，语义同 Restore
+// 数据损坏或格式不合法时返回错误且不修改哈希环
+func (h *ConsistentHash) DecodeBinary(data []byte) error {
+	s, err := decodeSnapshot(data)
+	if err != nil {
+		return err
+	}
+	h.Restore(s)
+	return nil
+}
+
+func (s Snapshot) appendBinary(buf []byte) []byte {
+	buf = append(buf, binaryMagic...)
+	if len(s.Addrs) > 0 {
+		buf = append(buf, binaryVersionV2)
+	} else {
+		buf = append(buf, binaryVersion)
+	}
+	buf = binary.AppendUvarint(buf, uint64(s.Replicas))
+
+	buf = binary.AppendUvarint(buf, uint64(len(s.Nodes)))
+	for _, node := range sortedKeys(s.Nodes) {
+		buf = appendString(buf, node)
+		buf = binary.AppendUvarint(buf, uint64(s.Nodes[node]))
+	}
+
+	buf = binary.AppendUvarint(buf, uint64(len(s.Pins)))
+	for _, key := range sortedKeys(s.Pins) {
+		buf = appendString(buf, key)
+		buf = appendString(buf, s.Pins[key])
+	}
+
+	if len(s.Addrs) > 0 {
+		buf = binary.AppendUvarint(buf, uint64(len(s.Addrs)))
+		for _, id := range sortedKeys(s.Addrs) {
+			buf = appendString(buf, id)
+			buf = appendString(buf, s.Addrs[id])
+		}
+	}
+
+	return appendChecksum(buf)
+}
+
+// 在末尾追加之前所有字节的校验和
+func appendChecksum(buf []byte) []byte {
+	return binary.BigEndian.AppendUint32(buf, crc32.Checksum(buf, castagnoli))
+}
+
+func decodeSnapshot(data []byte) (Snapshot, error) {
+	var s Snapshot
+	if len(data) < len(binaryMagic)+1+checksumSize || string(data[:len(binaryMagic)]) != binaryMagic {
+		return s, ErrBadEncoding
+	}
+	body, sum := data[:len(data)-checksumSize], data[len(data)-checksumSize:]
+	if crc32.Checksum(body, castagnoli) != binary.BigEndian.Uint32(sum) {
+		return s, ErrChecksum
+	}
+	version := body[len(binaryMagic)]
+	if version != binaryVersion && version != binaryVersionV2 {
+		return s, ErrBadEncoding
+	}
+
+	d := decoder{buf: body[len(binaryMagic)+1:]}
+	s.Replicas = int(d.uvarint())
+	if n := d.count(); n > 0 {
+		s.Nodes = make(map[string]int, n)
+		for i := 0; i < n; i++ {
+			node := d.string()
+			s.Nodes[node] = int(d.uvarint())
+		}
+	}
+	if n := d.count(); n > 0 {
+		s.Pins = make(map[string]string, n)
+		for i := 0; i < n; i++ {
+			key := d.string()
+			s.Pins[key] = d.string()
+		}
+	}
+	if version == binaryVersionV2 {
+		if n := d.count(); n > 0 {
+			s.Addrs = make(map[string]string, n)
+			for i := 0; i < n; i++ {
+				id := d.string()
+				s.Addrs[id] = d.string()
+			}
+		}
+	}
+	if d.err != nil || len(d.buf) > 0 {
+		return Snapshot{}, ErrBadEncoding
+	}
+	return s, nil
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// 顺序读取二进制格式，出错后的读取均返回零值
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 || v > math.MaxInt {
+		d.err = ErrBadEncoding
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+// 元素个数，每个元素至少占两个字节，超出剩余长度的个数必然非法
+func (d *decoder) count() int {
+	n := d.uvarint()
+	if n > uint64(len(d.buf)/2) {
+		d.err = ErrBadEncoding
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) string() string {
+	n := d.uvarint()
+	if d.err != nil {
+		return ""
+	}
+	if n > uint64(len(d.buf)) {
+		d.err = ErrBadEncoding
+		return ""
+	}
+	s := string(d.buf[:n])
+	d.buf = d.buf[n:]
+	return s
+}
@@ -0,0 +1,93 @@
+package zero
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeDecodeBinary(t *testing.T) {
+	ch := New(WithReplicas(200))
+	for i := 0; i < 5; i++ {
+		ch.Add("10.0.0." + strconv.Itoa(i) + ":6379")
+	}
+	ch.AddWithWeight("10.0.0.5:6379", 50)
+	ch.Pin("hot", "10.0.0.1:6379")
+
+	data := ch.EncodeBinary()
+	// 与成员添加顺序无关
+	other := New(WithReplicas(200))
+	other.Pin("hot", "10.0.0.1:6379")
+	other.AddWithWeight("10.0.0.5:6379", 50)
+	for i := 4; i >= 0; i-- {
+		other.Add("10.0.0." + strconv.Itoa(i) + ":6379")
+	}
+	assert.Equal(t, data, other.EncodeBinary())
+
+	js, err := json.Marshal(ch.Snapshot())
+	assert.Nil(t, err)
+	assert.Less(t, len(data), len(js))
+
+	restored := New()
+	assert.Nil(t, restored.DecodeBinary(data))
+	assert.Equal(t, ch.Snapshot(), restored.Snapshot())
+	for i := 0; i < requestSize; i++ {
+		key := strconv.Itoa(i)
+		expect, _ := ch.Get(key)
+		actual, _ := restored.Get(key)
+		assert.Equal(t, expect, actual)
+	}
+
+	empty := New()
+	assert.Nil(t, restored.DecodeBinary(empty.EncodeBinary()))
+	assert.Equal(t, 0, restored.Len())
+}
+
+func TestEncodeDecodeBinaryAddrs(t *testing.T) {
+	ch := New()
+	ch.Add("plain")
+	assert.Equal(t, byte(binaryVersion), ch.EncodeBinary()[len(binaryMagic)])
+
+	ch.AddNode(Node{ID: "cache-1", Addr: "10.0.0.1:6379"})
+	data := ch.EncodeBinary()
+	assert.Equal(t, byte(binaryVersionV2), data[len(binaryMagic)])
+
+	restored := New()
+	assert.Nil(t, restored.DecodeBinary(data))
+	assert.Equal(t, ch.Snapshot(), restored.Snapshot())
+	addr, _ := restored.Address("cache-1")
+	assert.Equal(t, "10.0.0.1:6379", addr)
+}
+
+func TestDecodeBinaryCorrupted(t *testing.T) {
+	ch := NewConsistentHash()
+	ch.Add("10.0.0.1:6379")
+	data := ch.EncodeBinary()
+
+	restored := NewConsistentHash()
+	restored.Add("10.0.0.9:6379")
+	for i := range data {
+		corrupted := append([]byte(nil), data...)
+		corrupted[i] ^= 0x40
+		assert.NotNil(t, restored.DecodeBinary(corrupted), i)
+	}
+	assert.Equal(t, ErrChecksum, restored.DecodeBinary(append(data[:len(data)-1:len(data)-1], data[len(data)-1]+1)))
+	assert.Equal(t, ErrBadEncoding, restored.DecodeBinary(data[:3]))
+	assert.Equal(t, ErrBadEncoding, restored.DecodeBinary(nil))
+	// 失败时不修改哈希环
+	assert.Equal(t, []string{"10.0.0.9:6379"}, restored.Nodes())
+}
+
+func TestDecodeBinaryMalformedBody(t *testing.T) {
+	// 校验和正确但节点数超出数据长度
+	s := Snapshot{Replicas: 100, Nodes: map[string]int{"a": 1}}
+	data := s.appendBinary(nil)
+	body := append([]byte(nil), data[:len(data)-checksumSize]...)
+	body[len(binaryMagic)+2] = 0x7f
+	forged := appendChecksum(body)
+
+	ch := NewConsistentHash()
+	assert.Equal(t, ErrBadEncoding, ch.DecodeBinary(forged))
+}
@@ -0,0 +1,203 @@
+// 基于 BoltDB 的虚拟节点存储
+// 虚拟节点数量超出内存预算时，把有序的位置索引放到磁盘上，由操作系统的页缓存承担热数据
+// 独立为单独的 module，核心包不依赖 bbolt
+package boltstore
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"consistenthash"
+	bolt "go.etcd.io/bbolt"
+)
+
+// 默认的 bucket 名称
+const defaultBucket = "points"
+
+// 基于 BoltDB 的 zero.RingStore
+// 位置以8字节大端序作为键保存，键的字节序即位置的大小顺序
+// 磁盘读写失败时无法通过 RingStore 返回错误，会直接 panic
+type Store struct {
+	db     *bolt.DB
+	bucket []byte
+	// 位置的数量，只在写锁下修改
+	n int
+	// 由 Open 打开的数据库在 Close 时关闭
+	owned bool
+}
+
+var _ zero.RingStore = (*Store)(nil)
+
+// 打开 path 处的数据库作为存储，options 为 nil 时关闭 fsync
+// 哈希环创建时会清空存储，位置不需要持久化，关闭 fsync 可大幅降低增删节点的开销
+func Open(path string, options *bolt.Options) (*Store, error) {
+	if options == nil {
+		options = &bolt.Options{Timeout: time.Second, NoSync: true}
+	}
+	db, err := bolt.Open(path, 0o600, options)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := New(db, defaultBucket)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	s.owned = true
+	return s, nil
+}
+
+// 使用已打开的数据库中名为 bucket 的 bucket 作为存储，不存在时创建
+func New(db *bolt.DB, bucket string) (*Store, error) {
+	s := &Store{db: db, bucket: []byte(bucket)}
+	err := db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(s.bucket)
+		if err != nil {
+			return err
+		}
+		s.n = b.Stats().KeyN
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// 关闭由 Open 打开的数据库，New 传入的数据库由调用方关闭
+func (s *Store) Close() error {
+	if !s.owned {
+		return nil
+	}
+	return s.db.Close()
+}
+
+func (s *Store) Insert(point uint64) {
+	s.update(func(b *bolt.Bucket) error {
+		key := encode(point)
+		if b.Get(key) != nil {
+			return nil
+		}
+		if err := b.Put(key, []byte{}); err != nil {
+			return err
+		}
+		s.n++
+		return nil
+	})
+}
+
+func (s *Store) Delete(point uint64) {
+	s.update(func(b *bolt.Bucket) error {
+		key := encode(point)
+		if b.Get(key) == nil {
+			return nil
+		}
+		if err := b.Delete(key); err != nil {
+			return err
+		}
+		s.n--
+		return nil
+	})
+}
+
+func (s *Store) Clear() {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(s.bucket); err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+		_, err := tx.CreateBucket(s.bucket)
+		return err
+	})
+	if err != nil {
+		panic(fmt.Errorf("boltstore: %w", err))
+	}
+	s.n = 0
+}
+
+func (s *Store) Len() int {
+	return s.n
+}
+
+func (s *Store) Min() (point uint64, ok bool) {
+	s.view(func(c *bolt.Cursor) {
+		if k, _ := c.First(); k != nil {
+			point, ok = decode(k), true
+		}
+	})
+	return
+}
+
+func (s *Store) Max() (point uint64, ok bool) {
+	s.view(func(c *bolt.Cursor) {
+		if k, _ := c.Last(); k != nil {
+			point, ok = decode(k), true
+		}
+	})
+	return
+}
+
+func (s *Store) AscendGreaterOrEqual(from uint64, fn func(point uint64) bool) {
+	s.view(func(c *bolt.Cursor) {
+		for k, _ := c.Seek(encode(from)); k != nil; k, _ = c.Next() {
+			if !fn(decode(k)) {
+				return
+			}
+		}
+	})
+}
+
+func (s *Store) DescendLessOrEqual(from uint64, fn func(point uint64) bool) {
+	s.view(func(c *bolt.Cursor) {
+		k, _ := c.Seek(encode(from))
+		// 定位到不大于 from 的最后一个位置
+		if k == nil {
+			k, _ = c.Last()
+		} else if decode(k) > from {
+			k, _ = c.Prev()
+		}
+		for ; k != nil; k, _ = c.Prev() {
+			if !fn(decode(k)) {
+				return
+			}
+		}
+	})
+}
+
+// 副本保存在内存中，Clone、Prepare 等得到的哈希环不占用磁盘
+func (s *Store) Clone() zero.RingStore {
+	c := zero.NewTreeStore()
+	s.AscendGreaterOrEqual(0, func(point uint64) bool {
+		c.Insert(point)
+		return true
+	})
+	return c
+}
+
+func (s *Store) update(fn func(b *bolt.Bucket) error) {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return fn(tx.Bucket(s.bucket))
+	})
+	if err != nil {
+		panic(fmt.Errorf("boltstore: %w", err))
+	}
+}
+
+func (s *Store) view(fn func(c *bolt.Cursor)) {
+	err := s.db.View(func(tx *bolt.Tx) error {
+		fn(tx.Bucket(s.bucket).Cursor())
+		return nil
+	})
+	if err != nil {
+		panic(fmt.Errorf("boltstore: %w", err))
+	}
+}
+
+func encode(point uint64) []byte {
+	return binary.BigEndian.AppendUint64(make([]byte, 0, 8), point)
+}
+
+func decode(key []byte) uint64 {
+	return binary.BigEndian.Uint64(key)
+}
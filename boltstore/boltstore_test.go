@@ -0,0 +1,108 @@
+package boltstore
+
+import (
+	"math"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"consistenthash"
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+func open(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "ring.db"), nil)
+	assert.Nil(t, err)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestStore(t *testing.T) {
+	s := open(t)
+	_, ok := s.Min()
+	assert.False(t, ok)
+
+	for _, p := range []uint64{30, 10, 20, math.MaxUint64, 10} {
+		s.Insert(p)
+	}
+	assert.Equal(t, 4, s.Len())
+	min, _ := s.Min()
+	max, _ := s.Max()
+	assert.Equal(t, uint64(10), min)
+	assert.Equal(t, uint64(math.MaxUint64), max)
+
+	assert.Equal(t, []uint64{20, 30, math.MaxUint64}, ascend(s, 15))
+	assert.Equal(t, []uint64{20, 10}, descend(s, 25))
+	assert.Equal(t, []uint64{20, 10}, descend(s, 20))
+	assert.Equal(t, []uint64(nil), descend(s, 5))
+	assert.Equal(t, []uint64{math.MaxUint64, 30, 20, 10}, descend(s, math.MaxUint64))
+
+	s.Delete(20)
+	s.Delete(25)
+	assert.Equal(t, 3, s.Len())
+	assert.Equal(t, []uint64{30, math.MaxUint64}, ascend(s, 11))
+
+	c := s.Clone()
+	s.Clear()
+	assert.Equal(t, 0, s.Len())
+	assert.Equal(t, 3, c.Len())
+}
+
+func TestReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring.db")
+	s, err := Open(path, nil)
+	assert.Nil(t, err)
+	s.Insert(1)
+	s.Insert(2)
+	assert.Nil(t, s.Close())
+
+	db, err := bolt.Open(path, 0o600, nil)
+	assert.Nil(t, err)
+	defer db.Close()
+	s, err = New(db, defaultBucket)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, s.Len())
+	// 数据库由调用方关闭
+	assert.Nil(t, s.Close())
+}
+
+func TestRing(t *testing.T) {
+	expect := zero.New()
+	ring := zero.New(zero.WithStore(open(t)))
+	for i := 0; i < 10; i++ {
+		node := "10.0.0." + strconv.Itoa(i) + ":6379"
+		expect.Add(node)
+		ring.Add(node)
+	}
+	ring.Remove("10.0.0.3:6379")
+	expect.Remove("10.0.0.3:6379")
+
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		want, _ := expect.Get(key)
+		got, ok := ring.Get(key)
+		assert.True(t, ok)
+		assert.Equal(t, want, got)
+	}
+	assert.Equal(t, expect.Len(), ring.Len())
+}
+
+func ascend(s *Store, from uint64) []uint64 {
+	var points []uint64
+	s.AscendGreaterOrEqual(from, func(p uint64) bool {
+		points = append(points, p)
+		return true
+	})
+	return points
+}
+
+func descend(s *Store, from uint64) []uint64 {
+	var points []uint64
+	s.DescendLessOrEqual(from, func(p uint64) bool {
+		points = append(points, p)
+		return true
+	})
+	return points
+}
@@ -0,0 +1,22 @@
+module consistenthash/boltstore
+
+go 1.23.4
+
+replace consistenthash => ../
+
+require (
+	consistenthash v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.10.0
+	go.etcd.io/bbolt v1.4.0
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/dchest/siphash v1.2.3 // indirect
+	github.com/google/btree v1.1.3 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/spaolacci/murmur3 v1.1.0 // indirect
+	golang.org/x/sys v0.29.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
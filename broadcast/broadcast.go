@@ -0,0 +1,242 @@
+// 通过 Server-Sent Events 把哈希环的成员变化实时推送给浏览器面板或边缘客户端
+// 客户端连接后先收到一次 state 事件（完整的成员），之后每次拓扑变更收到一次 change 事件，
+// 按版本号把 change 应用到 state 上即可维护与服务端一致的成员；断线重连后重新收到 state
+// 消息的格式见 Schema，浏览器中可直接使用 EventSource 订阅
+package broadcast
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"consistenthash"
+)
+
+const (
+	// 每个客户端缓存的未发送事件数量，超出时断开该客户端，由其重连后重新同步
+	clientBuffer = 64
+	// 空闲时发送心跳的间隔，防止代理断开长连接
+	heartbeatInterval = 15 * time.Second
+)
+
+// state 和 change 事件的 JSON Schema
+const Schema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$id": "consistenthash/broadcast",
+  "$defs": {
+    "state": {
+      "type": "object",
+      "required": ["version", "nodes"],
+      "properties": {
+        "version": {"type": "integer", "minimum": 0},
+        "nodes": {
+          "type": "array",
+          "items": {
+            "type": "object",
+            "required": ["node", "replicas"],
+            "properties": {
+              "node": {"type": "string"},
+              "replicas": {"type": "integer", "minimum": 0},
+              "addr": {"type": "string"}
+            }
+          }
+        }
+      }
+    },
+    "change": {
+      "type": "object",
+      "required": ["version", "time", "op", "node"],
+      "properties": {
+        "version": {"type": "integer", "minimum": 0},
+        "time": {"type": "string", "format": "date-time"},
+        "op": {"enum": ["add", "remove"]},
+        "node": {"type": "string"},
+        "replicas": {"type": "integer", "minimum": 0}
+      }
+    }
+  }
+}
+`
+
+type (
+	// 哈希环的完整成员，对应 state 事件
+	State struct {
+		Version uint64      `json:"version"`
+		Nodes   []NodeState `json:"nodes"`
+	}
+
+	NodeState struct {
+		Node     string `json:"node"`
+		Replicas int    `json:"replicas"`
+		Addr     string `json:"addr,omitempty"`
+	}
+
+	// 一次成员变化，对应 change 事件，同一次拓扑变更的多个节点以多个 change 事件发送
+	Change struct {
+		Version  uint64    `json:"version"`
+		Time     time.Time `json:"time"`
+		Op       string    `json:"op"`
+		Node     string    `json:"node"`
+		Replicas int       `json:"replicas,omitempty"`
+	}
+
+	// 推送哈希环变化的 HTTP 处理器
+	Server struct {
+		ring    *zero.ConsistentHash
+		cancel  func()
+		lock    sync.Mutex
+		clients map[chan []byte]struct{}
+		closed  bool
+	}
+)
+
+// 当前的完整成员，节点按字典序排列
+// 先读取版本号再读取成员，成员可能比版本号新，按版本号重复应用 change 事件不影响结果
+func CurrentState(ring *zero.ConsistentHash) State {
+	state := State{Version: ring.Epoch()}
+	snapshot := ring.Snapshot()
+	state.Nodes = make([]NodeState, 0, len(snapshot.Nodes))
+	for node, replicas := range snapshot.Nodes {
+		state.Nodes = append(state.Nodes, NodeState{
+			Node:     node,
+			Replicas: replicas,
+			Addr:     snapshot.Addrs[node],
+		})
+	}
+	sort.Slice(state.Nodes, func(i, j int) bool {
+		return state.Nodes[i].Node < state.Nodes[j].Node
+	})
+	return state
+}
+
+// 创建推送 ring 变化的服务，不再使用时调用 Close
+func NewServer(ring *zero.ConsistentHash) *Server {
+	s := &Server{
+		ring:    ring,
+		clients: make(map[chan []byte]struct{}),
+	}
+	s.cancel = ring.Watch(s.publish)
+	return s
+}
+
+// 以 text/event-stream 推送事件，直到客户端断开或服务关闭
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	// 先订阅再读取成员，不会漏掉期间的变化
+	client, ok := s.subscribe()
+	if !ok {
+		http.Error(w, "server closed", http.StatusServiceUnavailable)
+		return
+	}
+	defer s.unsubscribe(client)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	state, _ := json.Marshal(CurrentState(s.ring))
+	writeEvent(w, "state", state)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			w.Write([]byte(": ping\n\n"))
+			flusher.Flush()
+		case data, ok := <-client:
+			if !ok {
+				return
+			}
+			writeEvent(w, "change", data)
+			flusher.Flush()
+		}
+	}
+}
+
+// 停止推送并断开所有客户端
+func (s *Server) Close() {
+	s.cancel()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.closed = true
+	for client := range s.clients {
+		close(client)
+		delete(s.clients, client)
+	}
+}
+
+func (s *Server) subscribe() (chan []byte, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return nil, false
+	}
+	client := make(chan []byte, clientBuffer)
+	s.clients[client] = struct{}{}
+	return client, true
+}
+
+func (s *Server) unsubscribe(client chan []byte) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.clients[client]; ok {
+		close(client)
+		delete(s.clients, client)
+	}
+}
+
+// 把一批拓扑事件发送给所有客户端
+func (s *Server) publish(events []zero.TopologyEvent) {
+	messages := make([][]byte, 0, len(events))
+	for _, event := range events {
+		change := Change{
+			Version:  event.Version,
+			Time:     event.Time,
+			Op:       "add",
+			Node:     event.Node,
+			Replicas: event.Replicas,
+		}
+		if event.Op == zero.TopologyRemove {
+			change.Op = "remove"
+		}
+		data, _ := json.Marshal(change)
+		messages = append(messages, data)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for client := range s.clients {
+		for _, data := range messages {
+			select {
+			case client <- data:
+			default:
+				// 跟不上的客户端断开，重连后重新同步
+				close(client)
+				delete(s.clients, client)
+			}
+			if _, ok := s.clients[client]; !ok {
+				break
+			}
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event string, data []byte) {
+	w.Write([]byte("event: " + event + "\ndata: "))
+	w.Write(data)
+	w.Write([]byte("\n\n"))
+}
@@ -0,0 +1,85 @@
+package broadcast
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"consistenthash"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer(t *testing.T) {
+	ring := zero.New()
+	ring.AddNode(zero.Node{ID: "a", Addr: "10.0.0.1:80"})
+	server := NewServer(ring)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	r := bufio.NewReader(resp.Body)
+
+	event, data := readEvent(t, r)
+	assert.Equal(t, "state", event)
+	var state State
+	assert.Nil(t, json.Unmarshal(data, &state))
+	assert.Equal(t, []NodeState{{Node: "a", Replicas: 100, Addr: "10.0.0.1:80"}}, state.Nodes)
+	assert.Equal(t, ring.Epoch(), state.Version)
+
+	ring.AddWithWeight("b", 50)
+	ring.Remove("a")
+	var changes []Change
+	for len(changes) < 2 {
+		event, data = readEvent(t, r)
+		assert.Equal(t, "change", event)
+		var change Change
+		assert.Nil(t, json.Unmarshal(data, &change))
+		changes = append(changes, change)
+	}
+	assert.Equal(t, "add", changes[0].Op)
+	assert.Equal(t, "b", changes[0].Node)
+	assert.Equal(t, 50, changes[0].Replicas)
+	assert.Equal(t, "remove", changes[1].Op)
+	assert.Equal(t, "a", changes[1].Node)
+	assert.Equal(t, ring.Epoch(), changes[1].Version)
+	assert.Equal(t, []NodeState{{Node: "b", Replicas: 50}}, CurrentState(ring).Nodes)
+
+	// 关闭后断开客户端，新的连接被拒绝
+	server.Close()
+	_, err = r.ReadString('\n')
+	assert.NotNil(t, err)
+	resp, err = http.Get(ts.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	resp.Body.Close()
+}
+
+func TestSchema(t *testing.T) {
+	var schema map[string]any
+	assert.Nil(t, json.Unmarshal([]byte(Schema), &schema))
+	assert.Contains(t, schema["$defs"], "state")
+	assert.Contains(t, schema["$defs"], "change")
+}
+
+func readEvent(t *testing.T, r *bufio.Reader) (string, []byte) {
+	var event, data string
+	for {
+		line, err := r.ReadString('\n')
+		assert.Nil(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && event != "":
+			return event, []byte(data)
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
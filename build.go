@@ -0,0 +1,62 @@
+package zero
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// 虚拟节点总数达到该值时并行计算虚拟节点位置
+const parallelBuildPoints = 1 << 16
+
+// 重建或批量替换大量节点时，把虚拟节点位置的计算分给 GOMAXPROCS 个协程，合并后只排序一次
+// 自定义的虚拟节点键生成方法不保证并发安全，此时返回 nil，由调用方逐个计算
+// 调用方需持有写锁
+func (h *ConsistentHash) precomputePoints(nodes map[string]int) map[string][]uint64 {
+	if h.appendReplicaKey == nil && h.pointsFunc == nil {
+		return nil
+	}
+	var total int
+	for _, replicas := range nodes {
+		total += replicas
+	}
+	if total < parallelBuildPoints {
+		return nil
+	}
+
+	names := make([]string, 0, len(nodes))
+	for node := range nodes {
+		names = append(names, node)
+	}
+	results := make([][]uint64, len(names))
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for w := min(runtime.GOMAXPROCS(0), len(names)); w > 0; w-- {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(names) {
+					return
+				}
+				results[i] = h.virtualPoints(names[i], nodes[names[i]])
+			}
+		}()
+	}
+	wg.Wait()
+
+	points := make(map[string][]uint64, len(names))
+	for i, node := range names {
+		points[node] = results[i]
+	}
+	return points
+}
+
+// 优先使用预先计算好的虚拟节点位置
+func (h *ConsistentHash) pointsFor(precomputed map[string][]uint64, node string, replicas int) []uint64 {
+	if points, ok := precomputed[node]; ok {
+		return points
+	}
+	return h.virtualPoints(node, replicas)
+}
@@ -0,0 +1,54 @@
+package zero
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParallelBuild(t *testing.T) {
+	nodes := make([]WeightedNode, 0, 1000)
+	for i := 0; i < cap(nodes); i++ {
+		nodes = append(nodes, WeightedNode{Node: "node" + strconv.Itoa(i), Weight: TopWeight})
+	}
+	for _, opts := range [][]Option{nil, {WithLegacyReplicaKeys()}, {WithReplicaSpreading()}} {
+		ch := New(opts...)
+		assert.NotNil(t, ch.precomputePoints(map[string]int{"a": parallelBuildPoints}))
+		ch.ReplaceAll(nodes)
+
+		// 与逐个计算的结果相同
+		expect := make(map[string][]uint64, len(nodes))
+		var all []uint64
+		for _, n := range nodes {
+			expect[n.Node] = ch.virtualPoints(n.Node, minReplicas)
+			all = append(all, expect[n.Node]...)
+		}
+		slices.Sort(all)
+		assert.Equal(t, expect, ch.points)
+		assert.Equal(t, all, ch.sortedPoints())
+
+		restored := New(opts...)
+		restored.Restore(ch.Snapshot())
+		assert.Equal(t, expect, restored.points)
+	}
+
+	// 自定义的虚拟节点键逐个计算
+	custom := New(WithReplicaKeyFunc(DefaultReplicaKey))
+	assert.Nil(t, custom.precomputePoints(map[string]int{"a": parallelBuildPoints}))
+	// 虚拟节点较少时逐个计算
+	assert.Nil(t, New().precomputePoints(map[string]int{"a": minReplicas}))
+}
+
+func BenchmarkReplaceAllLarge(b *testing.B) {
+	nodes := make([]WeightedNode, 5000)
+	for i := range nodes {
+		nodes[i] = WeightedNode{Node: "localhost:" + strconv.Itoa(i)}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		New().ReplaceAll(nodes)
+	}
+}
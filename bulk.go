@@ -0,0 +1,108 @@
+package zero
+
+import (
+	"iter"
+	"sync"
+)
+
+// GetStream 每次持有读锁处理的键数
+const streamBatch = 256
+
+// 批量查找的结果
+type Result struct {
+	Key   string
+	Node  interface{}
+	Found bool
+}
+
+// 批量查找，结果与 keys 一一对应，与逐个调用 Get 的结果一致
+// 整批只加一次读锁并复用哈希缓冲区；批量查找不使用查找缓存，也不上报指标和跟踪
+func (h *ConsistentHash) GetMany(keys []string) []Result {
+	results := make([]Result, len(keys))
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	h.getManyLocked(keys, results, nil)
+	return results
+}
+
+// 用 workers 个 goroutine 并发地批量查找，结果与 GetMany 一致
+// workers 不大于1时退化为 GetMany
+func (h *ConsistentHash) GetManyParallel(keys []string, workers int) []Result {
+	if workers <= 1 || len(keys) < workers {
+		return h.GetMany(keys)
+	}
+
+	results := make([]Result, len(keys))
+	size := (len(keys) + workers - 1) / workers
+	var wg sync.WaitGroup
+	for start := 0; start < len(keys); start += size {
+		end := min(start+size, len(keys))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.lock.RLock()
+			defer h.lock.RUnlock()
+			h.getManyLocked(keys[start:end], results[start:end], nil)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// 流式批量查找，适合键数量很大、无法一次放入内存的回填任务
+// 每 streamBatch 个键加一次读锁，批与批之间允许拓扑变化
+func (h *ConsistentHash) GetStream(keys iter.Seq[string]) iter.Seq[Result] {
+	return func(yield func(Result) bool) {
+		batch := make([]string, 0, streamBatch)
+		results := make([]Result, streamBatch)
+		var buf []byte
+		flush := func() bool {
+			h.lock.RLock()
+			buf = h.getManyLocked(batch, results, buf)
+			h.lock.RUnlock()
+			for _, result := range results[:len(batch)] {
+				if !yield(result) {
+					return false
+				}
+			}
+			batch = batch[:0]
+			return true
+		}
+
+		for key := range keys {
+			batch = append(batch, key)
+			if len(batch) == streamBatch && !flush() {
+				return
+			}
+		}
+		if len(batch) > 0 {
+			flush()
+		}
+	}
+}
+
+// 返回扩容后的缓冲区以便复用
+// 调用方需持有读锁
+func (h *ConsistentHash) getManyLocked(keys []string, results []Result, buf []byte) []byte {
+	for i, key := range keys {
+		results[i] = Result{Key: key}
+		if node, ok := h.pinned(key); ok {
+			results[i].Node, results[i].Found = node, true
+			continue
+		}
+		if len(h.ring) == 0 {
+			continue
+		}
+		if h.proximity != nil {
+			results[i].Node, results[i].Found = h.nearestLocked(key), true
+		} else {
+			buf = append(buf[:0], key...)
+			results[i].Node, results[i].Found = h.locate(h.hashFunc(buf), buf)
+		}
+		if h.spill != nil {
+			results[i].Node = h.spillLocked(key, results[i].Node)
+		}
+	}
+	return buf
+}
@@ -0,0 +1,60 @@
+package zero
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetMany(t *testing.T) {
+	ch := NewConsistentHash()
+	assert.Equal(t, []Result{{Key: "a"}}, ch.GetMany([]string{"a"}))
+
+	for i := 0; i < 10; i++ {
+		ch.Add("10.0.0." + strconv.Itoa(i) + ":6379")
+	}
+	ch.Pin("hot", "10.0.0.3:6379")
+
+	keys := []string{"hot"}
+	for i := 0; i < 1000; i++ {
+		keys = append(keys, strconv.Itoa(i))
+	}
+	expect := make([]Result, len(keys))
+	for i, key := range keys {
+		node, ok := ch.Get(key)
+		expect[i] = Result{Key: key, Node: node, Found: ok}
+	}
+
+	assert.Equal(t, expect, ch.GetMany(keys))
+	assert.Equal(t, expect, ch.GetManyParallel(keys, 4))
+	assert.Equal(t, expect, ch.GetManyParallel(keys, 1))
+	assert.Equal(t, expect, slices.Collect(ch.GetStream(slices.Values(keys))))
+
+	// 提前结束迭代
+	var n int
+	for range ch.GetStream(slices.Values(keys)) {
+		n++
+		if n == 300 {
+			break
+		}
+	}
+	assert.Equal(t, 300, n)
+}
+
+func BenchmarkConsistentHashGetMany(b *testing.B) {
+	ch := NewConsistentHash()
+	for i := 0; i < keySize; i++ {
+		ch.Add("localhost:" + strconv.Itoa(i))
+	}
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ch.GetMany(keys)
+	}
+}
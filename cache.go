@@ -0,0 +1,144 @@
+package zero
+
+import (
+	"container/list"
+	"hash/maphash"
+	"math/bits"
+	"sync"
+)
+
+const (
+	// 缓存分片数量的上限
+	maxCacheShards = 64
+	// 每个分片至少缓存的键数，容量较小时减少分片数量
+	minCacheShardSize = 64
+)
+
+type (
+	// 最近查找结果的缓存，按键的哈希值分片，并发的查找只在同一分片上竞争
+	// 每个分片各自按 LRU 淘汰
+	lookupCache struct {
+		seed   maphash.Seed
+		mask   uint64
+		shards []cacheShard
+	}
+
+	// 一个分片的 LRU 缓存
+	// 记录缓存内容对应的拓扑版本号，拓扑变化后整体失效
+	cacheShard struct {
+		lock    sync.Mutex
+		size    int
+		version uint64
+		items   map[string]*list.Element
+		order   *list.List
+	}
+
+	cacheEntry struct {
+		key  string
+		node interface{}
+	}
+)
+
+// 缓存最近 size 个键的查找结果，size 不大于0时不缓存
+// 适合键重复度高的场景，命中时 Get 只需一次 map 查找，拓扑变化后缓存自动失效
+// 只有 Get 使用缓存；容量较大时缓存按键分片，命中时并发的查找之间很少竞争
+func WithLookupCache(size int) Option {
+	return func(h *ConsistentHash) {
+		if size > 0 {
+			h.cache = newLookupCache(size)
+		} else {
+			h.cache = nil
+		}
+	}
+}
+
+// 分片数量取2的幂，各分片平分容量
+func newLookupCache(size int) *lookupCache {
+	n := 1 << (bits.Len(uint(min(max(size/minCacheShardSize, 1), maxCacheShards))) - 1)
+	c := &lookupCache{
+		seed:   maphash.MakeSeed(),
+		mask:   uint64(n - 1),
+		shards: make([]cacheShard, n),
+	}
+	for i := range c.shards {
+		shardSize := size / n
+		if i < size%n {
+			shardSize++
+		}
+		c.shards[i] = cacheShard{
+			size:  shardSize,
+			items: make(map[string]*list.Element, shardSize),
+			order: list.New(),
+		}
+	}
+	return c
+}
+
+func (c *lookupCache) shard(key string) *cacheShard {
+	if c.mask == 0 {
+		return &c.shards[0]
+	}
+	return &c.shards[maphash.String(c.seed, key)&c.mask]
+}
+
+func (c *lookupCache) get(key string, version uint64) (interface{}, bool) {
+	return c.shard(key).get(key, version)
+}
+
+func (c *lookupCache) add(key string, node interface{}, version uint64) {
+	c.shard(key).add(key, node, version)
+}
+
+func (c *lookupCache) len() int {
+	var n int
+	for i := range c.shards {
+		n += c.shards[i].len()
+	}
+	return n
+}
+
+func (c *cacheShard) get(key string, version uint64) (interface{}, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.version != version {
+		return nil, false
+	}
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).node, true
+}
+
+func (c *cacheShard) add(key string, node interface{}, version uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.version != version {
+		// 旧版本的查找结果全部作废
+		c.version = version
+		c.items = make(map[string]*list.Element, c.size)
+		c.order.Init()
+	}
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*cacheEntry).node = node
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&cacheEntry{key: key, node: node})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func (c *cacheShard) len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.order.Len()
+}
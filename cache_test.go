@@ -0,0 +1,120 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithLookupCache(t *testing.T) {
+	ch := New(WithLookupCache(2))
+	plain := New()
+	for _, node := range []string{"first", "second", "third"} {
+		ch.Add(node)
+		plain.Add(node)
+	}
+
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i % 5)
+		expect, _ := plain.Get(key)
+		actual, _ := ch.Get(key)
+		assert.Equal(t, expect, actual)
+	}
+	assert.Equal(t, 2, ch.cache.len())
+
+	// 拓扑变化后缓存失效
+	key := "0"
+	cached, _ := ch.Get(key)
+	ch.Remove(cached.(string))
+	plain.Remove(cached.(string))
+	actual, _ := ch.Get(key)
+	expect, _ := plain.Get(key)
+	assert.Equal(t, expect, actual)
+	assert.NotEqual(t, cached, actual)
+	assert.Equal(t, 1, ch.cache.len())
+
+	// 固定路由优先于缓存
+	ch.Pin(key, cached.(string))
+	ch.Add(cached.(string))
+	actual, _ = ch.Get(key)
+	assert.Equal(t, cached, actual)
+
+	assert.Nil(t, New(WithLookupCache(0)).cache)
+}
+
+func TestLookupCacheEviction(t *testing.T) {
+	c := newLookupCache(2)
+	c.add("a", "x", 1)
+	c.add("b", "y", 1)
+	_, ok := c.get("a", 1)
+	assert.True(t, ok)
+	c.add("c", "z", 1)
+
+	// b 最久未使用，被淘汰
+	_, ok = c.get("b", 1)
+	assert.False(t, ok)
+	node, ok := c.get("a", 1)
+	assert.True(t, ok)
+	assert.Equal(t, "x", node)
+	_, ok = c.get("a", 2)
+	assert.False(t, ok)
+}
+
+func TestLookupCacheShards(t *testing.T) {
+	assert.Equal(t, 1, len(newLookupCache(2).shards))
+	assert.Equal(t, 2, len(newLookupCache(3*minCacheShardSize).shards))
+	c := newLookupCache(1 << 20)
+	assert.Equal(t, maxCacheShards, len(c.shards))
+
+	// 各分片平分容量，总数不超过 size
+	c = newLookupCache(1000)
+	var total int
+	for i := range c.shards {
+		total += c.shards[i].size
+	}
+	assert.Equal(t, 1000, total)
+	for i := 0; i < 5000; i++ {
+		c.add(strconv.Itoa(i), "x", 1)
+	}
+	assert.True(t, c.len() <= 1000)
+	node, ok := c.get("4999", 1)
+	assert.True(t, ok)
+	assert.Equal(t, "x", node)
+}
+
+func BenchmarkConsistentHashGetCachedParallel(b *testing.B) {
+	ch := New(WithLookupCache(1024))
+	for i := 0; i < keySize; i++ {
+		ch.Add("localhost:" + strconv.Itoa(i))
+	}
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		var i int
+		for pb.Next() {
+			ch.Get(keys[i%len(keys)])
+			i++
+		}
+	})
+}
+
+func BenchmarkConsistentHashGetCached(b *testing.B) {
+	ch := New(WithLookupCache(1024))
+	for i := 0; i < keySize; i++ {
+		ch.Add("localhost:" + strconv.Itoa(i))
+	}
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ch.Get(keys[i%len(keys)])
+	}
+}
@@ -0,0 +1,46 @@
+package zero
+
+// 按优先级排列的候选节点
+type Candidate struct {
+	Node interface{}
+	// 节点的虚拟节点数量，即权重
+	Replicas int
+	// 权重在所有候选节点中的占比，全部候选的占比之和为1
+	Share float64
+}
+
+// 键的前 n 个候选节点，第一个与 Get 的结果一致，其余按沿哈希环顺时针的顺序排列
+// 调用方可以按 Share 做概率性的溢出，或按顺序重试
+// 节点不足 n 个时返回全部节点
+func (h *ConsistentHash) GetCandidates(key string, n int) []Candidate {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.getCandidatesLocked(key, n)
+}
+
+// 调用方需持有读锁
+func (h *ConsistentHash) getCandidatesLocked(key string, n int) []Candidate {
+	if n <= 0 || len(h.ring) == 0 {
+		return nil
+	}
+
+	n = min(n, len(h.nodes))
+	candidates := make([]Candidate, 0, n)
+	seen := make(map[string]struct{}, n)
+	var total int
+	h.walk(key, func(node string) bool {
+		if _, ok := seen[node]; ok {
+			return true
+		}
+		seen[node] = struct{}{}
+		replicas := h.nodes[node]
+		total += replicas
+		candidates = append(candidates, Candidate{Node: node, Replicas: replicas})
+		return len(candidates) < n
+	})
+
+	for i := range candidates {
+		candidates[i].Share = float64(candidates[i].Replicas) / float64(total)
+	}
+	return candidates
+}
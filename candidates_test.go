@@ -0,0 +1,84 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCandidates(t *testing.T) {
+	ch := NewConsistentHash()
+	assert.Nil(t, ch.GetCandidates("any", 2))
+
+	for i := 0; i < 4; i++ {
+		ch.Add("10.0.0." + strconv.Itoa(i) + ":6379")
+	}
+	ch.AddWithWeight("10.0.0.4:6379", 50)
+
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		candidates := ch.GetCandidates(key, 3)
+		assert.Equal(t, 3, len(candidates))
+		node, _ := ch.Get(key)
+		assert.Equal(t, node, candidates[0].Node)
+
+		var share float64
+		seen := make(map[interface{}]bool)
+		for _, c := range candidates {
+			assert.False(t, seen[c.Node])
+			seen[c.Node] = true
+			assert.Equal(t, ch.ReplicaCount(c.Node.(string)), c.Replicas)
+			share += c.Share
+		}
+		assert.InDelta(t, 1, share, 1e-9)
+	}
+
+	all := ch.GetCandidates("any", 10)
+	assert.Equal(t, 5, len(all))
+	for _, c := range all {
+		expect := 100. / 450
+		if c.Node == "10.0.0.4:6379" {
+			expect = 50. / 450
+		}
+		assert.InDelta(t, expect, c.Share, 1e-9)
+	}
+	assert.Nil(t, ch.GetCandidates("any", 0))
+}
+
+func TestGetCandidatesProximity(t *testing.T) {
+	ch := New(WithProximity(func(node string) int {
+		if node == "near" {
+			return 0
+		}
+		return 1
+	}))
+	plain := NewConsistentHash()
+	for _, node := range []string{"near", "far1", "far2", "far3"} {
+		ch.Add(node)
+		plain.Add(node)
+	}
+	ch.Pin("pinned", "far1")
+
+	var promoted int
+	for i := 0; i < requestSize; i++ {
+		key := strconv.Itoa(i)
+		node, _ := ch.Get(key)
+		candidates := ch.GetCandidates(key, 2)
+		assert.Equal(t, 2, len(candidates))
+		assert.Equal(t, node, candidates[0].Node)
+		assert.NotEqual(t, candidates[0].Node, candidates[1].Node)
+		assert.InDelta(t, 1, candidates[0].Share+candidates[1].Share, 1e-9)
+
+		// 被提前的节点之后仍按环上的顺序排列
+		ring := plain.GetCandidates(key, 2)
+		if ring[0].Node != node {
+			promoted++
+			assert.Equal(t, ring[0].Node, candidates[1].Node)
+		}
+	}
+	assert.True(t, promoted > 0)
+
+	node, _ := ch.Get("pinned")
+	assert.Equal(t, node, ch.GetCandidates("pinned", 2)[0].Node)
+}
@@ -0,0 +1,111 @@
+package zero
+
+import (
+	"math"
+	"sort"
+)
+
+// 节点声明的资源容量
+type capacity struct {
+	cpu   float64
+	memGB float64
+}
+
+// 按资源容量添加节点，虚拟节点数量由容量相对集群的比例决定
+// 节点的权重为其 CPU 和内存分别占集群平均值的倍数的平均，平均容量的节点得到放大因子个虚拟节点
+// 集群成员变化时重新计算所有按容量添加的节点的权重，以普通方式添加的节点不参与计算
+// 容量为负数、非有限值或全部为0时忽略
+func (h *ConsistentHash) AddWithCapacity(node string, cpu, memGB float64) {
+	if !validCapacity(cpu) || !validCapacity(memGB) || cpu+memGB == 0 {
+		return
+	}
+
+	h.lock.Lock()
+	h.clearTTLLocked(node)
+	h.clearDrainLocked(node)
+	if h.capacities == nil {
+		h.capacities = make(map[string]capacity)
+	}
+	h.capacities[node] = capacity{cpu: cpu, memGB: memGB}
+	replicas := h.capacityReplicasLocked()[node]
+	// 其他节点的权重在收尾时重新计算
+	err := h.addWithReplicasLocked(node, replicas)
+	replicas = h.nodes[node]
+	h.lock.Unlock()
+
+	if err == nil {
+		h.nodeAdded(node, replicas)
+	}
+}
+
+func validCapacity(v float64) bool {
+	return v >= 0 && !math.IsInf(v, 1)
+}
+
+// 各个按容量添加的节点应得的虚拟节点数量
+// 调用方需持有读锁
+func (h *ConsistentHash) capacityReplicasLocked() map[string]int {
+	var cpu, mem float64
+	for _, c := range h.capacities {
+		cpu += c.cpu
+		mem += c.memGB
+	}
+
+	n := float64(len(h.capacities))
+	replicas := make(map[string]int, len(h.capacities))
+	for node, c := range h.capacities {
+		// 集群中没有声明的资源不参与计算
+		var score, dims float64
+		if cpu > 0 {
+			score += c.cpu * n / cpu
+			dims++
+		}
+		if mem > 0 {
+			score += c.memGB * n / mem
+			dims++
+		}
+		replicas[node] = max(int(math.Round(float64(h.replicas)*score/dims)), 1)
+	}
+	return replicas
+}
+
+// 成员变化后重新计算按容量添加的节点的虚拟节点数量
+// 调用方需持有写锁
+func (h *ConsistentHash) deriveCapacityLocked() {
+	if len(h.capacities) == 0 {
+		return
+	}
+
+	for node := range h.capacities {
+		if !h.containsNode(node) {
+			delete(h.capacities, node)
+		}
+	}
+	replicas := h.capacityReplicasLocked()
+	nodes := make([]string, 0, len(replicas))
+	for node, n := range replicas {
+		if h.nodes[node] != n {
+			nodes = append(nodes, node)
+		}
+	}
+	if len(nodes) == 0 {
+		return
+	}
+
+	// 固定处理顺序，使顺延的冲突位置可复现
+	sort.Strings(nodes)
+	for _, node := range nodes {
+		h.removeLocked(node)
+		// 重新计算时不能丢弃已有节点，冲突时退化为共享位置
+		if err := h.addLocked(node, replicas[node]); err != nil {
+			h.insertLocked(node, replicas[node], h.virtualPoints(node, replicas[node]))
+		}
+	}
+	h.sortKeys()
+}
+
+// 节点改为以普通方式添加，不再按容量计算权重
+// 调用方需持有写锁
+func (h *ConsistentHash) clearCapacityLocked(node string) {
+	delete(h.capacities, node)
+}
@@ -0,0 +1,100 @@
+package zero
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddWithCapacity(t *testing.T) {
+	ch := NewConsistentHash()
+	ch.AddWithCapacity("a", 8, 32)
+	// 唯一的节点即为平均容量
+	assert.Equal(t, minReplicas, ch.ReplicaCount("a"))
+
+	ch.AddWithCapacity("b", 8, 32)
+	ch.AddWithCapacity("c", 16, 64)
+	// 平均容量为 (32/3, 128/3)
+	assert.Equal(t, 75, ch.ReplicaCount("a"))
+	assert.Equal(t, 75, ch.ReplicaCount("b"))
+	assert.Equal(t, 150, ch.ReplicaCount("c"))
+
+	// 成员变化后重新计算
+	ch.Remove("c")
+	assert.Equal(t, minReplicas, ch.ReplicaCount("a"))
+	assert.Equal(t, minReplicas, ch.ReplicaCount("b"))
+}
+
+func TestAddWithCapacityMixedResources(t *testing.T) {
+	ch := NewConsistentHash()
+	ch.AddWithCapacity("cpu", 4, 8)
+	ch.AddWithCapacity("mem", 4, 24)
+	// CPU 相同，内存分别为平均值的 0.5 和 1.5 倍
+	assert.Equal(t, 75, ch.ReplicaCount("cpu"))
+	assert.Equal(t, 125, ch.ReplicaCount("mem"))
+
+	// 集群中都没有声明内存时只按 CPU 计算
+	ch = NewConsistentHash()
+	ch.AddWithCapacity("small", 2, 0)
+	ch.AddWithCapacity("large", 6, 0)
+	assert.Equal(t, 50, ch.ReplicaCount("small"))
+	assert.Equal(t, 150, ch.ReplicaCount("large"))
+}
+
+func TestAddWithCapacityPlainNodes(t *testing.T) {
+	ch := NewConsistentHash()
+	ch.Add("plain")
+	ch.AddWithCapacity("a", 1, 1)
+	ch.AddWithCapacity("b", 3, 3)
+	assert.Equal(t, minReplicas, ch.ReplicaCount("plain"))
+	assert.Equal(t, 50, ch.ReplicaCount("a"))
+	assert.Equal(t, 150, ch.ReplicaCount("b"))
+
+	// 以普通方式重新添加后不再参与计算
+	ch.AddWithWeight("b", TopWeight)
+	assert.Equal(t, minReplicas, ch.ReplicaCount("a"))
+	assert.Equal(t, minReplicas, ch.ReplicaCount("b"))
+	ch.Remove("plain")
+	assert.Equal(t, minReplicas, ch.ReplicaCount("a"))
+}
+
+func TestAddWithCapacityTTL(t *testing.T) {
+	ch := NewConsistentHash()
+	ch.AddWithCapacity("a", 1, 1)
+	ch.AddWithCapacity("b", 3, 3)
+
+	// 改为临时节点后立即按剩余的容量节点重新计算
+	ch.AddWithTTL("b", time.Hour)
+	assert.Equal(t, minReplicas, ch.ReplicaCount("a"))
+	assert.Equal(t, minReplicas, ch.ReplicaCount("b"))
+}
+
+func TestAddWithCapacityInvalid(t *testing.T) {
+	ch := NewConsistentHash()
+	for _, c := range [][2]float64{{0, 0}, {-1, 4}, {4, math.NaN()}, {math.Inf(1), 1}} {
+		ch.AddWithCapacity("invalid", c[0], c[1])
+		assert.False(t, ch.Contains("invalid"))
+	}
+}
+
+func TestAddWithCapacityRestore(t *testing.T) {
+	ch := NewConsistentHash()
+	ch.AddWithCapacity("a", 1, 1)
+	ch.AddWithCapacity("b", 3, 3)
+	ch.Restore(ch.Snapshot())
+
+	// 恢复后容量信息清除，虚拟节点数量保持快照中的值
+	ch.Remove("b")
+	assert.Equal(t, 50, ch.ReplicaCount("a"))
+}
+
+func TestAddWithCapacityClone(t *testing.T) {
+	ch := NewConsistentHash()
+	ch.AddWithCapacity("a", 1, 1)
+	c := ch.Clone()
+	c.AddWithCapacity("b", 3, 3)
+	assert.Equal(t, 50, c.ReplicaCount("a"))
+	assert.Equal(t, minReplicas, ch.ReplicaCount("a"))
+}
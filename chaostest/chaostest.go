@@ -0,0 +1,244 @@
+// 哈希环的混沌测试
+// 一个协程随机增删节点，同时多个协程并发查找，检查并发下的不变量：
+// 不发生 panic、返回的节点总在成员中、拓扑变化时键的迁移满足单调性
+// 既用于本仓库各实现的测试，也可用于验证下游的自定义实现
+package chaostest
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"consistenthash/chashtest"
+)
+
+const (
+	defaultNodes   = 16
+	defaultSteps   = 200
+	defaultReaders = 8
+	defaultKeys    = 1000
+)
+
+type (
+	// 混沌测试的参数，零值字段使用默认值
+	Config struct {
+		// 随机数种子，相同的种子得到相同的拓扑变更序列
+		Seed int64
+		// 候选节点的数量，初始时一半在环上，默认16
+		Nodes int
+		// 拓扑变更的次数，默认200
+		Steps int
+		// 并发查找的协程数，默认8
+		Readers int
+		// 检查迁移时使用的键数量，默认1000
+		Keys int
+		// 允许违反单调性的键比例，默认为0即严格单调
+		// Maglev 等近似一致的实现需设置一个较小的容忍度
+		Tolerance float64
+	}
+
+	// 一次混沌测试的统计
+	Report struct {
+		Adds    int
+		Removes int
+		// 并发查找的总次数
+		Lookups int64
+		// 违反单调性的迁移次数
+		Violations int
+	}
+
+	// 按拓扑版本记录的成员，供查找协程校验返回的节点
+	membership struct {
+		lock    sync.RWMutex
+		history []map[string]bool
+		epoch   atomic.Int64
+	}
+)
+
+// 对 ring 执行混沌测试，违反不变量时通过 t 报告错误
+// ring 初始应为空
+func Run(t testing.TB, ring chashtest.Ring, cfg Config) Report {
+	t.Helper()
+	cfg = cfg.withDefaults()
+
+	var (
+		report  Report
+		members = &membership{history: []map[string]bool{{}}}
+		rnd     = rand.New(rand.NewSource(cfg.Seed))
+		nodes   = make([]string, cfg.Nodes)
+		keys    = chashtest.Keys(cfg.Keys)
+	)
+	for i := range nodes {
+		nodes[i] = "chaos-" + strconv.Itoa(i)
+	}
+	for _, node := range nodes[:(cfg.Nodes+1)/2] {
+		members.add(node)
+		ring.Add(node)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		done    = make(chan struct{})
+		lookups atomic.Int64
+		errs    = make(chan error, cfg.Readers)
+	)
+	for i := 0; i < cfg.Readers; i++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			errs <- read(ring, members, keys, seed, done, &lookups)
+		}(cfg.Seed + int64(i) + 1)
+	}
+
+	before := chashtest.Assign(ring, keys)
+	for step := 0; step < cfg.Steps; step++ {
+		node := nodes[rnd.Intn(len(nodes))]
+		current := members.current()
+		var violations int
+		switch {
+		case !current[node]:
+			members.add(node)
+			ring.Add(node)
+			report.Adds++
+			after := chashtest.Assign(ring, keys)
+			violations = movedExcept(before, after, func(_, to string) bool { return to == node })
+			before = after
+		case len(current) > 1:
+			ring.Remove(node)
+			members.remove(node)
+			report.Removes++
+			after := chashtest.Assign(ring, keys)
+			violations = movedExcept(before, after, func(from, _ string) bool { return from == node })
+			before = after
+		default:
+			continue
+		}
+
+		report.Violations += violations
+		if ratio := float64(violations) / float64(len(keys)); ratio > cfg.Tolerance {
+			t.Errorf("step %d: %d/%d keys moved between surviving nodes, tolerance %.4f",
+				step, violations, len(keys), cfg.Tolerance)
+		}
+	}
+	close(done)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	report.Lookups = lookups.Load()
+	return report
+}
+
+func (c Config) withDefaults() Config {
+	if c.Nodes <= 0 {
+		c.Nodes = defaultNodes
+	}
+	if c.Steps <= 0 {
+		c.Steps = defaultSteps
+	}
+	if c.Readers <= 0 {
+		c.Readers = defaultReaders
+	}
+	if c.Keys <= 0 {
+		c.Keys = defaultKeys
+	}
+	return c
+}
+
+// 持续查找直到 done 关闭，返回第一个违反不变量的错误
+func read(ring chashtest.Ring, members *membership, keys []string, seed int64,
+	done <-chan struct{}, lookups *atomic.Int64) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic during Get: %v", p)
+		}
+	}()
+
+	rnd := rand.New(rand.NewSource(seed))
+	for {
+		select {
+		case <-done:
+			return nil
+		default:
+		}
+
+		key := keys[rnd.Intn(len(keys))]
+		from := members.epoch.Load()
+		node, ok := ring.Get(key)
+		to := members.epoch.Load()
+		lookups.Add(1)
+		if !ok {
+			return fmt.Errorf("key %q has no owner", key)
+		}
+		name := fmt.Sprint(node)
+		if !members.containsBetween(name, from, to) {
+			return fmt.Errorf("key %q routed to %q, which was not a member", key, name)
+		}
+	}
+}
+
+// 统计迁移了但不被 allowed 允许的键数量
+func movedExcept(before, after chashtest.Assignment, allowed func(from, to string) bool) int {
+	var moved int
+	for key, from := range before {
+		if to := after[key]; to != from && !allowed(from, to) {
+			moved++
+		}
+	}
+	return moved
+}
+
+// 当前的成员
+func (m *membership) current() map[string]bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.history[len(m.history)-1]
+}
+
+// 新节点在加入哈希环之前登记，删除的节点在离开哈希环之后注销
+// 因此查找期间哈希环可能返回的节点总在这段时间的某个版本中
+func (m *membership) add(node string) {
+	m.update(func(members map[string]bool) {
+		members[node] = true
+	})
+}
+
+func (m *membership) remove(node string) {
+	m.update(func(members map[string]bool) {
+		delete(members, node)
+	})
+}
+
+func (m *membership) update(fn func(members map[string]bool)) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	last := m.history[len(m.history)-1]
+	next := make(map[string]bool, len(last)+1)
+	for node := range last {
+		next[node] = true
+	}
+	fn(next)
+	m.history = append(m.history, next)
+	m.epoch.Store(int64(len(m.history) - 1))
+}
+
+// 节点是否在 [from, to] 之间的某个版本中
+func (m *membership) containsBetween(node string, from, to int64) bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	for epoch := from; epoch <= to; epoch++ {
+		if m.history[epoch][node] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package chaostest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"consistenthash"
+	"consistenthash/chashtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRing(t *testing.T) {
+	report := Run(t, zero.NewConsistentHash(), Config{Seed: 1})
+	assert.Equal(t, 0, report.Violations)
+	assert.True(t, report.Adds > 0)
+	assert.True(t, report.Removes > 0)
+	assert.True(t, report.Lookups > 0)
+}
+
+func TestTreeStore(t *testing.T) {
+	Run(t, zero.New(zero.WithTreeStore()), Config{Seed: 2, Steps: 50})
+}
+
+func TestRendezvous(t *testing.T) {
+	Run(t, zero.NewRendezvousHash(), Config{Seed: 3, Steps: 50})
+}
+
+func TestMaglev(t *testing.T) {
+	Run(t, zero.NewMaglevHash(), Config{Seed: 4, Steps: 50, Tolerance: 0.1})
+}
+
+func TestDetectsStaleMember(t *testing.T) {
+	var r recorder
+	Run(&r, &staleRing{Ring: zero.NewConsistentHash()}, Config{Seed: 5, Steps: 20, Readers: 2})
+	assert.NotEmpty(t, r.errors)
+}
+
+func TestDetectsNonMonotonic(t *testing.T) {
+	var r recorder
+	report := Run(&r, &reshuffleRing{}, Config{Seed: 6, Steps: 20, Readers: 1})
+	assert.True(t, report.Violations > 0)
+	assert.NotEmpty(t, r.errors)
+}
+
+// 删除节点后仍会返回它的错误实现
+type staleRing struct {
+	chashtest.Ring
+	lock    sync.RWMutex
+	removed string
+}
+
+func (r *staleRing) Remove(node string) {
+	r.Ring.Remove(node)
+	r.lock.Lock()
+	r.removed = node
+	r.lock.Unlock()
+}
+
+func (r *staleRing) Get(v string) (interface{}, bool) {
+	r.lock.RLock()
+	removed := r.removed
+	r.lock.RUnlock()
+	if removed != "" {
+		return removed, true
+	}
+	return r.Ring.Get(v)
+}
+
+// 取模分配，任何拓扑变化都会打乱大部分键
+type reshuffleRing struct {
+	lock  sync.RWMutex
+	nodes []string
+}
+
+func (r *reshuffleRing) Add(node string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.nodes = append(r.nodes, node)
+}
+
+func (r *reshuffleRing) Remove(node string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for i, n := range r.nodes {
+		if n == node {
+			r.nodes = append(r.nodes[:i:i], r.nodes[i+1:]...)
+			return
+		}
+	}
+}
+
+func (r *reshuffleRing) Get(v string) (interface{}, bool) {
+	r.lock.RLock()
+	nodes := r.nodes
+	r.lock.RUnlock()
+	if len(nodes) == 0 {
+		return nil, false
+	}
+	var sum int
+	for _, c := range v {
+		sum += int(c)
+	}
+	return nodes[sum%len(nodes)], true
+}
+
+// 收集错误而不使外层测试失败
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Error(args ...any) {
+	r.errors = append(r.errors, fmt.Sprint(args...))
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,107 @@
+package chashtest
+
+import (
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"consistenthash"
+)
+
+type (
+	// 手动推进的时钟，配合 zero.WithClock 测试 TTL、摘除和计划变更等功能而无需等待
+	// 定时任务在 Advance 中同步执行
+	FakeClock struct {
+		lock   sync.Mutex
+		now    time.Time
+		timers []*fakeTimer
+	}
+
+	fakeTimer struct {
+		clock *FakeClock
+		when  time.Time
+		f     func()
+	}
+
+	// 固定种子的随机数来源，配合 zero.WithRand 使用
+	FakeRand struct {
+		lock sync.Mutex
+		rnd  *rand.Rand
+	}
+)
+
+var (
+	_ zero.Clock = (*FakeClock)(nil)
+	_ zero.Rand  = (*FakeRand)(nil)
+)
+
+// 从 start 开始的手动时钟
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) zero.Timer {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	t := &fakeTimer{clock: c, when: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// 推进时间，按到期时间依次同步执行到期的定时任务
+// 定时任务中新安排的任务在推进后的时间之内到期时同样执行
+func (c *FakeClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	end := c.now.Add(d)
+	c.lock.Unlock()
+
+	for {
+		c.lock.Lock()
+		sort.SliceStable(c.timers, func(i, j int) bool {
+			return c.timers[i].when.Before(c.timers[j].when)
+		})
+		if len(c.timers) == 0 || c.timers[0].when.After(end) {
+			c.now = end
+			c.lock.Unlock()
+			return
+		}
+		t := c.timers[0]
+		c.timers = c.timers[1:]
+		if t.when.After(c.now) {
+			c.now = t.when
+		}
+		c.lock.Unlock()
+
+		t.f()
+	}
+}
+
+func (t *fakeTimer) Stop() bool {
+	c := t.clock
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for i, timer := range c.timers {
+		if timer == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func NewFakeRand(seed uint64) *FakeRand {
+	return &FakeRand{rnd: rand.New(rand.NewPCG(seed, seed))}
+}
+
+func (r *FakeRand) Float64() float64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.rnd.Float64()
+}
@@ -0,0 +1,69 @@
+package chashtest
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"consistenthash"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClock(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	ring := zero.New(zero.WithClock(clock))
+	ring.Add("static")
+	ring.AddWithTTL("ephemeral", 10*time.Second)
+	ring.ScheduleRemove("static", time.Unix(30, 0))
+
+	clock.Advance(9 * time.Second)
+	assert.True(t, ring.Contains("ephemeral"))
+	clock.Advance(time.Second)
+	assert.False(t, ring.Contains("ephemeral"))
+	assert.Equal(t, time.Unix(10, 0), clock.Now())
+
+	clock.Advance(time.Minute)
+	assert.False(t, ring.Contains("static"))
+	assert.Equal(t, time.Unix(70, 0), clock.Now())
+
+	// 停止的定时任务不再执行
+	var fired bool
+	timer := clock.AfterFunc(time.Second, func() { fired = true })
+	assert.True(t, timer.Stop())
+	assert.False(t, timer.Stop())
+	clock.Advance(time.Second)
+	assert.False(t, fired)
+}
+
+func TestFakeClockChained(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	var fired []time.Time
+	var tick func()
+	tick = func() {
+		fired = append(fired, clock.Now())
+		clock.AfterFunc(time.Second, tick)
+	}
+	clock.AfterFunc(time.Second, tick)
+
+	// 执行中安排的任务在同一次推进内到期时也会执行
+	clock.Advance(3 * time.Second)
+	assert.Equal(t, []time.Time{time.Unix(1, 0), time.Unix(2, 0), time.Unix(3, 0)}, fired)
+}
+
+func TestFakeRand(t *testing.T) {
+	sampled := func() uint64 {
+		ring := zero.New(zero.WithRand(NewFakeRand(1)))
+		ring.Add("a")
+		other := zero.NewConsistentHash()
+		other.Add("b")
+		ring.ShadowCompare(other, 0.5)
+		for i := 0; i < 100; i++ {
+			ring.Get(strconv.Itoa(i))
+		}
+		return ring.ShadowStats().Sampled
+	}
+	// 相同的种子得到相同的抽样
+	n := sampled()
+	assert.Equal(t, n, sampled())
+	assert.InDelta(t, 50, n, 20)
+}
@@ -0,0 +1,201 @@
+package chashtest
+
+import (
+	"sort"
+	"sync"
+
+	"consistenthash"
+)
+
+// 被测的哈希环
+// *zero.ConsistentHash 及其他实现了增删查的结构均可使用
+type Ring interface {
+	Add(node string)
+	Remove(node string)
+	Get(v string) (interface{}, bool)
+}
+
+var _ Ring = (*zero.ConsistentHash)(nil)
+
+// 健康状态变更
+type Transition struct {
+	Node    string
+	Healthy bool
+}
+
+// 按顺序执行的健康状态变更脚本
+type Script []Transition
+
+// 模拟的服务发现源
+// 维护注册节点及其健康状态，变更时通知所有订阅者
+type FakeDiscovery struct {
+	lock     sync.Mutex
+	nodes    map[string]bool
+	watchers []func(nodes []string)
+}
+
+func NewFakeDiscovery(nodes ...string) *FakeDiscovery {
+	d := &FakeDiscovery{
+		nodes: make(map[string]bool),
+	}
+	for _, node := range nodes {
+		d.nodes[node] = true
+	}
+	return d
+}
+
+// 注册节点，新注册的节点默认健康
+func (d *FakeDiscovery) Register(node string) {
+	d.update(func() {
+		d.nodes[node] = true
+	})
+}
+
+// 注销节点
+func (d *FakeDiscovery) Deregister(node string) {
+	d.update(func() {
+		delete(d.nodes, node)
+	})
+}
+
+// 设置节点健康状态，未注册的节点会被忽略
+func (d *FakeDiscovery) SetHealthy(node string, healthy bool) {
+	d.update(func() {
+		if _, ok := d.nodes[node]; ok {
+			d.nodes[node] = healthy
+		}
+	})
+}
+
+// 当前健康节点列表，按字典序排列
+func (d *FakeDiscovery) Healthy() []string {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.healthy()
+}
+
+// 订阅健康节点列表
+// 订阅时会立即回调一次当前列表
+func (d *FakeDiscovery) Watch(fn func(nodes []string)) {
+	d.lock.Lock()
+	d.watchers = append(d.watchers, fn)
+	nodes := d.healthy()
+	d.lock.Unlock()
+
+	fn(nodes)
+}
+
+// 按顺序执行脚本，每执行一步回调一次
+// fn 可为 nil
+func (d *FakeDiscovery) Play(script Script, fn func(step int, tr Transition)) {
+	for i, tr := range script {
+		d.SetHealthy(tr.Node, tr.Healthy)
+		if fn != nil {
+			fn(i, tr)
+		}
+	}
+}
+
+func (d *FakeDiscovery) update(fn func()) {
+	d.lock.Lock()
+	fn()
+	nodes := d.healthy()
+	watchers := make([]func(nodes []string), len(d.watchers))
+	copy(watchers, d.watchers)
+	d.lock.Unlock()
+
+	// 在锁外通知，避免订阅者回调中再次访问发现源导致死锁
+	for _, watcher := range watchers {
+		watcher(nodes)
+	}
+}
+
+func (d *FakeDiscovery) healthy() []string {
+	nodes := make([]string, 0, len(d.nodes))
+	for node, healthy := range d.nodes {
+		if healthy {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// 模拟集群
+// 发现源中健康节点的变化会同步到哈希环
+type Cluster struct {
+	Discovery *FakeDiscovery
+	Ring      Ring
+
+	lock    sync.Mutex
+	members map[string]struct{}
+}
+
+// 创建集群，ring 为 nil 时使用默认的一致性哈希
+func NewCluster(ring Ring, nodes ...string) *Cluster {
+	if ring == nil {
+		ring = zero.NewConsistentHash()
+	}
+
+	c := &Cluster{
+		Discovery: NewFakeDiscovery(nodes...),
+		Ring:      ring,
+		members:   make(map[string]struct{}),
+	}
+	c.Discovery.Watch(c.sync)
+	return c
+}
+
+// 节点加入集群
+func (c *Cluster) Join(node string) {
+	c.Discovery.Register(node)
+}
+
+// 节点离开集群
+func (c *Cluster) Leave(node string) {
+	c.Discovery.Deregister(node)
+}
+
+// 节点故障
+func (c *Cluster) Fail(node string) {
+	c.Discovery.SetHealthy(node, false)
+}
+
+// 节点恢复
+func (c *Cluster) Recover(node string) {
+	c.Discovery.SetHealthy(node, true)
+}
+
+// 当前在环上的节点，按字典序排列
+func (c *Cluster) Members() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	nodes := make([]string, 0, len(c.members))
+	for node := range c.members {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// 根据最新的健康节点列表增删环上的节点
+func (c *Cluster) sync(nodes []string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	healthy := make(map[string]struct{}, len(nodes))
+	for _, node := range nodes {
+		healthy[node] = struct{}{}
+		if _, ok := c.members[node]; !ok {
+			c.Ring.Add(node)
+			c.members[node] = struct{}{}
+		}
+	}
+	for node := range c.members {
+		if _, ok := healthy[node]; !ok {
+			c.Ring.Remove(node)
+			delete(c.members, node)
+		}
+	}
+}
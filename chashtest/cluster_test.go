@@ -0,0 +1,93 @@
+package chashtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterFailAndRecover(t *testing.T) {
+	c := NewCluster(nil, "a", "b", "c")
+	assert.Equal(t, []string{"a", "b", "c"}, c.Members())
+
+	keys := Keys(1000)
+	before := Assign(c.Ring, keys)
+
+	c.Fail("b")
+	assert.Equal(t, []string{"a", "c"}, c.Members())
+	after := Assign(c.Ring, keys)
+	for key, node := range after {
+		assert.NotEqual(t, "b", node)
+		if before[key] != "b" {
+			assert.Equal(t, before[key], node)
+		}
+	}
+	ExpectDisruptionBelow(t, before, after, 0.5)
+
+	c.Recover("b")
+	assert.Equal(t, before, Assign(c.Ring, keys))
+}
+
+func TestClusterJoinAndLeave(t *testing.T) {
+	c := NewCluster(nil, "a")
+	ExpectOwner(t, c.Ring, "anything", "a")
+
+	c.Join("b")
+	assert.Equal(t, []string{"a", "b"}, c.Members())
+
+	c.Leave("a")
+	assert.Equal(t, []string{"b"}, c.Members())
+	ExpectOwner(t, c.Ring, "anything", "b")
+}
+
+func TestDiscoveryPlay(t *testing.T) {
+	d := NewFakeDiscovery("a", "b")
+	var updates [][]string
+	d.Watch(func(nodes []string) {
+		updates = append(updates, nodes)
+	})
+
+	var steps []int
+	d.Play(Script{
+		{Node: "a", Healthy: false},
+		{Node: "a", Healthy: true},
+		{Node: "unknown", Healthy: true},
+	}, func(step int, tr Transition) {
+		steps = append(steps, step)
+	})
+
+	assert.Equal(t, []int{0, 1, 2}, steps)
+	assert.Equal(t, [][]string{
+		{"a", "b"},
+		{"b"},
+		{"a", "b"},
+		{"a", "b"},
+	}, updates)
+}
+
+func TestExpectHelpers(t *testing.T) {
+	c := NewCluster(nil, "a", "b")
+	node, ok := c.Ring.Get("k")
+	assert.True(t, ok)
+
+	mock := new(recorder)
+	assert.True(t, ExpectOwner(mock, c.Ring, "k", fmt.Sprint(node)))
+	assert.False(t, ExpectOwner(mock, c.Ring, "k", "nonexistent"))
+
+	before := Assignment{"x": "a", "y": "a"}
+	after := Assignment{"x": "a", "y": "b"}
+	assert.True(t, ExpectDisruptionBelow(mock, before, after, 0.6))
+	assert.False(t, ExpectDisruptionBelow(mock, before, after, 0.5))
+}
+
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failed = true
+}
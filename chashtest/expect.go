@@ -0,0 +1,80 @@
+package chashtest
+
+import (
+	"strconv"
+	"testing"
+)
+
+// 键到节点的映射快照
+type Assignment map[string]string
+
+// 生成 n 个测试用的键
+func Keys(n int) []string {
+	keys := make([]string, n)
+	for i := 0; i < n; i++ {
+		keys[i] = "key:" + strconv.Itoa(i)
+	}
+	return keys
+}
+
+// 记录当前每个键所属的节点
+func Assign(ring Ring, keys []string) Assignment {
+	assignment := make(Assignment, len(keys))
+	for _, key := range keys {
+		if node, ok := ring.Get(key); ok {
+			assignment[key] = toString(node)
+		}
+	}
+	return assignment
+}
+
+// 断言键落在指定节点上
+func ExpectOwner(t testing.TB, ring Ring, key, node string) bool {
+	t.Helper()
+
+	owner, ok := ring.Get(key)
+	if !ok {
+		t.Errorf("key %q has no owner, expected %q", key, node)
+		return false
+	}
+	if toString(owner) != node {
+		t.Errorf("key %q owned by %q, expected %q", key, toString(owner), node)
+		return false
+	}
+	return true
+}
+
+// 断言两次快照之间迁移的键比例低于 max
+func ExpectDisruptionBelow(t testing.TB, before, after Assignment, max float64) bool {
+	t.Helper()
+
+	if len(before) == 0 {
+		t.Errorf("empty assignment before change")
+		return false
+	}
+
+	var moved int
+	for key, node := range before {
+		if after[key] != node {
+			moved++
+		}
+	}
+
+	ratio := float64(moved) / float64(len(before))
+	if ratio >= max {
+		t.Errorf("disruption %.4f (%d/%d keys moved), expected below %.4f",
+			ratio, moved, len(before), max)
+		return false
+	}
+	return true
+}
+
+func toString(node interface{}) string {
+	if s, ok := node.(string); ok {
+		return s
+	}
+	if s, ok := node.(interface{ String() string }); ok {
+		return s.String()
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+package zero
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// 探测哈希函数和虚拟节点生成方式时使用的节点名
+const checksumProbe = "consistenthash/checksum"
+
+// 校验和不一致时的详情
+type ChecksumMismatch struct {
+	Local, Remote uint64
+	// 本地的拓扑纪元
+	Epoch uint64
+	// 对方恰好缺少本地的这个节点，无法推断时为空
+	Missing string
+	// 对方与本地保留的某个历史版本一致，此时 Version 为该版本号
+	Stale   bool
+	Version uint64
+}
+
+func (e *ChecksumMismatch) Error() string {
+	msg := fmt.Sprintf("consistenthash: checksum mismatch, local %x remote %x at epoch %d", e.Local, e.Remote, e.Epoch)
+	if e.Missing != "" {
+		msg += ", remote is missing node " + e.Missing
+	}
+	if e.Stale {
+		msg += fmt.Sprintf(", remote matches version %d", e.Version)
+	}
+	return msg
+}
+
+// 由成员、虚拟节点数量、哈希函数和虚拟节点生成方式确定的校验和，与添加顺序无关
+// 节点之间交换校验和即可发现哈希环不一致；哈希函数和生成方式以固定输入的哈希值代表
+// 固定路由、摘除中的节点和查找表模式不参与计算
+func (h *ConsistentHash) Checksum() uint64 {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return checksumOf(h.schemeChecksum(), membershipChecksum(h.nodes))
+}
+
+// 与对方的校验和比较，一致时返回 nil，否则返回 *ChecksumMismatch
+// 对方恰好缺少一个本地节点，或与 WithHistory 保留的某个版本一致时，在详情中给出
+func (h *ConsistentHash) VerifyAgainst(checksum uint64) error {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	scheme := h.schemeChecksum()
+	members := membershipChecksum(h.nodes)
+	local := checksumOf(scheme, members)
+	if local == checksum {
+		return nil
+	}
+
+	mismatch := &ChecksumMismatch{Local: local, Remote: checksum, Epoch: h.version}
+	for node, replicas := range h.nodes {
+		if checksumOf(scheme, members-nodeChecksum(node, replicas)) == checksum {
+			mismatch.Missing = node
+			break
+		}
+	}
+	if hist := h.history; hist != nil {
+		versions := []uint64{hist.baseVersion}
+		for _, event := range hist.events {
+			versions = append(versions, event.Version)
+		}
+		// 从新到旧查找
+		for i := len(versions) - 1; i >= 0; i-- {
+			nodes, _ := h.nodesAtLocked(versions[i])
+			if checksumOf(scheme, membershipChecksum(nodes)) == checksum {
+				mismatch.Stale, mismatch.Version = true, versions[i]
+				break
+			}
+		}
+	}
+	return mismatch
+}
+
+// 调用方需持有读锁
+func (h *ConsistentHash) schemeChecksum() uint64 {
+	buf := binary.BigEndian.AppendUint64(nil, h.hashFunc([]byte(checksumProbe)))
+	for _, point := range h.virtualPoints(checksumProbe, 2) {
+		buf = binary.BigEndian.AppendUint64(buf, point)
+	}
+	return Hash(buf)
+}
+
+// 各节点校验和的和，与遍历顺序无关，也便于去掉单个节点
+func membershipChecksum(nodes map[string]int) uint64 {
+	var sum uint64
+	for node, replicas := range nodes {
+		sum += nodeChecksum(node, replicas)
+	}
+	return sum
+}
+
+func checksumOf(scheme, members uint64) uint64 {
+	return mix64(scheme ^ mix64(members))
+}
+
+func nodeChecksum(node string, replicas int) uint64 {
+	buf := binary.BigEndian.AppendUint64(nil, uint64(replicas))
+	return mix64(Hash(append(buf, node...)))
+}
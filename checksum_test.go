@@ -0,0 +1,57 @@
+package zero
+
+import (
+	"testing"
+
+	"consistenthash/hashes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecksum(t *testing.T) {
+	a, b := NewConsistentHash(), NewConsistentHash()
+	a.Add("x")
+	a.AddWithWeight("y", 50)
+	b.AddWithWeight("y", 50)
+	b.Add("x")
+	// 与添加顺序无关
+	assert.Equal(t, a.Checksum(), b.Checksum())
+	assert.Nil(t, a.VerifyAgainst(b.Checksum()))
+
+	b.AddWithWeight("y", 60)
+	assert.NotEqual(t, a.Checksum(), b.Checksum())
+
+	// 哈希函数和虚拟节点生成方式不同
+	for _, c := range []*ConsistentHash{New(WithHashFunc(hashes.XXHash64)), New(WithLegacyReplicaKeys())} {
+		c.Add("x")
+		c.AddWithWeight("y", 50)
+		assert.NotEqual(t, a.Checksum(), c.Checksum())
+	}
+}
+
+func TestVerifyAgainst(t *testing.T) {
+	ch := New(WithHistory(10))
+	ch.Add("a")
+	ch.Add("b")
+	stale := ch.Checksum()
+	version := ch.Epoch()
+	ch.Add("c")
+
+	err := ch.VerifyAgainst(stale)
+	var mismatch *ChecksumMismatch
+	assert.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, ChecksumMismatch{
+		Local:   ch.Checksum(),
+		Remote:  stale,
+		Epoch:   ch.Epoch(),
+		Missing: "c",
+		Stale:   true,
+		Version: version,
+	}, *mismatch)
+	assert.Contains(t, err.Error(), "missing node c")
+
+	// 无法推断时只给出校验和
+	err = ch.VerifyAgainst(1)
+	assert.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, "", mismatch.Missing)
+	assert.False(t, mismatch.Stale)
+}
@@ -0,0 +1,76 @@
+package zero
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+type (
+	// 时间来源，TTL、摘除、计划变更、再平衡等所有与时间有关的功能都通过它计时
+	// 测试时可通过 WithClock 替换为手动推进的实现，无需真正等待
+	Clock interface {
+		Now() time.Time
+		// d 之后执行 f，实际时钟在独立的 goroutine 中执行
+		AfterFunc(d time.Duration, f func()) Timer
+	}
+
+	// 可取消的定时任务
+	Timer interface {
+		Stop() bool
+	}
+
+	// 随机数来源，用于影子对比的抽样和 gossip 选择成员等，需要支持并发调用
+	Rand interface {
+		// [0, 1) 内的随机数
+		Float64() float64
+	}
+
+	realClock struct{}
+
+	globalRand struct{}
+)
+
+// 使用指定的时间来源，nil 表示使用系统时钟
+// 基于哈希环创建的 Rebalancer、Quarantine、StickySessions 等组件沿用该时间来源
+func WithClock(c Clock) Option {
+	return func(h *ConsistentHash) {
+		if c != nil {
+			h.clock = c
+		} else {
+			h.clock = realClock{}
+		}
+	}
+}
+
+// 哈希环使用的时间来源，供基于哈希环创建的组件计时
+func (h *ConsistentHash) Clock() Clock {
+	return h.clock
+}
+
+// 使用指定的随机数来源，nil 表示使用 math/rand/v2 的全局实现
+func WithRand(r Rand) Option {
+	return func(h *ConsistentHash) {
+		if r != nil {
+			h.rand = r
+		} else {
+			h.rand = globalRand{}
+		}
+	}
+}
+
+// 哈希环使用的随机数来源，供基于哈希环创建的组件使用
+func (h *ConsistentHash) Rand() Rand {
+	return h.rand
+}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+func (globalRand) Float64() float64 {
+	return rand.Float64()
+}
@@ -0,0 +1,77 @@
+package zero
+
+// 深拷贝哈希环，用于推演拓扑变化而不影响原有的环
+// 副本包含配置、拓扑和固定路由，不包含指标、日志、预写日志、订阅者、租户视图、过载保护、跟踪回调、影子对比、查找次数、查找缓存、拓扑历史、临时节点、摘除状态和计划的变更，这些只属于原有的环
+func (h *ConsistentHash) Clone() *ConsistentHash {
+	c, _ := h.clone()
+	return c
+}
+
+// 同 Clone，同时返回复制时的版本号
+func (h *ConsistentHash) clone() (*ConsistentHash, uint64) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	c := &ConsistentHash{
+		hashFunc:            h.hashFunc,
+		replicas:            h.replicas,
+		replicaFloor:        h.replicaFloor,
+		keys:                h.appendLiveKeys(make([]uint64, 0, len(h.keys)-h.dead), h.keys),
+		store:               h.cloneStore(),
+		ring:                make(map[uint64][]interface{}, len(h.ring)),
+		nodes:               make(map[string]int, len(h.nodes)),
+		points:              make(map[string][]uint64, len(h.points)),
+		collision:           h.collision,
+		tieBreaker:          h.tieBreaker,
+		replicaKey:          h.replicaKey,
+		appendReplicaKey:    h.appendReplicaKey,
+		pointsFunc:          h.pointsFunc,
+		hashBits:            h.hashBits,
+		seed:                h.seed,
+		seeded:              h.seeded,
+		hashSamples:         h.hashSamples,
+		targetStdDev:        h.targetStdDev,
+		targetPoints:        h.targetPoints,
+		minPoints:           h.minPoints,
+		ownershipLimit:      h.ownershipLimit,
+		ownershipPolicy:     h.ownershipPolicy,
+		proximity:           h.proximity,
+		proximityCandidates: h.proximityCandidates,
+		clock:               h.clock,
+		rand:                h.rand,
+		drainGrace:          h.drainGrace,
+		compile:             h.compile,
+	}
+	for hash, nodes := range h.ring {
+		c.ring[hash] = append([]interface{}(nil), nodes...)
+	}
+	for node, replicas := range h.nodes {
+		c.nodes[node] = replicas
+	}
+	for node, points := range h.points {
+		c.points[node] = append([]uint64(nil), points...)
+	}
+	if len(h.capacities) > 0 {
+		c.capacities = make(map[string]capacity, len(h.capacities))
+		for node, capacity := range h.capacities {
+			c.capacities[node] = capacity
+		}
+	}
+	if len(h.addrs) > 0 {
+		c.addrs = make(map[string]string, len(h.addrs))
+		for id, addr := range h.addrs {
+			c.addrs[id] = addr
+		}
+	}
+	if len(h.pins) > 0 {
+		c.pins = make(map[string]string, len(h.pins))
+		for key, node := range h.pins {
+			c.pins[key] = node
+		}
+	}
+	if h.failover != nil {
+		c.failover = h.failover.clone()
+	}
+	c.recompileLocked()
+	return c, h.version
+}
@@ -0,0 +1,44 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClone(t *testing.T) {
+	metrics := &countingMetrics{t: t, added: make(map[string]int)}
+	ch := New(WithMetrics(metrics), WithLookupCache(16))
+	metrics.ring = ch
+	for i := 0; i < 4; i++ {
+		ch.Add("10.0.0." + strconv.Itoa(i) + ":6379")
+	}
+	ch.Pin("hot", "10.0.0.1:6379")
+	ch.Compile()
+
+	c := ch.Clone()
+	assert.Equal(t, ch.Nodes(), c.Nodes())
+	assert.NotNil(t, c.compiled)
+	for i := 0; i < requestSize; i++ {
+		key := strconv.Itoa(i)
+		expect, _ := ch.Get(key)
+		actual, _ := c.Get(key)
+		assert.Equal(t, expect, actual)
+	}
+	node, _ := c.Get("hot")
+	assert.Equal(t, "10.0.0.1:6379", node)
+
+	// 修改副本不影响原有的环，也不上报指标
+	c.Add("10.0.0.4:6379")
+	c.Remove("10.0.0.0:6379")
+	c.Unpin("hot")
+	assert.Equal(t, 4, len(metrics.added))
+	assert.Empty(t, metrics.removed)
+	assert.True(t, ch.Contains("10.0.0.0:6379"))
+	assert.False(t, ch.Contains("10.0.0.4:6379"))
+	assert.Equal(t, 4*minReplicas, len(ch.keys))
+	pinned, ok := ch.Pinned("hot")
+	assert.True(t, ok)
+	assert.Equal(t, "10.0.0.1:6379", pinned)
+}
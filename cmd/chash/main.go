@@ -0,0 +1,319 @@
+// chash 用于查看一致性哈希环的负载分布和规划扩缩容
+//
+//	chash -nodes a,b,c                    打印各节点占有的哈希空间比例
+//	chash lookup -file nodes.txt <key>... 查询键所在的节点
+//	chash plan -nodes a,b,c -add d        计算扩缩容时需要迁移的键比例
+//
+// 节点文件每行一个节点，可在节点名后跟权重，以 # 开头的行为注释
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"consistenthash"
+)
+
+const usage = `usage:
+  chash [dist] [flags]
+  chash lookup [flags] <key>...
+  chash plan [flags] [-add node]... [-remove node]...`
+
+var errUsage = errors.New(usage)
+
+type (
+	// 节点及其权重
+	member struct {
+		name   string
+		weight int
+	}
+
+	// 可重复指定的字符串参数
+	stringList []string
+
+	config struct {
+		nodes    string
+		file     string
+		replicas int
+		members  []member
+	}
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	cmd := "dist"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "dist":
+		return runDist(args, stdout)
+	case "lookup":
+		return runLookup(args, stdout)
+	case "plan":
+		return runPlan(args, stdout)
+	default:
+		return errUsage
+	}
+}
+
+// 打印各节点占有的哈希空间比例
+func runDist(args []string, stdout io.Writer) error {
+	var c config
+	fs := c.flagSet("dist")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return errUsage
+	}
+	if err := c.load(); err != nil {
+		return err
+	}
+
+	ring := c.ring(c.members)
+	share := ownership(ring)
+	names := ring.Nodes()
+	sort.Slice(names, func(i, j int) bool {
+		return share[names[i]] > share[names[j]]
+	})
+
+	w := tabwriter.NewWriter(stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tREPLICAS\tOWNERSHIP")
+	for _, name := range names {
+		fmt.Fprintf(w, "%s\t%d\t%.2f%%\n", name, ring.ReplicaCount(name), share[name]*100)
+	}
+	return w.Flush()
+}
+
+// 查询键所在的节点
+func runLookup(args []string, stdout io.Writer) error {
+	var c config
+	fs := c.flagSet("lookup")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errUsage
+	}
+	if err := c.load(); err != nil {
+		return err
+	}
+
+	ring := c.ring(c.members)
+	for _, key := range fs.Args() {
+		node, ok := ring.Get(key)
+		if !ok {
+			return errors.New("chash: no nodes")
+		}
+		fmt.Fprintf(stdout, "%s\t%s\n", key, node)
+	}
+	return nil
+}
+
+// 计算扩缩容时需要迁移的键比例
+func runPlan(args []string, stdout io.Writer) error {
+	var (
+		c       config
+		added   stringList
+		removed stringList
+	)
+	fs := c.flagSet("plan")
+	fs.Var(&added, "add", "node to add, may be repeated, name[=weight]")
+	fs.Var(&removed, "remove", "node to remove, may be repeated")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 || len(added)+len(removed) == 0 {
+		return errUsage
+	}
+	if err := c.load(); err != nil {
+		return err
+	}
+
+	after := make([]member, 0, len(c.members)+len(added))
+	for _, m := range c.members {
+		if !removed.contains(m.name) {
+			after = append(after, m)
+		}
+	}
+	for _, s := range added {
+		m, err := parseFlagMember(s)
+		if err != nil {
+			return err
+		}
+		after = append(after, m)
+	}
+
+	before := c.ring(c.members)
+	next := c.ring(after)
+	moved := movement(before, next)
+	var total float64
+	for _, fraction := range moved {
+		total += fraction
+	}
+
+	fmt.Fprintf(stdout, "keys moved: %.2f%%\n", total*100)
+	flows := make([]string, 0, len(moved))
+	for flow := range moved {
+		flows = append(flows, flow)
+	}
+	sort.Strings(flows)
+	w := tabwriter.NewWriter(stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "FROM\tTO\tFRACTION")
+	for _, flow := range flows {
+		from, to, _ := strings.Cut(flow, "\x00")
+		fmt.Fprintf(w, "%s\t%s\t%.2f%%\n", from, to, moved[flow]*100)
+	}
+	return w.Flush()
+}
+
+func (c *config) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&c.nodes, "nodes", "", "comma separated nodes, name[=weight]")
+	fs.StringVar(&c.file, "file", "", "file with one node per line, name [weight]")
+	fs.IntVar(&c.replicas, "replicas", 0, "virtual nodes per node, 0 for the default")
+	return fs
+}
+
+// 从参数和文件中读取节点列表
+func (c *config) load() error {
+	if c.file != "" {
+		f, err := os.Open(c.file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			m, err := parseMember(line)
+			if err != nil {
+				return err
+			}
+			c.members = append(c.members, m)
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range strings.Split(c.nodes, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		m, err := parseFlagMember(s)
+		if err != nil {
+			return err
+		}
+		c.members = append(c.members, m)
+	}
+
+	if len(c.members) == 0 {
+		return errors.New("chash: no nodes, use -nodes or -file")
+	}
+	return nil
+}
+
+func (c *config) ring(members []member) *zero.ConsistentHash {
+	ring := zero.NewConsistentHash()
+	if c.replicas > 0 {
+		ring = zero.NewCustomConsistentHash(c.replicas, nil)
+	}
+	for _, m := range members {
+		ring.AddWithWeight(m.name, m.weight)
+	}
+	return ring
+}
+
+// 解析命令行中的 "name[=weight]"，节点名常为 host:port，不能用冒号分隔权重
+func parseFlagMember(s string) (member, error) {
+	name, weight, ok := strings.Cut(s, "=")
+	if ok {
+		return parseMember(name + " " + weight)
+	}
+	return parseMember(name)
+}
+
+// 解析 "name [weight]"
+func parseMember(s string) (member, error) {
+	fields := strings.Fields(s)
+	m := member{weight: zero.TopWeight}
+	switch len(fields) {
+	case 2:
+		weight, err := strconv.Atoi(fields[1])
+		if err != nil || weight <= 0 {
+			return m, fmt.Errorf("chash: invalid weight %q", fields[1])
+		}
+		m.weight = weight
+		fallthrough
+	case 1:
+		m.name = fields[0]
+		return m, nil
+	default:
+		return m, fmt.Errorf("chash: invalid node %q", s)
+	}
+}
+
+// 各节点占有的哈希空间比例
+func ownership(ring *zero.ConsistentHash) map[string]float64 {
+	share := make(map[string]float64)
+	ring.ForEachSegment(func(start, end uint64, node string) bool {
+		share[node] += span(start, end)
+		return true
+	})
+	return share
+}
+
+// 从 before 变为 after 时，哈希空间在节点间的迁移比例
+// 键为 "from\x00to"
+func movement(before, after *zero.ConsistentHash) map[string]float64 {
+	moved := make(map[string]float64)
+	for _, task := range zero.NewPlanner(0, 0).Plan(before, after) {
+		moved[task.From+"\x00"+task.To] += task.Fraction
+	}
+	return moved
+}
+
+// 闭区间 [start, end] 占整个哈希空间的比例
+func span(start, end uint64) float64 {
+	return (float64(end-start) + 1) / (1 << 64)
+}
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+func (l stringList) contains(s string) bool {
+	for _, v := range l {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
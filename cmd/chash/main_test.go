@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"consistenthash"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunDist(t *testing.T) {
+	var out bytes.Buffer
+	assert.Nil(t, run([]string{"-nodes", "a,b,c=50"}, &out))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, 4, len(lines))
+	assert.True(t, strings.HasPrefix(lines[0], "NODE"))
+
+	var total float64
+	for _, m := range regexp.MustCompile(`([\d.]+)%`).FindAllStringSubmatch(out.String(), -1) {
+		v, err := strconv.ParseFloat(m[1], 64)
+		assert.Nil(t, err)
+		total += v
+	}
+	assert.InDelta(t, 100, total, .05)
+	assert.Contains(t, out.String(), "c     50")
+}
+
+func TestRunLookup(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "nodes.txt")
+	assert.Nil(t, os.WriteFile(file, []byte("# cluster\na\nb 100\n\nc\n"), 0o644))
+
+	var out bytes.Buffer
+	assert.Nil(t, run([]string{"lookup", "-file", file, "k1", "k2"}, &out))
+
+	ring := zero.NewConsistentHash()
+	ring.Add("a")
+	ring.Add("b")
+	ring.Add("c")
+	n1, _ := ring.Get("k1")
+	n2, _ := ring.Get("k2")
+	assert.Equal(t, "k1\t"+n1.(string)+"\nk2\t"+n2.(string)+"\n", out.String())
+}
+
+func TestRunPlan(t *testing.T) {
+	var out bytes.Buffer
+	assert.Nil(t, run([]string{"plan", "-nodes", "10.0.0.1,10.0.0.2,10.0.0.3,10.0.0.4", "--add", "10.0.0.5"}, &out))
+
+	m := regexp.MustCompile(`keys moved: ([\d.]+)%`).FindStringSubmatch(out.String())
+	assert.NotNil(t, m)
+	moved, _ := strconv.ParseFloat(m[1], 64)
+	// 理想的迁移比例为 1/5
+	assert.InDelta(t, 20, moved, 5)
+	// 只有迁往新节点的流向
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n")[2:] {
+		assert.Equal(t, "10.0.0.5", strings.Fields(line)[1])
+	}
+
+	out.Reset()
+	assert.Nil(t, run([]string{"plan", "-nodes", "a,b", "-remove", "b"}, &out))
+	assert.Contains(t, out.String(), "keys moved: ")
+	assert.Contains(t, out.String(), "b     a")
+}
+
+func TestRunHostPort(t *testing.T) {
+	var out bytes.Buffer
+	assert.Nil(t, run([]string{"-nodes", "10.0.0.1:6379,10.0.0.2:6379=50"}, &out))
+	assert.Contains(t, out.String(), "10.0.0.1:6379  100")
+	assert.Contains(t, out.String(), "10.0.0.2:6379  50")
+
+	out.Reset()
+	assert.Nil(t, run([]string{"plan", "-nodes", "10.0.0.1:6379", "-add", "10.0.0.2:6379"}, &out))
+	assert.Contains(t, out.String(), "10.0.0.1:6379  10.0.0.2:6379")
+}
+
+func TestRunErrors(t *testing.T) {
+	var out bytes.Buffer
+	assert.Equal(t, errUsage, run([]string{"unknown"}, &out))
+	assert.Equal(t, errUsage, run([]string{"lookup", "-nodes", "a"}, &out))
+	assert.Equal(t, errUsage, run([]string{"plan", "-nodes", "a"}, &out))
+	assert.NotNil(t, run(nil, &out))
+	assert.NotNil(t, run([]string{"-nodes", "a=0"}, &out))
+	assert.NotNil(t, run([]string{"-file", filepath.Join(t.TempDir(), "missing")}, &out))
+}
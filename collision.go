@@ -0,0 +1,25 @@
+package zero
+
+import "errors"
+
+// 虚拟节点位置与已有虚拟节点重合
+var ErrHashCollision = errors.New("consistenthash: hash collision")
+
+// 哈希冲突的处理策略
+type CollisionPolicy int
+
+const (
+	// 冲突的节点追加到同一位置的冲突链上，查找时对键再次哈希选择，默认策略
+	CollisionChain CollisionPolicy = iota
+	// 顺延到下一个空闲位置，每个位置只属于一个节点
+	CollisionRehash
+	// 拒绝加入产生冲突的节点，哈希环保持不变，可通过 Contains 判断是否加入成功
+	CollisionError
+)
+
+// 指定哈希冲突的处理策略
+func WithCollisionPolicy(policy CollisionPolicy) Option {
+	return func(h *ConsistentHash) {
+		h.collision = policy
+	}
+}
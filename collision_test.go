@@ -0,0 +1,101 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// a 占用 0-99，b 占用 50-149，c 占用 200-299
+func newOverlappingHash(policy CollisionPolicy) *ConsistentHash {
+	offsets := map[string]int{"a": 0, "b": 50, "c": 200}
+	return New(
+		WithCollisionPolicy(policy),
+		WithReplicaKeyFunc(func(node string, index int) []byte {
+			return []byte(strconv.Itoa(offsets[node] + index))
+		}),
+		WithHashFunc(func(data []byte) uint64 {
+			v, err := strconv.Atoi(string(data))
+			if err != nil {
+				return Hash(data)
+			}
+			return uint64(v)
+		}),
+	)
+}
+
+func TestCollisionChain(t *testing.T) {
+	ch := newOverlappingHash(CollisionChain)
+	ch.Add("a")
+	ch.Add("b")
+	assert.Equal(t, 2, len(ch.ring[60]))
+	assert.Equal(t, 2*minReplicas, len(ch.keys))
+
+	ch.Remove("a")
+	assert.Equal(t, []interface{}{"b"}, ch.ring[60])
+	assert.Equal(t, minReplicas, len(ch.keys))
+}
+
+func TestCollisionRehash(t *testing.T) {
+	ch := newOverlappingHash(CollisionRehash)
+	ch.Add("a")
+	ch.Add("b")
+	ch.Add("c")
+	for _, nodes := range ch.ring {
+		assert.Equal(t, 1, len(nodes))
+	}
+	// b 的冲突位置顺延到了 100-149，随后与 c 也不冲突
+	assert.Equal(t, []interface{}{"b"}, ch.ring[149])
+	assert.Equal(t, []interface{}{"b"}, ch.ring[199])
+	assert.Equal(t, []interface{}{"c"}, ch.ring[200])
+
+	ch.Remove("a")
+	assert.Equal(t, 2*minReplicas, len(ch.keys))
+	_, ok := ch.ring[0]
+	assert.False(t, ok)
+	ch.Remove("b")
+	assert.Equal(t, minReplicas, len(ch.keys))
+	assert.Equal(t, []string{"c"}, ch.Nodes())
+}
+
+func TestCollisionError(t *testing.T) {
+	ch := newOverlappingHash(CollisionError)
+	ch.Add("a")
+	ch.Add("b")
+	ch.Add("c")
+	assert.Equal(t, []string{"a", "c"}, ch.Nodes())
+	assert.Equal(t, 2*minReplicas, len(ch.keys))
+	for _, nodes := range ch.ring {
+		assert.Equal(t, 1, len(nodes))
+	}
+
+	// 重复添加时与自身原有的虚拟节点不算冲突
+	ch.Add("a")
+	assert.Equal(t, []string{"a", "c"}, ch.Nodes())
+	assert.Equal(t, 2*minReplicas, len(ch.keys))
+}
+
+func TestCollisionChainOrder(t *testing.T) {
+	create := func(nodes ...string) *ConsistentHash {
+		ch := New(WithHashFunc(func(data []byte) uint64 {
+			return 1
+		}))
+		for _, node := range nodes {
+			ch.Add(node)
+		}
+		return ch
+	}
+
+	forward := create("a", "b", "c")
+	backward := create("c", "b", "a")
+	assert.Equal(t, 3*minReplicas, len(forward.ring[1]))
+	assert.Equal(t, "a", forward.ring[1][0])
+	assert.Equal(t, "c", forward.ring[1][len(forward.ring[1])-1])
+	assert.Equal(t, forward.ring[1], backward.ring[1])
+	for i := 0; i < requestSize; i++ {
+		v1, _ := forward.Get(strconv.Itoa(i))
+		v2, _ := backward.Get(strconv.Itoa(i))
+		assert.Equal(t, v1, v2)
+	}
+}
@@ -0,0 +1,106 @@
+package zero
+
+import (
+	"strconv"
+
+	"consistenthash/hashes"
+)
+
+// 跨语言兼容模式的名称
+const CompatV1 = "compat/v1"
+
+// 固定哈希环的算法，使其他语言的实现可以逐字节地得到相同的路由，规则如下：
+//   - 哈希函数为种子为0的 xxHash64（XXH64），键的哈希值为 XXH64(键的 UTF-8 字节)
+//   - 第 i 个虚拟节点的位置为 XXH64(节点名 + 0x00 + i 的4字节大端序)，i 从0开始
+//   - 节点的虚拟节点数量为 放大因子 * 权重 / 100（整数除法），放大因子默认为100
+//   - 查找时取第一个不小于键哈希值的位置，超过最大位置时回到最小的位置
+//   - 多个节点落在同一位置时按节点名的字节序排成冲突链，
+//     选择第 XXH64("16777619:" + 键) % 冲突链长度 个节点
+//
+// 该模式覆盖之前设置的哈希函数、种子、虚拟节点键、分层和冲突策略，应放在其他选项之后，
+// 之后再修改这些配置将不再兼容；其余选项（如 WithReplicas、WithTreeStore）不影响兼容性
+// 其他语言的实现可用 CompatV1Vectors 导出的测试向量（testdata/compat_v1.json）校验
+func WithCompatV1() Option {
+	return func(h *ConsistentHash) {
+		h.hashFunc = hashes.XXHash64
+		h.seeded = false
+		h.replicaKey = DefaultReplicaKey
+		h.appendReplicaKey = appendDefaultReplicaKey
+		h.pointsFunc = nil
+		h.spread = false
+		h.collision = CollisionChain
+	}
+}
+
+type (
+	// 兼容模式的测试向量
+	CompatVectors struct {
+		Mode     string         `json:"mode"`
+		Replicas int            `json:"replicas"`
+		Nodes    []CompatNode   `json:"nodes"`
+		Points   []CompatPoint  `json:"points"`
+		Lookups  []CompatLookup `json:"lookups"`
+	}
+
+	// 按顺序加入哈希环的节点
+	CompatNode struct {
+		Node     string `json:"node"`
+		Weight   int    `json:"weight"`
+		Replicas int    `json:"replicas"`
+	}
+
+	// 节点的虚拟节点位置，哈希值以十进制字符串表示，避免在 JSON 中丢失精度
+	CompatPoint struct {
+		Node  string `json:"node"`
+		Index int    `json:"index"`
+		Hash  string `json:"hash"`
+	}
+
+	// 键的哈希值及其所属节点
+	CompatLookup struct {
+		Key  string `json:"key"`
+		Hash string `json:"hash"`
+		Node string `json:"node"`
+	}
+)
+
+// 生成兼容模式的测试向量，每次调用结果相同
+func CompatV1Vectors() CompatVectors {
+	nodes := []CompatNode{
+		{Node: "10.0.0.1:6379", Weight: TopWeight},
+		{Node: "10.0.0.2:6379", Weight: TopWeight},
+		{Node: "10.0.0.3:6379", Weight: TopWeight / 2},
+		{Node: "cache-东京", Weight: 2 * TopWeight},
+	}
+	h := New(WithCompatV1())
+	v := CompatVectors{
+		Mode:     CompatV1,
+		Replicas: h.replicas,
+	}
+	for _, n := range nodes {
+		h.AddWithWeight(n.Node, n.Weight)
+		n.Replicas = h.ReplicaCount(n.Node)
+		v.Nodes = append(v.Nodes, n)
+		for i := 0; i < 3; i++ {
+			v.Points = append(v.Points, CompatPoint{
+				Node:  n.Node,
+				Index: i,
+				Hash:  strconv.FormatUint(h.hashFunc(h.replicaKey(n.Node, i)), 10),
+			})
+		}
+	}
+
+	keys := []string{"", "a", "user:1", "user:2", "订单:42", "key with spaces"}
+	for i := 0; i < 50; i++ {
+		keys = append(keys, "key"+strconv.Itoa(i))
+	}
+	for _, key := range keys {
+		node, _ := h.Get(key)
+		v.Lookups = append(v.Lookups, CompatLookup{
+			Key:  key,
+			Hash: strconv.FormatUint(h.hashFunc([]byte(key)), 10),
+			Node: node.(string),
+		})
+	}
+	return v
+}
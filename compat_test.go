@@ -0,0 +1,67 @@
+package zero
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompatV1Vectors(t *testing.T) {
+	data, err := os.ReadFile("testdata/compat_v1.json")
+	assert.Nil(t, err)
+	var golden CompatVectors
+	assert.Nil(t, json.Unmarshal(data, &golden))
+	// 测试向量是对外的约定，不能改变
+	assert.Equal(t, golden, CompatV1Vectors())
+}
+
+// 按 WithCompatV1 文档中的规则独立实现查找，与测试向量逐一比对
+func TestCompatV1Spec(t *testing.T) {
+	v := CompatV1Vectors()
+	owners := make(map[uint64][]string)
+	var points []uint64
+	for _, n := range v.Nodes {
+		assert.Equal(t, v.Replicas*n.Weight/100, n.Replicas)
+		for i := 0; i < n.Replicas; i++ {
+			key := binary.BigEndian.AppendUint32(append([]byte(n.Node), 0), uint32(i))
+			hash := xxhash.Sum64(key)
+			if len(owners[hash]) == 0 {
+				points = append(points, hash)
+			}
+			owners[hash] = append(owners[hash], n.Node)
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i] < points[j] })
+
+	for _, p := range v.Points {
+		key := binary.BigEndian.AppendUint32(append([]byte(p.Node), 0), uint32(p.Index))
+		assert.Equal(t, strconv.FormatUint(xxhash.Sum64(key), 10), p.Hash)
+	}
+	for _, l := range v.Lookups {
+		hash := xxhash.Sum64String(l.Key)
+		assert.Equal(t, strconv.FormatUint(hash, 10), l.Hash)
+		i := sort.Search(len(points), func(i int) bool { return points[i] >= hash }) % len(points)
+		chain := owners[points[i]]
+		sort.Strings(chain)
+		node := chain[xxhash.Sum64String("16777619:"+l.Key)%uint64(len(chain))]
+		assert.Equal(t, node, l.Node, l.Key)
+	}
+}
+
+func TestWithCompatV1(t *testing.T) {
+	expect := New(WithCompatV1())
+	// 覆盖之前的哈希相关配置，不影响存储方式
+	actual := New(WithSeed(1), WithReplicaSpreading(), WithCollisionPolicy(CollisionRehash),
+		WithLegacyReplicaKeys(), WithTreeStore(), WithCompatV1())
+	for _, node := range []string{"a", "b", "c"} {
+		expect.Add(node)
+		actual.Add(node)
+	}
+	assert.Equal(t, 0., MovedFraction(expect, actual, 10000))
+}
@@ -0,0 +1,171 @@
+package zero
+
+import "math/bits"
+
+const (
+	// 编译查找表的桶数量范围
+	minCompiledBits = 8
+	maxCompiledBits = 20
+	// 每个虚拟节点平均对应的桶数量
+	bucketsPerPoint = 4
+)
+
+type (
+	// 把哈希空间按高位等分为 2^k 个桶的查找表
+	// 虚拟节点以平行的切片保存：keys[i] 的节点为 nodes[owners[i]]，查找时不再访问虚拟节点映射，
+	// 节点以下标引用，每个虚拟节点只多占4字节，远小于映射中的冲突链
+	compiledTable struct {
+		shift   uint
+		buckets []compiledBucket
+		// 编译时有序且不含墓碑的虚拟节点位置
+		keys []uint64
+		// 各虚拟节点所属节点在 nodes 中的下标，冲突链上的位置为 -1
+		owners []int32
+		nodes  []interface{}
+		// 编译时的拓扑版本号
+		version uint64
+	}
+
+	// 桶内的哈希值全部属于同一节点时 owner 为该节点的下标，查找只需一次移位和两次索引
+	// 否则为 -1，在 keys[lo:hi] 中以 lowerBound 查找
+	compiledBucket struct {
+		owner  int32
+		lo, hi uint32
+	}
+)
+
+// 开启查找表模式
+// 按当前的虚拟节点把哈希空间展开为 2^k 个桶，桶内只有一个节点时 Get 直接取得结果，
+// 跨越多个节点的桶仍在桶内的少量虚拟节点中以无分支的二分查找定位，查找结果与未编译时完全一致
+// 之后每次拓扑变化都会自动重新编译
+func (h *ConsistentHash) Compile() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.compile = true
+	h.recompileLocked()
+}
+
+// 拓扑变更后的收尾：重新计算按容量添加的节点，清理已离开节点的地址，按需压缩墓碑、自动调优、按集群规模缩放虚拟节点、检查占比上限、记录离开节点的区间，
+// 在开启查找表模式时重新编译，记录拓扑历史和预写日志，并通知订阅者
+// 调用方需持有写锁
+func (h *ConsistentHash) settleLocked() {
+	h.deriveCapacityLocked()
+	h.pruneAddrsLocked()
+	h.maybeCompactLocked()
+	h.tune()
+	h.scaleReplicasLocked()
+	h.enforceOwnershipLocked()
+	h.trackRemovedLocked()
+	h.recompileLocked()
+	h.recordLocked()
+	h.appendWALLocked()
+	h.notifyWatchersLocked()
+}
+
+// 调用方需持有写锁
+func (h *ConsistentHash) recompileLocked() {
+	if !h.compile || len(h.ring) == 0 {
+		h.compiled = nil
+		return
+	}
+	// 查找表按桶内的虚拟节点判断所属节点，不能含有墓碑
+	points := h.sortedPoints()
+	k := bits.Len(uint(len(points)*bucketsPerPoint - 1))
+	k = min(max(k, minCompiledBits), maxCompiledBits)
+	t := &compiledTable{
+		shift:   uint(64 - k),
+		buckets: make([]compiledBucket, 1<<k),
+		keys:    points,
+		owners:  make([]int32, len(points)),
+		version: h.version,
+	}
+	index := make(map[string]int32, len(h.nodes))
+	for i, point := range points {
+		nodes := h.ring[point]
+		if len(nodes) != 1 {
+			t.owners[i] = -1
+			continue
+		}
+		node := nodes[0].(string)
+		owner, ok := index[node]
+		if !ok {
+			owner = int32(len(t.nodes))
+			index[node] = owner
+			t.nodes = append(t.nodes, node)
+		}
+		t.owners[i] = owner
+	}
+
+	n := len(points)
+	var lo int
+	for i := range t.buckets {
+		end := uint64(i)<<t.shift | (1<<t.shift - 1)
+		// [lo, hi) 为落在桶内的虚拟节点
+		hi := lo
+		for hi < n && points[hi] <= end {
+			hi++
+		}
+		t.buckets[i] = compiledBucket{
+			owner: t.soleOwner(lo, hi),
+			lo:    uint32(lo),
+			hi:    uint32(hi),
+		}
+		lo = hi
+	}
+	h.compiled = t
+}
+
+// 桶内的哈希值都由 keys[lo:hi] 和其后的第一个虚拟节点决定
+// 它们属于同一节点且没有冲突时返回该节点的下标，否则返回 -1
+func (t *compiledTable) soleOwner(lo, hi int) int32 {
+	owner := t.owners[lo%len(t.keys)]
+	for i := lo + 1; i <= hi && owner >= 0; i++ {
+		if t.owners[i%len(t.keys)] != owner {
+			return -1
+		}
+	}
+	return owner
+}
+
+// 在查找表中定位哈希值，返回顺时针方向的第一个虚拟节点位置
+// 查找表未开启或已过期时 ok 为 false
+// 调用方需持有读锁
+func (h *ConsistentHash) searchCompiled(hash uint64) (node interface{}, point uint64, ok bool) {
+	t := h.compiled
+	if t == nil || t.version != h.version {
+		return nil, 0, false
+	}
+
+	b := &t.buckets[hash>>t.shift]
+	if b.owner >= 0 {
+		return t.nodes[b.owner], 0, true
+	}
+	lo, hi := int(b.lo), int(b.hi)
+	index := (lo + lowerBound(t.keys[lo:hi], hash)) % len(t.keys)
+	// 冲突链上的位置仍需按键选择节点
+	if owner := t.owners[index]; owner >= 0 {
+		return t.nodes[owner], 0, true
+	}
+	return nil, t.keys[index], true
+}
+
+// 第一个不小于 hash 的位置，都小于时返回 len(keys)
+// 循环次数只取决于长度，比较结果只用于条件赋值，编译为条件传送，避免分支预测失败和闭包调用
+func lowerBound(keys []uint64, hash uint64) int {
+	if len(keys) == 0 {
+		return 0
+	}
+	base, n := 0, len(keys)
+	for n > 1 {
+		half := n >> 1
+		if keys[base+half-1] < hash {
+			base += half
+		}
+		n -= half
+	}
+	if keys[base] < hash {
+		base++
+	}
+	return base
+}
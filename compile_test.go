@@ -0,0 +1,129 @@
+package zero
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompile(t *testing.T) {
+	compiled := NewConsistentHash()
+	compiled.Compile()
+	plain := NewConsistentHash()
+
+	check := func() {
+		hashes := []uint64{0, 1, math.MaxUint64, math.MaxUint64 - 1}
+		for _, hash := range compiled.keys {
+			hashes = append(hashes, hash-1, hash, hash+1)
+		}
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < requestSize; i++ {
+			hashes = append(hashes, r.Uint64())
+		}
+		for _, hash := range hashes {
+			expect, _ := plain.GetHash(hash)
+			actual, _ := compiled.GetHash(hash)
+			assert.Equal(t, expect, actual, hash)
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		node := "10.0.0." + strconv.Itoa(i) + ":6379"
+		compiled.Add(node)
+		plain.Add(node)
+	}
+	assert.NotNil(t, compiled.compiled)
+	assert.Equal(t, compiled.version, compiled.compiled.version)
+	assert.Equal(t, 1<<13, len(compiled.compiled.buckets))
+	check()
+
+	// 拓扑变化后自动重新编译
+	compiled.Remove("10.0.0.3:6379")
+	plain.Remove("10.0.0.3:6379")
+	assert.Equal(t, compiled.version, compiled.compiled.version)
+	check()
+
+	compiled.Drain("10.0.0.4:6379")
+	plain.Remove("10.0.0.4:6379")
+	check()
+
+	for _, node := range compiled.Nodes() {
+		compiled.Remove(node)
+	}
+	assert.Nil(t, compiled.compiled)
+	_, ok := compiled.Get("any")
+	assert.False(t, ok)
+}
+
+func TestCompileWithCollisions(t *testing.T) {
+	fn := func(data []byte) uint64 {
+		// 高位相同，大量虚拟节点落入同一个桶且互相冲突
+		return Hash(data) & 0xff
+	}
+	compiled := NewCustomConsistentHash(minReplicas, fn)
+	plain := NewCustomConsistentHash(minReplicas, fn)
+	compiled.Compile()
+	for _, node := range []string{"first", "second", "third"} {
+		compiled.Add(node)
+		plain.Add(node)
+	}
+
+	for i := 0; i < requestSize; i++ {
+		key := strconv.Itoa(i)
+		expect, _ := plain.Get(key)
+		actual, _ := compiled.Get(key)
+		assert.Equal(t, expect, actual)
+	}
+}
+
+func TestCompileOwners(t *testing.T) {
+	ch := NewConsistentHash()
+	ch.Compile()
+	for i := 0; i < 10; i++ {
+		ch.Add("localhost:" + strconv.Itoa(i))
+	}
+
+	table := ch.compiled
+	assert.Equal(t, len(table.keys), len(table.owners))
+	assert.Equal(t, 10, len(table.nodes))
+	for i, point := range table.keys {
+		nodes := ch.ring[point]
+		if table.owners[i] < 0 {
+			assert.True(t, len(nodes) > 1)
+			continue
+		}
+		assert.Equal(t, nodes[0], table.nodes[table.owners[i]])
+	}
+}
+
+func TestLowerBound(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 40; n++ {
+		keys := make([]uint64, n)
+		for i := range keys {
+			keys[i] = uint64(r.Intn(64))
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+		for hash := uint64(0); hash < 66; hash++ {
+			expect := sort.Search(n, func(i int) bool { return keys[i] >= hash })
+			assert.Equal(t, expect, lowerBound(keys, hash))
+		}
+	}
+}
+
+func BenchmarkConsistentHashGetCompiled(b *testing.B) {
+	ch := NewConsistentHash()
+	for i := 0; i < keySize; i++ {
+		ch.Add("localhost:" + strconv.Itoa(i))
+	}
+	ch.Compile()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ch.GetHash(uint64(i) * 0x9e3779b97f4a7c15)
+	}
+}
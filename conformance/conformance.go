@@ -0,0 +1,175 @@
+// 哈希环实现的一致性测试套件
+// 任何实现了增删查的后端（环、Maglev、Rendezvous 或自定义实现）都应通过这些检查：
+// 负载均衡在给定偏差内、增删节点时只迁移少量的键、相同的操作序列在不同实例上得到相同的结果
+// 替换哈希函数后也可用来验证其分布是否满足要求
+package conformance
+
+import (
+	"strconv"
+	"testing"
+
+	"consistenthash/chashtest"
+)
+
+const (
+	defaultNodes      = 10
+	defaultKeys       = 10000
+	defaultImbalance  = 0.3
+	defaultDisruption = 2
+)
+
+type (
+	// 创建一个空的被测哈希环
+	Factory func() chashtest.Ring
+
+	// 检查的参数，零值字段使用默认值
+	Config struct {
+		// 节点数量，默认10
+		Nodes int
+		// 键的数量，默认10000
+		Keys int
+		// 各节点负载偏离均值的最大比例，默认0.3
+		Imbalance float64
+		// 增删一个节点时迁移比例相对理想值的最大倍数，默认2
+		// 理想值为增删的节点应得的份额，即 1/节点数
+		Disruption float64
+	}
+)
+
+// 以子测试的形式运行所有检查
+func Run(t *testing.T, factory Factory, cfg Config) {
+	t.Run("Balance", func(t *testing.T) {
+		Balance(t, factory, cfg)
+	})
+	t.Run("DisruptionOnAdd", func(t *testing.T) {
+		DisruptionOnAdd(t, factory, cfg)
+	})
+	t.Run("DisruptionOnRemove", func(t *testing.T) {
+		DisruptionOnRemove(t, factory, cfg)
+	})
+	t.Run("Determinism", func(t *testing.T) {
+		Determinism(t, factory, cfg)
+	})
+}
+
+// 检查每个节点分到的键数量都在均值的 Imbalance 比例之内
+func Balance(t testing.TB, factory Factory, cfg Config) bool {
+	t.Helper()
+	cfg = cfg.withDefaults()
+
+	nodes := nodeNames(cfg.Nodes)
+	ring := build(factory, nodes)
+	counts := make(map[string]int, len(nodes))
+	for _, node := range chashtest.Assign(ring, chashtest.Keys(cfg.Keys)) {
+		counts[node]++
+	}
+
+	mean := float64(cfg.Keys) / float64(cfg.Nodes)
+	ok := true
+	for _, node := range nodes {
+		deviation := (float64(counts[node]) - mean) / mean
+		if deviation > cfg.Imbalance || deviation < -cfg.Imbalance {
+			t.Errorf("node %q owns %d keys, deviates %.4f from mean %.1f, expected within %.4f",
+				node, counts[node], deviation, mean, cfg.Imbalance)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// 检查新增一个节点时迁移的键比例不超过 Disruption 倍的理想值
+func DisruptionOnAdd(t testing.TB, factory Factory, cfg Config) bool {
+	t.Helper()
+	cfg = cfg.withDefaults()
+
+	nodes := nodeNames(cfg.Nodes + 1)
+	ring := build(factory, nodes[:cfg.Nodes])
+	keys := chashtest.Keys(cfg.Keys)
+	before := chashtest.Assign(ring, keys)
+	ring.Add(nodes[cfg.Nodes])
+	after := chashtest.Assign(ring, keys)
+
+	ideal := 1 / float64(cfg.Nodes+1)
+	return chashtest.ExpectDisruptionBelow(t, before, after, ideal*cfg.Disruption)
+}
+
+// 检查删除一个节点时它的键全部迁走，且迁移的键比例不超过 Disruption 倍的理想值
+func DisruptionOnRemove(t testing.TB, factory Factory, cfg Config) bool {
+	t.Helper()
+	cfg = cfg.withDefaults()
+
+	nodes := nodeNames(cfg.Nodes)
+	ring := build(factory, nodes)
+	keys := chashtest.Keys(cfg.Keys)
+	before := chashtest.Assign(ring, keys)
+	removed := nodes[len(nodes)/2]
+	ring.Remove(removed)
+	after := chashtest.Assign(ring, keys)
+
+	ok := true
+	for key, node := range after {
+		if node == removed {
+			t.Errorf("key %q still owned by removed node %q", key, removed)
+			ok = false
+			break
+		}
+	}
+	ideal := 1 / float64(cfg.Nodes)
+	return chashtest.ExpectDisruptionBelow(t, before, after, ideal*cfg.Disruption) && ok
+}
+
+// 检查两个实例经过相同的增删序列后，每个键都落在相同的节点上
+func Determinism(t testing.TB, factory Factory, cfg Config) bool {
+	t.Helper()
+	cfg = cfg.withDefaults()
+
+	nodes := nodeNames(cfg.Nodes)
+	keys := chashtest.Keys(cfg.Keys)
+	replay := func() chashtest.Assignment {
+		ring := build(factory, nodes)
+		ring.Remove(nodes[0])
+		ring.Add(nodes[0])
+		return chashtest.Assign(ring, keys)
+	}
+
+	first, second := replay(), replay()
+	for _, key := range keys {
+		if first[key] != second[key] {
+			t.Errorf("key %q owned by %q and %q in two identical rings", key, first[key], second[key])
+			return false
+		}
+	}
+	return true
+}
+
+func (c Config) withDefaults() Config {
+	if c.Nodes <= 0 {
+		c.Nodes = defaultNodes
+	}
+	if c.Keys <= 0 {
+		c.Keys = defaultKeys
+	}
+	if c.Imbalance <= 0 {
+		c.Imbalance = defaultImbalance
+	}
+	if c.Disruption <= 0 {
+		c.Disruption = defaultDisruption
+	}
+	return c
+}
+
+func build(factory Factory, nodes []string) chashtest.Ring {
+	ring := factory()
+	for _, node := range nodes {
+		ring.Add(node)
+	}
+	return ring
+}
+
+func nodeNames(n int) []string {
+	nodes := make([]string, n)
+	for i := range nodes {
+		nodes[i] = "10.0.0." + strconv.Itoa(i+1) + ":6379"
+	}
+	return nodes
+}
@@ -0,0 +1,141 @@
+package conformance
+
+import (
+	"fmt"
+	"testing"
+
+	"consistenthash"
+	"consistenthash/chashtest"
+	"consistenthash/hashes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackends(t *testing.T) {
+	backends := []struct {
+		name    string
+		factory Factory
+		cfg     Config
+	}{
+		{"ring", func() chashtest.Ring { return zero.NewConsistentHash() }, Config{}},
+		{"tree", func() chashtest.Ring { return zero.New(zero.WithTreeStore()) }, Config{}},
+		{"ketama", func() chashtest.Ring { return zero.NewKetamaHash() }, Config{}},
+		{"maglev", func() chashtest.Ring { return zero.NewMaglevHash() }, Config{}},
+		{"rendezvous", func() chashtest.Ring { return zero.NewRendezvousHash() }, Config{}},
+		// 多探针的均衡度取决于探针数量，默认的探针数量下偏差略大
+		{"multiprobe", func() chashtest.Ring { return zero.NewMultiProbeHash() }, Config{Imbalance: 0.4}},
+		{"anchor", func() chashtest.Ring { return zero.NewAnchorHash(1 << 10) }, Config{}},
+		{"jump", func() chashtest.Ring { return zero.NewJumpHash() }, Config{}},
+	}
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			Run(t, backend.factory, backend.cfg)
+		})
+	}
+}
+
+func TestHashFuncs(t *testing.T) {
+	funcs := map[string]zero.Func{
+		"xxhash":  hashes.XXHash64,
+		"murmur3": hashes.Murmur128,
+	}
+	for name, fn := range funcs {
+		t.Run(name, func(t *testing.T) {
+			Run(t, func() chashtest.Ring {
+				return zero.New(zero.WithHashFunc(fn))
+			}, Config{})
+		})
+	}
+}
+
+func TestDetectsImbalance(t *testing.T) {
+	var r recorder
+	assert.False(t, Balance(&r, func() chashtest.Ring { return &firstRing{} }, Config{}))
+	assert.NotEmpty(t, r.errors)
+}
+
+func TestDetectsDisruption(t *testing.T) {
+	var r recorder
+	assert.False(t, DisruptionOnAdd(&r, func() chashtest.Ring { return &modRing{} }, Config{}))
+	assert.False(t, DisruptionOnRemove(&r, func() chashtest.Ring { return &modRing{} }, Config{}))
+	assert.NotEmpty(t, r.errors)
+}
+
+func TestDetectsNondeterminism(t *testing.T) {
+	var r recorder
+	var instances int
+	factory := func() chashtest.Ring {
+		instances++
+		return &offsetRing{offset: instances}
+	}
+	assert.False(t, Determinism(&r, factory, Config{}))
+	assert.NotEmpty(t, r.errors)
+}
+
+// 所有键都落在第一个节点上
+type firstRing struct {
+	nodes []string
+}
+
+func (r *firstRing) Add(node string) { r.nodes = append(r.nodes, node) }
+
+func (r *firstRing) Remove(node string) {}
+
+func (r *firstRing) Get(v string) (interface{}, bool) {
+	if len(r.nodes) == 0 {
+		return nil, false
+	}
+	return r.nodes[0], true
+}
+
+// 按键的字节和取模分配，任何拓扑变化都会打乱大部分键
+type modRing struct {
+	nodes []string
+}
+
+func (r *modRing) Add(node string) { r.nodes = append(r.nodes, node) }
+
+func (r *modRing) Remove(node string) {
+	for i, n := range r.nodes {
+		if n == node {
+			r.nodes = append(r.nodes[:i], r.nodes[i+1:]...)
+			return
+		}
+	}
+}
+
+func (r *modRing) Get(v string) (interface{}, bool) {
+	return r.get(v, 0)
+}
+
+func (r *modRing) get(v string, offset int) (interface{}, bool) {
+	if len(r.nodes) == 0 {
+		return nil, false
+	}
+	sum := offset
+	for _, c := range v {
+		sum += int(c)
+	}
+	return r.nodes[sum%len(r.nodes)], true
+}
+
+// 每个实例的分配都有不同的偏移
+type offsetRing struct {
+	modRing
+	offset int
+}
+
+func (r *offsetRing) Get(v string) (interface{}, bool) {
+	return r.get(v, r.offset)
+}
+
+// 收集错误而不使外层测试失败
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
@@ -3,6 +3,7 @@ package zero
 import (
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
 	"testing"
 
@@ -197,3 +198,139 @@ func newMockNode(addr string, id int) *mockNode {
 func (n *mockNode) String() string {
 	return n.addr
 }
+
+func TestConsistentHash_GetBytesAndHash(t *testing.T) {
+	ch := NewConsistentHash()
+	_, ok := ch.GetBytes([]byte("any"))
+	assert.False(t, ok)
+	_, ok = ch.GetHash(1)
+	assert.False(t, ok)
+
+	for i := 0; i < keySize; i++ {
+		ch.Add("10.0.0." + strconv.Itoa(i) + ":6379")
+	}
+
+	for i := 0; i < requestSize; i++ {
+		key := strconv.Itoa(i)
+		expect, ok := ch.Get(key)
+		assert.True(t, ok)
+		val, ok := ch.GetBytes([]byte(key))
+		assert.True(t, ok)
+		assert.Equal(t, expect, val)
+		val, ok = ch.GetHash(Hash([]byte(key)))
+		assert.True(t, ok)
+		assert.Equal(t, expect, val)
+	}
+}
+
+func BenchmarkConsistentHashGetHash(b *testing.B) {
+	ch := NewConsistentHash()
+	for i := 0; i < keySize; i++ {
+		ch.Add("localhost:" + strconv.Itoa(i))
+	}
+	hash := Hash([]byte("any"))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ch.GetHash(hash)
+	}
+}
+
+func BenchmarkConsistentHashGetAllocs(b *testing.B) {
+	ch := NewConsistentHash()
+	for i := 0; i < keySize; i++ {
+		ch.Add("localhost:" + strconv.Itoa(i))
+	}
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = "user:" + strconv.Itoa(i)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ch.Get(keys[i%len(keys)])
+	}
+}
+
+func TestConsistentHash_GetZeroAlloc(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are not stable with the race detector")
+	}
+
+	ch := NewConsistentHash()
+	for i := 0; i < keySize; i++ {
+		ch.Add("localhost:" + strconv.Itoa(i))
+	}
+	key := "a key longer than the 32 byte stack buffer of the compiler"
+	b := []byte(key)
+
+	assert.Equal(t, 0., testing.AllocsPerRun(100, func() {
+		ch.Get(key)
+	}))
+	assert.Equal(t, 0., testing.AllocsPerRun(100, func() {
+		ch.GetBytes(b)
+	}))
+	assert.Equal(t, 0., testing.AllocsPerRun(100, func() {
+		ch.GetHash(1)
+	}))
+
+	// 冲突链上的查找同样不分配内存
+	collided := NewCustomConsistentHash(minReplicas, func([]byte) uint64 { return 1 })
+	collided.Add("first")
+	collided.Add("second")
+	assert.Equal(t, 0., testing.AllocsPerRun(100, func() {
+		collided.Get(key)
+	}))
+	assert.Equal(t, 0., testing.AllocsPerRun(100, func() {
+		collided.GetHash(1)
+	}))
+}
+
+func TestAppendInnerRepr(t *testing.T) {
+	assert.Equal(t, innerRepr("key"), string(appendInnerRepr(nil, 0, []byte("key"))))
+	assert.Equal(t, innerRepr(uint64(42)), string(appendInnerRepr(nil, 42, nil)))
+	assert.Equal(t, innerRepr(""), string(appendInnerRepr(nil, 42, []byte{})))
+}
+
+func TestConsistentHash_AddWithWeightAboveTop(t *testing.T) {
+	ch := NewConsistentHash()
+	ch.AddWithWeight("heavy", 4*TopWeight)
+	ch.AddWithWeight("light", TopWeight)
+	assert.Equal(t, 4*minReplicas, ch.ReplicaCount("heavy"))
+	assert.Equal(t, minReplicas, ch.ReplicaCount("light"))
+
+	var heavy int
+	const keys = 10000
+	for i := 0; i < keys; i++ {
+		if node, _ := ch.Get(strconv.Itoa(i)); node == "heavy" {
+			heavy++
+		}
+	}
+	assert.InDelta(t, 0.8, float64(heavy)/keys, 0.05)
+
+	// AddWithReplicas 仍以放大因子为上限
+	ch.AddWithReplicas("capped", 4*minReplicas)
+	assert.Equal(t, minReplicas, ch.ReplicaCount("capped"))
+}
+
+func TestConsistentHash_AddWithFloatWeight(t *testing.T) {
+	ch := NewConsistentHash()
+	ch.AddWithFloatWeight("a", 1.5)
+	ch.AddWithFloatWeight("b", 0.25)
+	ch.AddWithFloatWeight("c", 0.004)
+	ch.AddWithFloatWeight("d", 0.006)
+	ch.AddWithFloatWeight("e", 1)
+	assert.Equal(t, 150, ch.ReplicaCount("a"))
+	assert.Equal(t, 25, ch.ReplicaCount("b"))
+	// 很小的权重至少得到一个虚拟节点
+	assert.Equal(t, 1, ch.ReplicaCount("c"))
+	assert.Equal(t, 1, ch.ReplicaCount("d"))
+	assert.Equal(t, minReplicas, ch.ReplicaCount("e"))
+
+	for _, weight := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		ch.AddWithFloatWeight("invalid", weight)
+		assert.False(t, ch.Contains("invalid"))
+	}
+}
@@ -1,13 +1,18 @@
 package zero
 
 import (
+	"encoding/binary"
 	"fmt"
+	"math"
 	"sort"
 	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
+	// 基准权重，对应放大因子个虚拟节点
 	TopWeight   = 100
 	minReplicas = 100
 	prime       = 16777619
@@ -16,41 +21,128 @@ const (
 type (
 	Func func(data []byte) uint64
 
+	// 生成第 index 个虚拟节点用于哈希的键
+	ReplicaKeyFunc func(node string, index int) []byte
+
 	ConsistentHash struct {
 		// 哈希函数
 		hashFunc Func
 		// 虚拟节点放大因子
 		// 确定node的虚拟节点数量
 		replicas int
-		// 虚拟节点列表
+		// 虚拟节点放大因子的下限
+		replicaFloor int
+		// 虚拟节点列表，删除节点后可能含有墓碑
 		keys []uint64
+		// keys 中墓碑的数量
+		dead int
+		// 不为 nil 时代替 keys 保存虚拟节点
+		store RingStore
 		// 虚拟节点到物理节点的映射
 		ring map[uint64][]interface{}
 		// 物理节点映射，快速判断是否存在node
-		nodes map[string]struct{}
+		// 值为该节点的虚拟节点数量
+		nodes map[string]int
+		// 物理节点实际占用的虚拟节点位置
+		points map[string][]uint64
+		// 哈希冲突的处理策略
+		collision CollisionPolicy
+		// 冲突链中选择节点的策略，为 nil 时等概率选择
+		tieBreaker TieBreaker
+		// 虚拟节点键的生成方法
+		replicaKey ReplicaKeyFunc
+		// 与 replicaKey 相同但追加到缓冲区中，只有内置的生成方法才有，为 nil 时调用 replicaKey
+		appendReplicaKey func(buf []byte, node string, index int) []byte
+		// 计算物理节点的虚拟节点位置，为 nil 时使用 replicaKey 生成
+		pointsFunc func(node string, replicas int) []uint64
+		// 是否由节点的基础哈希均匀派生虚拟节点位置，在 New 中转换为 pointsFunc
+		spread bool
+		// 哈希空间的位数，键和虚拟节点的位置都小于 2^hashBits，为0时为64位
+		hashBits uint
+		// 哈希种子，seeded 为 true 时生效
+		seed   uint64
+		seeded bool
+		// 检查哈希函数的样本数量，为0时不检查
+		hashSamples int
+		// 自动调优的目标不均衡度，为0时不调优
+		targetStdDev float64
+		// 按集群规模缩放虚拟节点时整个环的目标虚拟节点数量及每个节点的下限，为0时不缩放
+		targetPoints int
+		minPoints    int
+		// 单个节点的哈希空间占比上限，为0时不检查
+		ownershipLimit       float64
+		ownershipPolicy      OwnershipPolicy
+		onOwnershipViolation func(node string, share float64)
+		// 节点的访问代价，为 nil 时不按就近原则选择；及参与比较的候选节点数量
+		proximity           func(node string) int
+		proximityCandidates int
+		// 指标上报，为 nil 时不上报
+		metrics Metrics
+		// 结构化日志，为 nil 时不记录
+		logger Logger
+		// 查找的跟踪回调，为 nil 时不跟踪
+		traceHook TraceHook
+		// 临时节点的存活信息
+		ttls map[string]*ttlEntry
+		// 时间来源
+		clock Clock
+		// 随机数来源
+		rand Rand
+		// 摘除中的节点及其宽限期
+		drains     map[string]*drainEntry
+		drainGrace time.Duration
+		// 摘除中节点的虚拟节点，供 GetExisting 查找
+		drainKeys []uint64
+		drainRing map[uint64][]interface{}
+		// 计划在指定时刻生效的拓扑变更
+		schedules map[string]*scheduleEntry
+		// 按资源容量添加的节点，成员变化时重新计算其虚拟节点数量
+		capacities map[string]capacity
+		// 影子对比的状态，为 nil 时不对比
+		shadow atomic.Pointer[shadowState]
+		// 各节点被查找选中的次数，开启指标或跟踪回调后统计
+		loads atomic.Pointer[loadCounter]
+		// 按逻辑 ID 添加的节点的地址
+		addrs map[string]string
+		// 键的固定路由
+		pins map[string]string
+		// 最近查找结果的缓存，为 nil 时不缓存
+		cache *lookupCache
+		// 是否开启查找表模式，及编译好的查找表
+		compile  bool
+		compiled *compiledTable
+		// 拓扑变更的历史，为 nil 时不记录
+		history *topologyHistory
+		// 成员变更的预写日志，为 nil 时不写入
+		wal *WAL
+		// 成员变化的订阅者，及上一次通知时的成员
+		watchers  map[*watcher]struct{}
+		watchLast map[string]int
+		// 分散已离开节点的区间，为 nil 时不分散
+		failover *failureSpread
+		// 过载保护，为 nil 时不转移热点键
+		spill *spillState
+		// 按租户缓存的权重视图
+		tenants map[string]*tenantView
+		// 最近一次整体重建的耗时
+		lastRebuild time.Duration
+		// 拓扑的版本号，每次修改都会递增
+		version uint64
 		// 读写锁
 		lock sync.RWMutex
 	}
 )
 
+// 等同于 New()
 func NewConsistentHash() *ConsistentHash {
-	return NewCustomConsistentHash(minReplicas, Hash)
+	return New()
 }
-func NewCustomConsistentHash(replicas int, fn Func) *ConsistentHash {
-	if replicas < minReplicas {
-		replicas = minReplicas
-	}
 
-	if fn == nil {
-		fn = Hash
-	}
-
-	return &ConsistentHash{
-		replicas: replicas,
-		hashFunc: fn,
-		ring:     make(map[uint64][]interface{}),
-		nodes:    make(map[string]struct{}),
-	}
+// 等同于 New(WithReplicas(replicas), WithMinReplicas(minReplicas), WithHashFunc(fn), opts...)
+// 为兼容旧版本，replicas 小于 minReplicas 时使用 minReplicas，需要更少的虚拟节点时使用 New
+// 可通过 WithHashValidation 拒绝分布不均的自定义哈希函数
+func NewCustomConsistentHash(replicas int, fn Func, opts ...Option) *ConsistentHash {
+	return New(append([]Option{WithReplicas(replicas), WithMinReplicas(minReplicas), WithHashFunc(fn)}, opts...)...)
 }
 
 // 扩容操作，增加物理节点
@@ -59,30 +151,173 @@ func (h *ConsistentHash) Add(node string) {
 }
 
 // 扩容操作，增加物理节点
+// 虚拟节点数量不超过放大因子，需要更多的虚拟节点时使用 AddWithWeight
 func (h *ConsistentHash) AddWithReplicas(node string, replicas int) {
-	// 支持可重复添加
-	// 先执行删除操作
-	h.Remove(node)
-
 	if replicas > h.replicas {
 		replicas = h.replicas
 	}
+	h.addWithReplicas(node, replicas)
+}
+
+func (h *ConsistentHash) addWithReplicas(node string, replicas int) {
 	h.lock.Lock()
-	defer h.lock.Unlock()
+	// 以普通方式添加的节点不再过期，也不再摘除或按容量计算权重
+	h.clearTTLLocked(node)
+	h.clearDrainLocked(node)
+	h.clearCapacityLocked(node)
+	err := h.addWithReplicasLocked(node, replicas)
+	h.lock.Unlock()
+
+	if err == nil {
+		h.nodeAdded(node, replicas)
+	}
+}
+
+// 调用方需持有写锁
+func (h *ConsistentHash) addWithReplicasLocked(node string, replicas int) error {
+	// 支持可重复添加
+	// 先执行删除操作，新的虚拟节点被拒绝时恢复原有的虚拟节点
+	oldReplicas, existed := h.nodes[node]
+	oldPoints := h.points[node]
+	h.removeLocked(node)
+	err := h.addLocked(node, replicas)
+	if err != nil && existed {
+		h.insertLocked(node, oldReplicas, oldPoints)
+	}
+	//排序
+	//后面会使用二分查找虚拟节点
+	h.sortKeys()
+	h.settleLocked()
+	return err
+}
+
+// 添加物理节点及其虚拟节点，不排序
+// 按冲突策略处理与已有虚拟节点重合的位置
+// 调用方需持有写锁
+func (h *ConsistentHash) addLocked(node string, replicas int) error {
+	return h.addPointsLocked(node, replicas, h.virtualPoints(node, replicas))
+}
+
+// 按冲突策略放入已计算好的虚拟节点位置，CollisionRehash 策略下会修改 points
+// 调用方需持有写锁
+func (h *ConsistentHash) addPointsLocked(node string, replicas int, points []uint64) error {
+	switch h.collision {
+	case CollisionError:
+		seen := make(map[uint64]struct{}, len(points))
+		for _, hash := range points {
+			if _, ok := h.ring[hash]; ok {
+				return ErrHashCollision
+			}
+			if _, ok := seen[hash]; ok {
+				return ErrHashCollision
+			}
+			seen[hash] = struct{}{}
+		}
+	case CollisionRehash:
+		for i, hash := range points {
+			// 顺延到下一个空闲位置，已放入的虚拟节点也参与判断
+			for len(h.ring[hash]) > 0 {
+				hash++
+			}
+			points[i] = hash
+			h.ring[hash] = append(h.ring[hash], node)
+		}
+		for _, hash := range points {
+			h.removeRingNode(hash, node)
+		}
+	}
+
+	h.insertLocked(node, replicas, points)
+	return nil
+}
+
+// 按给定的位置放入虚拟节点，不排序
+// 调用方需持有写锁
+func (h *ConsistentHash) insertLocked(node string, replicas int, points []uint64) {
+	h.version++
 	// 添加node map映射
-	h.addNode(node)
-	for i := 0; i < replicas; i++ {
-		hash := h.hashFunc([]byte(node + strconv.Itoa(i)))
+	h.addNode(node, replicas)
+	h.points[node] = points
+	for _, hash := range points {
 		// 添加虚拟节点
-		h.keys = append(h.keys, hash)
+		h.insertPoint(hash)
 		// 映射虚拟节点-真实节点
 		// 注意hashFunc可能会出现hash冲突，所以采用的是追加操作
 		// 虚拟节点-真实节点的映射对应的其实是个数组
 		// 一个虚拟节点可能对应多个真实节点，当然概率很小
-		h.ring[hash] = append(h.ring[hash], node)
+		h.ring[hash] = insertChain(h.ring[hash], node)
+	}
+}
+
+// 按字典序把节点插入冲突链
+// 冲突链的顺序与添加顺序无关，不同进程对同一冲突的选择才能一致
+func insertChain(nodes []interface{}, node string) []interface{} {
+	index := sort.Search(len(nodes), func(i int) bool {
+		return nodes[i].(string) >= node
+	})
+	nodes = append(nodes, nil)
+	copy(nodes[index+1:], nodes[index:])
+	nodes[index] = node
+	return nodes
+}
+
+// 计算物理节点对应的全部虚拟节点位置
+func (h *ConsistentHash) virtualPoints(node string, replicas int) []uint64 {
+	if h.pointsFunc != nil {
+		return h.pointsFunc(node, replicas)
+	}
+
+	points := make([]uint64, replicas)
+	if h.appendReplicaKey == nil {
+		for i := range points {
+			points[i] = h.hashFunc(h.replicaKey(node, i))
+		}
+		return points
+	}
+
+	// 所有虚拟节点共用一个缓冲区，不为每个虚拟节点分配键
+	buf := getBuffer()
+	for i := range points {
+		*buf = h.appendReplicaKey((*buf)[:0], node, i)
+		points[i] = h.hashFunc(*buf)
+	}
+	putBuffer(buf)
+	return points
+}
+
+// 默认的虚拟节点键：节点名 + 0分隔符 + 4字节大端序编号
+// 分隔符避免了 "node1"+"1" 与 "node"+"11" 这类拼接冲突
+func DefaultReplicaKey(node string, index int) []byte {
+	key := make([]byte, len(node)+5)
+	copy(key, node)
+	binary.BigEndian.PutUint32(key[len(node)+1:], uint32(index))
+	return key
+}
+
+// 旧版本的虚拟节点键：节点名直接拼接十进制编号
+// 需要与旧版本保持相同的键分布时使用
+func LegacyReplicaKey(node string, index int) []byte {
+	return []byte(node + strconv.Itoa(index))
+}
+
+// 与 DefaultReplicaKey 的结果相同，但追加到 buf 中
+func appendDefaultReplicaKey(buf []byte, node string, index int) []byte {
+	buf = append(buf, node...)
+	buf = append(buf, 0)
+	return binary.BigEndian.AppendUint32(buf, uint32(index))
+}
+
+// 与 LegacyReplicaKey 的结果相同，但追加到 buf 中
+func appendLegacyReplicaKey(buf []byte, node string, index int) []byte {
+	buf = append(buf, node...)
+	return strconv.AppendInt(buf, int64(index), 10)
+}
+
+// 虚拟节点排序，存储本身有序
+func (h *ConsistentHash) sortKeys() {
+	if h.store != nil {
+		return
 	}
-	//排序
-	//后面会使用二分查找虚拟节点
 	sort.Slice(h.keys, func(i, j int) bool {
 		return h.keys[i] < h.keys[j]
 	})
@@ -91,33 +326,183 @@ func (h *ConsistentHash) AddWithReplicas(node string, replicas int) {
 // 按权重添加节点
 // 通过权重来计算方法因子， 最终控制虚拟节点的数量
 // 权重越高，虚拟节点越多
+// 权重为 TopWeight 时虚拟节点数量等于放大因子，权重可以超过 TopWeight，如 4*TopWeight 得到4倍的虚拟节点
 func (h *ConsistentHash) AddWithWeight(node string, weight int) {
 	replicas := h.replicas * weight / TopWeight
-	h.AddWithReplicas(node, replicas)
+	h.addWithReplicas(node, replicas)
+}
+
+// 按浮点权重添加节点，权重1对应放大因子个虚拟节点，如 1.5 或 0.25
+// 虚拟节点数量四舍五入，正权重至少得到一个虚拟节点，权重不为正数时忽略
+func (h *ConsistentHash) AddWithFloatWeight(node string, weight float64) {
+	if !(weight > 0) || math.IsInf(weight, 1) {
+		return
+	}
+	replicas := max(int(math.Round(float64(h.replicas)*weight)), 1)
+	h.addWithReplicas(node, replicas)
 }
 
 // 根据V顺时针找到最近的虚拟节点
 // 再通过虚拟节点映射找到真实节点
 func (h *ConsistentHash) Get(v string) (interface{}, bool) {
+	var node interface{}
+	var ok bool
+	if h.traceHook != nil {
+		node, ok = h.observe(h.trace(v, func() (interface{}, bool) {
+			return h.get(v)
+		}))
+	} else {
+		node, ok = h.observe(h.get(v))
+	}
+	h.compareShadow(v, node)
+	return node, ok
+}
+
+func (h *ConsistentHash) get(v string) (interface{}, bool) {
 	h.lock.RLock()
 	defer h.lock.RUnlock()
+	return h.getLocked(v)
+}
 
+// 调用方需持有读锁
+func (h *ConsistentHash) getLocked(v string) (interface{}, bool) {
+	if node, ok := h.pinned(v); ok {
+		return node, true
+	}
 	// 如果还没有物理节点
 	if len(h.ring) == 0 {
 		return nil, false
 	}
+	node, ok := h.routeLocked(v)
+	if ok && h.spill != nil {
+		node = h.spillLocked(v, node)
+	}
+	return node, ok
+}
+
+// 不含固定路由和过载转移的查找
+// 调用方需持有读锁，且环上至少有一个节点
+func (h *ConsistentHash) routeLocked(v string) (interface{}, bool) {
+	// 代价可能随时变化，不使用缓存
+	if h.proximity != nil {
+		return h.nearestLocked(v), true
+	}
+	if h.cache != nil {
+		if node, ok := h.cache.get(v, h.version); ok {
+			return node, true
+		}
+	}
 	// 计算哈希值
-	hash := h.hashFunc([]byte(v))
+	// 借用缓冲区转换键，避免每次查找都分配内存
+	buf := getBuffer()
+	*buf = append((*buf)[:0], v...)
+	node, ok := h.locate(h.hashFunc(*buf), *buf)
+	putBuffer(buf)
+	if ok && h.cache != nil {
+		h.cache.add(v, node, h.version)
+	}
+	return node, ok
+}
+
+// 同 routeLocked，但不写入查找缓存，用于诊断
+// 调用方需持有读锁，且环上至少有一个节点
+func (h *ConsistentHash) peekRouteLocked(v string) (interface{}, bool) {
+	if h.proximity != nil {
+		return h.nearestLocked(v), true
+	}
+	key := []byte(v)
+	return h.locate(h.hashFunc(key), key)
+}
+
+// 二进制键的查找，结果与 Get(string(b)) 一致
+func (h *ConsistentHash) GetBytes(b []byte) (interface{}, bool) {
+	if h.traceHook != nil {
+		return h.observe(h.trace(string(b), func() (interface{}, bool) {
+			return h.getBytes(b)
+		}))
+	}
+	return h.observe(h.getBytes(b))
+}
+
+func (h *ConsistentHash) getBytes(b []byte) (interface{}, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.getBytesLocked(b)
+}
+
+// 调用方需持有读锁
+func (h *ConsistentHash) getBytesLocked(b []byte) (interface{}, bool) {
+	if node, ok := h.pinned(string(b)); ok {
+		return node, true
+	}
+	if len(h.ring) == 0 {
+		return nil, false
+	}
+	// 就近选择和过载转移按字符串键处理
+	if h.proximity != nil || h.spill != nil {
+		return h.getLocked(string(b))
+	}
+	if b == nil {
+		// nil 表示按哈希值处理冲突，空键需要与 Get("") 一致
+		b = []byte{}
+	}
+	return h.locate(h.hashFunc(b), b)
+}
+
+// 按预先计算好的哈希值查找，省去重复的哈希计算
+// 由于拿不到原始键，固定路由不生效，遇到哈希冲突时选中的节点也可能与 Get 不同
+func (h *ConsistentHash) GetHash(hash uint64) (interface{}, bool) {
+	return h.observe(h.getHash(hash))
+}
+
+func (h *ConsistentHash) getHash(hash uint64) (interface{}, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.getHashLocked(hash)
+}
+
+// 调用方需持有读锁
+func (h *ConsistentHash) getHashLocked(hash uint64) (interface{}, bool) {
+	if len(h.ring) == 0 {
+		return nil, false
+	}
+	return h.locate(hash, nil)
+}
+
+// 上报查找结果，需在释放读锁后调用
+func (h *ConsistentHash) observe(node interface{}, ok bool) (interface{}, bool) {
+	if h.metrics != nil {
+		h.metrics.Lookup(ok)
+	}
+	if ok && (h.metrics != nil || h.traceHook != nil) {
+		h.countLoad(node)
+	}
+	return node, ok
+}
+
+// 根据哈希值顺时针找到最近的虚拟节点
+// key 用于在哈希冲突时重新计算哈希，为 nil 时使用哈希值本身
+// 调用方需持有读锁
+func (h *ConsistentHash) locate(hash uint64, key []byte) (interface{}, bool) {
 	// 二分查找
 	// 因为每次添加节点后虚拟节点都会重新排序
 	// 所以查找到的第一个节点就是我们的目标节点
 	// 取余则可以实现环形列表的效果，顺时针查找节点
-	index := sort.Search(len(h.keys), func(i int) bool {
-		return h.keys[i] >= hash
-	}) % len(h.keys)
+	if h.failover != nil && len(h.failover.keys) > 0 {
+		if node, ok := h.failoverLocked(hash); ok {
+			return node, true
+		}
+	}
+	node, point, ok := h.searchCompiled(hash)
+	if node != nil {
+		return node, true
+	}
+	if !ok {
+		point = h.successorPoint(hash)
+	}
 
 	// 虚拟节点->物理节点映射
-	nodes := h.ring[h.keys[index]]
+	nodes := h.ring[point]
 	switch len(nodes) {
 	case 0:
 		return nil, false
@@ -125,37 +510,57 @@ func (h *ConsistentHash) Get(v string) (interface{}, bool) {
 		return nodes[0], true
 	//存在多个真实节点意味着这出现hash冲突
 	default:
-		innerIndex := h.hashFunc([]byte(innerRepr(v)))
-		pos := int(innerIndex % uint64(len(nodes)))
-		return nodes[pos], true
+		return h.pickChain(nodes, hash, key), true
+	}
+}
+
+// 在冲突链中为键选择节点
+func (h *ConsistentHash) pickChain(nodes []interface{}, hash uint64, key []byte) interface{} {
+	buf := getBuffer()
+	*buf = appendInnerRepr((*buf)[:0], hash, key)
+	innerIndex := h.hashFunc(*buf)
+	putBuffer(buf)
+	if h.tieBreaker != nil {
+		return h.breakTie(nodes, innerIndex)
 	}
+	pos := int(innerIndex % uint64(len(nodes)))
+	return nodes[pos]
 }
 
 // 删除物理节点
 func (h *ConsistentHash) Remove(node string) {
 	h.lock.Lock()
-	defer h.lock.Unlock()
-
+	// 摘除中的节点立即删除
+	h.clearDrainLocked(node)
 	//	节点不存在
 	if !h.containsNode(node) {
+		h.lock.Unlock()
 		return
 	}
-	// 移除虚拟节点映射
-	for i := 0; i < h.replicas; i++ {
-		hash := h.hashFunc([]byte(node + strconv.Itoa(i)))
-		// 二分查找到第一个虚拟节点
-		index := sort.Search(len(h.keys), func(i int) bool {
-			return h.keys[i] >= hash
-		})
+	h.clearTTLLocked(node)
+	h.removeLocked(node)
+	h.settleLocked()
+	h.lock.Unlock()
 
-		if index < len(h.keys) && h.keys[index] == hash {
-			h.keys = append(h.keys[:index], h.keys[index+1:]...)
-		}
+	h.nodeRemoved(node)
+}
+
+// 删除物理节点及其虚拟节点
+// 调用方需持有写锁
+func (h *ConsistentHash) removeLocked(node string) {
+	if !h.containsNode(node) {
+		return
+	}
+	h.version++
+	// 移除虚拟节点映射，切片模式下 keys 中留下墓碑等待压缩
+	for _, hash := range h.points[node] {
 		//虚拟节点删除映射
 		h.removeRingNode(hash, node)
+		h.removePoint(hash)
 	}
 	//删除真实节点
 	h.removeNode(node)
+	delete(h.points, node)
 }
 
 // 删除虚拟-真实节点映射关系
@@ -179,8 +584,8 @@ func (h *ConsistentHash) removeRingNode(hash uint64, node string) {
 	}
 }
 
-func (h *ConsistentHash) addNode(node string) {
-	h.nodes[node] = struct{}{}
+func (h *ConsistentHash) addNode(node string, replicas int) {
+	h.nodes[node] = replicas
 }
 
 // 判断节点是否已存在
@@ -200,3 +605,30 @@ func (h *ConsistentHash) removeNode(node string) {
 func innerRepr(v interface{}) string {
 	return fmt.Sprintf("%d:%v", prime, v)
 }
+
+// 与 innerRepr 的结果相同，但追加到 buf 中且不分配内存
+// key 为 nil 时序列化哈希值
+func appendInnerRepr(buf []byte, hash uint64, key []byte) []byte {
+	buf = strconv.AppendInt(buf, prime, 10)
+	buf = append(buf, ':')
+	if key == nil {
+		return strconv.AppendUint(buf, hash, 10)
+	}
+	return append(buf, key...)
+}
+
+// 查找时使用的临时缓冲区
+var bufferPool = sync.Pool{
+	New: func() any {
+		buf := make([]byte, 0, 64)
+		return &buf
+	},
+}
+
+func getBuffer() *[]byte {
+	return bufferPool.Get().(*[]byte)
+}
+
+func putBuffer(buf *[]byte) {
+	bufferPool.Put(buf)
+}
@@ -0,0 +1,32 @@
+package zero
+
+import "context"
+
+type routeOverrideKey struct{}
+
+// 在 ctx 中指定路由节点，用于灰度或调试时强制访问某个节点
+func WithRouteOverride(ctx context.Context, node string) context.Context {
+	return context.WithValue(ctx, routeOverrideKey{}, node)
+}
+
+// 取出 ctx 中指定的路由节点
+func RouteOverride(ctx context.Context) (string, bool) {
+	node, ok := ctx.Value(routeOverrideKey{}).(string)
+	return node, ok
+}
+
+// 优先使用 ctx 中指定的路由节点
+// 指定的节点不在环上时回退到正常查找
+func (h *ConsistentHash) GetCtx(ctx context.Context, key string) (interface{}, bool) {
+	if node, ok := RouteOverride(ctx); ok {
+		h.lock.RLock()
+		contains := h.containsNode(node)
+		h.lock.RUnlock()
+
+		if contains {
+			return node, true
+		}
+	}
+
+	return h.Get(key)
+}
@@ -0,0 +1,36 @@
+package zero
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsistentHash_GetCtx(t *testing.T) {
+	ch := NewConsistentHash()
+	ch.Add("first")
+	ch.Add("second")
+
+	expect, ok := ch.Get("key")
+	assert.True(t, ok)
+	val, ok := ch.GetCtx(context.Background(), "key")
+	assert.True(t, ok)
+	assert.Equal(t, expect, val)
+
+	other := "first"
+	if expect == other {
+		other = "second"
+	}
+	ctx := WithRouteOverride(context.Background(), other)
+	node, ok := RouteOverride(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, other, node)
+	val, ok = ch.GetCtx(ctx, "key")
+	assert.True(t, ok)
+	assert.Equal(t, other, val)
+
+	val, ok = ch.GetCtx(WithRouteOverride(context.Background(), "unknown"), "key")
+	assert.True(t, ok)
+	assert.Equal(t, expect, val)
+}
@@ -0,0 +1,97 @@
+package zero
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+)
+
+// 调试页面展示的最近拓扑变更数量
+const debugEvents = 20
+
+type (
+	// 调试页面的内容，format=json 时原样输出
+	debugState struct {
+		Version uint64          `json:"version"`
+		Nodes   []debugNode     `json:"nodes"`
+		Events  []TopologyEvent `json:"events,omitempty"`
+		Key     string          `json:"key,omitempty"`
+		Lookup  *LookupInfo     `json:"lookup,omitempty"`
+	}
+
+	debugNode struct {
+		Node     string `json:"node"`
+		Replicas int    `json:"replicas"`
+		// 占有的哈希空间百分比
+		Ownership float64 `json:"ownership"`
+		Draining  bool    `json:"draining,omitempty"`
+	}
+)
+
+var debugPage = template.Must(template.New("chash").Parse(`<!DOCTYPE html>
+<html><head><title>consistenthash</title></head><body>
+<h1>consistenthash (version {{.Version}})</h1>
+<form method="get"><input name="key" value="{{.Key}}" placeholder="key"> <input type="submit" value="lookup"></form>
+{{with .Lookup}}<p>{{$.Key}} &rarr; <b>{{.Node}}</b> (hash {{.Hash}}, point {{.Point}}{{if .Pinned}}, pinned{{end}}{{if .Collision}}, collision{{end}})</p>
+{{else}}{{if .Key}}<p>{{.Key}} &rarr; no node</p>{{end}}{{end}}
+<h2>nodes ({{len .Nodes}})</h2>
+<table border="1"><tr><th>node</th><th>replicas</th><th>ownership</th></tr>
+{{range .Nodes}}<tr><td>{{.Node}}{{if .Draining}} (draining){{end}}</td><td>{{.Replicas}}</td><td>{{printf "%.2f" .Ownership}}%</td></tr>
+{{end}}</table>
+{{if .Events}}<h2>recent topology events</h2>
+<table border="1"><tr><th>version</th><th>time</th><th>op</th><th>node</th><th>replicas</th></tr>
+{{range .Events}}<tr><td>{{.Version}}</td><td>{{.Time.Format "2006-01-02 15:04:05"}}</td><td>{{if eq .Op 1}}remove{{else}}add{{end}}</td><td>{{.Node}}</td><td>{{.Replicas}}</td></tr>
+{{end}}</table>{{end}}
+</body></html>
+`))
+
+// 用于在线查看哈希环的 HTTP 处理器，可挂载在 /debug/chash 下
+// 展示成员、各节点的哈希空间占比和虚拟节点数量、最近的拓扑变更（需开启 WithHistory），
+// 带 key 参数时给出该键的查找结果，带 format=json 参数时以 JSON 输出
+func (h *ConsistentHash) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := h.debugState()
+		if key := r.URL.Query().Get("key"); key != "" {
+			state.Key = key
+			if info, ok := h.Lookup(key); ok {
+				state.Lookup = &info
+			}
+		}
+
+		if r.URL.Query().Get("format") == "json" {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(state)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		debugPage.Execute(w, state)
+	})
+}
+
+func (h *ConsistentHash) debugState() debugState {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	owned := h.ownership()
+	state := debugState{
+		Version: h.version,
+		Nodes:   make([]debugNode, 0, len(h.nodes)),
+	}
+	for _, node := range h.nodesLocked() {
+		_, draining := h.drains[node]
+		state.Nodes = append(state.Nodes, debugNode{
+			Node:      node,
+			Replicas:  h.nodes[node],
+			Ownership: owned[node] * 100,
+			Draining:  draining,
+		})
+	}
+	if h.history != nil {
+		events := h.history.events
+		if len(events) > debugEvents {
+			events = events[len(events)-debugEvents:]
+		}
+		state.Events = append([]TopologyEvent(nil), events...)
+	}
+	return state
+}
@@ -0,0 +1,48 @@
+package zero
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	ch := New(WithHistory(10))
+	ch.Add("a")
+	ch.AddWithWeight("b", 50)
+	ch.Pin("pinned", "b")
+
+	rec := httptest.NewRecorder()
+	ch.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/chash?format=json&key=pinned", nil))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var state debugState
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &state))
+	assert.Equal(t, ch.Epoch(), state.Version)
+	assert.Equal(t, 2, len(state.Nodes))
+	assert.Equal(t, "a", state.Nodes[0].Node)
+	assert.Equal(t, 100, state.Nodes[0].Replicas)
+	assert.Equal(t, 50, state.Nodes[1].Replicas)
+	assert.InDelta(t, 100, state.Nodes[0].Ownership+state.Nodes[1].Ownership, 1e-9)
+	assert.Equal(t, 2, len(state.Events))
+	assert.Equal(t, "b", state.Lookup.Node)
+	assert.True(t, state.Lookup.Pinned)
+}
+
+func TestHandlerHTML(t *testing.T) {
+	ch := New()
+	ch.Add("<a>")
+
+	rec := httptest.NewRecorder()
+	ch.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/?key=foo", nil))
+	body := rec.Body.String()
+	assert.Contains(t, body, "<b>&lt;a&gt;</b>")
+	assert.Contains(t, body, "100.00%")
+	assert.NotContains(t, body, "recent topology events")
+
+	rec = httptest.NewRecorder()
+	New().Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/?key=foo", nil))
+	assert.Contains(t, rec.Body.String(), "foo &rarr; no node")
+}
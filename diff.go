@@ -0,0 +1,100 @@
+package zero
+
+import "sort"
+
+// 与另一个哈希环比较成员
+// added 为 other 中有而当前环中没有的节点，removed 反之，均按字典序排列
+func (h *ConsistentHash) Diff(other *ConsistentHash) (added, removed []string) {
+	if other == h {
+		return nil, nil
+	}
+
+	theirs := other.replicaSnapshot()
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	for node := range theirs {
+		if !h.containsNode(node) {
+			added = append(added, node)
+		}
+	}
+	for node := range h.nodes {
+		if _, ok := theirs[node]; !ok {
+			removed = append(removed, node)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// 合并另一个哈希环的成员
+// 取两者节点的并集，同一节点的虚拟节点数量取较大值，合并结果与合并顺序无关
+func (h *ConsistentHash) Merge(other *ConsistentHash) {
+	if other == h {
+		return
+	}
+
+	// 先复制 other 的成员再加锁，避免两个环互相合并时死锁
+	theirs := other.replicaSnapshot()
+	nodes := make([]string, 0, len(theirs))
+	for node := range theirs {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	h.lock.Lock()
+	pending := make(map[string]int)
+	for _, node := range nodes {
+		if current, ok := h.nodes[node]; !ok || current < theirs[node] {
+			pending[node] = theirs[node]
+		}
+	}
+	if len(pending) == 0 {
+		h.lock.Unlock()
+		return
+	}
+
+	// 整批放入后只排序和收尾一次
+	precomputed := h.precomputePoints(pending)
+	var added []string
+	for _, node := range nodes {
+		replicas, ok := pending[node]
+		if !ok {
+			continue
+		}
+		oldReplicas, existed := h.nodes[node]
+		oldPoints := h.points[node]
+		h.removeLocked(node)
+		if err := h.addPointsLocked(node, replicas, h.pointsFor(precomputed, node, replicas)); err != nil {
+			if existed {
+				h.insertLocked(node, oldReplicas, oldPoints)
+			}
+			continue
+		}
+		added = append(added, node)
+	}
+	h.sortKeys()
+	h.settleLocked()
+	replicas := make([]int, len(added))
+	for i, node := range added {
+		replicas[i] = h.nodes[node]
+	}
+	h.lock.Unlock()
+
+	for i, node := range added {
+		h.nodeAdded(node, replicas[i])
+	}
+}
+
+// 复制节点及其虚拟节点数量
+func (h *ConsistentHash) replicaSnapshot() map[string]int {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	nodes := make(map[string]int, len(h.nodes))
+	for node, replicas := range h.nodes {
+		nodes[node] = replicas
+	}
+	return nodes
+}
@@ -0,0 +1,84 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsistentHash_Diff(t *testing.T) {
+	a := NewConsistentHash()
+	a.Add("first")
+	a.Add("second")
+	b := NewConsistentHash()
+	b.Add("second")
+	b.Add("third")
+	b.Add("fourth")
+
+	added, removed := a.Diff(b)
+	assert.Equal(t, []string{"fourth", "third"}, added)
+	assert.Equal(t, []string{"first"}, removed)
+
+	added, removed = a.Diff(a)
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+}
+
+func TestConsistentHash_Merge(t *testing.T) {
+	create := func(weights map[string]int) *ConsistentHash {
+		ch := NewConsistentHash()
+		for node, weight := range weights {
+			ch.AddWithWeight(node, weight)
+		}
+		return ch
+	}
+
+	a := create(map[string]int{"first": 50, "second": 100})
+	b := create(map[string]int{"second": 20, "third": 80})
+	a.Merge(b)
+	b.Merge(create(map[string]int{"first": 50, "second": 100}))
+
+	assert.Equal(t, []string{"first", "second", "third"}, a.Nodes())
+	assert.Equal(t, minReplicas, a.ReplicaCount("second"))
+	assert.Equal(t, minReplicas*80/TopWeight, a.ReplicaCount("third"))
+	added, removed := a.Diff(b)
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+
+	for i := 0; i < requestSize; i++ {
+		va, _ := a.Get(strconv.Itoa(i))
+		vb, _ := b.Get(strconv.Itoa(i))
+		assert.Equal(t, va, vb)
+	}
+}
+
+func TestConsistentHash_MergeNotifies(t *testing.T) {
+	metrics := &countingMetrics{t: t, added: make(map[string]int)}
+	a := New(WithMetrics(metrics))
+	metrics.ring = a
+	a.Add("first")
+	b := NewConsistentHash()
+	b.Add("first")
+	b.Add("second")
+	b.AddWithWeight("third", 50)
+
+	batches := make(chan []TopologyEvent, 4)
+	cancel := a.Watch(func(events []TopologyEvent) {
+		batches <- events
+	})
+	defer cancel()
+
+	a.Merge(b)
+	// 合并的节点在一次收尾中通知
+	events := <-batches
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, "second", events[0].Node)
+	assert.Equal(t, "third", events[1].Node)
+	assert.Equal(t, map[string]int{"first": minReplicas, "second": minReplicas, "third": minReplicas / 2}, metrics.added)
+
+	// 没有变化时不通知
+	a.Merge(b)
+	a.Add("fourth")
+	assert.Equal(t, "fourth", (<-batches)[0].Node)
+}
@@ -0,0 +1,137 @@
+package zero
+
+import (
+	"sort"
+	"time"
+)
+
+// 默认的摘除宽限期
+const defaultDrainGrace = 30 * time.Second
+
+// 摘除中的节点
+type drainEntry struct {
+	points []uint64
+	timer  Timer
+}
+
+// 节点摘除的宽限期，期间 GetExisting 仍把原有的键路由到该节点
+func WithDrainGrace(grace time.Duration) Option {
+	return func(h *ConsistentHash) {
+		h.drainGrace = grace
+	}
+}
+
+// 平滑摘除节点
+// 节点立即退出 Get 的查找，新的键不再分配给它
+// 宽限期内 GetExisting 仍按摘除前的拓扑路由，已分配给该节点的连接可以从容迁移
+// 宽限期结束后彻底删除，期间重新 Add 该节点则取消摘除
+func (h *ConsistentHash) Drain(node string) {
+	h.lock.Lock()
+	if !h.containsNode(node) {
+		h.lock.Unlock()
+		return
+	}
+
+	entry := &drainEntry{points: h.points[node]}
+	h.clearTTLLocked(node)
+	h.removeLocked(node)
+	h.settleLocked()
+	entry.timer = h.clock.AfterFunc(h.drainGrace, func() {
+		h.finishDrain(node, entry)
+	})
+	if h.drains == nil {
+		h.drains = make(map[string]*drainEntry)
+	}
+	h.drains[node] = entry
+	h.rebuildDrainsLocked()
+	h.lock.Unlock()
+
+	h.nodeRemoved(node)
+}
+
+// 节点是否处于摘除的宽限期内
+func (h *ConsistentHash) Draining(node string) bool {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	_, ok := h.drains[node]
+	return ok
+}
+
+// 按摘除前的拓扑查找节点，摘除中的节点仍然参与查找
+// 没有摘除中的节点时与 Get 一致
+func (h *ConsistentHash) GetExisting(v string) (interface{}, bool) {
+	return h.observe(h.getExisting(v))
+}
+
+func (h *ConsistentHash) getExisting(v string) (interface{}, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if node, ok := h.pinned(v); ok {
+		return node, true
+	}
+	if len(h.drainKeys) == 0 {
+		return h.getLocked(v)
+	}
+
+	key := []byte(v)
+	hash := h.hashFunc(key)
+	drained := h.drainKeys[sort.Search(len(h.drainKeys), func(i int) bool {
+		return h.drainKeys[i] >= hash
+	})%len(h.drainKeys)]
+	if len(h.ring) > 0 {
+		current := h.successorPoint(hash)
+		// 顺时针方向更近的虚拟节点胜出，距离按环形计算
+		if current-hash <= drained-hash {
+			return h.getLocked(v)
+		}
+	}
+
+	nodes := h.drainRing[drained]
+	if len(nodes) == 1 {
+		return nodes[0], true
+	}
+	return h.pickChain(nodes, hash, key), true
+}
+
+// 宽限期结束，彻底删除节点
+func (h *ConsistentHash) finishDrain(node string, entry *drainEntry) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	// 已取消摘除或重新摘除
+	if h.drains[node] != entry {
+		return
+	}
+	delete(h.drains, node)
+	h.rebuildDrainsLocked()
+}
+
+// 取消节点的摘除
+// 调用方需持有写锁
+func (h *ConsistentHash) clearDrainLocked(node string) {
+	if entry, ok := h.drains[node]; ok {
+		entry.timer.Stop()
+		delete(h.drains, node)
+		h.rebuildDrainsLocked()
+	}
+}
+
+// 重建摘除中节点的虚拟节点索引
+// 调用方需持有写锁
+func (h *ConsistentHash) rebuildDrainsLocked() {
+	h.drainKeys = h.drainKeys[:0]
+	h.drainRing = make(map[uint64][]interface{})
+	for node, entry := range h.drains {
+		for _, hash := range entry.points {
+			if _, ok := h.drainRing[hash]; !ok {
+				h.drainKeys = append(h.drainKeys, hash)
+			}
+			h.drainRing[hash] = insertChain(h.drainRing[hash], node)
+		}
+	}
+	sort.Slice(h.drainKeys, func(i, j int) bool {
+		return h.drainKeys[i] < h.drainKeys[j]
+	})
+}
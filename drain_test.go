@@ -0,0 +1,79 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrain(t *testing.T) {
+	clock := newFakeClock()
+	ch := New(WithDrainGrace(time.Minute))
+	ch.clock = clock
+	for i := 0; i < 5; i++ {
+		ch.Add("10.0.0." + strconv.Itoa(i) + ":6379")
+	}
+
+	const drained = "10.0.0.2:6379"
+	before := make(map[string]interface{})
+	for i := 0; i < requestSize; i++ {
+		key := strconv.Itoa(i)
+		before[key], _ = ch.Get(key)
+	}
+
+	ch.Drain(drained)
+	assert.False(t, ch.Contains(drained))
+	assert.True(t, ch.Draining(drained))
+	for key, node := range before {
+		current, _ := ch.Get(key)
+		assert.NotEqual(t, drained, current)
+		existing, _ := ch.GetExisting(key)
+		assert.Equal(t, node, existing, key)
+		if node != drained {
+			assert.Equal(t, node, current, key)
+		}
+	}
+
+	clock.Advance(time.Minute)
+	assert.False(t, ch.Draining(drained))
+	for key := range before {
+		current, _ := ch.Get(key)
+		existing, _ := ch.GetExisting(key)
+		assert.Equal(t, current, existing)
+	}
+}
+
+func TestDrainCancel(t *testing.T) {
+	clock := newFakeClock()
+	ch := New()
+	ch.clock = clock
+	ch.Add("first")
+	ch.Add("second")
+
+	// 重新添加取消摘除
+	ch.Drain("first")
+	ch.Add("first")
+	assert.False(t, ch.Draining("first"))
+	clock.Advance(defaultDrainGrace)
+	assert.True(t, ch.Contains("first"))
+
+	// 删除立即结束摘除
+	ch.Drain("second")
+	ch.Remove("second")
+	assert.False(t, ch.Draining("second"))
+	node, _ := ch.GetExisting("any")
+	assert.Equal(t, "first", node)
+
+	// 只剩摘除中的节点
+	ch.Drain("first")
+	_, ok := ch.Get("any")
+	assert.False(t, ok)
+	node, ok = ch.GetExisting("any")
+	assert.True(t, ok)
+	assert.Equal(t, "first", node)
+
+	ch.Drain("unknown")
+	assert.False(t, ch.Draining("unknown"))
+}
@@ -0,0 +1,30 @@
+package zero
+
+// 当前拓扑的纪元，每次拓扑变更都会递增，与 History 中的版本号一致
+// 重复添加已存在的节点也视为一次变更，固定路由的变化不改变纪元
+func (h *ConsistentHash) Epoch() uint64 {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.version
+}
+
+// 同 Get，同时返回查找所依据的拓扑纪元
+// 分布式的调用方可以比较两次查找的纪元：相同时两次查找基于同一个拓扑，不同时应重新协调
+func (h *ConsistentHash) GetWithEpoch(v string) (interface{}, uint64, bool) {
+	var epoch uint64
+	lookup := func() (interface{}, bool) {
+		h.lock.RLock()
+		defer h.lock.RUnlock()
+		epoch = h.version
+		return h.getLocked(v)
+	}
+
+	var node interface{}
+	var ok bool
+	if h.traceHook != nil {
+		node, ok = h.observe(h.trace(v, lookup))
+	} else {
+		node, ok = h.observe(lookup())
+	}
+	return node, epoch, ok
+}
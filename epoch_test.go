@@ -0,0 +1,61 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetWithEpoch(t *testing.T) {
+	ch := NewConsistentHash()
+	_, epoch, ok := ch.GetWithEpoch("key")
+	assert.False(t, ok)
+	assert.Equal(t, uint64(0), epoch)
+
+	ch.Add("a")
+	ch.Add("b")
+	first := ch.Epoch()
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		expect, _ := ch.Get(key)
+		node, epoch, ok := ch.GetWithEpoch(key)
+		assert.True(t, ok)
+		assert.Equal(t, expect, node)
+		assert.Equal(t, first, epoch)
+	}
+
+	// 固定路由不改变纪元
+	ch.Pin("key", "a")
+	node, epoch, _ := ch.GetWithEpoch("key")
+	assert.Equal(t, "a", node)
+	assert.Equal(t, first, epoch)
+
+	ch.Remove("a")
+	_, epoch, _ = ch.GetWithEpoch("key")
+	assert.True(t, epoch > first)
+	assert.Equal(t, ch.Epoch(), epoch)
+}
+
+func TestGetWithEpochHistory(t *testing.T) {
+	ch := New(WithHistory(10))
+	ch.Add("a")
+	ch.Add("b")
+	node, epoch, _ := ch.GetWithEpoch("key")
+	ch.Remove(node.(string))
+
+	// 纪元即历史版本号，可以重现当时的路由
+	past, _ := ch.At(epoch).Get("key")
+	assert.Equal(t, node, past)
+}
+
+func TestGetWithEpochTrace(t *testing.T) {
+	var traced []interface{}
+	ch := New(WithTraceHook(func(key string, hash uint64, node interface{}, durationNs int64) {
+		traced = append(traced, node)
+	}))
+	ch.Add("a")
+	node, _, ok := ch.GetWithEpoch("key")
+	assert.True(t, ok)
+	assert.Equal(t, []interface{}{node}, traced)
+}
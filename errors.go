@@ -0,0 +1,87 @@
+package zero
+
+import "errors"
+
+var (
+	// 节点名称为空
+	ErrEmptyNode = errors.New("consistenthash: empty node name")
+	// 权重或虚拟节点数量不合法，或换算后没有虚拟节点
+	ErrInvalidWeight = errors.New("consistenthash: invalid weight")
+	// 节点已经在环上
+	ErrNodeExists = errors.New("consistenthash: node already exists")
+	// 节点不在环上
+	ErrNodeNotFound = errors.New("consistenthash: node not found")
+)
+
+// 同 Add，但不接受重复添加，输入不合法时返回错误而不是静默忽略
+func (h *ConsistentHash) AddE(node string) error {
+	return h.addE(node, h.replicas)
+}
+
+// 同 AddWithWeight，但不接受重复添加
+// 权重不大于0，或换算后没有虚拟节点时返回 ErrInvalidWeight
+func (h *ConsistentHash) AddWithWeightE(node string, weight int) error {
+	if weight <= 0 {
+		return ErrInvalidWeight
+	}
+	return h.addE(node, h.replicas*weight/TopWeight)
+}
+
+// 同 AddWithReplicas，但不接受重复添加，也不把超过放大因子的数量截断
+func (h *ConsistentHash) AddWithReplicasE(node string, replicas int) error {
+	if replicas > h.replicas {
+		return ErrInvalidWeight
+	}
+	return h.addE(node, replicas)
+}
+
+// 同 Remove，节点既不在环上也不在摘除中时返回 ErrNodeNotFound
+func (h *ConsistentHash) RemoveE(node string) error {
+	if node == "" {
+		return ErrEmptyNode
+	}
+
+	h.lock.Lock()
+	_, draining := h.drains[node]
+	h.clearDrainLocked(node)
+	if !h.containsNode(node) {
+		h.lock.Unlock()
+		if draining {
+			return nil
+		}
+		return ErrNodeNotFound
+	}
+	h.clearTTLLocked(node)
+	h.removeLocked(node)
+	h.settleLocked()
+	h.lock.Unlock()
+
+	h.nodeRemoved(node)
+	return nil
+}
+
+// 检查并添加节点，冲突策略为 CollisionError 时可能返回 ErrHashCollision
+func (h *ConsistentHash) addE(node string, replicas int) error {
+	if node == "" {
+		return ErrEmptyNode
+	}
+	if replicas <= 0 {
+		return ErrInvalidWeight
+	}
+
+	h.lock.Lock()
+	if h.containsNode(node) {
+		h.lock.Unlock()
+		return ErrNodeExists
+	}
+	h.clearTTLLocked(node)
+	h.clearDrainLocked(node)
+	h.clearCapacityLocked(node)
+	err := h.addWithReplicasLocked(node, replicas)
+	h.lock.Unlock()
+
+	if err == nil {
+		h.nodeAdded(node, replicas)
+	}
+	return err
+}
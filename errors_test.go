@@ -0,0 +1,51 @@
+package zero
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddE(t *testing.T) {
+	ch := New()
+	assert.Equal(t, ErrEmptyNode, ch.AddE(""))
+	assert.Nil(t, ch.AddE("a"))
+	assert.Equal(t, ErrNodeExists, ch.AddE("a"))
+	assert.Equal(t, ErrNodeExists, ch.AddWithWeightE("a", 50))
+	assert.Equal(t, minReplicas, ch.ReplicaCount("a"))
+
+	assert.Equal(t, ErrInvalidWeight, ch.AddWithWeightE("b", 0))
+	assert.Equal(t, ErrInvalidWeight, ch.AddWithWeightE("b", -10))
+	// 换算后没有虚拟节点
+	assert.Equal(t, ErrInvalidWeight, New(WithReplicas(10)).AddWithWeightE("b", 5))
+	assert.Nil(t, ch.AddWithWeightE("b", 200))
+	assert.Equal(t, 2*minReplicas, ch.ReplicaCount("b"))
+
+	assert.Equal(t, ErrInvalidWeight, ch.AddWithReplicasE("c", 0))
+	assert.Equal(t, ErrInvalidWeight, ch.AddWithReplicasE("c", minReplicas+1))
+	assert.Nil(t, ch.AddWithReplicasE("c", 10))
+	assert.Equal(t, []string{"a", "b", "c"}, ch.Nodes())
+}
+
+func TestAddECollision(t *testing.T) {
+	ch := newOverlappingHash(CollisionError)
+	assert.Nil(t, ch.AddE("a"))
+	assert.Equal(t, ErrHashCollision, ch.AddE("b"))
+}
+
+func TestRemoveE(t *testing.T) {
+	ch := New()
+	assert.Equal(t, ErrEmptyNode, ch.RemoveE(""))
+	assert.Equal(t, ErrNodeNotFound, ch.RemoveE("a"))
+
+	ch.Add("a")
+	assert.Nil(t, ch.RemoveE("a"))
+	assert.False(t, ch.Contains("a"))
+	assert.Equal(t, ErrNodeNotFound, ch.RemoveE("a"))
+
+	// 摘除中的节点可以立即删除
+	ch.Add("b")
+	ch.Drain("b")
+	assert.Nil(t, ch.RemoveE("b"))
+	assert.False(t, ch.Draining("b"))
+}
@@ -0,0 +1,117 @@
+package zero
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 键的查找过程，用于回答“键为什么落在这个节点上”
+// 可直接以 JSON 输出，String 给出便于阅读的文本
+type Explanation struct {
+	Key  string `json:"key"`
+	Hash uint64 `json:"hash"`
+	// 命中了固定路由，此时没有虚拟节点的信息
+	Pinned bool `json:"pinned,omitempty"`
+	// 顺时针方向第一个虚拟节点的位置，及其在所有有效虚拟节点中的序号
+	Point uint64 `json:"point"`
+	Index int    `json:"index"`
+	// 该位置上的冲突链，没有冲突时只有一个节点
+	Chain []string `json:"chain,omitempty"`
+	// 冲突时用于选择节点的哈希值、选择方式（modulo 或 tiebreaker）及选中节点在链中的下标
+	InnerHash  uint64 `json:"inner_hash,omitempty"`
+	TieBreak   string `json:"tie_break,omitempty"`
+	ChainIndex int    `json:"chain_index,omitempty"`
+	// 虚拟节点本身的节点被替换的原因：proximity（就近选择）、failover（分散已离开节点的区间）或 spill（过载转移）
+	Override string `json:"override,omitempty"`
+	// 最终的节点，没有节点时为空
+	Node  string `json:"node"`
+	Found bool   `json:"found"`
+}
+
+// 给出键的查找过程，最终节点与 Get 的结果一致，查找本身不计入热点统计
+func (h *ConsistentHash) Explain(key string) Explanation {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	b := []byte(key)
+	e := Explanation{Key: key, Hash: h.hashFunc(b)}
+	if node, ok := h.pinned(key); ok {
+		e.Pinned, e.Node, e.Found = true, node, true
+		return e
+	}
+	if len(h.ring) == 0 {
+		return e
+	}
+
+	e.Point = h.successorPoint(e.Hash)
+	visited, last := false, uint64(0)
+	h.ascend(0, func(point uint64) bool {
+		if point >= e.Point {
+			return false
+		}
+		// 切片模式下冲突的位置会被访问多次
+		if !visited || point != last {
+			e.Index++
+		}
+		visited, last = true, point
+		return true
+	})
+	chain := h.ring[e.Point]
+	for _, node := range chain {
+		e.Chain = append(e.Chain, node.(string))
+	}
+
+	natural := chain[0]
+	if len(chain) > 1 {
+		buf := appendInnerRepr(nil, e.Hash, b)
+		e.InnerHash = h.hashFunc(buf)
+		natural = h.pickChain(chain, e.Hash, b)
+		e.TieBreak = "modulo"
+		if h.tieBreaker != nil {
+			e.TieBreak = "tiebreaker"
+		}
+		for i, node := range chain {
+			if node == natural {
+				e.ChainIndex = i
+			}
+		}
+	}
+
+	// 不经过 getLocked，避免诊断本身计入热点统计
+	node, ok := h.peekRouteLocked(key)
+	if to, spilled := h.spilledToLocked(key); ok && spilled {
+		node = to
+		e.Override = "spill"
+	} else if node != natural {
+		e.Override = "failover"
+		if h.proximity != nil {
+			e.Override = "proximity"
+		}
+	}
+	e.Node, _ = node.(string)
+	e.Found = ok
+	return e
+}
+
+func (e Explanation) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "key %q hash %d\n", e.Key, e.Hash)
+	switch {
+	case e.Pinned:
+		fmt.Fprintf(&sb, "pinned to %s\n", e.Node)
+		return sb.String()
+	case !e.Found:
+		sb.WriteString("no node on the ring\n")
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "point %d (index %d)\n", e.Point, e.Index)
+	if len(e.Chain) > 1 {
+		fmt.Fprintf(&sb, "collision chain %v, inner hash %d, %s picked index %d\n",
+			e.Chain, e.InnerHash, e.TieBreak, e.ChainIndex)
+	}
+	if e.Override != "" {
+		fmt.Fprintf(&sb, "overridden by %s\n", e.Override)
+	}
+	fmt.Fprintf(&sb, "node %s\n", e.Node)
+	return sb.String()
+}
@@ -0,0 +1,87 @@
+package zero
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	ch := NewConsistentHash()
+	for i := 0; i < 5; i++ {
+		ch.Add("node" + strconv.Itoa(i))
+	}
+	points := ch.Clone().sortedPoints()
+
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		e := ch.Explain(key)
+		node, _ := ch.Get(key)
+		assert.Equal(t, node, e.Node)
+		assert.True(t, e.Found)
+		assert.Equal(t, e.Point, points[e.Index])
+		assert.Equal(t, []string{e.Node}, e.Chain)
+		assert.Empty(t, e.Override)
+	}
+
+	ch.Pin("pinned", "node1")
+	e := ch.Explain("pinned")
+	assert.True(t, e.Pinned)
+	assert.Equal(t, "node1", e.Node)
+	assert.Contains(t, e.String(), "pinned to node1")
+
+	e = NewConsistentHash().Explain("key")
+	assert.False(t, e.Found)
+	assert.Contains(t, e.String(), "no node")
+}
+
+func TestExplainCollision(t *testing.T) {
+	ch := NewCustomConsistentHash(minReplicas, func(data []byte) uint64 {
+		return Hash(data) & 0xff
+	})
+	ch.Add("first")
+	ch.Add("second")
+
+	var collided bool
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		e := ch.Explain(key)
+		node, _ := ch.Get(key)
+		assert.Equal(t, node, e.Node)
+		if len(e.Chain) > 1 {
+			collided = true
+			assert.Equal(t, "modulo", e.TieBreak)
+			assert.Equal(t, e.Node, e.Chain[e.ChainIndex])
+			assert.Contains(t, e.String(), "collision chain")
+		}
+	}
+	assert.True(t, collided)
+}
+
+func TestExplainOverride(t *testing.T) {
+	ch := New(WithProximity(func(node string) int {
+		if node == "near" {
+			return 0
+		}
+		return 1
+	}))
+	ch.Add("near")
+	ch.Add("far")
+
+	var overridden bool
+	for i := 0; i < 100; i++ {
+		e := ch.Explain(strconv.Itoa(i))
+		assert.Equal(t, "near", e.Node)
+		if e.Chain[0] == "far" {
+			overridden = true
+			assert.Equal(t, "proximity", e.Override)
+		}
+	}
+	assert.True(t, overridden)
+
+	data, err := json.Marshal(ch.Explain("1"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"node":"near"`)
+}
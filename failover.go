@@ -0,0 +1,139 @@
+package zero
+
+import (
+	"math"
+	"sort"
+)
+
+// 已离开节点的虚拟节点，用于把其区间分散给多个后继
+type failureSpread struct {
+	// 分散给顺时针方向的前几个不同节点
+	successors int
+	// 上一次收尾时的成员
+	last map[string]int
+	// 已离开节点的虚拟节点位置，及其有序的并集
+	removed map[string][]uint64
+	keys    []uint64
+}
+
+// 节点离开后，把原本属于它的键按权重分散给顺时针方向的前 successors 个不同节点，
+// 而不是全部交给每个虚拟节点的下一个节点，避免单个邻居因接收全部流量而连锁过载
+// 每个键在候选节点中按加权的最高随机权重选择，候选节点不变时结果稳定
+// 离开的节点重新加入后恢复原有的路由；记录的虚拟节点随离开的节点数增长，需要时通过 ForgetRemoved 释放
+// successors 不大于1时关闭
+func WithFailureSpread(successors int) Option {
+	return func(h *ConsistentHash) {
+		if successors > 1 {
+			h.failover = &failureSpread{
+				successors: successors,
+				last:       make(map[string]int),
+				removed:    make(map[string][]uint64),
+			}
+		} else {
+			h.failover = nil
+		}
+	}
+}
+
+// 不再分散已离开节点的区间，原本属于它的键回到各虚拟节点的下一个节点
+func (h *ConsistentHash) ForgetRemoved(node string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.failover == nil {
+		return
+	}
+	if _, ok := h.failover.removed[node]; ok {
+		delete(h.failover.removed, node)
+		h.failover.rebuild()
+	}
+}
+
+// 对比上一次收尾时的成员，记录离开节点的虚拟节点，清除重新加入节点的记录
+// 调用方需持有写锁
+func (h *ConsistentHash) trackRemovedLocked() {
+	s := h.failover
+	if s == nil {
+		return
+	}
+
+	added, removed := diffNodes(s.last, h.nodes)
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+	for _, node := range removed {
+		s.removed[node] = h.virtualPoints(node, s.last[node])
+		delete(s.last, node)
+	}
+	for _, node := range added {
+		delete(s.removed, node)
+		s.last[node] = h.nodes[node]
+	}
+	s.rebuild()
+}
+
+func (s *failureSpread) rebuild() {
+	s.keys = s.keys[:0]
+	for _, points := range s.removed {
+		s.keys = append(s.keys, points...)
+	}
+	sort.Slice(s.keys, func(i, j int) bool {
+		return s.keys[i] < s.keys[j]
+	})
+}
+
+func (s *failureSpread) clone() *failureSpread {
+	c := &failureSpread{
+		successors: s.successors,
+		last:       make(map[string]int, len(s.last)),
+		removed:    make(map[string][]uint64, len(s.removed)),
+		keys:       append([]uint64(nil), s.keys...),
+	}
+	for node, replicas := range s.last {
+		c.last[node] = replicas
+	}
+	for node, points := range s.removed {
+		c.removed[node] = points
+	}
+	return c
+}
+
+// 哈希值落在已离开节点的区间内时，在后继节点中按权重选择
+// 调用方需持有读锁，且环上至少有一个节点
+func (h *ConsistentHash) failoverLocked(hash uint64) (interface{}, bool) {
+	keys := h.failover.keys
+	removed := keys[sort.Search(len(keys), func(i int) bool {
+		return keys[i] >= hash
+	})%len(keys)]
+	// 顺时针方向现有的虚拟节点不比离开的虚拟节点远时，正常查找
+	if h.successorPoint(hash)-hash <= removed-hash {
+		return nil, false
+	}
+
+	var best string
+	var bestScore float64
+	seen := make(map[string]struct{}, h.failover.successors)
+	h.ascendRing(hash, func(point uint64) bool {
+		for _, n := range h.ring[point] {
+			node := n.(string)
+			if _, ok := seen[node]; ok {
+				continue
+			}
+			seen[node] = struct{}{}
+			if score := failoverScore(hash, node, h.nodes[node]); best == "" || score < bestScore {
+				best, bestScore = node, score
+			}
+			if len(seen) == h.failover.successors {
+				return false
+			}
+		}
+		return true
+	})
+	return best, true
+}
+
+// 加权的最高随机权重，得分越小越优先，权重越大得分越小的概率越高
+func failoverScore(hash uint64, node string, weight int) float64 {
+	u := (float64(mix64(hash^HashString(node))>>11) + 0.5) / (1 << 53)
+	return -math.Log(u) / float64(weight)
+}
@@ -0,0 +1,82 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailureSpread(t *testing.T) {
+	ch := New(WithFailureSpread(3))
+	plain := NewConsistentHash()
+	for i := 0; i < 10; i++ {
+		node := "node" + strconv.Itoa(i)
+		ch.Add(node)
+		plain.Add(node)
+	}
+	before := plain.Clone()
+	ch.Remove("node3")
+	plain.Remove("node3")
+
+	spread := make(map[interface{}]int)
+	for i := 0; i < requestSize; i++ {
+		key := strconv.Itoa(i)
+		owner, _ := before.Get(key)
+		node, ok := ch.Get(key)
+		assert.True(t, ok)
+		if owner != "node3" {
+			// 其他节点的键不受影响
+			assert.Equal(t, owner, node)
+			continue
+		}
+
+		spread[node]++
+		var candidates []interface{}
+		for _, c := range plain.GetCandidates(key, 3) {
+			candidates = append(candidates, c.Node)
+		}
+		assert.Contains(t, candidates, node)
+	}
+	assert.True(t, len(spread) > 3)
+
+	// 重新加入后恢复原有的路由
+	c := ch.Clone()
+	ch.Add("node3")
+	for i := 0; i < requestSize; i++ {
+		key := strconv.Itoa(i)
+		expect, _ := before.Get(key)
+		actual, _ := ch.Get(key)
+		assert.Equal(t, expect, actual)
+	}
+
+	// 不再分散后与普通的删除一致
+	c.ForgetRemoved("node3")
+	assert.Empty(t, c.failover.keys)
+	for i := 0; i < requestSize; i++ {
+		key := strconv.Itoa(i)
+		expect, _ := plain.Get(key)
+		actual, _ := c.Get(key)
+		assert.Equal(t, expect, actual)
+	}
+}
+
+func TestFailureSpreadWeights(t *testing.T) {
+	ch := New(WithFailureSpread(2))
+	ch.Add("a")
+	ch.AddWithWeight("b", 300)
+	ch.Add("failed")
+	before := ch.Clone()
+	ch.Remove("failed")
+
+	counts := make(map[interface{}]int)
+	for i := 0; i < requestSize; i++ {
+		key := strconv.Itoa(i)
+		if owner, _ := before.Get(key); owner == "failed" {
+			node, _ := ch.Get(key)
+			counts[node]++
+		}
+	}
+	// 权重大的后继分得更多的键
+	assert.True(t, counts["b"] > 2*counts["a"])
+}
@@ -0,0 +1,108 @@
+package zero
+
+import (
+	"sort"
+	"sync"
+)
+
+// 故障检测的回调，SWIM、memberlist 等故障检测器在节点状态变化时调用
+type FailureDetector interface {
+	// 节点疑似故障
+	Suspect(node string)
+	// 确认节点故障
+	Confirm(node string)
+	// 节点恢复存活
+	Alive(node string)
+}
+
+// 把故障检测的结果应用到哈希环上
+// 疑似故障的节点被摘除，宽限期内 GetExisting 仍把原有的键路由给它；确认故障后立即删除；
+// 恢复存活后按故障前的虚拟节点数量和地址重新加入，取消尚未结束的摘除
+// 只恢复经由 Suspect 或 Confirm 离开的节点，新节点的加入仍由成员发现负责
+type HealthSink struct {
+	ring *ConsistentHash
+	lock sync.Mutex
+	// 因故障离开的节点在离开前的状态
+	failed map[string]failedNode
+}
+
+type failedNode struct {
+	replicas int
+	addr     string
+	hasAddr  bool
+}
+
+var _ FailureDetector = (*HealthSink)(nil)
+
+// 创建把故障检测结果应用到 ring 上的 HealthSink
+func NewHealthSink(ring *ConsistentHash) *HealthSink {
+	return &HealthSink{
+		ring:   ring,
+		failed: make(map[string]failedNode),
+	}
+}
+
+func (s *HealthSink) Suspect(node string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.remember(node) {
+		s.ring.Drain(node)
+	}
+}
+
+func (s *HealthSink) Confirm(node string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.remember(node)
+	s.ring.Remove(node)
+}
+
+func (s *HealthSink) Alive(node string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	state, ok := s.failed[node]
+	if !ok {
+		return
+	}
+	delete(s.failed, node)
+	// 期间已被重新加入时不覆盖
+	if s.ring.Contains(node) {
+		return
+	}
+	s.ring.addWithReplicas(node, state.replicas)
+	if state.hasAddr {
+		s.ring.UpdateAddress(node, state.addr)
+	}
+}
+
+// 因故障离开且尚未恢复的节点，按字典序排列
+func (s *HealthSink) Failed() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	nodes := make([]string, 0, len(s.failed))
+	for node := range s.failed {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// 记录仍在哈希环上的节点的状态，节点不在哈希环上时返回 false
+// 调用方需持有锁
+func (s *HealthSink) remember(node string) bool {
+	h := s.ring
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	replicas, ok := h.nodes[node]
+	if !ok {
+		return false
+	}
+	addr, hasAddr := h.addrs[node]
+	s.failed[node] = failedNode{replicas: replicas, addr: addr, hasAddr: hasAddr}
+	return true
+}
@@ -0,0 +1,51 @@
+package zero
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthSink(t *testing.T) {
+	clock := newFakeClock()
+	ch := New(WithDrainGrace(time.Minute))
+	ch.clock = clock
+	ch.AddNode(Node{ID: "a", Addr: "10.0.0.1:80", Weight: 50})
+	ch.Add("b")
+	sink := NewHealthSink(ch)
+
+	// 疑似故障时摘除，恢复后取消摘除
+	sink.Suspect("a")
+	assert.True(t, ch.Draining("a"))
+	assert.Equal(t, []string{"a"}, sink.Failed())
+	sink.Alive("a")
+	assert.False(t, ch.Draining("a"))
+	assert.Equal(t, 50, ch.ReplicaCount("a"))
+	addr, _ := ch.Address("a")
+	assert.Equal(t, "10.0.0.1:80", addr)
+	assert.Empty(t, sink.Failed())
+
+	// 确认故障后删除，恢复后按原有的权重重新加入
+	sink.Suspect("a")
+	sink.Confirm("a")
+	assert.False(t, ch.Contains("a"))
+	assert.False(t, ch.Draining("a"))
+	clock.Advance(time.Hour)
+	sink.Alive("a")
+	assert.Equal(t, 50, ch.ReplicaCount("a"))
+	addr, _ = ch.Address("a")
+	assert.Equal(t, "10.0.0.1:80", addr)
+
+	// 未经故障离开的节点不会因 Alive 加入
+	sink.Alive("c")
+	assert.False(t, ch.Contains("c"))
+	sink.Suspect("c")
+	assert.Empty(t, sink.Failed())
+
+	// 期间已重新加入的节点保持不变
+	sink.Confirm("b")
+	ch.AddWithWeight("b", 200)
+	sink.Alive("b")
+	assert.Equal(t, 200, ch.ReplicaCount("b"))
+}
@@ -0,0 +1,64 @@
+package zero
+
+// 哈希环某一时刻的只读视图
+// 创建后不再变化，查找不加锁，可以放入请求级的结构体或跨越 API 边界传递而不暴露修改方法
+// 不上报指标，不调用跟踪回调，也不使用查找缓存
+type FrozenRing struct {
+	// 冻结时复制的哈希环，之后不再修改，因此读取时无需持有锁
+	ring    *ConsistentHash
+	version uint64
+}
+
+// 冻结当前的拓扑和固定路由，返回只读视图
+// 之后对哈希环的修改不影响已冻结的视图
+func (h *ConsistentHash) Freeze() *FrozenRing {
+	ring, version := h.clone()
+	return &FrozenRing{ring: ring, version: version}
+}
+
+// 查找键所属的物理节点，结果与冻结时的 Get 一致
+func (f *FrozenRing) Get(v string) (interface{}, bool) {
+	return f.ring.getLocked(v)
+}
+
+// 二进制键的查找，结果与 Get(string(b)) 一致
+func (f *FrozenRing) GetBytes(b []byte) (interface{}, bool) {
+	return f.ring.getBytesLocked(b)
+}
+
+// 按预先计算好的哈希值查找，固定路由不生效
+func (f *FrozenRing) GetHash(hash uint64) (interface{}, bool) {
+	return f.ring.getHashLocked(hash)
+}
+
+// 批量查找，结果与 keys 一一对应
+func (f *FrozenRing) GetMany(keys []string) []Result {
+	results := make([]Result, len(keys))
+	f.ring.getManyLocked(keys, results, nil)
+	return results
+}
+
+// 键的前 n 个候选节点，同 ConsistentHash.GetCandidates
+func (f *FrozenRing) GetCandidates(key string, n int) []Candidate {
+	return f.ring.getCandidatesLocked(key, n)
+}
+
+// 冻结时的所有物理节点，按字典序排列
+func (f *FrozenRing) Nodes() []string {
+	return f.ring.nodesLocked()
+}
+
+// 物理节点数量
+func (f *FrozenRing) Len() int {
+	return len(f.ring.nodes)
+}
+
+// 判断物理节点是否在环上
+func (f *FrozenRing) Contains(node string) bool {
+	return f.ring.containsNode(node)
+}
+
+// 冻结时的拓扑纪元，与 ConsistentHash.Epoch 一致
+func (f *FrozenRing) Epoch() uint64 {
+	return f.version
+}
@@ -0,0 +1,82 @@
+package zero
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFreeze(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithTreeStore()}} {
+		ch := New(opts...)
+		for i := 0; i < 10; i++ {
+			ch.Add("node" + strconv.Itoa(i))
+		}
+		ch.Pin("pinned", "node3")
+		frozen := ch.Freeze()
+		assert.Equal(t, ch.Epoch(), frozen.Epoch())
+		assert.Equal(t, ch.Nodes(), frozen.Nodes())
+		assert.Equal(t, 10, frozen.Len())
+		assert.True(t, frozen.Contains("node1"))
+
+		keys := make([]string, 1000)
+		for i := range keys {
+			keys[i] = strconv.Itoa(i)
+		}
+		keys = append(keys, "pinned")
+		expect := ch.GetMany(keys)
+		assert.Equal(t, expect, frozen.GetMany(keys))
+		for _, result := range expect {
+			node, ok := frozen.Get(result.Key)
+			assert.True(t, ok)
+			assert.Equal(t, result.Node, node)
+			node, _ = frozen.GetBytes([]byte(result.Key))
+			assert.Equal(t, result.Node, node)
+		}
+		assert.Equal(t, ch.GetCandidates("key", 3), frozen.GetCandidates("key", 3))
+		expectHash, _ := ch.GetHash(42)
+		actualHash, _ := frozen.GetHash(42)
+		assert.Equal(t, expectHash, actualHash)
+
+		// 之后的修改不影响已冻结的视图
+		ch.Remove("node3")
+		ch.Add("node10")
+		assert.False(t, frozen.Contains("node10"))
+		assert.Equal(t, expect, frozen.GetMany(keys))
+	}
+}
+
+func TestFreezeEmpty(t *testing.T) {
+	frozen := NewConsistentHash().Freeze()
+	_, ok := frozen.Get("key")
+	assert.False(t, ok)
+	_, ok = frozen.GetHash(1)
+	assert.False(t, ok)
+	assert.Empty(t, frozen.Nodes())
+	assert.Nil(t, frozen.GetCandidates("key", 1))
+}
+
+func TestFreezeConcurrent(t *testing.T) {
+	ch := NewConsistentHash()
+	ch.Add("a")
+	ch.Add("b")
+	frozen := ch.Freeze()
+	expect := frozen.GetMany([]string{"1", "2", "3"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				assert.Equal(t, expect, frozen.GetMany([]string{"1", "2", "3"}))
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		ch.Add("n" + strconv.Itoa(i))
+	}
+	wg.Wait()
+}
@@ -0,0 +1,81 @@
+package zero
+
+import (
+	"math"
+	"sort"
+)
+
+// 相邻虚拟节点之间的弧长统计，弧长以占整个哈希空间的比例表示
+type GapStats struct {
+	// 弧的数量，即不同虚拟节点位置的数量
+	Arcs     int
+	Largest  float64
+	Smallest float64
+	Mean     float64
+	P99      float64
+	// 最长的弧顺时针方向的终点，落在该弧上的键都属于这个位置上的节点
+	LargestEnd uint64
+}
+
+// 统计相邻虚拟节点之间的弧长
+// 最长的弧远大于均值时，一个节点独占了一大段连续的哈希空间，该节点故障时这段键会整体迁移到同一个后继节点
+// 环上没有虚拟节点时返回零值
+func (h *ConsistentHash) GapStats() GapStats {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if len(h.ring) == 0 {
+		return GapStats{}
+	}
+
+	var (
+		arcs    []uint64
+		largest uint64
+		stats   GapStats
+	)
+	prev := h.lastPoint()
+	first := true
+	h.ascend(0, func(hash uint64) bool {
+		// 无符号减法加掩码处理了首个虚拟节点跨越0点的情况
+		arc := (hash - prev) & h.hashMask()
+		// 只有一个位置时占满整个环
+		if first && hash == prev {
+			arc = h.hashMask()
+		}
+		first = false
+		prev = hash
+		// 切片模式下冲突的位置会重复出现
+		if arc == 0 {
+			return true
+		}
+
+		if arc > largest {
+			largest, stats.LargestEnd = arc, hash
+		}
+		arcs = append(arcs, arc)
+		return true
+	})
+
+	sort.Slice(arcs, func(i, j int) bool {
+		return arcs[i] < arcs[j]
+	})
+	stats.Arcs = len(arcs)
+	stats.Smallest = h.arcFraction(arcs[0])
+	stats.Largest = h.arcFraction(arcs[len(arcs)-1])
+	stats.Mean = 1 / float64(len(arcs))
+	stats.P99 = h.arcFraction(arcs[int(math.Ceil(float64(len(arcs))*0.99))-1])
+	return stats
+}
+
+// 哈希空间的最大值，如 ketama 的位置只有32位
+func (h *ConsistentHash) hashMask() uint64 {
+	if h.hashBits == 0 || h.hashBits >= 64 {
+		return math.MaxUint64
+	}
+	return 1<<h.hashBits - 1
+}
+
+// 弧长占整个哈希空间的比例
+func (h *ConsistentHash) arcFraction(arc uint64) float64 {
+	return float64(arc) / (float64(h.hashMask()) + 1)
+}
@@ -0,0 +1,49 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGapStats(t *testing.T) {
+	assert.Equal(t, GapStats{}, NewConsistentHash().GapStats())
+
+	// 单个位置占满整个环
+	ch := New(WithReplicas(1))
+	ch.Add("a")
+	stats := ch.GapStats()
+	assert.Equal(t, 1, stats.Arcs)
+	assert.InDelta(t, 1, stats.Largest, 1e-9)
+	assert.Equal(t, ch.points["a"][0], stats.LargestEnd)
+
+	for _, opts := range [][]Option{nil, {WithTreeStore()}} {
+		ch := New(opts...)
+		for i := 0; i < 10; i++ {
+			ch.Add("node" + strconv.Itoa(i))
+		}
+		stats := ch.GapStats()
+		assert.Equal(t, 1000, stats.Arcs)
+		assert.InDelta(t, 0.001, stats.Mean, 1e-12)
+		assert.True(t, stats.Smallest <= stats.Mean)
+		assert.True(t, stats.Mean <= stats.P99)
+		assert.True(t, stats.P99 <= stats.Largest)
+
+		// 最长弧的终点之前没有其他虚拟节点
+		ch.lock.RLock()
+		prev := ch.predecessorPoint(stats.LargestEnd)
+		ch.lock.RUnlock()
+		assert.InDelta(t, stats.Largest, ch.arcFraction(stats.LargestEnd-prev), 1e-12)
+	}
+}
+
+func TestGapStatsCollision(t *testing.T) {
+	// 所有节点落在相同的位置，只有一段弧
+	ch := New(WithReplicas(1), WithHashFunc(func([]byte) uint64 { return 42 }))
+	ch.Add("a")
+	ch.Add("b")
+	stats := ch.GapStats()
+	assert.Equal(t, 1, stats.Arcs)
+	assert.Equal(t, uint64(42), stats.LargestEnd)
+}
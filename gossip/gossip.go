@@ -0,0 +1,358 @@
+// 基于心跳 gossip 的成员传播
+// 每个进程周期性地把自己已知的成员表发送给若干随机成员，
+// 所有参与者据此维护相同的哈希环，不需要中心化的注册中心
+package gossip
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"consistenthash"
+)
+
+const (
+	defaultInterval = 200 * time.Millisecond
+	defaultFanout   = 3
+	// 单个 UDP 报文的最大长度
+	maxPacketSize = 65507
+)
+
+// 节点名称为空
+var ErrEmptyName = errors.New("gossip: empty node name")
+
+type (
+	Config struct {
+		// 节点名称，即加入哈希环的节点
+		Name string
+		// 节点权重，默认为 zero.TopWeight
+		Weight int
+		// UDP 监听地址，如 "127.0.0.1:7946"
+		BindAddr string
+		// 启动时联系的其他成员地址
+		Seeds []string
+		// gossip 周期
+		Interval time.Duration
+		// 每个周期发送的成员数量
+		Fanout int
+		// 超过该时间没有心跳更新的成员会被移出哈希环，默认 10 个周期
+		DeadTimeout time.Duration
+	}
+
+	// 成员信息
+	Member struct {
+		Name   string
+		Addr   string
+		Weight int
+		// 进程每次启动时取当前时间，重启后的记录总是新于重启前的记录
+		Incarnation uint64
+		// 同一次启动内递增的心跳
+		Heartbeat uint64
+		// 主动离开
+		Left bool
+	}
+
+	// 加入 gossip 集群的本地节点
+	// 周期、心跳超时和随机选择成员都使用哈希环的时间来源和随机数来源
+	Node struct {
+		config  Config
+		ring    *zero.ConsistentHash
+		conn    *net.UDPConn
+		lock    sync.Mutex
+		members map[string]*memberState
+		// 下一次 gossip 的定时任务
+		timer zero.Timer
+		done  chan struct{}
+		wg    sync.WaitGroup
+		once  sync.Once
+	}
+
+	memberState struct {
+		Member
+		// 最近一次心跳更新的本地时间
+		updated time.Time
+		// 已被判定为失效或离开
+		dead bool
+	}
+
+	message struct {
+		Members []Member
+	}
+)
+
+// 加入 gossip 集群，并把本节点加入哈希环
+func Join(ring *zero.ConsistentHash, cfg Config) (*Node, error) {
+	if cfg.Name == "" {
+		return nil, ErrEmptyName
+	}
+	if cfg.Weight <= 0 {
+		cfg.Weight = zero.TopWeight
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+	if cfg.Fanout <= 0 {
+		cfg.Fanout = defaultFanout
+	}
+	if cfg.DeadTimeout <= 0 {
+		cfg.DeadTimeout = 10 * cfg.Interval
+	}
+
+	addr, err := net.ResolveUDPAddr("udp", cfg.BindAddr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &Node{
+		config:  cfg,
+		ring:    ring,
+		conn:    conn,
+		members: make(map[string]*memberState),
+		done:    make(chan struct{}),
+	}
+	now := ring.Clock().Now()
+	n.members[cfg.Name] = &memberState{
+		Member: Member{
+			Name:        cfg.Name,
+			Addr:        conn.LocalAddr().String(),
+			Weight:      cfg.Weight,
+			Incarnation: uint64(now.UnixNano()),
+		},
+		updated: now,
+	}
+	ring.AddWithWeight(cfg.Name, cfg.Weight)
+
+	n.wg.Add(1)
+	go n.receive()
+	n.lock.Lock()
+	n.schedule()
+	n.lock.Unlock()
+	return n, nil
+}
+
+// 实际监听的地址
+func (n *Node) Addr() string {
+	return n.conn.LocalAddr().String()
+}
+
+// 当前存活的成员，按名称排序
+func (n *Node) Members() []Member {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	members := make([]Member, 0, len(n.members))
+	for _, m := range n.members {
+		if !m.dead {
+			members = append(members, m.Member)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Name < members[j].Name
+	})
+	return members
+}
+
+// 通知其他成员本节点主动离开，然后关闭
+func (n *Node) Leave() error {
+	n.lock.Lock()
+	self := n.members[n.config.Name]
+	self.Heartbeat++
+	self.Left = true
+	payload, err := n.encode()
+	peers := n.peers(len(n.members))
+	n.lock.Unlock()
+
+	if err == nil {
+		for _, peer := range peers {
+			n.send(peer, payload)
+		}
+	}
+	n.ring.Remove(n.config.Name)
+	return errors.Join(err, n.Close())
+}
+
+// 停止 gossip，不通知其他成员
+func (n *Node) Close() error {
+	var err error
+	n.once.Do(func() {
+		n.lock.Lock()
+		close(n.done)
+		n.timer.Stop()
+		n.lock.Unlock()
+		err = n.conn.Close()
+		n.wg.Wait()
+	})
+	return err
+}
+
+// 安排下一次 gossip，每次执行完再安排，关闭后不再安排
+// 调用方需持有锁
+func (n *Node) schedule() {
+	n.timer = n.ring.Clock().AfterFunc(n.config.Interval, func() {
+		select {
+		case <-n.done:
+			return
+		default:
+		}
+		n.tick()
+
+		n.lock.Lock()
+		defer n.lock.Unlock()
+		select {
+		case <-n.done:
+		default:
+			n.schedule()
+		}
+	})
+}
+
+func (n *Node) tick() {
+	now := n.ring.Clock().Now()
+
+	n.lock.Lock()
+	self := n.members[n.config.Name]
+	self.Heartbeat++
+	self.updated = now
+	for name, m := range n.members {
+		if name == n.config.Name {
+			continue
+		}
+		if !m.dead && now.Sub(m.updated) > n.config.DeadTimeout {
+			m.dead = true
+			n.ring.Remove(name)
+		}
+		// 保留一段时间的失效记录，防止过期的 gossip 消息让节点复活
+		if m.dead && now.Sub(m.updated) > 2*n.config.DeadTimeout {
+			delete(n.members, name)
+		}
+	}
+	payload, err := n.encode()
+	peers := n.peers(n.config.Fanout)
+	n.lock.Unlock()
+
+	if err != nil {
+		return
+	}
+	for _, peer := range peers {
+		n.send(peer, payload)
+	}
+}
+
+func (n *Node) receive() {
+	defer n.wg.Done()
+
+	buf := make([]byte, maxPacketSize)
+	for {
+		size, _, err := n.conn.ReadFromUDP(buf)
+		if err != nil {
+			select {
+			case <-n.done:
+				return
+			default:
+				continue
+			}
+		}
+
+		var msg message
+		if err := json.Unmarshal(buf[:size], &msg); err != nil {
+			continue
+		}
+		n.merge(msg.Members)
+	}
+}
+
+// 合并收到的成员表，按 (Incarnation, Heartbeat) 比较，更大的记录更新
+func (n *Node) merge(members []Member) {
+	now := n.ring.Clock().Now()
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	for _, m := range members {
+		if m.Name == n.config.Name || m.Name == "" {
+			continue
+		}
+
+		current, ok := n.members[m.Name]
+		if ok && !newer(m, current.Member) {
+			continue
+		}
+		if !ok && m.Left {
+			continue
+		}
+
+		state := &memberState{
+			Member:  m,
+			updated: now,
+			dead:    m.Left,
+		}
+		n.members[m.Name] = state
+		switch {
+		case m.Left:
+			n.ring.Remove(m.Name)
+		case !ok || current.dead || current.Weight != m.Weight:
+			n.ring.AddWithWeight(m.Name, m.Weight)
+		}
+	}
+}
+
+// m 是否比 current 新，重启的成员心跳从0开始，但启动时间更晚
+func newer(m, current Member) bool {
+	if m.Incarnation != current.Incarnation {
+		return m.Incarnation > current.Incarnation
+	}
+	return m.Heartbeat > current.Heartbeat
+}
+
+// 调用方需持有锁
+func (n *Node) encode() ([]byte, error) {
+	msg := message{
+		Members: make([]Member, 0, len(n.members)),
+	}
+	for _, m := range n.members {
+		if !m.dead || m.Left {
+			msg.Members = append(msg.Members, m.Member)
+		}
+	}
+	return json.Marshal(msg)
+}
+
+// 随机选择最多 count 个存活成员的地址，没有已知成员时使用种子地址
+// 调用方需持有锁
+func (n *Node) peers(count int) []string {
+	var addrs []string
+	for name, m := range n.members {
+		if name != n.config.Name && !m.dead {
+			addrs = append(addrs, m.Addr)
+		}
+	}
+	if len(addrs) == 0 {
+		addrs = append(addrs, n.config.Seeds...)
+	}
+
+	r := n.ring.Rand()
+	for i := len(addrs) - 1; i > 0; i-- {
+		j := int(r.Float64() * float64(i+1))
+		addrs[i], addrs[j] = addrs[j], addrs[i]
+	}
+	if len(addrs) > count {
+		addrs = addrs[:count]
+	}
+	return addrs
+}
+
+func (n *Node) send(addr string, payload []byte) {
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return
+	}
+	// 发送失败由后续周期的 gossip 弥补
+	_, _ = n.conn.WriteToUDP(payload, udpAddr)
+}
@@ -0,0 +1,113 @@
+package gossip
+
+import (
+	"testing"
+	"time"
+
+	"consistenthash"
+	"consistenthash/chashtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func join(t *testing.T, name string, seeds ...string) (*Node, *zero.ConsistentHash) {
+	ring := zero.NewConsistentHash()
+	node, err := Join(ring, Config{
+		Name:        name,
+		BindAddr:    "127.0.0.1:0",
+		Seeds:       seeds,
+		Interval:    10 * time.Millisecond,
+		DeadTimeout: 200 * time.Millisecond,
+	})
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		node.Close()
+	})
+	return node, ring
+}
+
+func TestGossipMembership(t *testing.T) {
+	n1, r1 := join(t, "first")
+	n2, r2 := join(t, "second", n1.Addr())
+	n3, r3 := join(t, "third", n1.Addr())
+
+	all := []string{"first", "second", "third"}
+	for _, ring := range []*zero.ConsistentHash{r1, r2, r3} {
+		assert.Eventually(t, func() bool {
+			return assert.ObjectsAreEqual(all, ring.Nodes())
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+	assert.Equal(t, 3, len(n2.Members()))
+
+	// 主动离开，其他成员立刻移除
+	assert.NoError(t, n3.Leave())
+	for _, ring := range []*zero.ConsistentHash{r1, r2} {
+		assert.Eventually(t, func() bool {
+			return assert.ObjectsAreEqual([]string{"first", "second"}, ring.Nodes())
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	// 不通知直接退出，超时后被移除
+	assert.NoError(t, n2.Close())
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"first"}, r1.Nodes())
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []Member{n1.Members()[0]}, n1.Members())
+}
+
+func TestJoinWithoutName(t *testing.T) {
+	_, err := Join(zero.NewConsistentHash(), Config{BindAddr: "127.0.0.1:0"})
+	assert.ErrorIs(t, err, ErrEmptyName)
+}
+
+func TestMergeRestarted(t *testing.T) {
+	n, ring := join(t, "self")
+	n.merge([]Member{{Name: "peer", Weight: zero.TopWeight, Incarnation: 1, Heartbeat: 100}})
+	assert.Equal(t, []string{"peer", "self"}, ring.Nodes())
+
+	// 重启后心跳从0开始，启动时间更晚的记录仍然生效
+	n.merge([]Member{{Name: "peer", Weight: 50, Incarnation: 2, Heartbeat: 1}})
+	assert.Equal(t, ring.ReplicaCount("self")/2, ring.ReplicaCount("peer"))
+
+	// 重启前的旧记录被忽略
+	n.merge([]Member{{Name: "peer", Weight: zero.TopWeight, Incarnation: 1, Heartbeat: 200}})
+	members := n.Members()
+	assert.Equal(t, uint64(2), members[0].Incarnation)
+	assert.Equal(t, 50, members[0].Weight)
+}
+
+func TestDeadTimeout(t *testing.T) {
+	clock := chashtest.NewFakeClock(time.Unix(0, 0))
+	ring := zero.New(zero.WithClock(clock), zero.WithRand(chashtest.NewFakeRand(1)))
+	n, err := Join(ring, Config{
+		Name:        "self",
+		BindAddr:    "127.0.0.1:0",
+		Interval:    10 * time.Millisecond,
+		DeadTimeout: 100 * time.Millisecond,
+	})
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		n.Close()
+	})
+	n.merge([]Member{{Name: "peer", Addr: "127.0.0.1:1", Weight: zero.TopWeight, Incarnation: 1}})
+	assert.Equal(t, []string{"peer", "self"}, ring.Nodes())
+
+	// 超时之前仍在环上
+	clock.Advance(100 * time.Millisecond)
+	assert.Equal(t, []string{"peer", "self"}, ring.Nodes())
+	clock.Advance(10 * time.Millisecond)
+	assert.Equal(t, []string{"self"}, ring.Nodes())
+	members := n.Members()
+	assert.Equal(t, 1, len(members))
+	// 每个周期递增一次心跳
+	assert.Equal(t, uint64(11), members[0].Heartbeat)
+
+	// 失效记录保留两倍超时时间
+	clock.Advance(90 * time.Millisecond)
+	n.lock.Lock()
+	assert.Equal(t, 2, len(n.members))
+	n.lock.Unlock()
+	clock.Advance(10 * time.Millisecond)
+	n.lock.Lock()
+	assert.Equal(t, 1, len(n.members))
+	n.lock.Unlock()
+}
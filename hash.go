@@ -2,8 +2,11 @@ package zero
 
 import (
 	"crypto/md5"
+	"encoding/binary"
 	"fmt"
 
+	"consistenthash/hashes"
+
 	"github.com/spaolacci/murmur3"
 )
 
@@ -11,6 +14,55 @@ func Hash(data []byte) uint64 {
 	return murmur3.Sum64(data)
 }
 
+// 取 MD5 摘要的前8字节（大端序）作为哈希值
+// 用于与按 MD5 分布键的旧系统保持兼容
+var Md5Hash Func = func(data []byte) uint64 {
+	digest := md5.Sum(data)
+	return binary.BigEndian.Uint64(digest[:8])
+}
+
+// 字符串的哈希值，与 Hash([]byte(s)) 一致
+func HashString(s string) uint64 {
+	return Hash([]byte(s))
+}
+
+// 128位的 MurmurHash3，返回高低两个64位
+func HashBytes128(data []byte) (hi, lo uint64) {
+	return murmur3.Sum128(data)
+}
+
+// 使用 xxHash64 的一致性哈希
+func NewWithXXHash() *ConsistentHash {
+	return New(WithHashFunc(hashes.XXHash64))
+}
+
+// 使用 FNV-1a 的一致性哈希
+func NewWithFNV() *ConsistentHash {
+	return New(WithHashFunc(hashes.FNV1a))
+}
+
+// 使用 CRC32 的一致性哈希，哈希值按 hashes.CRC32 扩展为64位
+func NewWithCRC32() *ConsistentHash {
+	return New(WithHashFunc(hashes.CRC32))
+}
+
+// 使用128位 MurmurHash3 的一致性哈希
+func NewWithMurmur128() *ConsistentHash {
+	return New(WithHashFunc(hashes.Murmur128))
+}
+
+// 使用带密钥 SipHash 的一致性哈希
+func NewWithSipHash(k0, k1 uint64) *ConsistentHash {
+	return New(WithHashFunc(hashes.SipHash(k0, k1)))
+}
+
+// splitmix64 的混淆函数，用于由一个哈希值派生出分布均匀的新哈希值
+func mix64(z uint64) uint64 {
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
 func Md5(data []byte) []byte {
 	digest := md5.New()
 	digest.Write(data)
@@ -1,6 +1,10 @@
 package zero
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
 
 const (
 	text      = "hello, world!\n"
@@ -18,3 +22,39 @@ func TestHash2(t *testing.T) {
 	t.Log(hash)
 
 }
+
+func TestNewWithBuiltinHashes(t *testing.T) {
+	for _, ch := range []*ConsistentHash{
+		NewWithXXHash(),
+		NewWithFNV(),
+		NewWithCRC32(),
+		NewWithMurmur128(),
+		NewWithSipHash(1, 2),
+	} {
+		ch.Add("first")
+		ch.Add("second")
+		node, ok := ch.Get("any")
+		if !ok || (node != "first" && node != "second") {
+			t.Fatalf("unexpected node: %v", node)
+		}
+	}
+}
+
+func TestMd5Hash(t *testing.T) {
+	assert.Equal(t, md5Digest, Md5Hex([]byte(text)))
+	assert.Equal(t, uint64(0x910c8bc73110b0cd), Md5Hash([]byte(text)))
+
+	ch := NewCustomConsistentHash(minReplicas, Md5Hash)
+	ch.Add("first")
+	node, ok := ch.Get("any")
+	assert.True(t, ok)
+	assert.Equal(t, "first", node)
+}
+
+func TestHashStringAndBytes128(t *testing.T) {
+	assert.Equal(t, Hash([]byte(text)), HashString(text))
+
+	hi, lo := HashBytes128([]byte(text))
+	assert.Equal(t, Hash([]byte(text)), hi)
+	assert.NotEqual(t, hi, lo)
+}
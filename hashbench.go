@@ -0,0 +1,188 @@
+package zero
+
+import (
+	"math"
+	"math/bits"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"consistenthash/hashes"
+)
+
+const (
+	// 每个哈希函数计时的最短时长
+	benchDuration = 10 * time.Millisecond
+	// 卡方检验的桶数量上限，样本较少时按每桶至少 benchPerBucket 个键减少
+	benchMaxBuckets = 1024
+	benchPerBucket  = 10
+)
+
+var (
+	hashFuncsLock sync.RWMutex
+	// 参与 BenchmarkHashFuncs 比较的哈希函数
+	hashFuncs = map[string]Func{
+		"murmur3":    Hash,
+		"md5":        Md5Hash,
+		"xxhash64":   hashes.XXHash64,
+		"fnv1a":      hashes.FNV1a,
+		"crc32":      hashes.CRC32,
+		"murmur3128": hashes.Murmur128,
+	}
+)
+
+// 注册哈希函数，使其参与 BenchmarkHashFuncs 的比较，同名时替换
+func RegisterHashFunc(name string, fn Func) {
+	hashFuncsLock.Lock()
+	defer hashFuncsLock.Unlock()
+	hashFuncs[name] = fn
+}
+
+type (
+	// 一个哈希函数在样本上的表现
+	HashFuncResult struct {
+		Name string
+		Func Func `json:"-"`
+		// 每个键的平均耗时
+		PerKey time.Duration
+		// 哈希值按高位分桶后的卡方统计量，均匀分布时接近 Buckets-1
+		ChiSquare float64
+		// 卡方检验在 0.1% 的显著性水平下未拒绝均匀分布
+		Uniform bool
+	}
+
+	// BenchmarkHashFuncs 的结果
+	Report struct {
+		// 按名称排列的各哈希函数的表现
+		Results []HashFuncResult
+		// 卡方检验的桶数量
+		Buckets int
+		// 推荐的哈希函数：分布均匀的函数中最快的一个，都不均匀时取卡方统计量最小的
+		// 样本为空时为空字符串
+		Recommended string
+	}
+)
+
+// 用调用方的真实键测量已注册的哈希函数的速度和分布均匀度，并给出推荐
+// 哈希函数在实际键上的分布可能与随机键差别很大，如只有32位输出的哈希函数在按高位分桶时极不均匀
+func BenchmarkHashFuncs(sample [][]byte) Report {
+	hashFuncsLock.RLock()
+	names := make([]string, 0, len(hashFuncs))
+	funcs := make(map[string]Func, len(hashFuncs))
+	for name, fn := range hashFuncs {
+		names = append(names, name)
+		funcs[name] = fn
+	}
+	hashFuncsLock.RUnlock()
+	sort.Strings(names)
+
+	if len(sample) == 0 {
+		return Report{}
+	}
+
+	// 桶数量取2的幂，直接用哈希值的高位分桶
+	shift := 64 - bits.Len(uint(min(benchMaxBuckets, max(len(sample)/benchPerBucket, 2)))) + 1
+	buckets := 1 << (64 - shift)
+	report := Report{
+		Results: make([]HashFuncResult, 0, len(names)),
+		Buckets: buckets,
+	}
+	for _, name := range names {
+		fn := funcs[name]
+		chi := chiSquare(fn, sample, shift, buckets)
+		report.Results = append(report.Results, HashFuncResult{
+			Name:      name,
+			Func:      fn,
+			PerKey:    timeHashFunc(fn, sample),
+			ChiSquare: chi,
+			Uniform:   chi <= chiSquareCritical(buckets-1),
+		})
+	}
+
+	best := -1
+	for i, r := range report.Results {
+		if best < 0 {
+			best = i
+			continue
+		}
+		b := report.Results[best]
+		switch {
+		case r.Uniform != b.Uniform:
+			if r.Uniform {
+				best = i
+			}
+		case r.Uniform:
+			if r.PerKey < b.PerKey {
+				best = i
+			}
+		case r.ChiSquare < b.ChiSquare:
+			best = i
+		}
+	}
+	report.Recommended = report.Results[best].Name
+	return report
+}
+
+// 在空的哈希环上换用推荐的哈希函数，环上已有节点或没有推荐时不做修改
+// 已有节点的哈希环可通过 SetHashFunc 换用并重建
+func (r Report) Apply(h *ConsistentHash) bool {
+	var fn Func
+	for _, result := range r.Results {
+		if result.Name == r.Recommended {
+			fn = result.Func
+		}
+	}
+	if fn == nil {
+		return false
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if len(h.nodes) > 0 {
+		return false
+	}
+	h.setHashFuncLocked(fn)
+	return true
+}
+
+// 按哈希值的高位分桶，计算相对均匀分布的卡方统计量
+func chiSquare(fn Func, sample [][]byte, shift, buckets int) float64 {
+	counts := make([]int, buckets)
+	for _, key := range sample {
+		counts[fn(key)>>shift]++
+	}
+
+	expect := float64(len(sample)) / float64(buckets)
+	var chi float64
+	for _, count := range counts {
+		d := float64(count) - expect
+		chi += d * d / expect
+	}
+	return chi
+}
+
+// 自由度为 df 的卡方分布在 0.1% 显著性水平下的临界值，使用 Wilson-Hilferty 近似
+func chiSquareCritical(df int) float64 {
+	const z = 3.090232
+	k := float64(df)
+	t := 1 - 2/(9*k) + z*math.Sqrt(2/(9*k))
+	return k * t * t * t
+}
+
+// 反复计算样本的哈希值，直到累计耗时不少于 benchDuration，返回每个键的平均耗时
+func timeHashFunc(fn Func, sample [][]byte) time.Duration {
+	var sink uint64
+	var rounds int
+	start := time.Now()
+	for rounds == 0 || time.Since(start) < benchDuration {
+		for _, key := range sample {
+			sink ^= fn(key)
+		}
+		rounds++
+	}
+	elapsed := time.Since(start)
+	runtime.KeepAlive(sink)
+	return elapsed / time.Duration(rounds*len(sample))
+}
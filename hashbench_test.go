@@ -0,0 +1,55 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBenchmarkHashFuncs(t *testing.T) {
+	RegisterHashFunc("constant", func([]byte) uint64 { return 42 })
+	t.Cleanup(func() {
+		hashFuncsLock.Lock()
+		delete(hashFuncs, "constant")
+		hashFuncsLock.Unlock()
+	})
+
+	sample := make([][]byte, 20000)
+	for i := range sample {
+		sample[i] = []byte("user:" + strconv.Itoa(i))
+	}
+	report := BenchmarkHashFuncs(sample)
+	assert.Equal(t, 1024, report.Buckets)
+	assert.Equal(t, 7, len(report.Results))
+
+	results := make(map[string]HashFuncResult)
+	for _, r := range report.Results {
+		results[r.Name] = r
+		assert.True(t, r.PerKey >= 0)
+	}
+	assert.True(t, results["murmur3"].Uniform)
+	assert.True(t, results["xxhash64"].Uniform)
+	assert.True(t, results["crc32"].Uniform)
+	assert.False(t, results["constant"].Uniform)
+	assert.True(t, results[report.Recommended].Uniform)
+
+	assert.Equal(t, Report{}, BenchmarkHashFuncs(nil))
+}
+
+func TestReportApply(t *testing.T) {
+	report := Report{
+		Results:     []HashFuncResult{{Name: "length", Func: func(b []byte) uint64 { return uint64(len(b)) }}},
+		Recommended: "length",
+	}
+
+	ch := New(WithSeed(1))
+	assert.True(t, report.Apply(ch))
+	ch.Add("a")
+	// 沿用种子配置，种子追加在数据之前
+	assert.Equal(t, uint64(11), ch.hashFunc([]byte("key")))
+
+	// 环上已有节点时不替换
+	assert.False(t, report.Apply(ch))
+	assert.False(t, Report{}.Apply(New()))
+}
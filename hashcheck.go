@@ -0,0 +1,76 @@
+package zero
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// 哈希函数的输出不足以均匀分布虚拟节点
+var ErrDegenerateHash = errors.New("consistenthash: degenerate hash function")
+
+const (
+	// 检查哈希函数的默认样本数量，也是样本数量的下限
+	minHashSamples = 1 << 12
+	// 每一位的置位次数偏离期望值超过该倍数的标准差时视为不均匀
+	hashBitSigmas = 6
+	// 可接受的碰撞比例，64位的哈希函数在常见的样本数量下几乎不会碰撞
+	maxHashCollisionRate = 0.001
+)
+
+// 用连续的十进制键检查哈希函数，碰撞比例不应超过 0.1%，且输出的每一位都应约有一半的样本置位
+// 只输出32位的哈希、常量哈希或高位不变的哈希都会被拒绝，这些函数会使虚拟节点挤在环的一小段上
+// samples 小于 4096 时使用 4096，检查不通过时返回包装了 ErrDegenerateHash 的错误
+func ValidateHashFunc(fn Func, samples int) error {
+	if fn == nil {
+		return fmt.Errorf("%w: nil", ErrDegenerateHash)
+	}
+	samples = max(samples, minHashSamples)
+
+	var ones [64]int
+	seen := make(map[uint64]struct{}, samples)
+	var buf []byte
+	for i := 0; i < samples; i++ {
+		buf = strconv.AppendInt(buf[:0], int64(i), 10)
+		hash := fn(buf)
+		seen[hash] = struct{}{}
+		for b := range ones {
+			ones[b] += int(hash >> b & 1)
+		}
+	}
+
+	if collisions := samples - len(seen); float64(collisions) > float64(samples)*maxHashCollisionRate {
+		return fmt.Errorf("%w: %d collisions in %d samples", ErrDegenerateHash, collisions, samples)
+	}
+
+	// 置位次数服从 B(samples, 0.5)
+	limit := hashBitSigmas * math.Sqrt(float64(samples)) / 2
+	for b, n := range ones {
+		if math.Abs(float64(n)-float64(samples)/2) > limit {
+			return fmt.Errorf("%w: bit %d set in %d of %d samples", ErrDegenerateHash, b, n, samples)
+		}
+	}
+	return nil
+}
+
+// 创建哈希环和更换哈希函数时先用 samples 个样本检查哈希函数
+// New 中检查不通过时保留默认的哈希函数，SetHashFunc 中检查不通过时不做修改，均通过日志记录 EventHashRejected
+// samples 小于 4096 时使用 4096
+func WithHashValidation(samples int) Option {
+	return func(h *ConsistentHash) {
+		h.hashSamples = max(samples, minHashSamples)
+	}
+}
+
+// 检查即将使用的哈希函数，未开启 WithHashValidation 时总是通过
+func (h *ConsistentHash) acceptHashFunc(fn Func) bool {
+	if h.hashSamples == 0 {
+		return true
+	}
+	err := ValidateHashFunc(fn, h.hashSamples)
+	if err != nil && h.logger != nil {
+		h.logger.Info(EventHashRejected, "error", err)
+	}
+	return err == nil
+}
@@ -0,0 +1,71 @@
+package zero
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"consistenthash/hashes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateHashFunc(t *testing.T) {
+	for name, fn := range map[string]Func{
+		"murmur3":    Hash,
+		"md5":        Md5Hash,
+		"xxhash64":   hashes.XXHash64,
+		"murmur3128": hashes.Murmur128,
+		"crc32":      hashes.CRC32,
+	} {
+		assert.Nil(t, ValidateHashFunc(fn, 0), name)
+	}
+
+	for name, fn := range map[string]Func{
+		"constant": func([]byte) uint64 { return 42 },
+		"length":   func(data []byte) uint64 { return uint64(len(data)) },
+		"lower32":  lower32,
+	} {
+		err := ValidateHashFunc(fn, 0)
+		assert.True(t, errors.Is(err, ErrDegenerateHash), name)
+	}
+	assert.True(t, errors.Is(ValidateHashFunc(nil, 0), ErrDegenerateHash))
+}
+
+func TestValidateHashFuncCollisions(t *testing.T) {
+	// 每一位都均匀但只有256种取值
+	fn := func(data []byte) uint64 {
+		return Hash([]byte{byte(Hash(data))})
+	}
+	err := ValidateHashFunc(fn, 0)
+	assert.ErrorContains(t, err, "collisions")
+}
+
+// 只有32位输出的哈希函数
+func lower32(data []byte) uint64 {
+	return Hash(data) & 0xffffffff
+}
+
+func TestWithHashValidation(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	// 拒绝后保留默认的哈希函数
+	ch := NewCustomConsistentHash(100, lower32, WithHashValidation(0), WithLogger(logger))
+	ch.Add("a")
+	assert.Contains(t, ch.points["a"], Hash(DefaultReplicaKey("a", 0)))
+	assert.Contains(t, buf.String(), EventHashRejected)
+
+	buf.Reset()
+	ch.Add("b")
+	before, _ := ch.Get("key")
+	assert.Equal(t, float64(0), ch.SetHashFunc(lower32))
+	assert.Contains(t, buf.String(), EventHashRejected)
+	after, _ := ch.Get("key")
+	assert.Equal(t, before, after)
+	assert.True(t, ch.SetHashFunc(hashes.XXHash64) > 0)
+
+	// 未开启时不检查
+	ch = NewCustomConsistentHash(100, lower32)
+	ch.Add("a")
+	assert.Contains(t, ch.points["a"], lower32(DefaultReplicaKey("a", 0)))
+}
@@ -0,0 +1,60 @@
+// 常用哈希函数，签名与 zero.Func 一致，可直接作为一致性哈希的哈希函数
+package hashes
+
+import (
+	"hash/crc32"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/dchest/siphash"
+	"github.com/spaolacci/murmur3"
+)
+
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+// 64位 xxHash
+func XXHash64(data []byte) uint64 {
+	return xxhash.Sum64(data)
+}
+
+// 64位 FNV-1a
+// 直接展开计算，避免 hash/fnv 的对象分配
+func FNV1a(data []byte) uint64 {
+	hash := uint64(fnvOffset64)
+	for _, c := range data {
+		hash ^= uint64(c)
+		hash *= fnvPrime64
+	}
+	return hash
+}
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// 基于 CRC32 的64位哈希：高32位为 IEEE 多项式、低32位为 Castagnoli 多项式的校验和，再经 MurmurHash3 的 fmix64 混合
+// 单个 CRC32 只有32位输出，虚拟节点会挤在环的一小段上，无法用于按 2^64 计算的哈希空间
+// 两种多项式在支持的平台上均有硬件加速
+func CRC32(data []byte) uint64 {
+	hash := uint64(crc32.ChecksumIEEE(data))<<32 | uint64(crc32.Checksum(data, castagnoli))
+	hash ^= hash >> 33
+	hash *= 0xff51afd7ed558ccd
+	hash ^= hash >> 33
+	hash *= 0xc4ceb9fe1a85ec53
+	hash ^= hash >> 33
+	return hash
+}
+
+// 128位 MurmurHash3，取高64位
+func Murmur128(data []byte) uint64 {
+	h1, _ := murmur3.Sum128(data)
+	return h1
+}
+
+// 带密钥的 SipHash-2-4
+// 不同部署使用不同的密钥，键到节点的映射便无法被预测
+func SipHash(k0, k1 uint64) func(data []byte) uint64 {
+	return func(data []byte) uint64 {
+		return siphash.Hash(k0, k1, data)
+	}
+}
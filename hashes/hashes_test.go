@@ -0,0 +1,56 @@
+package hashes
+
+import (
+	"hash/fnv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const text = "hello, world!\n"
+
+func TestFNV1a(t *testing.T) {
+	h := fnv.New64a()
+	h.Write([]byte(text))
+	assert.Equal(t, h.Sum64(), FNV1a([]byte(text)))
+	assert.Equal(t, uint64(fnvOffset64), FNV1a(nil))
+}
+
+func TestCRC32(t *testing.T) {
+	assert.Equal(t, uint64(0), CRC32(nil))
+	// 输出占满64位
+	assert.True(t, CRC32([]byte(text)) > 1<<32-1)
+}
+
+func TestSipHash(t *testing.T) {
+	// SipHash-2-4 参考实现的测试向量：key 为 00..0f，消息为空
+	fn := SipHash(0x0706050403020100, 0x0f0e0d0c0b0a0908)
+	assert.Equal(t, uint64(0x726fdb47dd0e0e31), fn(nil))
+	assert.NotEqual(t, fn([]byte(text)), SipHash(1, 2)([]byte(text)))
+}
+
+func TestDeterministic(t *testing.T) {
+	fns := map[string]func([]byte) uint64{
+		"xxhash64":  XXHash64,
+		"fnv1a":     FNV1a,
+		"crc32":     CRC32,
+		"murmur128": Murmur128,
+		"siphash":   SipHash(1, 2),
+	}
+	for name, fn := range fns {
+		assert.Equal(t, fn([]byte(text)), fn([]byte(text)), name)
+		assert.NotEqual(t, fn([]byte("a")), fn([]byte("b")), name)
+	}
+}
+
+func BenchmarkXXHash64(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		XXHash64([]byte(text))
+	}
+}
+
+func BenchmarkFNV1a(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		FNV1a([]byte(text))
+	}
+}
@@ -0,0 +1,136 @@
+package zero
+
+import (
+	"sort"
+	"sync"
+)
+
+// 两级一致性哈希
+// 键先映射到分组（如数据中心），再映射到分组内的节点
+// 所有分组在第一级中权重相同，单个节点故障只会让键在本组内迁移，不会跨组移动；
+// 只有分组内的节点全部下线时，该组的键才会迁往其他分组
+type HierarchicalHash struct {
+	// 分组的哈希环
+	groups *ConsistentHash
+	// 分组内节点的哈希环
+	members map[string]*ConsistentHash
+	// 节点所属的分组
+	nodes map[string]string
+	// 读写锁
+	lock sync.RWMutex
+}
+
+// 按分组及其节点创建两级哈希，节点名在所有分组中唯一
+func NewHierarchicalHash(groups map[string][]string) *HierarchicalHash {
+	h := &HierarchicalHash{
+		groups:  NewConsistentHash(),
+		members: make(map[string]*ConsistentHash),
+		nodes:   make(map[string]string),
+	}
+
+	names := make([]string, 0, len(groups))
+	for group := range groups {
+		names = append(names, group)
+	}
+	sort.Strings(names)
+	for _, group := range names {
+		for _, node := range groups[group] {
+			h.AddNode(group, node)
+		}
+	}
+	return h
+}
+
+// 向分组中添加节点，分组不存在时自动创建
+// 节点已属于其他分组时先从原分组中移除
+func (h *HierarchicalHash) AddNode(group, node string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if current, ok := h.nodes[node]; ok {
+		if current == group {
+			return
+		}
+		h.removeLocked(node)
+	}
+
+	ring, ok := h.members[group]
+	if !ok {
+		ring = NewConsistentHash()
+		h.members[group] = ring
+		h.groups.Add(group)
+	}
+	ring.Add(node)
+	h.nodes[node] = group
+}
+
+// 删除节点，分组内没有节点时删除分组
+func (h *HierarchicalHash) Remove(node string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.removeLocked(node)
+}
+
+// 调用方需持有写锁
+func (h *HierarchicalHash) removeLocked(node string) {
+	group, ok := h.nodes[node]
+	if !ok {
+		return
+	}
+
+	delete(h.nodes, node)
+	ring := h.members[group]
+	ring.Remove(node)
+	if ring.Len() == 0 {
+		delete(h.members, group)
+		h.groups.Remove(group)
+	}
+}
+
+// 键所在的节点
+func (h *HierarchicalHash) Get(key string) (interface{}, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	group, ok := h.groups.Get(key)
+	if !ok {
+		return nil, false
+	}
+	return h.members[group.(string)].Get(key)
+}
+
+// 键所在的分组
+func (h *HierarchicalHash) GetGroup(key string) (string, bool) {
+	group, ok := h.groups.Get(key)
+	if !ok {
+		return "", false
+	}
+	return group.(string), true
+}
+
+// 节点所属的分组
+func (h *HierarchicalHash) GroupOf(node string) (string, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	group, ok := h.nodes[node]
+	return group, ok
+}
+
+// 当前所有分组，按字典序排列
+func (h *HierarchicalHash) Groups() []string {
+	return h.groups.Nodes()
+}
+
+// 分组内的节点，按字典序排列
+func (h *HierarchicalHash) Members(group string) []string {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	ring, ok := h.members[group]
+	if !ok {
+		return nil
+	}
+	return ring.Nodes()
+}
@@ -0,0 +1,59 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHierarchicalHash(t *testing.T) {
+	h := NewHierarchicalHash(map[string][]string{
+		"us-east": {"10.0.0.1:6379", "10.0.0.2:6379", "10.0.0.3:6379"},
+		"eu-west": {"10.1.0.1:6379", "10.1.0.2:6379"},
+	})
+	assert.Equal(t, []string{"eu-west", "us-east"}, h.Groups())
+	assert.Equal(t, []string{"10.1.0.1:6379", "10.1.0.2:6379"}, h.Members("eu-west"))
+
+	before := make(map[string]interface{})
+	groups := make(map[string]string)
+	for i := 0; i < requestSize; i++ {
+		key := strconv.Itoa(i)
+		node, ok := h.Get(key)
+		assert.True(t, ok)
+		group, _ := h.GetGroup(key)
+		nodeGroup, _ := h.GroupOf(node.(string))
+		assert.Equal(t, group, nodeGroup)
+		before[key] = node
+		groups[key] = group
+	}
+
+	// 单个节点故障时键不跨组迁移
+	h.Remove("10.0.0.2:6379")
+	for key, node := range before {
+		current, _ := h.Get(key)
+		group, _ := h.GetGroup(key)
+		assert.Equal(t, groups[key], group)
+		if node != "10.0.0.2:6379" {
+			assert.Equal(t, node, current)
+		}
+	}
+
+	// 分组内节点全部下线后分组被删除
+	h.Remove("10.1.0.1:6379")
+	h.Remove("10.1.0.2:6379")
+	assert.Equal(t, []string{"us-east"}, h.Groups())
+	assert.Nil(t, h.Members("eu-west"))
+
+	// 节点换组
+	h.AddNode("ap-south", "10.0.0.1:6379")
+	group, _ := h.GroupOf("10.0.0.1:6379")
+	assert.Equal(t, "ap-south", group)
+	assert.Equal(t, []string{"10.0.0.3:6379"}, h.Members("us-east"))
+
+	empty := NewHierarchicalHash(nil)
+	_, ok := empty.Get("any")
+	assert.False(t, ok)
+	_, ok = empty.GetGroup("any")
+	assert.False(t, ok)
+}
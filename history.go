@@ -0,0 +1,202 @@
+package zero
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// 版本号不在保留的拓扑历史中
+var ErrVersionNotFound = errors.New("consistenthash: version not in history")
+
+// 拓扑变更的类型
+type TopologyOp int
+
+const (
+	// 节点加入，或虚拟节点数量发生变化
+	TopologyAdd TopologyOp = iota
+	// 节点离开
+	TopologyRemove
+)
+
+type (
+	// 一次拓扑变更
+	TopologyEvent struct {
+		// 变更后的版本号，同一操作涉及多个节点时版本号相同
+		Version uint64
+		Time    time.Time
+		Op      TopologyOp
+		Node    string
+		// 变更后的虚拟节点数量，节点离开时为0
+		Replicas int
+	}
+
+	// 保留最近的拓扑变更，以及最早一条变更之前的成员
+	topologyHistory struct {
+		limit  int
+		events []TopologyEvent
+		// base 为版本号 baseVersion 时的成员
+		base        map[string]int
+		baseVersion uint64
+		// 最近一次记录时的成员，用于计算下一次的变更
+		last map[string]int
+	}
+)
+
+// 记录最近 limit 次拓扑变更，limit 不大于0时不记录
+// 记录后可通过 History 查看变更时间，通过 At 重现历史版本的路由，通过 Rollback 撤销错误的变更
+// 只记录成员和虚拟节点数量，固定路由不在历史中
+func WithHistory(limit int) Option {
+	return func(h *ConsistentHash) {
+		if limit > 0 {
+			h.history = &topologyHistory{
+				limit: limit,
+				base:  make(map[string]int),
+				last:  make(map[string]int),
+			}
+		} else {
+			h.history = nil
+		}
+	}
+}
+
+// 保留的拓扑变更，按版本号升序排列，未开启 WithHistory 时返回 nil
+func (h *ConsistentHash) History() []TopologyEvent {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if h.history == nil {
+		return nil
+	}
+	return append([]TopologyEvent(nil), h.history.events...)
+}
+
+// 重建版本号为 version 时的哈希环，可用于查询某个键当时的路由
+// 配置沿用当前的哈希环，不含固定路由，版本不在保留的历史中时返回 nil
+func (h *ConsistentHash) At(version uint64) *ConsistentHash {
+	h.lock.RLock()
+	nodes, ok := h.nodesAtLocked(version)
+	h.lock.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	c, _ := h.clone()
+	c.pins = nil
+	c.rebuild(nodes)
+	c.recompileLocked()
+	return c
+}
+
+// 把成员恢复到版本号为 version 时的状态，回滚本身作为一次新的变更记入历史
+// 临时节点、摘除中的节点和节点容量一并清除，固定路由保持不变
+func (h *ConsistentHash) Rollback(version uint64) error {
+	h.lock.Lock()
+	nodes, ok := h.nodesAtLocked(version)
+	if !ok {
+		h.lock.Unlock()
+		return ErrVersionNotFound
+	}
+
+	for node := range h.ttls {
+		h.clearTTLLocked(node)
+	}
+	for node := range h.drains {
+		h.clearDrainLocked(node)
+	}
+	h.capacities = nil
+	h.rebuild(nodes)
+	h.version++
+	h.settleLocked()
+	h.lock.Unlock()
+	return nil
+}
+
+// 版本号为 version 时的成员
+// 调用方需持有读锁
+func (h *ConsistentHash) nodesAtLocked(version uint64) (map[string]int, bool) {
+	hist := h.history
+	if hist == nil || version < hist.baseVersion || version > h.version {
+		return nil, false
+	}
+
+	nodes := make(map[string]int, len(hist.base))
+	for node, replicas := range hist.base {
+		nodes[node] = replicas
+	}
+	for _, event := range hist.events {
+		if event.Version > version {
+			break
+		}
+		applyEvent(nodes, event)
+	}
+	return nodes, true
+}
+
+// 对比上一次记录的成员，把变化记入历史
+// 调用方需持有写锁
+func (h *ConsistentHash) recordLocked() {
+	hist := h.history
+	if hist == nil {
+		return
+	}
+
+	added, removed := diffNodes(hist.last, h.nodes)
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+	now := h.clock.Now()
+	for _, node := range removed {
+		hist.events = append(hist.events, TopologyEvent{
+			Version: h.version,
+			Time:    now,
+			Op:      TopologyRemove,
+			Node:    node,
+		})
+		delete(hist.last, node)
+	}
+	for _, node := range added {
+		hist.events = append(hist.events, TopologyEvent{
+			Version:  h.version,
+			Time:     now,
+			Op:       TopologyAdd,
+			Node:     node,
+			Replicas: h.nodes[node],
+		})
+		hist.last[node] = h.nodes[node]
+	}
+
+	// 超出保留数量的变更合并到 base 中
+	if trim := len(hist.events) - hist.limit; trim > 0 {
+		for _, event := range hist.events[:trim] {
+			applyEvent(hist.base, event)
+			hist.baseVersion = event.Version
+		}
+		hist.events = append(hist.events[:0], hist.events[trim:]...)
+	}
+}
+
+func applyEvent(nodes map[string]int, event TopologyEvent) {
+	if event.Op == TopologyRemove {
+		delete(nodes, event.Node)
+	} else {
+		nodes[event.Node] = event.Replicas
+	}
+}
+
+// 从 from 到 to 加入（或虚拟节点数量变化）和离开的节点，均按字典序排列
+func diffNodes(from, to map[string]int) (added, removed []string) {
+	for node, replicas := range to {
+		if current, ok := from[node]; !ok || current != replicas {
+			added = append(added, node)
+		}
+	}
+	for node := range from {
+		if _, ok := to[node]; !ok {
+			removed = append(removed, node)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
@@ -0,0 +1,123 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	clock := newFakeClock()
+	ch := New(WithHistory(10))
+	ch.clock = clock
+
+	ch.Add("a")
+	clock.Advance(time.Minute)
+	ch.AddWithWeight("b", 50)
+	clock.Advance(time.Minute)
+	ch.Remove("a")
+	ch.Add("b")
+	// 成员没有变化时不记录
+	ch.Add("b")
+
+	events := ch.History()
+	assert.Equal(t, 4, len(events))
+	assert.Equal(t, TopologyEvent{Version: events[0].Version, Time: time.Unix(0, 0),
+		Op: TopologyAdd, Node: "a", Replicas: 100}, events[0])
+	assert.Equal(t, TopologyEvent{Version: events[1].Version, Time: time.Unix(60, 0),
+		Op: TopologyAdd, Node: "b", Replicas: 50}, events[1])
+	assert.Equal(t, TopologyEvent{Version: events[2].Version, Time: time.Unix(120, 0),
+		Op: TopologyRemove, Node: "a"}, events[2])
+	assert.Equal(t, TopologyEvent{Version: events[3].Version, Time: time.Unix(120, 0),
+		Op: TopologyAdd, Node: "b", Replicas: 100}, events[3])
+	for i := 1; i < len(events); i++ {
+		assert.True(t, events[i].Version > events[i-1].Version)
+	}
+
+	assert.Nil(t, New().History())
+}
+
+func TestHistoryAt(t *testing.T) {
+	ch := New(WithHistory(10))
+	ch.Add("a")
+	ch.Add("b")
+	before := ch.Clone()
+	version := ch.History()[1].Version
+	ch.Add("c")
+	ch.Remove("a")
+
+	past := ch.At(version)
+	assert.ElementsMatch(t, []string{"a", "b"}, past.Nodes())
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		expect, _ := before.Get(key)
+		actual, _ := past.Get(key)
+		assert.Equal(t, expect, actual)
+	}
+	// At 得到的是独立的哈希环
+	past.Add("d")
+	assert.ElementsMatch(t, []string{"b", "c"}, ch.Nodes())
+
+	assert.Empty(t, ch.At(0).Nodes())
+	assert.Nil(t, ch.At(ch.History()[3].Version+100))
+	assert.Nil(t, New().At(0))
+}
+
+func TestHistoryRollback(t *testing.T) {
+	ch := New(WithHistory(10))
+	ch.Add("a")
+	ch.Add("b")
+	ch.Pin("key", "a")
+	version := ch.History()[1].Version
+	ch.Remove("a")
+	ch.AddWithWeight("c", 50)
+
+	assert.Nil(t, ch.Rollback(version))
+	assert.ElementsMatch(t, []string{"a", "b"}, ch.Nodes())
+	assert.Equal(t, map[string]string{"key": "a"}, ch.Snapshot().Pins)
+
+	// 回滚本身也记入历史，可以再次撤销
+	events := ch.History()
+	removed, added := events[len(events)-2], events[len(events)-1]
+	assert.Equal(t, removed.Version, added.Version)
+	assert.Equal(t, TopologyEvent{Version: removed.Version, Time: removed.Time,
+		Op: TopologyRemove, Node: "c"}, removed)
+	assert.Equal(t, TopologyEvent{Version: added.Version, Time: added.Time,
+		Op: TopologyAdd, Node: "a", Replicas: 100}, added)
+	assert.Nil(t, ch.Rollback(events[len(events)-3].Version))
+	assert.ElementsMatch(t, []string{"b", "c"}, ch.Nodes())
+
+	assert.Equal(t, ErrVersionNotFound, New().Rollback(0))
+}
+
+func TestHistoryLimit(t *testing.T) {
+	ch := New(WithHistory(2))
+	ch.Add("a")
+	ch.Add("b")
+	first := ch.History()[0].Version
+	ch.Add("c")
+
+	events := ch.History()
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, "b", events[0].Node)
+	// 最早的版本已合并，不能再重建
+	assert.Nil(t, ch.At(first-1))
+	assert.ElementsMatch(t, []string{"a"}, ch.At(first).Nodes())
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, ch.At(events[1].Version).Nodes())
+	assert.Equal(t, ErrVersionNotFound, ch.Rollback(0))
+}
+
+func TestHistoryTTL(t *testing.T) {
+	clock := newFakeClock()
+	ch := New(WithHistory(10))
+	ch.clock = clock
+	ch.AddWithTTL("a", time.Second)
+	clock.Advance(2 * time.Second)
+
+	events := ch.History()
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, TopologyRemove, events[1].Op)
+	assert.Equal(t, time.Unix(2, 0), events[1].Time)
+}
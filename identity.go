@@ -0,0 +1,93 @@
+package zero
+
+// 以稳定的逻辑 ID 标识的节点
+// 哈希环只按 ID 计算虚拟节点，地址变化（如重启后 IP 改变）不会迁移任何键
+type Node struct {
+	// 逻辑 ID，作为哈希环上的物理节点
+	ID string
+	// 节点当前的地址，可通过 UpdateAddress 修改
+	Addr string
+	// 权重，含义同 AddWithWeight，不大于0时为 TopWeight
+	Weight int
+}
+
+// 按逻辑 ID 添加节点并记录其地址，重复添加会更新权重和地址
+// Get 等查找方法返回的是 ID，可通过 Address 或 GetNode 得到地址
+func (h *ConsistentHash) AddNode(n Node) {
+	weight := n.Weight
+	if weight <= 0 {
+		weight = TopWeight
+	}
+	replicas := h.replicas * weight / TopWeight
+
+	h.lock.Lock()
+	h.clearTTLLocked(n.ID)
+	h.clearDrainLocked(n.ID)
+	h.clearCapacityLocked(n.ID)
+	err := h.addWithReplicasLocked(n.ID, replicas)
+	if err == nil {
+		if h.addrs == nil {
+			h.addrs = make(map[string]string)
+		}
+		h.addrs[n.ID] = n.Addr
+	}
+	h.lock.Unlock()
+
+	if err == nil {
+		h.nodeAdded(n.ID, replicas)
+	}
+}
+
+// 修改节点的地址，不改变哈希环，节点不存在时返回 false
+func (h *ConsistentHash) UpdateAddress(id, addr string) bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if !h.containsNode(id) {
+		return false
+	}
+	if h.addrs == nil {
+		h.addrs = make(map[string]string)
+	}
+	h.addrs[id] = addr
+	return true
+}
+
+// 节点的地址，节点不存在或没有记录地址时 ok 为 false
+func (h *ConsistentHash) Address(id string) (string, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if !h.containsNode(id) {
+		return "", false
+	}
+	addr, ok := h.addrs[id]
+	return addr, ok
+}
+
+// 与 Get 的查找结果一致，同时给出节点的地址和由虚拟节点数量换算的权重
+func (h *ConsistentHash) GetNode(v string) (Node, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	node, ok := h.getLocked(v)
+	if !ok {
+		return Node{}, false
+	}
+	id := node.(string)
+	return Node{
+		ID:     id,
+		Addr:   h.addrs[id],
+		Weight: h.nodes[id] * TopWeight / h.replicas,
+	}, true
+}
+
+// 删除已离开的节点的地址
+// 调用方需持有写锁
+func (h *ConsistentHash) pruneAddrsLocked() {
+	for id := range h.addrs {
+		if !h.containsNode(id) {
+			delete(h.addrs, id)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddNode(t *testing.T) {
+	ch := New()
+	for i := 0; i < 5; i++ {
+		ch.AddNode(Node{ID: "cache-" + strconv.Itoa(i), Addr: "10.0.0." + strconv.Itoa(i) + ":6379"})
+	}
+	ch.AddNode(Node{ID: "small", Addr: "10.0.1.1:6379", Weight: 50})
+	assert.Equal(t, minReplicas, ch.ReplicaCount("cache-0"))
+	assert.Equal(t, 50, ch.ReplicaCount("small"))
+
+	before, epoch := ch.Clone(), ch.Epoch()
+	// 地址变化不影响键的分布
+	assert.True(t, ch.UpdateAddress("cache-1", "10.0.2.1:6379"))
+	assert.Equal(t, 0., MovedFraction(before, ch, 10000))
+	assert.Equal(t, epoch, ch.Epoch())
+
+	addr, ok := ch.Address("cache-1")
+	assert.True(t, ok)
+	assert.Equal(t, "10.0.2.1:6379", addr)
+	addr, _ = before.Address("cache-1")
+	assert.Equal(t, "10.0.0.1:6379", addr)
+
+	for i := 0; i < 100; i++ {
+		node, ok := ch.GetNode(strconv.Itoa(i))
+		assert.True(t, ok)
+		id, _ := ch.Get(strconv.Itoa(i))
+		expect, _ := ch.Address(id.(string))
+		assert.Equal(t, id, node.ID)
+		assert.Equal(t, expect, node.Addr)
+	}
+	node, _ := ch.GetNode("key")
+	if node.ID == "small" {
+		assert.Equal(t, 50, node.Weight)
+	} else {
+		assert.Equal(t, TopWeight, node.Weight)
+	}
+}
+
+func TestAddNodeRemove(t *testing.T) {
+	ch := New()
+	ch.AddNode(Node{ID: "a", Addr: "10.0.0.1:80"})
+	ch.Remove("a")
+	_, ok := ch.Address("a")
+	assert.False(t, ok)
+	assert.False(t, ch.UpdateAddress("a", "10.0.0.2:80"))
+	_, ok = ch.GetNode("key")
+	assert.False(t, ok)
+
+	// 重新添加时不会沿用旧地址
+	ch.Add("a")
+	_, ok = ch.Address("a")
+	assert.False(t, ok)
+	assert.True(t, ch.UpdateAddress("a", "10.0.0.2:80"))
+	addr, _ := ch.Address("a")
+	assert.Equal(t, "10.0.0.2:80", addr)
+}
+
+func TestAddNodeSnapshot(t *testing.T) {
+	ch := New()
+	ch.AddNode(Node{ID: "a", Addr: "10.0.0.1:80"})
+	ch.Add("b")
+	s := ch.Snapshot()
+	assert.Equal(t, map[string]string{"a": "10.0.0.1:80"}, s.Addrs)
+
+	restored := New()
+	restored.Restore(s)
+	addr, ok := restored.Address("a")
+	assert.True(t, ok)
+	assert.Equal(t, "10.0.0.1:80", addr)
+	_, ok = restored.Address("b")
+	assert.False(t, ok)
+}
@@ -0,0 +1,87 @@
+package zero
+
+import "sort"
+
+// 当前所有物理节点，按字典序排列
+func (h *ConsistentHash) Nodes() []string {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.nodesLocked()
+}
+
+// 调用方需持有读锁
+func (h *ConsistentHash) nodesLocked() []string {
+	nodes := make([]string, 0, len(h.nodes))
+	for node := range h.nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// 物理节点数量
+func (h *ConsistentHash) Len() int {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return len(h.nodes)
+}
+
+// 判断物理节点是否在环上
+func (h *ConsistentHash) Contains(node string) bool {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.containsNode(node)
+}
+
+// 物理节点的虚拟节点数量，节点不存在时为0
+func (h *ConsistentHash) ReplicaCount(node string) int {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.nodes[node]
+}
+
+// 一次查找的详细信息
+type LookupInfo struct {
+	// 选中的节点
+	Node interface{}
+	// 键的哈希值
+	Hash uint64
+	// 命中的虚拟节点位置
+	Point uint64
+	// 命中的虚拟节点上有多个物理节点
+	Collision bool
+	// 命中了固定路由，此时 Point 无意义
+	Pinned bool
+	// 查找时的物理节点数量
+	RingSize int
+}
+
+// 与 Get 的查找结果一致，同时给出命中的虚拟节点等信息，用于诊断和埋点
+// 就近选择或过载转移时 Node 可能不是 Point 上的节点，查找本身不计入热点统计
+func (h *ConsistentHash) Lookup(v string) (LookupInfo, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	key := []byte(v)
+	info := LookupInfo{
+		Hash:     h.hashFunc(key),
+		RingSize: len(h.nodes),
+	}
+	if node, ok := h.pinned(v); ok {
+		info.Node = node
+		info.Pinned = true
+		return info, true
+	}
+	if len(h.ring) == 0 {
+		return info, false
+	}
+
+	info.Point = h.successorPoint(info.Hash)
+	info.Collision = len(h.ring[info.Point]) > 1
+	node, ok := h.peekRouteLocked(v)
+	if to, spilled := h.spilledToLocked(v); ok && spilled {
+		node = to
+	}
+	info.Node = node
+	return info, ok
+}
@@ -0,0 +1,61 @@
+package zero
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsistentHash_Introspection(t *testing.T) {
+	ch := NewConsistentHash()
+	assert.Empty(t, ch.Nodes())
+	assert.Equal(t, 0, ch.Len())
+	assert.False(t, ch.Contains("first"))
+
+	ch.Add("second")
+	ch.Add("first")
+	ch.AddWithWeight("third", 50)
+	assert.Equal(t, []string{"first", "second", "third"}, ch.Nodes())
+	assert.Equal(t, 3, ch.Len())
+	assert.True(t, ch.Contains("first"))
+	assert.Equal(t, minReplicas, ch.ReplicaCount("first"))
+	assert.Equal(t, minReplicas/2, ch.ReplicaCount("third"))
+	assert.Equal(t, 0, ch.ReplicaCount("unknown"))
+
+	ch.Remove("first")
+	assert.Equal(t, []string{"second", "third"}, ch.Nodes())
+	assert.False(t, ch.Contains("first"))
+	assert.Equal(t, 0, ch.ReplicaCount("first"))
+}
+
+func TestLookup(t *testing.T) {
+	ch := NewConsistentHash()
+	_, ok := ch.Lookup("any")
+	assert.False(t, ok)
+
+	ch.Add("first")
+	ch.Add("second")
+	for _, key := range []string{"a", "b", "c", "d"} {
+		info, ok := ch.Lookup(key)
+		assert.True(t, ok)
+		node, _ := ch.Get(key)
+		assert.Equal(t, node, info.Node)
+		assert.Equal(t, Hash([]byte(key)), info.Hash)
+		assert.Equal(t, 2, info.RingSize)
+		assert.False(t, info.Collision)
+		owner, _ := ch.Successor(info.Hash)
+		assert.Equal(t, owner, info.Node)
+	}
+
+	ch.Pin("a", "second")
+	info, _ := ch.Lookup("a")
+	assert.True(t, info.Pinned)
+	assert.Equal(t, "second", info.Node)
+
+	collided := NewCustomConsistentHash(minReplicas, func([]byte) uint64 { return 1 })
+	collided.Add("first")
+	collided.Add("second")
+	info, _ = collided.Lookup("any")
+	assert.True(t, info.Collision)
+	assert.Equal(t, uint64(1), info.Point)
+}
@@ -0,0 +1,42 @@
+package zero
+
+import "iter"
+
+// 按位置从小到大遍历所有虚拟节点及其物理节点，冲突的位置按链上的顺序逐个给出
+// 遍历期间持有读锁，不复制环上的数据，循环体内不能调用本哈希环的方法
+func (h *ConsistentHash) All() iter.Seq2[uint64, string] {
+	return func(yield func(uint64, string) bool) {
+		h.lock.RLock()
+		defer h.lock.RUnlock()
+
+		visited, last := false, uint64(0)
+		h.ascend(0, func(point uint64) bool {
+			// 切片模式下冲突的位置会被访问多次
+			if visited && point == last {
+				return true
+			}
+			visited, last = true, point
+			for _, node := range h.ring[point] {
+				if !yield(point, node.(string)) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+}
+
+// 遍历所有物理节点，顺序不固定，需要有序结果时使用 Nodes
+// 遍历期间持有读锁，循环体内不能调用本哈希环的方法
+func (h *ConsistentHash) PhysicalNodes() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		h.lock.RLock()
+		defer h.lock.RUnlock()
+
+		for node := range h.nodes {
+			if !yield(node) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAll(t *testing.T) {
+	for _, ch := range []*ConsistentHash{NewConsistentHash(), New(WithTreeStore())} {
+		for i := 0; i < 5; i++ {
+			ch.Add("localhost:" + strconv.Itoa(i))
+		}
+		ch.Remove("localhost:2")
+
+		var points []uint64
+		counts := make(map[string]int)
+		for point, node := range ch.All() {
+			points = append(points, point)
+			counts[node]++
+		}
+		assert.Equal(t, 4*minReplicas, len(points))
+		assert.IsIncreasing(t, points)
+		for _, node := range ch.Nodes() {
+			assert.Equal(t, minReplicas, counts[node])
+		}
+
+		// 提前结束遍历
+		n := 0
+		for range ch.All() {
+			n++
+			if n == 10 {
+				break
+			}
+		}
+		assert.Equal(t, 10, n)
+	}
+}
+
+func TestAllWithCollisions(t *testing.T) {
+	ch := NewCustomConsistentHash(minReplicas, func(data []byte) uint64 {
+		return Hash(data) & 0xff
+	})
+	ch.Add("first")
+	ch.Add("second")
+
+	counts := make(map[string]int)
+	for point, node := range ch.All() {
+		assert.Contains(t, ch.ring[point], node)
+		counts[node]++
+	}
+	assert.Equal(t, map[string]int{"first": ch.ReplicaCount("first"), "second": ch.ReplicaCount("second")}, counts)
+}
+
+func TestPhysicalNodes(t *testing.T) {
+	ch := NewConsistentHash()
+	ch.Add("a")
+	ch.Add("b")
+	ch.Add("c")
+
+	var nodes []string
+	for node := range ch.PhysicalNodes() {
+		nodes = append(nodes, node)
+	}
+	assert.ElementsMatch(t, ch.Nodes(), nodes)
+
+	for range ch.PhysicalNodes() {
+		break
+	}
+	assert.Equal(t, 3, ch.Len())
+}
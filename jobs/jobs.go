@@ -0,0 +1,226 @@
+// 基于一致性哈希的任务分配
+// 固定数量的任务（或分区）按一致性哈希分给各个工作进程，成员变化时只迁移少量任务，
+// 常用于分布式定时任务的分片：每个进程只执行分给自己的任务
+package jobs
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+
+	"consistenthash"
+)
+
+type (
+	// 任务分配的可选配置
+	Option func(o *options)
+
+	options struct {
+		newRing func() *zero.ConsistentHash
+	}
+
+	// 把编号为 0 到 jobs-1 的任务分配给当前的工作进程
+	// 成员由服务发现通过 Add、Remove 维护，各进程的成员一致时分配结果一致
+	Assigner struct {
+		lock   sync.Mutex
+		ring   *zero.ConsistentHash
+		jobs   int
+		claims map[*Claim]struct{}
+	}
+
+	// 一个工作进程认领的任务
+	Claim struct {
+		assigner *Assigner
+		worker   string
+		events   chan Reassignment
+		// 已发出的最新一次分配
+		jobs []int
+	}
+
+	// 成员变化后认领的任务发生了变化
+	Reassignment struct {
+		// 变化后认领的全部任务，升序排列
+		Jobs []int
+		// 新分给该进程的任务
+		Acquired []int
+		// 不再属于该进程的任务，应停止执行
+		Released []int
+		// 事件所对应变化之前的任务，合并未读取的事件时使用
+		from []int
+	}
+)
+
+// 指定创建哈希环的方法，默认 zero.NewConsistentHash
+func WithRing(fn func() *zero.ConsistentHash) Option {
+	return func(o *options) {
+		o.newRing = fn
+	}
+}
+
+// 创建分配 jobs 个任务的 Assigner
+func NewAssigner(jobs int, opts ...Option) *Assigner {
+	o := options{
+		newRing: zero.NewConsistentHash,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &Assigner{
+		ring:   o.newRing(),
+		jobs:   max(jobs, 0),
+		claims: make(map[*Claim]struct{}),
+	}
+}
+
+// 添加工作进程
+func (a *Assigner) Add(worker string) {
+	a.AddWithWeight(worker, zero.TopWeight)
+}
+
+// 按权重添加工作进程
+func (a *Assigner) AddWithWeight(worker string, weight int) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.ring.AddWithWeight(worker, weight)
+	a.notifyLocked()
+}
+
+// 移除工作进程
+func (a *Assigner) Remove(worker string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.ring.Remove(worker)
+	a.notifyLocked()
+}
+
+// 当前的工作进程，按字典序排列
+func (a *Assigner) Workers() []string {
+	nodes := a.ring.Nodes()
+	sort.Strings(nodes)
+	return nodes
+}
+
+// 任务所属的工作进程，编号越界或没有工作进程时 ok 为 false
+func (a *Assigner) Owner(job int) (string, bool) {
+	if job < 0 || job >= a.jobs {
+		return "", false
+	}
+	node, ok := a.ring.Get(jobKey(job))
+	if !ok {
+		return "", false
+	}
+	return node.(string), true
+}
+
+// 以 worker 的身份认领任务，认领本身不把 worker 加入成员
+// 成员变化导致认领的任务变化时，Events 收到一次 Reassignment
+func (a *Assigner) Claim(worker string) *Claim {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	c := &Claim{
+		assigner: a,
+		worker:   worker,
+		// 只保留最新的一次变化，读取不及时的事件会被合并
+		events: make(chan Reassignment, 1),
+		jobs:   a.jobsOfLocked(worker),
+	}
+	a.claims[c] = struct{}{}
+	return c
+}
+
+// 认领时或最近一次事件中的全部任务，升序排列
+func (c *Claim) Jobs() []int {
+	c.assigner.lock.Lock()
+	defer c.assigner.lock.Unlock()
+	return slices.Clone(c.jobs)
+}
+
+// 认领的任务变化的事件，Close 后关闭
+// 未读取的事件与之后的变化合并为一次，Acquired 和 Released 始终相对最后读取的事件计算
+func (c *Claim) Events() <-chan Reassignment {
+	return c.events
+}
+
+// 停止接收事件
+func (c *Claim) Close() {
+	a := c.assigner
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if _, ok := a.claims[c]; ok {
+		delete(a.claims, c)
+		close(c.events)
+	}
+}
+
+// 成员变化后通知任务发生变化的认领
+// 调用方需持有锁
+func (a *Assigner) notifyLocked() {
+	owners := make(map[string][]int)
+	for job := 0; job < a.jobs; job++ {
+		if owner, ok := a.ring.Get(jobKey(job)); ok {
+			owners[owner.(string)] = append(owners[owner.(string)], job)
+		}
+	}
+
+	for c := range a.claims {
+		jobs := owners[c.worker]
+		from := c.jobs
+		// 合并尚未读取的事件
+		select {
+		case pending := <-c.events:
+			from = pending.from
+		default:
+		}
+		c.jobs = jobs
+		acquired, released := diffJobs(from, jobs)
+		if len(acquired) == 0 && len(released) == 0 {
+			continue
+		}
+		c.events <- Reassignment{
+			Jobs:     slices.Clone(jobs),
+			Acquired: acquired,
+			Released: released,
+			from:     from,
+		}
+	}
+}
+
+// 调用方需持有锁
+func (a *Assigner) jobsOfLocked(worker string) []int {
+	var jobs []int
+	for job := 0; job < a.jobs; job++ {
+		if owner, ok := a.ring.Get(jobKey(job)); ok && owner == worker {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs
+}
+
+func jobKey(job int) string {
+	return "job:" + strconv.Itoa(job)
+}
+
+// 两个升序的任务列表之间新增和减少的任务
+func diffJobs(from, to []int) (acquired, released []int) {
+	i, j := 0, 0
+	for i < len(from) || j < len(to) {
+		switch {
+		case j == len(to) || i < len(from) && from[i] < to[j]:
+			released = append(released, from[i])
+			i++
+		case i == len(from) || to[j] < from[i]:
+			acquired = append(acquired, to[j])
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	return acquired, released
+}
@@ -0,0 +1,79 @@
+package jobs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssigner(t *testing.T) {
+	a := NewAssigner(64)
+	a.Add("w1")
+	a.Add("w2")
+	c1 := a.Claim("w1")
+	c2 := a.Claim("w2")
+
+	// 每个任务恰好属于一个进程
+	assert.Equal(t, 64, len(c1.Jobs())+len(c2.Jobs()))
+	for _, job := range c1.Jobs() {
+		owner, ok := a.Owner(job)
+		assert.True(t, ok)
+		assert.Equal(t, "w1", owner)
+	}
+	_, ok := a.Owner(64)
+	assert.False(t, ok)
+
+	before := c1.Jobs()
+	a.Add("w3")
+	event := <-c1.Events()
+	assert.Empty(t, event.Acquired)
+	assert.NotEmpty(t, event.Released)
+	assert.Equal(t, c1.Jobs(), event.Jobs)
+	assert.Equal(t, len(before), len(event.Jobs)+len(event.Released))
+	<-c2.Events()
+
+	// 新加入的进程之后认领
+	c3 := a.Claim("w3")
+	assert.Equal(t, 64, len(c1.Jobs())+len(c2.Jobs())+len(c3.Jobs()))
+
+	a.Remove("w3")
+	event = <-c1.Events()
+	assert.Empty(t, event.Released)
+	assert.ElementsMatch(t, before, event.Jobs)
+	assert.Empty(t, (<-c3.Events()).Jobs)
+	<-c2.Events()
+}
+
+func TestAssignerCoalesce(t *testing.T) {
+	a := NewAssigner(64)
+	a.Add("w1")
+	c := a.Claim("w1")
+	assert.Equal(t, 64, len(c.Jobs()))
+
+	// 未读取的事件合并，相互抵消时不产生事件
+	a.Add("w2")
+	a.Remove("w2")
+	select {
+	case event := <-c.Events():
+		t.Fatalf("unexpected event %v", event)
+	default:
+	}
+
+	a.Add("w2")
+	a.Add("w3")
+	event := <-c.Events()
+	assert.Empty(t, event.Acquired)
+	assert.Equal(t, 64, len(event.Jobs)+len(event.Released))
+
+	c.Close()
+	c.Close()
+	_, ok := <-c.Events()
+	assert.False(t, ok)
+	a.Remove("w2")
+}
+
+func TestDiffJobs(t *testing.T) {
+	acquired, released := diffJobs([]int{1, 2, 4}, []int{2, 3, 4, 5})
+	assert.Equal(t, []int{3, 5}, acquired)
+	assert.Equal(t, []int{1}, released)
+}
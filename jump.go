@@ -0,0 +1,98 @@
+package zero
+
+import (
+	"sort"
+	"sync"
+)
+
+// Jump 一致性哈希
+// 节点按加入顺序编号，查找只需对数次计算且不占用额外内存，在末尾增删节点时只有必要的键迁移
+// 删除中间的节点时由最后一个节点补位，该节点原有的键也会迁移
+// 映射依赖增删顺序，多个进程需按相同顺序变更才能得到相同的映射
+type JumpHash struct {
+	// 哈希函数
+	hashFunc Func
+	// 按编号排列的物理节点
+	list []string
+	// 物理节点到编号的映射
+	nodes map[string]int
+	// 读写锁
+	lock sync.RWMutex
+}
+
+func NewJumpHash() *JumpHash {
+	return NewCustomJumpHash(Hash)
+}
+
+func NewCustomJumpHash(fn Func) *JumpHash {
+	if fn == nil {
+		fn = Hash
+	}
+
+	return &JumpHash{
+		hashFunc: fn,
+		nodes:    make(map[string]int),
+	}
+}
+
+// 增加物理节点，支持重复添加
+func (h *JumpHash) Add(node string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if _, ok := h.nodes[node]; ok {
+		return
+	}
+	h.nodes[node] = len(h.list)
+	h.list = append(h.list, node)
+}
+
+// 删除物理节点，最后一个节点移到被删除节点的编号上
+func (h *JumpHash) Remove(node string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	index, ok := h.nodes[node]
+	if !ok {
+		return
+	}
+	delete(h.nodes, node)
+	last := len(h.list) - 1
+	if index != last {
+		h.list[index] = h.list[last]
+		h.nodes[h.list[index]] = index
+	}
+	h.list = h.list[:last]
+}
+
+// 查找键所属的物理节点
+func (h *JumpHash) Get(v string) (interface{}, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if len(h.list) == 0 {
+		return nil, false
+	}
+	return h.list[jumpHash(h.hashFunc([]byte(v)), len(h.list))], true
+}
+
+// 当前所有物理节点，按字典序排列
+func (h *JumpHash) Nodes() []string {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	nodes := append([]string(nil), h.list...)
+	sort.Strings(nodes)
+	return nodes
+}
+
+// Lamping 和 Veach 的 Jump Consistent Hash，返回 [0, buckets) 内的桶编号
+func jumpHash(key uint64, buckets int) int {
+	var b, j int64 = -1, 0
+	for j < int64(buckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
@@ -0,0 +1,73 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJumpHash(t *testing.T) {
+	ch := NewJumpHash()
+	_, ok := ch.Get("key")
+	assert.False(t, ok)
+
+	for i := 0; i < 10; i++ {
+		ch.Add("node" + strconv.Itoa(i))
+	}
+	ch.Add("node0")
+	assert.Equal(t, 10, len(ch.Nodes()))
+
+	const keys = 100000
+	counts := make(map[interface{}]int)
+	before := make([]interface{}, keys)
+	for i := 0; i < keys; i++ {
+		before[i], _ = ch.Get(strconv.Itoa(i))
+		counts[before[i]]++
+	}
+	for _, n := range counts {
+		assert.InEpsilon(t, keys/10, n, 0.1)
+	}
+
+	// 新增节点时只有迁移到新节点的键发生变化
+	ch.Add("node10")
+	var moved int
+	for i := 0; i < keys; i++ {
+		node, _ := ch.Get(strconv.Itoa(i))
+		if node != before[i] {
+			assert.Equal(t, "node10", node)
+			moved++
+		}
+	}
+	assert.InEpsilon(t, keys/11, moved, 0.1)
+
+	// 删除最后加入的节点后恢复原有映射
+	ch.Remove("node10")
+	for i := 0; i < keys; i++ {
+		node, _ := ch.Get(strconv.Itoa(i))
+		assert.Equal(t, before[i], node)
+	}
+}
+
+func TestJumpHashRemove(t *testing.T) {
+	ch := NewJumpHash()
+	ch.Add("a")
+	ch.Add("b")
+	ch.Add("c")
+	ch.Remove("a")
+	ch.Remove("missing")
+	assert.Equal(t, []string{"b", "c"}, ch.Nodes())
+	for i := 0; i < 100; i++ {
+		node, ok := ch.Get(strconv.Itoa(i))
+		assert.True(t, ok)
+		assert.NotEqual(t, "a", node)
+	}
+}
+
+func TestJumpHashBuckets(t *testing.T) {
+	for i := uint64(0); i < 1000; i++ {
+		assert.Equal(t, 0, jumpHash(i, 1))
+		b := jumpHash(i, 7)
+		assert.True(t, b >= 0 && b < 7)
+	}
+}
@@ -0,0 +1,43 @@
+package zero
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"strconv"
+)
+
+const (
+	// libketama 中每个 MD5 摘要生成的虚拟节点数
+	ketamaPointsPerHash = 4
+	// libketama 中等权重节点的虚拟节点数，40个摘要 * 4
+	ketamaReplicas = 40 * ketamaPointsPerHash
+)
+
+// 创建与 libketama 兼容的一致性哈希
+// 节点名称需为 "host:port" 形式，对每个 "host:port-index" 取 MD5，
+// 每个摘要按小端序拆出4个32位的虚拟节点，键取 MD5 的前4字节
+// 等权重节点的键分布与 libketama 客户端一致；
+// libketama 按占总权重的比例分配虚拟节点，这里的 AddWithWeight 则按 TopWeight 计算，二者并不等价
+func NewKetamaHash() *ConsistentHash {
+	h := New(WithReplicas(ketamaReplicas), WithHashFunc(KetamaHash))
+	h.pointsFunc = ketamaVirtualPoints
+	h.hashBits = 32
+	return h
+}
+
+// libketama 的键哈希算法，MD5 前4字节的小端序整数
+func KetamaHash(data []byte) uint64 {
+	digest := md5.Sum(data)
+	return uint64(binary.LittleEndian.Uint32(digest[:]))
+}
+
+func ketamaVirtualPoints(node string, replicas int) []uint64 {
+	points := make([]uint64, 0, replicas)
+	for i := 0; len(points) < replicas; i++ {
+		digest := md5.Sum([]byte(node + "-" + strconv.Itoa(i)))
+		for j := 0; j < ketamaPointsPerHash && len(points) < replicas; j++ {
+			points = append(points, uint64(binary.LittleEndian.Uint32(digest[j*4:])))
+		}
+	}
+	return points
+}
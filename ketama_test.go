@@ -0,0 +1,69 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKetamaHash(t *testing.T) {
+	assert.Equal(t, uint64(0xdb18bdac), KetamaHash([]byte("foo")))
+}
+
+func TestKetamaVirtualPoints(t *testing.T) {
+	points := ketamaVirtualPoints("127.0.0.1:11211", 6)
+	assert.Equal(t, []uint64{
+		0x9a56fae2, 0x585ecf4e, 0x98bf09ab, 0x59c848d9,
+		0xdf0b2385, 0x7f4ea821,
+	}, points)
+}
+
+func TestKetamaConsistentHash(t *testing.T) {
+	ch := NewKetamaHash()
+	for i := 0; i < 4; i++ {
+		ch.Add("10.0.0." + strconv.Itoa(i) + ":11211")
+	}
+	assert.Equal(t, 4*ketamaReplicas, len(ch.keys))
+
+	hash := KetamaHash([]byte("foo"))
+	var expect interface{}
+	for _, point := range ch.keys {
+		if point >= hash {
+			expect = ch.ring[point][0]
+			break
+		}
+	}
+	if expect == nil {
+		expect = ch.ring[ch.keys[0]][0]
+	}
+	node, ok := ch.Get("foo")
+	assert.True(t, ok)
+	assert.Equal(t, expect, node)
+
+	ch.Remove("10.0.0.0:11211")
+	assert.Equal(t, 3*ketamaReplicas, len(ch.keys))
+	assert.Equal(t, 3, len(ch.nodes))
+}
+
+func TestKetamaOwnership(t *testing.T) {
+	ch := NewKetamaHash()
+	for i := 0; i < 4; i++ {
+		ch.Add("10.0.0." + strconv.Itoa(i) + ":11211")
+	}
+
+	// 位置只有32位，占比按 2^32 计算
+	ch.lock.RLock()
+	owned := ch.ownership()
+	ch.lock.RUnlock()
+	var total float64
+	for _, share := range owned {
+		assert.InDelta(t, 0.25, share, 0.05)
+		total += share
+	}
+	assert.InDelta(t, 1, total, 1e-9)
+
+	stats := ch.GapStats()
+	assert.True(t, stats.Largest < 0.1)
+	assert.True(t, stats.Smallest <= stats.Mean)
+}
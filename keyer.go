@@ -0,0 +1,63 @@
+package zero
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// 可以直接作为键查找的类型，如由租户和用户 ID 组成的结构体
+// HashKey 的结果决定路由，相等的键必须返回相同的字节
+type Keyer interface {
+	HashKey() []byte
+}
+
+// 按任意类型的键查找，无需调用方事先序列化
+//   - Keyer 使用 HashKey 的结果
+//   - string 和 []byte 与 Get、GetBytes 一致
+//   - 整数按十进制表示，与 Get(strconv.Itoa(n)) 一致
+//   - 元素为 byte 的数组（如 uuid.UUID）使用其原始字节
+//   - 其他类型按 fmt.Sprint 的结果
+func (h *ConsistentHash) GetKey(key any) (interface{}, bool) {
+	if s, ok := key.(string); ok {
+		return h.Get(s)
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	*buf = appendKey((*buf)[:0], key)
+	return h.GetBytes(*buf)
+}
+
+// 把键序列化后追加到 buf 中
+func appendKey(buf []byte, key any) []byte {
+	switch k := key.(type) {
+	case Keyer:
+		return append(buf, k.HashKey()...)
+	case string:
+		return append(buf, k...)
+	case []byte:
+		return append(buf, k...)
+	case int64:
+		return strconv.AppendInt(buf, k, 10)
+	case int:
+		return strconv.AppendInt(buf, int64(k), 10)
+	case int32:
+		return strconv.AppendInt(buf, int64(k), 10)
+	case uint64:
+		return strconv.AppendUint(buf, k, 10)
+	case uint32:
+		return strconv.AppendUint(buf, uint64(k), 10)
+	case [16]byte:
+		return append(buf, k[:]...)
+	}
+
+	// 以 [N]byte 为底层类型的具名类型
+	if v := reflect.ValueOf(key); v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8 {
+		for i := 0; i < v.Len(); i++ {
+			buf = append(buf, byte(v.Index(i).Uint()))
+		}
+		return buf
+	}
+	return fmt.Append(buf, key)
+}
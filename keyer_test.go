@@ -0,0 +1,58 @@
+package zero
+
+import (
+	"encoding/binary"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tenantUser struct {
+	tenant uint32
+	user   uint64
+}
+
+func (k tenantUser) HashKey() []byte {
+	b := binary.BigEndian.AppendUint32(nil, k.tenant)
+	return binary.BigEndian.AppendUint64(b, k.user)
+}
+
+// 与 uuid.UUID 相同的定义
+type testUUID [16]byte
+
+func TestGetKey(t *testing.T) {
+	ch := NewConsistentHash()
+	for i := 0; i < 10; i++ {
+		ch.Add("localhost:" + strconv.Itoa(i))
+	}
+
+	for i := 0; i < 1000; i++ {
+		s := strconv.Itoa(i)
+		expect, _ := ch.Get(s)
+		for _, key := range []any{s, []byte(s), int64(i), i, int32(i), uint64(i), uint32(i)} {
+			actual, ok := ch.GetKey(key)
+			assert.True(t, ok)
+			assert.Equal(t, expect, actual)
+		}
+
+		k := tenantUser{tenant: 1, user: uint64(i)}
+		expect, _ = ch.GetBytes(k.HashKey())
+		actual, _ := ch.GetKey(k)
+		assert.Equal(t, expect, actual)
+
+		id := testUUID{byte(i), byte(i >> 8), 0xff}
+		expect, _ = ch.GetBytes(id[:])
+		actual, _ = ch.GetKey(id)
+		assert.Equal(t, expect, actual)
+		actual, _ = ch.GetKey([16]byte(id))
+		assert.Equal(t, expect, actual)
+	}
+
+	expect, _ := ch.Get("1.5")
+	actual, _ := ch.GetKey(1.5)
+	assert.Equal(t, expect, actual)
+
+	_, ok := NewConsistentHash().GetKey(1)
+	assert.False(t, ok)
+}
@@ -0,0 +1,276 @@
+// 负载均衡器配置与哈希环成员的相互转换
+// 支持 nginx 的 upstream 块和 HAProxy 的 server 行，节点列表已经维护在负载均衡配置中时可直接复用
+// 负载均衡器的权重 w 对应哈希环上的权重 w*TopWeight，默认权重1即为基准权重
+package lbconfig
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"consistenthash"
+)
+
+var (
+	// 配置中没有指定的 upstream 块或 backend 段
+	ErrNotFound = errors.New("lbconfig: upstream or backend not found")
+	// 配置的格式无法解析
+	ErrSyntax = errors.New("lbconfig: syntax error")
+)
+
+// 读取 nginx 配置中名为 name 的 upstream 块，name 为空时读取第一个 upstream 块
+// 节点的 ID 和地址都是 server 的地址，标记为 down 或 backup 的 server 不在结果中
+func ParseNginx(r io.Reader, name string) ([]zero.Node, error) {
+	tokens, err := nginxTokens(r)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i+2 < len(tokens); i++ {
+		if tokens[i] != "upstream" || tokens[i+2] != "{" || (name != "" && tokens[i+1] != name) {
+			continue
+		}
+		return parseNginxBlock(tokens[i+3:])
+	}
+	return nil, ErrNotFound
+}
+
+// 解析 upstream 块的内容，直到块结束
+func parseNginxBlock(tokens []string) ([]zero.Node, error) {
+	var nodes []zero.Node
+	var stmt []string
+	for _, token := range tokens {
+		switch token {
+		case "}":
+			if len(stmt) > 0 {
+				return nil, fmt.Errorf("%w: unterminated directive %q", ErrSyntax, stmt[0])
+			}
+			return nodes, nil
+		case "{":
+			return nil, fmt.Errorf("%w: unexpected block in upstream", ErrSyntax)
+		case ";":
+			if len(stmt) > 0 && stmt[0] == "server" {
+				node, ok, err := parseNginxServer(stmt[1:])
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					nodes = append(nodes, node)
+				}
+			}
+			stmt = stmt[:0]
+		default:
+			stmt = append(stmt, token)
+		}
+	}
+	return nil, fmt.Errorf("%w: unterminated upstream block", ErrSyntax)
+}
+
+func parseNginxServer(args []string) (zero.Node, bool, error) {
+	if len(args) == 0 {
+		return zero.Node{}, false, fmt.Errorf("%w: server without address", ErrSyntax)
+	}
+
+	weight := 1
+	for _, arg := range args[1:] {
+		switch {
+		case arg == "down" || arg == "backup":
+			return zero.Node{}, false, nil
+		case strings.HasPrefix(arg, "weight="):
+			w, err := strconv.Atoi(strings.TrimPrefix(arg, "weight="))
+			if err != nil || w <= 0 {
+				return zero.Node{}, false, fmt.Errorf("%w: invalid %s", ErrSyntax, arg)
+			}
+			weight = w
+		}
+	}
+	return zero.Node{ID: args[0], Addr: args[0], Weight: weight * zero.TopWeight}, true, nil
+}
+
+// 把 nginx 配置拆分为单词，花括号和分号单独成词，去掉注释
+func nginxTokens(r io.Reader) ([]string, error) {
+	var tokens []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.NewReplacer("{", " { ", "}", " } ", ";", " ; ").Replace(line)
+		tokens = append(tokens, strings.Fields(line)...)
+	}
+	return tokens, scanner.Err()
+}
+
+// 把节点写为名为 name 的 nginx upstream 块，按 ID 排序
+// server 使用节点的地址，没有地址时使用 ID
+func WriteNginx(w io.Writer, name string, nodes []zero.Node) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "upstream %s {\n", name)
+	for _, node := range sortedNodes(nodes) {
+		fmt.Fprintf(bw, "    server %s weight=%d;\n", address(node), lbWeight(node.Weight))
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// 读取 HAProxy 配置中名为 name 的 backend 或 listen 段，name 为空时读取第一个含有 server 的段
+// 节点的 ID 为 server 的名称，地址为 server 的地址，权重为0或标记为 backup、disabled 的 server 不在结果中
+func ParseHAProxy(r io.Reader, name string) ([]zero.Node, error) {
+	var nodes []zero.Node
+	// inside 表示当前位于目标段中，found 表示已经找到目标段
+	var inside, found bool
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if haproxySections[fields[0]] {
+			// 已读完要找的段
+			if found {
+				break
+			}
+			inside = (fields[0] == "backend" || fields[0] == "listen") &&
+				(name == "" || len(fields) > 1 && fields[1] == name)
+			// 指定名称时找到段即可，不指定时要求段中含有 server
+			found = inside && name != ""
+			continue
+		}
+		if !inside || fields[0] != "server" {
+			continue
+		}
+
+		found = true
+		node, ok, err := parseHAProxyServer(fields[1:])
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			nodes = append(nodes, node)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrNotFound
+	}
+	return nodes, nil
+}
+
+// HAProxy 配置中开始新段的关键字
+var haproxySections = map[string]bool{
+	"global":    true,
+	"defaults":  true,
+	"frontend":  true,
+	"backend":   true,
+	"listen":    true,
+	"resolvers": true,
+	"peers":     true,
+	"userlist":  true,
+	"cache":     true,
+	"program":   true,
+}
+
+func parseHAProxyServer(args []string) (zero.Node, bool, error) {
+	if len(args) < 2 {
+		return zero.Node{}, false, fmt.Errorf("%w: server without address", ErrSyntax)
+	}
+
+	weight := 1
+	for i := 2; i < len(args); i++ {
+		switch args[i] {
+		case "backup", "disabled":
+			return zero.Node{}, false, nil
+		case "weight":
+			if i+1 == len(args) {
+				return zero.Node{}, false, fmt.Errorf("%w: weight without value", ErrSyntax)
+			}
+			i++
+			w, err := strconv.Atoi(args[i])
+			if err != nil || w < 0 {
+				return zero.Node{}, false, fmt.Errorf("%w: invalid weight %s", ErrSyntax, args[i])
+			}
+			// 权重为0的 server 不接收新的流量
+			if w == 0 {
+				return zero.Node{}, false, nil
+			}
+			weight = w
+		}
+	}
+	return zero.Node{ID: args[0], Addr: args[1], Weight: weight * zero.TopWeight}, true, nil
+}
+
+// 把节点写为名为 name 的 HAProxy backend 段，按 ID 排序
+// server 的名称为节点的 ID，地址为节点的地址，没有地址时使用 ID
+func WriteHAProxy(w io.Writer, name string, nodes []zero.Node) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "backend %s\n", name)
+	for _, node := range sortedNodes(nodes) {
+		fmt.Fprintf(bw, "    server %s %s weight %d\n", node.ID, address(node), lbWeight(node.Weight))
+	}
+	return bw.Flush()
+}
+
+// 用节点列表替换哈希环的成员并记录节点的地址
+func Apply(ring *zero.ConsistentHash, nodes []zero.Node) zero.ReplaceSummary {
+	weighted := make([]zero.WeightedNode, len(nodes))
+	for i, node := range nodes {
+		weighted[i] = zero.WeightedNode{Node: node.ID, Weight: node.Weight}
+	}
+	summary := ring.ReplaceAll(weighted)
+	for _, node := range nodes {
+		if node.Addr != "" {
+			ring.UpdateAddress(node.ID, node.Addr)
+		}
+	}
+	return summary
+}
+
+// 哈希环当前的成员，权重由虚拟节点数量换算，按 ID 排序
+func Export(ring *zero.ConsistentHash) []zero.Node {
+	s := ring.Snapshot()
+	nodes := make([]zero.Node, 0, len(s.Nodes))
+	for id, replicas := range s.Nodes {
+		nodes = append(nodes, zero.Node{
+			ID:     id,
+			Addr:   s.Addrs[id],
+			Weight: replicas * zero.TopWeight / s.Replicas,
+		})
+	}
+	return sortedNodes(nodes)
+}
+
+func sortedNodes(nodes []zero.Node) []zero.Node {
+	sorted := append([]zero.Node(nil), nodes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+	return sorted
+}
+
+func address(node zero.Node) string {
+	if node.Addr != "" {
+		return node.Addr
+	}
+	return node.ID
+}
+
+// 哈希环上的权重换算为负载均衡器的权重，至少为1
+func lbWeight(weight int) int {
+	if weight <= 0 {
+		return 1
+	}
+	return max(int(math.Round(float64(weight)/zero.TopWeight)), 1)
+}
@@ -0,0 +1,122 @@
+package lbconfig
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"consistenthash"
+	"github.com/stretchr/testify/assert"
+)
+
+const nginxConf = `
+http {
+    upstream static {
+        server 10.0.0.9:80;
+    }
+
+    # 缓存集群
+    upstream cache {
+        least_conn;
+        server 10.0.0.1:11211 weight=2 max_fails=3;
+        server 10.0.0.2:11211;
+        server 10.0.0.3:11211 down;
+        server 10.0.0.4:11211 backup;
+    }
+}
+`
+
+const haproxyConf = `
+global
+    maxconn 4096
+
+frontend web
+    bind *:80
+    default_backend cache
+
+backend cache
+    balance uri
+    server c1 10.0.0.1:11211 check weight 2
+    server c2 10.0.0.2:11211 check
+    server c3 10.0.0.3:11211 weight 0
+    server c4 10.0.0.4:11211 backup
+
+backend empty
+`
+
+func TestParseNginx(t *testing.T) {
+	nodes, err := ParseNginx(strings.NewReader(nginxConf), "cache")
+	assert.Nil(t, err)
+	assert.Equal(t, []zero.Node{
+		{ID: "10.0.0.1:11211", Addr: "10.0.0.1:11211", Weight: 200},
+		{ID: "10.0.0.2:11211", Addr: "10.0.0.2:11211", Weight: 100},
+	}, nodes)
+
+	nodes, err = ParseNginx(strings.NewReader(nginxConf), "")
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.9:80", nodes[0].ID)
+
+	_, err = ParseNginx(strings.NewReader(nginxConf), "missing")
+	assert.Equal(t, ErrNotFound, err)
+	_, err = ParseNginx(strings.NewReader("upstream a { server x weight=abc; }"), "a")
+	assert.ErrorIs(t, err, ErrSyntax)
+	_, err = ParseNginx(strings.NewReader("upstream a { server x;"), "a")
+	assert.ErrorIs(t, err, ErrSyntax)
+}
+
+func TestParseHAProxy(t *testing.T) {
+	nodes, err := ParseHAProxy(strings.NewReader(haproxyConf), "cache")
+	assert.Nil(t, err)
+	assert.Equal(t, []zero.Node{
+		{ID: "c1", Addr: "10.0.0.1:11211", Weight: 200},
+		{ID: "c2", Addr: "10.0.0.2:11211", Weight: 100},
+	}, nodes)
+
+	nodes, err = ParseHAProxy(strings.NewReader(haproxyConf), "")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(nodes))
+
+	nodes, err = ParseHAProxy(strings.NewReader(haproxyConf), "empty")
+	assert.Nil(t, err)
+	assert.Empty(t, nodes)
+
+	_, err = ParseHAProxy(strings.NewReader(haproxyConf), "missing")
+	assert.Equal(t, ErrNotFound, err)
+	_, err = ParseHAProxy(strings.NewReader("backend a\n server x 1.2.3.4 weight"), "a")
+	assert.ErrorIs(t, err, ErrSyntax)
+}
+
+func TestRoundTrip(t *testing.T) {
+	nodes, _ := ParseHAProxy(strings.NewReader(haproxyConf), "cache")
+	ring := zero.New()
+	ring.Add("stale")
+	summary := Apply(ring, nodes)
+	assert.Equal(t, []string{"c1", "c2"}, summary.Added)
+	assert.Equal(t, []string{"stale"}, summary.Removed)
+	assert.Equal(t, 200, ring.ReplicaCount("c1"))
+	addr, _ := ring.Address("c1")
+	assert.Equal(t, "10.0.0.1:11211", addr)
+	assert.Equal(t, nodes, Export(ring))
+
+	var buf bytes.Buffer
+	assert.Nil(t, WriteHAProxy(&buf, "cache", Export(ring)))
+	assert.Equal(t, `backend cache
+    server c1 10.0.0.1:11211 weight 2
+    server c2 10.0.0.2:11211 weight 1
+`, buf.String())
+	parsed, err := ParseHAProxy(&buf, "cache")
+	assert.Nil(t, err)
+	assert.Equal(t, nodes, parsed)
+
+	buf.Reset()
+	assert.Nil(t, WriteNginx(&buf, "cache", Export(ring)))
+	assert.Equal(t, `upstream cache {
+    server 10.0.0.1:11211 weight=2;
+    server 10.0.0.2:11211 weight=1;
+}
+`, buf.String())
+	parsed, err = ParseNginx(&buf, "cache")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"10.0.0.1:11211", "10.0.0.2:11211"}, []string{parsed[0].ID, parsed[1].ID})
+	assert.Equal(t, 200, parsed[0].Weight)
+}
@@ -0,0 +1,179 @@
+package zero
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// 组件已经关闭，不能再次启动
+var ErrComponentClosed = errors.New("consistenthash: component closed")
+
+type (
+	// 在后台运行的组件，如成员发现、健康检查和定时清理
+	// Start 不阻塞，ctx 取消后组件应尽快停止后台任务；
+	// Close 停止组件并等待后台任务退出，可重复调用，关闭后不能再次启动
+	Component interface {
+		Start(ctx context.Context) error
+		Close() error
+	}
+
+	// 按顺序启动、逆序关闭的一组组件
+	Group struct {
+		lock       sync.Mutex
+		components []Component
+		// 已启动的组件数量
+		started int
+		cancel  context.CancelFunc
+		closed  bool
+	}
+
+	// 按固定间隔执行任务的组件，可用作健康检查或定时清理
+	// 上一次执行结束后才开始计时，任务不会并发执行，任务中不能调用 Close
+	Periodic struct {
+		clock    Clock
+		interval time.Duration
+		fn       func(ctx context.Context)
+
+		lock   sync.Mutex
+		timer  Timer
+		ctx    context.Context
+		cancel context.CancelFunc
+		closed bool
+		// 正在执行的任务
+		running sync.WaitGroup
+	}
+)
+
+var (
+	_ Component = (*Group)(nil)
+	_ Component = (*Periodic)(nil)
+)
+
+// 创建组件组，启动顺序为参数顺序
+func NewGroup(components ...Component) *Group {
+	return &Group{components: components}
+}
+
+// 依次启动所有组件，组件得到的 ctx 在 Group 关闭时取消
+// 某个组件启动失败时逆序关闭已启动的组件，返回启动错误和关闭错误
+func (g *Group) Start(ctx context.Context) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.closed {
+		return ErrComponentClosed
+	}
+	ctx, g.cancel = context.WithCancel(ctx)
+	for _, c := range g.components[g.started:] {
+		if err := c.Start(ctx); err != nil {
+			return errors.Join(err, g.closeLocked())
+		}
+		g.started++
+	}
+	return nil
+}
+
+// 取消启动时的 ctx，逆序关闭已启动的组件，返回所有关闭错误
+func (g *Group) Close() error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return g.closeLocked()
+}
+
+// 同 Close，但 ctx 结束时不再等待，返回 ctx 的错误，剩余的组件继续在后台关闭
+func (g *Group) Shutdown(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- g.Close()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// 调用方需持有 g.lock
+func (g *Group) closeLocked() error {
+	g.closed = true
+	if g.cancel != nil {
+		g.cancel()
+	}
+
+	var errs []error
+	for i := g.started - 1; i >= 0; i-- {
+		if err := g.components[i].Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	g.started = 0
+	return errors.Join(errs...)
+}
+
+// 创建每隔 interval 执行一次 fn 的组件，时间来源沿用哈希环的配置
+// fn 得到的 ctx 在组件关闭或启动时的 ctx 取消后结束，耗时的任务应据此提前返回
+func NewPeriodic(ring *ConsistentHash, interval time.Duration, fn func(ctx context.Context)) *Periodic {
+	return &Periodic{
+		clock:    ring.clock,
+		interval: interval,
+		fn:       fn,
+	}
+}
+
+// 开始计时，第一次执行在 interval 之后，重复启动时忽略
+func (p *Periodic) Start(ctx context.Context) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.closed {
+		return ErrComponentClosed
+	}
+	if p.ctx != nil {
+		return nil
+	}
+	p.ctx, p.cancel = context.WithCancel(ctx)
+	p.timer = p.clock.AfterFunc(p.interval, p.tick)
+	return nil
+}
+
+// 停止计时，取消正在执行的任务并等待其返回
+func (p *Periodic) Close() error {
+	p.lock.Lock()
+	p.closed = true
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.lock.Unlock()
+
+	p.running.Wait()
+	return nil
+}
+
+func (p *Periodic) tick() {
+	p.lock.Lock()
+	p.timer = nil
+	if p.closed || p.ctx.Err() != nil {
+		p.lock.Unlock()
+		return
+	}
+	ctx := p.ctx
+	p.running.Add(1)
+	p.lock.Unlock()
+
+	p.fn(ctx)
+	p.running.Done()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if !p.closed && ctx.Err() == nil {
+		p.timer = p.clock.AfterFunc(p.interval, p.tick)
+	}
+}
@@ -0,0 +1,143 @@
+package zero
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 记录启动和关闭顺序的组件
+type recordComponent struct {
+	name     string
+	log      *[]string
+	startErr error
+	closeErr error
+	ctx      context.Context
+	block    chan struct{}
+}
+
+func (c *recordComponent) Start(ctx context.Context) error {
+	*c.log = append(*c.log, "start "+c.name)
+	c.ctx = ctx
+	return c.startErr
+}
+
+func (c *recordComponent) Close() error {
+	if c.block != nil {
+		<-c.block
+	}
+	*c.log = append(*c.log, "close "+c.name)
+	return c.closeErr
+}
+
+func TestGroup(t *testing.T) {
+	var log []string
+	a := &recordComponent{name: "a", log: &log}
+	b := &recordComponent{name: "b", log: &log, closeErr: errors.New("b")}
+	g := NewGroup(a, b)
+
+	assert.Nil(t, g.Start(context.Background()))
+	assert.Nil(t, a.ctx.Err())
+	err := g.Close()
+	assert.Equal(t, "b", err.Error())
+	assert.Equal(t, []string{"start a", "start b", "close b", "close a"}, log)
+	// 关闭时取消组件的 ctx
+	assert.Equal(t, context.Canceled, a.ctx.Err())
+
+	// 重复关闭不再关闭组件
+	assert.Nil(t, g.Close())
+	assert.Equal(t, 4, len(log))
+	assert.Equal(t, ErrComponentClosed, g.Start(context.Background()))
+}
+
+func TestGroupStartFailure(t *testing.T) {
+	var log []string
+	startErr := errors.New("start")
+	g := NewGroup(
+		&recordComponent{name: "a", log: &log},
+		&recordComponent{name: "b", log: &log},
+		&recordComponent{name: "c", log: &log, startErr: startErr},
+		&recordComponent{name: "d", log: &log},
+	)
+
+	assert.ErrorIs(t, g.Start(context.Background()), startErr)
+	assert.Equal(t, []string{"start a", "start b", "start c", "close b", "close a"}, log)
+}
+
+func TestGroupShutdown(t *testing.T) {
+	var log []string
+	block := make(chan struct{})
+	g := NewGroup(&recordComponent{name: "slow", log: &log, block: block})
+	assert.Nil(t, g.Start(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, g.Shutdown(ctx))
+	close(block)
+
+	// 超时后关闭继续在后台完成
+	assert.Nil(t, g.Shutdown(context.Background()))
+	assert.Equal(t, []string{"start slow", "close slow"}, log)
+}
+
+func TestPeriodic(t *testing.T) {
+	ch, clock := newRebalancerRing()
+	var runs int
+	p := NewPeriodic(ch, time.Second, func(ctx context.Context) {
+		runs++
+	})
+
+	clock.Advance(time.Second)
+	assert.Equal(t, 0, runs)
+	assert.Nil(t, p.Start(context.Background()))
+	assert.Nil(t, p.Start(context.Background()))
+	clock.Advance(time.Second)
+	clock.Advance(time.Second)
+	assert.Equal(t, 2, runs)
+
+	assert.Nil(t, p.Close())
+	assert.Nil(t, p.Close())
+	clock.Advance(time.Second)
+	assert.Equal(t, 2, runs)
+	assert.Equal(t, ErrComponentClosed, p.Start(context.Background()))
+}
+
+func TestPeriodicContext(t *testing.T) {
+	ch, clock := newRebalancerRing()
+	var runs int
+	p := NewPeriodic(ch, time.Second, func(ctx context.Context) {
+		runs++
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.Nil(t, p.Start(ctx))
+	clock.Advance(time.Second)
+	cancel()
+	clock.Advance(time.Second)
+	clock.Advance(time.Second)
+	assert.Equal(t, 1, runs)
+	assert.Nil(t, p.Close())
+}
+
+func TestPeriodicCloseWaits(t *testing.T) {
+	started := make(chan struct{})
+	done := make(chan struct{})
+	p := NewPeriodic(NewConsistentHash(), time.Millisecond, func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		close(done)
+	})
+	assert.Nil(t, p.Start(context.Background()))
+
+	<-started
+	assert.Nil(t, p.Close())
+	// Close 返回时任务已经结束
+	select {
+	case <-done:
+	default:
+		t.Fatal("Close returned before the running task")
+	}
+}
@@ -0,0 +1,100 @@
+package zero
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// 估算负载时哈希空间占比相当的查找次数
+// 观测到的查找越多，估算越接近实际的查找分布
+const loadPrior = 1000
+
+// 各节点被查找选中的次数，只在开启指标或跟踪回调时统计
+type loadCounter struct {
+	nodes sync.Map // string -> *atomic.Uint64
+	total atomic.Uint64
+}
+
+func (c *loadCounter) add(node string) {
+	n, ok := c.nodes.Load(node)
+	if !ok {
+		n, _ = c.nodes.LoadOrStore(node, new(atomic.Uint64))
+	}
+	n.(*atomic.Uint64).Add(1)
+	c.total.Add(1)
+}
+
+func (c *loadCounter) count(node string) uint64 {
+	if n, ok := c.nodes.Load(node); ok {
+		return n.(*atomic.Uint64).Load()
+	}
+	return 0
+}
+
+// 节点承担的负载占全部负载的比例，取值 [0, 1]
+// 以节点占有的哈希空间比例为先验，开启 WithMetrics 或 WithTraceHook 后结合观测到的查找次数，
+// 查找越多越接近实际的查找分布；节点不存在时为0
+func (h *ConsistentHash) LoadEstimate(node string) float64 {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if !h.containsNode(node) {
+		return 0
+	}
+	return h.loadEstimateLocked(node, h.ownership())
+}
+
+// 负载超过其应得份额 threshold 倍的节点，按字典序排列
+// 应得份额按虚拟节点数量计算，如 threshold 为 1.5 时返回负载比权重应得的多出50%以上的节点
+func (h *ConsistentHash) HotNodes(threshold float64) []string {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	var total int
+	for _, replicas := range h.nodes {
+		total += replicas
+	}
+	if total == 0 {
+		return nil
+	}
+
+	owned := h.ownership()
+	var hot []string
+	for node, replicas := range h.nodes {
+		fair := float64(replicas) / float64(total)
+		if h.loadEstimateLocked(node, owned) > fair*threshold {
+			hot = append(hot, node)
+		}
+	}
+	sort.Strings(hot)
+	return hot
+}
+
+// 清零观测到的查找次数，用于按时间窗口统计
+func (h *ConsistentHash) ResetLoad() {
+	h.loads.Store(new(loadCounter))
+}
+
+// 调用方需持有读锁
+func (h *ConsistentHash) loadEstimateLocked(node string, owned map[string]float64) float64 {
+	c := h.loads.Load()
+	if c == nil {
+		return owned[node]
+	}
+	return (float64(c.count(node)) + loadPrior*owned[node]) / (float64(c.total.Load()) + loadPrior)
+}
+
+// 记录一次选中 node 的查找
+func (h *ConsistentHash) countLoad(node interface{}) {
+	c := h.loads.Load()
+	if c == nil {
+		c = new(loadCounter)
+		if !h.loads.CompareAndSwap(nil, c) {
+			c = h.loads.Load()
+		}
+	}
+	if name, ok := node.(string); ok {
+		c.add(name)
+	}
+}
@@ -0,0 +1,67 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadEstimate(t *testing.T) {
+	ch := New()
+	ch.Add("a")
+	ch.Add("b")
+	ch.AddWithWeight("c", 200)
+
+	// 没有观测时等于哈希空间占比
+	owned := ch.Clone().ownership()
+	for _, node := range []string{"a", "b", "c"} {
+		assert.InDelta(t, owned[node], ch.LoadEstimate(node), 1e-9)
+	}
+	assert.Equal(t, 0., ch.LoadEstimate("unknown"))
+	assert.Empty(t, ch.HotNodes(1.5))
+	assert.Nil(t, New().HotNodes(1.5))
+}
+
+func TestHotNodes(t *testing.T) {
+	metrics := &countingMetrics{t: t, added: make(map[string]int)}
+	ch := New(WithMetrics(metrics))
+	metrics.ring = ch
+	for i := 0; i < 4; i++ {
+		ch.Add("node" + strconv.Itoa(i))
+	}
+
+	// 热点键全部落在同一个节点上
+	hotNode, _ := ch.Get("hot")
+	for i := 0; i < 10000; i++ {
+		ch.Get("hot")
+	}
+	for i := 0; i < 1000; i++ {
+		ch.Get(strconv.Itoa(i))
+	}
+	assert.Greater(t, ch.LoadEstimate(hotNode.(string)), 0.8)
+	assert.Equal(t, []string{hotNode.(string)}, ch.HotNodes(1.5))
+
+	ch.ResetLoad()
+	assert.Empty(t, ch.HotNodes(1.5))
+}
+
+func TestLoadWithTraceHook(t *testing.T) {
+	ch := New(WithTraceHook(func(string, uint64, interface{}, int64) {}))
+	ch.Add("a")
+	ch.Add("b")
+	node, _ := ch.Get("key")
+	for i := 0; i < 5000; i++ {
+		ch.GetBytes([]byte("key"))
+	}
+	assert.Equal(t, []string{node.(string)}, ch.HotNodes(1.5))
+
+	// 未开启指标和跟踪时不统计
+	plain := New()
+	plain.Add("a")
+	plain.Add("b")
+	for i := 0; i < 5000; i++ {
+		plain.Get("key")
+	}
+	assert.Empty(t, plain.HotNodes(1.5))
+}
@@ -0,0 +1,55 @@
+package zero
+
+import "time"
+
+// 结构化日志的事件名称
+const (
+	// 节点加入或更新了虚拟节点数量，属性为 node、replicas
+	EventNodeAdded = "node_added"
+	// 节点被删除或开始摘除，属性为 node
+	EventNodeRemoved = "node_removed"
+	// 更换哈希函数或放大因子后重建完成，属性为 reason、duration、moved_fraction
+	EventRebuildDone = "rebuild_done"
+	// WithHashValidation 拒绝了哈希函数，属性为 error
+	EventHashRejected = "hash_rejected"
+)
+
+// 结构化日志，args 为交替的键和值，*slog.Logger 可直接使用
+// 回调在释放锁之后执行，实现中可以安全地访问哈希环
+type Logger interface {
+	Info(msg string, args ...any)
+}
+
+// 以结构化日志记录成员变化和重建，不需要指标系统即可观察哈希环的运行
+func WithLogger(logger Logger) Option {
+	return func(h *ConsistentHash) {
+		h.logger = logger
+	}
+}
+
+// 上报节点加入
+func (h *ConsistentHash) nodeAdded(node string, replicas int) {
+	if h.metrics != nil {
+		h.metrics.NodeAdded(node, replicas)
+	}
+	if h.logger != nil {
+		h.logger.Info(EventNodeAdded, "node", node, "replicas", replicas)
+	}
+}
+
+// 上报节点离开
+func (h *ConsistentHash) nodeRemoved(node string) {
+	if h.metrics != nil {
+		h.metrics.NodeRemoved(node)
+	}
+	if h.logger != nil {
+		h.logger.Info(EventNodeRemoved, "node", node)
+	}
+}
+
+// 记录一次重建，reason 为重建的原因
+func (h *ConsistentHash) rebuildDone(reason string, duration time.Duration, moved float64) {
+	if h.logger != nil {
+		h.logger.Info(EventRebuildDone, "reason", reason, "duration", duration, "moved_fraction", moved)
+	}
+}
@@ -0,0 +1,40 @@
+package zero
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	ch := New(WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))))
+	ch.Add("a")
+	ch.AddWithWeight("b", 50)
+	ch.Remove("a")
+	// 不存在的节点不记录
+	ch.Remove("missing")
+	ch.SetReplicas(200)
+
+	var events []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var event map[string]any
+		assert.Nil(t, json.Unmarshal([]byte(line), &event))
+		events = append(events, event)
+	}
+	assert.Equal(t, 4, len(events))
+	assert.Equal(t, EventNodeAdded, events[0]["msg"])
+	assert.Equal(t, "a", events[0]["node"])
+	assert.Equal(t, float64(100), events[0]["replicas"])
+	assert.Equal(t, float64(50), events[1]["replicas"])
+	assert.Equal(t, EventNodeRemoved, events[2]["msg"])
+	assert.Equal(t, "a", events[2]["node"])
+	assert.Equal(t, EventRebuildDone, events[3]["msg"])
+	assert.Equal(t, "replicas", events[3]["reason"])
+	assert.Contains(t, events[3], "duration")
+	assert.Contains(t, events[3], "moved_fraction")
+}
@@ -0,0 +1,153 @@
+package zero
+
+import (
+	"sort"
+	"sync"
+)
+
+// Maglev 查找表的默认大小，需为质数
+const defaultMaglevTableSize = 65537
+
+// Maglev 一致性哈希
+// 每个节点按各自的排列轮流填充固定大小的查找表，查找只需一次取模
+// 节点变更时重建查找表，只有少量表项改变归属
+type MaglevHash struct {
+	// 哈希函数
+	hashFunc Func
+	// 查找表大小
+	size uint64
+	// 按名称排序的物理节点
+	nodes []string
+	// 查找表，值为 nodes 的下标
+	table []int
+	// 读写锁
+	lock sync.RWMutex
+}
+
+func NewMaglevHash() *MaglevHash {
+	return NewCustomMaglevHash(defaultMaglevTableSize, Hash)
+}
+
+// size 会向上取整为质数，保证每个节点的排列覆盖整张表
+func NewCustomMaglevHash(size int, fn Func) *MaglevHash {
+	if size < 2 {
+		size = defaultMaglevTableSize
+	}
+
+	if fn == nil {
+		fn = Hash
+	}
+
+	return &MaglevHash{
+		hashFunc: fn,
+		size:     nextPrime(uint64(size)),
+	}
+}
+
+// 增加物理节点，支持重复添加
+func (h *MaglevHash) Add(node string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	index := sort.SearchStrings(h.nodes, node)
+	if index < len(h.nodes) && h.nodes[index] == node {
+		return
+	}
+	h.nodes = append(h.nodes, "")
+	copy(h.nodes[index+1:], h.nodes[index:])
+	h.nodes[index] = node
+	h.populate()
+}
+
+// 删除物理节点
+func (h *MaglevHash) Remove(node string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	index := sort.SearchStrings(h.nodes, node)
+	if index == len(h.nodes) || h.nodes[index] != node {
+		return
+	}
+	h.nodes = append(h.nodes[:index], h.nodes[index+1:]...)
+	h.populate()
+}
+
+// 查找键所属的物理节点
+func (h *MaglevHash) Get(v string) (interface{}, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if len(h.nodes) == 0 {
+		return nil, false
+	}
+	return h.nodes[h.table[h.hashFunc([]byte(v))%h.size]], true
+}
+
+// 当前所有物理节点，按字典序排列
+func (h *MaglevHash) Nodes() []string {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return append([]string(nil), h.nodes...)
+}
+
+// 重建查找表
+// 调用方需持有写锁
+func (h *MaglevHash) populate() {
+	if len(h.nodes) == 0 {
+		h.table = nil
+		return
+	}
+
+	offsets := make([]uint64, len(h.nodes))
+	skips := make([]uint64, len(h.nodes))
+	for i, node := range h.nodes {
+		hash := h.hashFunc([]byte(node))
+		offsets[i] = hash % h.size
+		skips[i] = mix64(hash)%(h.size-1) + 1
+	}
+
+	table := make([]int, h.size)
+	for i := range table {
+		table[i] = -1
+	}
+	next := make([]uint64, len(h.nodes))
+	var filled uint64
+	for {
+		for i := range h.nodes {
+			// 按节点自己的排列找到下一个空位
+			c := (offsets[i] + next[i]*skips[i]) % h.size
+			for table[c] >= 0 {
+				next[i]++
+				c = (offsets[i] + next[i]*skips[i]) % h.size
+			}
+			table[c] = i
+			next[i]++
+			filled++
+			if filled == h.size {
+				h.table = table
+				return
+			}
+		}
+	}
+}
+
+// 不小于 n 的最小质数
+func nextPrime(n uint64) uint64 {
+	for ; ; n++ {
+		if isPrime(n) {
+			return n
+		}
+	}
+}
+
+func isPrime(n uint64) bool {
+	if n < 2 {
+		return false
+	}
+	for i := uint64(2); i*i <= n; i++ {
+		if n%i == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaglevHash(t *testing.T) {
+	h := NewMaglevHash()
+	_, ok := h.Get("any")
+	assert.False(t, ok)
+
+	for i := 0; i < keySize; i++ {
+		h.Add("localhost:" + strconv.Itoa(i))
+		h.Add("localhost:" + strconv.Itoa(i))
+	}
+
+	counts := make(map[int]int)
+	for _, index := range h.table {
+		counts[index]++
+	}
+	// 查找表几乎均分给所有节点
+	for index, count := range counts {
+		assert.InDelta(t, defaultMaglevTableSize/keySize, count, 1, index)
+	}
+
+	keys := make(map[int]interface{}, requestSize)
+	for i := 0; i < requestSize; i++ {
+		keys[i], ok = h.Get(strconv.Itoa(i))
+		assert.True(t, ok)
+	}
+
+	const removed = "localhost:5"
+	h.Remove(removed)
+	h.Remove(removed)
+	var moved int
+	for i := 0; i < requestSize; i++ {
+		node, _ := h.Get(strconv.Itoa(i))
+		assert.NotEqual(t, removed, node)
+		if keys[i] != removed && keys[i] != node {
+			moved++
+		}
+	}
+	assert.True(t, float64(moved)/requestSize < .05)
+}
+
+func TestNextPrime(t *testing.T) {
+	assert.Equal(t, uint64(2), nextPrime(0))
+	assert.Equal(t, uint64(11), nextPrime(8))
+	assert.Equal(t, uint64(65537), nextPrime(65536))
+
+	h := NewCustomMaglevHash(100, nil)
+	assert.Equal(t, uint64(101), h.size)
+}
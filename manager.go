@@ -0,0 +1,165 @@
+package zero
+
+import (
+	"sort"
+	"sync"
+)
+
+type (
+	// 按命名空间区分的指标上报，管理器中的所有哈希环共享同一个实现
+	// 回调在释放锁之后执行
+	NamespaceMetrics interface {
+		NodeAdded(namespace, node string, replicas int)
+		NodeRemoved(namespace, node string)
+		Lookup(namespace string, found bool)
+	}
+
+	// 按命名空间管理多个哈希环，如每个缓存集群一个
+	// 哈希环在第一次访问时按相同的配置创建
+	RingManager struct {
+		opts    []Option
+		metrics NamespaceMetrics
+		lock    sync.RWMutex
+		rings   map[string]*ConsistentHash
+	}
+
+	// 把单个哈希环的指标转发到共享的实现
+	namespaceMetrics struct {
+		namespace string
+		metrics   NamespaceMetrics
+	}
+)
+
+// 创建管理器，opts 用于创建每个命名空间的哈希环
+// metrics 不为 nil 时所有哈希环的指标都上报给它，并覆盖 opts 中的 WithMetrics
+func NewRingManager(metrics NamespaceMetrics, opts ...Option) *RingManager {
+	return &RingManager{
+		opts:    opts,
+		metrics: metrics,
+		rings:   make(map[string]*ConsistentHash),
+	}
+}
+
+// 命名空间的哈希环，不存在时创建
+func (m *RingManager) Ring(namespace string) *ConsistentHash {
+	m.lock.RLock()
+	ring, ok := m.rings[namespace]
+	m.lock.RUnlock()
+	if ok {
+		return ring
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.ringLocked(namespace)
+}
+
+// 命名空间的哈希环，不存在时返回 false 且不创建
+func (m *RingManager) Find(namespace string) (*ConsistentHash, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	ring, ok := m.rings[namespace]
+	return ring, ok
+}
+
+// 所有命名空间，按字典序排列
+func (m *RingManager) Namespaces() []string {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	namespaces := make([]string, 0, len(m.rings))
+	for namespace := range m.rings {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// 删除命名空间，其哈希环被清空，临时节点和摘除的定时任务随之停止
+// 命名空间不存在时返回 false
+func (m *RingManager) Delete(namespace string) bool {
+	m.lock.Lock()
+	ring, ok := m.rings[namespace]
+	delete(m.rings, namespace)
+	m.lock.Unlock()
+
+	if ok {
+		ring.Restore(Snapshot{})
+	}
+	return ok
+}
+
+// 删除所有命名空间
+func (m *RingManager) Close() {
+	m.lock.Lock()
+	rings := m.rings
+	m.rings = make(map[string]*ConsistentHash)
+	m.lock.Unlock()
+
+	for _, ring := range rings {
+		ring.Restore(Snapshot{})
+	}
+}
+
+// 导出所有命名空间的快照
+func (m *RingManager) Snapshot() map[string]Snapshot {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	snapshots := make(map[string]Snapshot, len(m.rings))
+	for namespace, ring := range m.rings {
+		snapshots[namespace] = ring.Snapshot()
+	}
+	return snapshots
+}
+
+// 用快照替换所有命名空间，快照中没有的命名空间被删除
+func (m *RingManager) Restore(snapshots map[string]Snapshot) {
+	m.lock.Lock()
+	var removed []*ConsistentHash
+	for namespace, ring := range m.rings {
+		if _, ok := snapshots[namespace]; !ok {
+			removed = append(removed, ring)
+			delete(m.rings, namespace)
+		}
+	}
+	for namespace, s := range snapshots {
+		m.ringLocked(namespace).Restore(s)
+	}
+	m.lock.Unlock()
+
+	for _, ring := range removed {
+		ring.Restore(Snapshot{})
+	}
+}
+
+// 调用方需持有写锁
+func (m *RingManager) ringLocked(namespace string) *ConsistentHash {
+	if ring, ok := m.rings[namespace]; ok {
+		return ring
+	}
+
+	opts := m.opts
+	if m.metrics != nil {
+		opts = append(opts[:len(opts):len(opts)], WithMetrics(namespaceMetrics{
+			namespace: namespace,
+			metrics:   m.metrics,
+		}))
+	}
+	ring := New(opts...)
+	m.rings[namespace] = ring
+	return ring
+}
+
+func (n namespaceMetrics) NodeAdded(node string, replicas int) {
+	n.metrics.NodeAdded(n.namespace, node, replicas)
+}
+
+func (n namespaceMetrics) NodeRemoved(node string) {
+	n.metrics.NodeRemoved(n.namespace, node)
+}
+
+func (n namespaceMetrics) Lookup(found bool) {
+	n.metrics.Lookup(n.namespace, found)
+}
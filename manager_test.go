@@ -0,0 +1,132 @@
+package zero
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type namespaceCounter struct {
+	lock    sync.Mutex
+	added   map[string][]string
+	removed map[string][]string
+	lookups map[string]int
+}
+
+func newNamespaceCounter() *namespaceCounter {
+	return &namespaceCounter{
+		added:   make(map[string][]string),
+		removed: make(map[string][]string),
+		lookups: make(map[string]int),
+	}
+}
+
+func (c *namespaceCounter) NodeAdded(namespace, node string, replicas int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.added[namespace] = append(c.added[namespace], node)
+}
+
+func (c *namespaceCounter) NodeRemoved(namespace, node string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.removed[namespace] = append(c.removed[namespace], node)
+}
+
+func (c *namespaceCounter) Lookup(namespace string, found bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.lookups[namespace]++
+}
+
+func TestRingManager(t *testing.T) {
+	m := NewRingManager(nil, WithReplicas(50))
+	sessions := m.Ring("sessions")
+	assert.Same(t, sessions, m.Ring("sessions"))
+	sessions.Add("a")
+	assert.Equal(t, 50, sessions.ReplicaCount("a"))
+
+	_, ok := m.Find("products")
+	assert.False(t, ok)
+	m.Ring("products").Add("b")
+	found, ok := m.Find("products")
+	assert.True(t, ok)
+	assert.True(t, found.Contains("b"))
+	assert.Equal(t, []string{"products", "sessions"}, m.Namespaces())
+
+	assert.True(t, m.Delete("products"))
+	assert.False(t, m.Delete("products"))
+	assert.Equal(t, 0, found.Len())
+	assert.Equal(t, []string{"sessions"}, m.Namespaces())
+
+	m.Close()
+	assert.Empty(t, m.Namespaces())
+	assert.Equal(t, 0, sessions.Len())
+}
+
+func TestRingManagerMetrics(t *testing.T) {
+	counter := newNamespaceCounter()
+	m := NewRingManager(counter)
+	m.Ring("sessions").Add("a")
+	m.Ring("products").Add("b")
+	m.Ring("products").Remove("b")
+	m.Ring("sessions").Get("key")
+	m.Ring("search").Get("key")
+
+	assert.Equal(t, map[string][]string{"sessions": {"a"}, "products": {"b"}}, counter.added)
+	assert.Equal(t, map[string][]string{"products": {"b"}}, counter.removed)
+	assert.Equal(t, map[string]int{"sessions": 1, "search": 1}, counter.lookups)
+}
+
+func TestRingManagerSnapshot(t *testing.T) {
+	m := NewRingManager(nil)
+	m.Ring("sessions").Add("a")
+	m.Ring("sessions").Add("b")
+	m.Ring("products").Add("c")
+	snapshots := m.Snapshot()
+
+	restored := NewRingManager(nil)
+	restored.Ring("stale").Add("d")
+	stale := restored.Ring("stale")
+	restored.Restore(snapshots)
+	assert.Equal(t, []string{"products", "sessions"}, restored.Namespaces())
+	assert.Equal(t, snapshots, restored.Snapshot())
+	assert.Equal(t, 0, stale.Len())
+	for _, key := range []string{"1", "2", "3"} {
+		expect, _ := m.Ring("sessions").Get(key)
+		actual, _ := restored.Ring("sessions").Get(key)
+		assert.Equal(t, expect, actual)
+	}
+}
+
+func TestRingManagerDeleteStopsTimers(t *testing.T) {
+	clock := newFakeClock()
+	m := NewRingManager(nil)
+	ring := m.Ring("sessions")
+	ring.clock = clock
+	ring.AddWithTTL("a", time.Second)
+
+	m.Delete("sessions")
+	assert.Empty(t, ring.ttls)
+	clock.Advance(2 * time.Second)
+	assert.Equal(t, 0, ring.Len())
+}
+
+func TestRingManagerConcurrent(t *testing.T) {
+	m := NewRingManager(nil)
+	var wg sync.WaitGroup
+	rings := make([]*ConsistentHash, 8)
+	for i := range rings {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rings[i] = m.Ring("shared")
+		}()
+	}
+	wg.Wait()
+	for _, ring := range rings {
+		assert.Same(t, rings[0], ring)
+	}
+}
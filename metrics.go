@@ -0,0 +1,12 @@
+package zero
+
+// 一致性哈希的指标上报
+// 回调在释放锁之后执行，实现中可以安全地访问哈希环
+type Metrics interface {
+	// 节点加入或更新了虚拟节点数量
+	NodeAdded(node string, replicas int)
+	// 节点被删除
+	NodeRemoved(node string)
+	// 一次查找，found 表示是否找到了节点
+	Lookup(found bool)
+}
@@ -0,0 +1,54 @@
+package zero
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingMetrics struct {
+	t       *testing.T
+	ring    *ConsistentHash
+	added   map[string]int
+	removed []string
+	hits    int
+	misses  int
+}
+
+func (m *countingMetrics) NodeAdded(node string, replicas int) {
+	m.added[node] = replicas
+	// 回调时已释放锁
+	assert.True(m.t, m.ring.Contains(node))
+}
+
+func (m *countingMetrics) NodeRemoved(node string) {
+	m.removed = append(m.removed, node)
+}
+
+func (m *countingMetrics) Lookup(found bool) {
+	if found {
+		m.hits++
+	} else {
+		m.misses++
+	}
+}
+
+func TestWithMetrics(t *testing.T) {
+	metrics := &countingMetrics{t: t, added: make(map[string]int)}
+	ch := New(WithMetrics(metrics), WithReplicas(200))
+	metrics.ring = ch
+
+	ch.Get("miss")
+	ch.Add("first")
+	ch.AddWithWeight("second", 50)
+	ch.Get("hit")
+	ch.GetBytes([]byte("hit"))
+	ch.GetHash(1)
+	ch.Remove("second")
+	ch.Remove("unknown")
+
+	assert.Equal(t, map[string]int{"first": 200, "second": 100}, metrics.added)
+	assert.Equal(t, []string{"second"}, metrics.removed)
+	assert.Equal(t, 3, metrics.hits)
+	assert.Equal(t, 1, metrics.misses)
+}
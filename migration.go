@@ -0,0 +1,78 @@
+package zero
+
+import (
+	"sync"
+	"time"
+)
+
+// 缓存集群迁移期间的路由：迁移窗口内写入新旧两个环的节点，读取先新后旧；窗口结束后只使用新的环
+// 新旧环在迁移期间仍可各自变化，路由总是按它们的当前拓扑计算
+type MigrationRouter struct {
+	old, new *ConsistentHash
+	clock    Clock
+
+	lock sync.RWMutex
+	// 窗口的结束时间
+	deadline time.Time
+	flipped  bool
+}
+
+// 创建从 old 迁移到 new 的路由，迁移窗口从现在开始，持续 window，时间来源沿用 new 的配置
+// 窗口应不短于缓存的过期时间，使窗口结束时新的环已有完整的数据
+func NewMigrationRouter(old, new *ConsistentHash, window time.Duration) *MigrationRouter {
+	return &MigrationRouter{
+		old:      old,
+		new:      new,
+		clock:    new.clock,
+		deadline: new.clock.Now().Add(window),
+	}
+}
+
+// 键的写入节点，窗口内依次为新、旧环的节点，两者相同时只有一个；窗口结束后只有新环的节点
+// 环上没有节点时跳过该环
+func (m *MigrationRouter) WriteTargets(key string) []string {
+	return m.targets(key)
+}
+
+// 键的读取顺序，窗口内先读新环的节点，未命中时再读旧环的节点；窗口结束后只读新环的节点
+func (m *MigrationRouter) ReadOrder(key string) []string {
+	return m.targets(key)
+}
+
+// 键在新环上的节点，即迁移完成后的归属
+func (m *MigrationRouter) Get(key string) (interface{}, bool) {
+	return m.new.Get(key)
+}
+
+// 迁移窗口是否已经结束
+func (m *MigrationRouter) Flipped() bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.flippedLocked()
+}
+
+// 提前结束迁移窗口，之后只使用新的环
+func (m *MigrationRouter) Flip() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.flipped = true
+}
+
+// 调用方需持有读锁
+func (m *MigrationRouter) flippedLocked() bool {
+	return m.flipped || !m.clock.Now().Before(m.deadline)
+}
+
+func (m *MigrationRouter) targets(key string) []string {
+	targets := make([]string, 0, 2)
+	if node, ok := m.new.Get(key); ok {
+		targets = append(targets, node.(string))
+	}
+	if m.Flipped() {
+		return targets
+	}
+	if node, ok := m.old.Get(key); ok && (len(targets) == 0 || targets[0] != node) {
+		targets = append(targets, node.(string))
+	}
+	return targets
+}
@@ -0,0 +1,61 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrationRouter(t *testing.T) {
+	clock := newFakeClock()
+	old, new := NewConsistentHash(), NewConsistentHash()
+	new.clock = clock
+	for i := 0; i < 3; i++ {
+		old.Add("old" + strconv.Itoa(i))
+		new.Add("old" + strconv.Itoa(i))
+	}
+	new.Add("new")
+	m := NewMigrationRouter(old, new, time.Hour)
+
+	var moved int
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		from, _ := old.Get(key)
+		to, _ := new.Get(key)
+		targets := m.WriteTargets(key)
+		if from == to {
+			assert.Equal(t, []string{to.(string)}, targets)
+		} else {
+			moved++
+			assert.Equal(t, []string{to.(string), from.(string)}, targets)
+		}
+		assert.Equal(t, targets, m.ReadOrder(key))
+		node, _ := m.Get(key)
+		assert.Equal(t, to, node)
+	}
+	assert.True(t, moved > 0)
+
+	// 窗口结束后只使用新的环
+	clock.Advance(time.Hour)
+	assert.True(t, m.Flipped())
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		to, _ := new.Get(key)
+		assert.Equal(t, []string{to.(string)}, m.WriteTargets(key))
+	}
+}
+
+func TestMigrationRouterFlip(t *testing.T) {
+	old, new := NewConsistentHash(), NewConsistentHash()
+	old.Add("a")
+	m := NewMigrationRouter(old, new, time.Hour)
+	// 新环为空时只有旧环的节点
+	assert.Equal(t, []string{"a"}, m.ReadOrder("key"))
+	assert.False(t, m.Flipped())
+
+	m.Flip()
+	assert.True(t, m.Flipped())
+	assert.Empty(t, m.ReadOrder("key"))
+}
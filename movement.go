@@ -0,0 +1,23 @@
+package zero
+
+import "strconv"
+
+// 用 sampleKeys 个样本键估算从 before 变为 after 时改变所属节点的键比例
+// 两个环可以使用不同的哈希函数和虚拟节点数量，用于验证调参或更换哈希函数带来的迁移量
+// 样本键固定，相同的输入总是得到相同的结果；sampleKeys 不大于0时返回0
+func MovedFraction(before, after *ConsistentHash, sampleKeys int) float64 {
+	if sampleKeys <= 0 {
+		return 0
+	}
+
+	var moved int
+	for i := 0; i < sampleKeys; i++ {
+		key := "sample:" + strconv.Itoa(i)
+		from, _ := before.Get(key)
+		to, _ := after.Get(key)
+		if from != to {
+			moved++
+		}
+	}
+	return float64(moved) / float64(sampleKeys)
+}
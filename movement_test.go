@@ -0,0 +1,28 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMovedFraction(t *testing.T) {
+	before := NewConsistentHash()
+	for i := 0; i < 10; i++ {
+		before.Add("10.0.0." + strconv.Itoa(i) + ":6379")
+	}
+	assert.Equal(t, 0., MovedFraction(before, before.Clone(), 10000))
+	assert.Equal(t, 0., MovedFraction(before, before, 0))
+
+	after := before.Clone()
+	after.Add("10.0.0.10:6379")
+	assert.InDelta(t, 1./11, MovedFraction(before, after, 10000), .04)
+
+	// 更换哈希函数几乎让所有的键都发生迁移
+	rehashed := NewCustomConsistentHash(minReplicas, Md5Hash)
+	for _, node := range before.Nodes() {
+		rehashed.Add(node)
+	}
+	assert.InDelta(t, .9, MovedFraction(before, rehashed, 10000), .05)
+}
@@ -0,0 +1,143 @@
+package zero
+
+import (
+	"sort"
+	"sync"
+)
+
+// 多探针一致性哈希的默认探针数量，论文中21个探针可将峰均比控制在1.05左右
+const defaultProbes = 21
+
+// 多探针一致性哈希
+// 每个物理节点只占一个位置，查找时对键做多次探测，选择顺时针距离最近的节点
+// 以查找时的多次计算换取远小于虚拟节点方案的内存占用
+type MultiProbeHash struct {
+	// 哈希函数
+	hashFunc Func
+	// 每次查找的探针数量
+	probes int
+	// 节点位置列表
+	keys []uint64
+	// 位置到物理节点的映射
+	ring map[uint64][]interface{}
+	// 物理节点到位置的映射
+	nodes map[string]uint64
+	// 读写锁
+	lock sync.RWMutex
+}
+
+func NewMultiProbeHash() *MultiProbeHash {
+	return NewCustomMultiProbeHash(defaultProbes, Hash)
+}
+
+func NewCustomMultiProbeHash(probes int, fn Func) *MultiProbeHash {
+	if probes < 1 {
+		probes = defaultProbes
+	}
+
+	if fn == nil {
+		fn = Hash
+	}
+
+	return &MultiProbeHash{
+		hashFunc: fn,
+		probes:   probes,
+		ring:     make(map[uint64][]interface{}),
+		nodes:    make(map[string]uint64),
+	}
+}
+
+// 增加物理节点，支持重复添加
+func (h *MultiProbeHash) Add(node string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if _, ok := h.nodes[node]; ok {
+		return
+	}
+
+	hash := h.hashFunc([]byte(node))
+	h.nodes[node] = hash
+	if _, ok := h.ring[hash]; !ok {
+		h.keys = append(h.keys, hash)
+		sort.Slice(h.keys, func(i, j int) bool {
+			return h.keys[i] < h.keys[j]
+		})
+	}
+	h.ring[hash] = insertChain(h.ring[hash], node)
+}
+
+// 删除物理节点
+func (h *MultiProbeHash) Remove(node string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	hash, ok := h.nodes[node]
+	if !ok {
+		return
+	}
+	delete(h.nodes, node)
+
+	newNodes := h.ring[hash][:0]
+	for _, x := range h.ring[hash] {
+		if x != node {
+			newNodes = append(newNodes, x)
+		}
+	}
+	if len(newNodes) > 0 {
+		h.ring[hash] = newNodes
+		return
+	}
+
+	delete(h.ring, hash)
+	index := sort.Search(len(h.keys), func(i int) bool {
+		return h.keys[i] >= hash
+	})
+	h.keys = append(h.keys[:index], h.keys[index+1:]...)
+}
+
+// 对键做多次探测，返回顺时针距离最近的节点
+func (h *MultiProbeHash) Get(v string) (interface{}, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if len(h.keys) == 0 {
+		return nil, false
+	}
+
+	// 双重哈希生成探针序列，步长取奇数保证探针互不相同
+	h1 := h.hashFunc([]byte(v))
+	h2 := h.hashFunc([]byte(innerRepr(v))) | 1
+	var point uint64
+	minDistance := ^uint64(0)
+	for i := 0; i < h.probes; i++ {
+		probe := h1 + uint64(i)*h2
+		index := sort.Search(len(h.keys), func(i int) bool {
+			return h.keys[i] >= probe
+		}) % len(h.keys)
+		// 无符号减法处理了跨越0点的情况
+		if distance := h.keys[index] - probe; distance < minDistance {
+			minDistance = distance
+			point = h.keys[index]
+		}
+	}
+
+	nodes := h.ring[point]
+	if len(nodes) == 1 {
+		return nodes[0], true
+	}
+	return nodes[int(h2%uint64(len(nodes)))], true
+}
+
+// 当前所有物理节点，按字典序排列
+func (h *MultiProbeHash) Nodes() []string {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	nodes := make([]string, 0, len(h.nodes))
+	for node := range h.nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
@@ -0,0 +1,59 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiProbeHash(t *testing.T) {
+	h := NewMultiProbeHash()
+	_, ok := h.Get("any")
+	assert.False(t, ok)
+
+	for i := 0; i < keySize; i++ {
+		h.Add("localhost:" + strconv.Itoa(i))
+	}
+	assert.Equal(t, keySize, len(h.keys))
+
+	const total = requestSize * 10
+	loads := make(map[interface{}]int)
+	for i := 0; i < total; i++ {
+		node, ok := h.Get(strconv.Itoa(i))
+		assert.True(t, ok)
+		loads[node]++
+	}
+
+	var peak int
+	for _, load := range loads {
+		peak = max(peak, load)
+	}
+	ratio := float64(peak) / (float64(total) / keySize)
+	assert.True(t, ratio < 1.5, ratio)
+}
+
+func TestMultiProbeHashLeastTransfer(t *testing.T) {
+	h := NewMultiProbeHash()
+	for i := 0; i < keySize; i++ {
+		h.Add("localhost:" + strconv.Itoa(i))
+	}
+
+	keys := make(map[int]interface{}, requestSize)
+	for i := 0; i < requestSize; i++ {
+		keys[i], _ = h.Get(strconv.Itoa(i))
+	}
+
+	const removed = "localhost:3"
+	h.Remove(removed)
+	h.Remove(removed)
+	assert.Equal(t, keySize-1, len(h.keys))
+	for i := 0; i < requestSize; i++ {
+		node, ok := h.Get(strconv.Itoa(i))
+		assert.True(t, ok)
+		assert.NotEqual(t, removed, node)
+		if keys[i] != removed {
+			assert.Equal(t, keys[i], node)
+		}
+	}
+}
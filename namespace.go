@@ -0,0 +1,55 @@
+package zero
+
+import "encoding/binary"
+
+// 按键查找节点
+// ConsistentHash、FrozenRing 以及 Namespaced 和 TenantView 返回的视图都实现了该接口
+type KeyRouter interface {
+	Get(key string) (interface{}, bool)
+	GetBytes(b []byte) (interface{}, bool)
+	GetCandidates(key string, n int) []Candidate
+}
+
+var (
+	_ KeyRouter = (*ConsistentHash)(nil)
+	_ KeyRouter = (*FrozenRing)(nil)
+)
+
+// 自动为键加上命名空间前缀的轻量视图，不复制哈希环
+type namespacedRouter struct {
+	ring   *ConsistentHash
+	prefix []byte
+}
+
+// 返回在键前加上命名空间前缀再查找的视图，多个租户可以共用同一个哈希环而互不冲突
+// 前缀以长度开头编码，不同前缀下的任意键都不会得到相同的字节，如 "a"+"bc" 与 "ab"+"c"
+// 视图随哈希环的拓扑变化；固定路由按加上前缀后的键匹配，可通过 NamespacedKey 得到
+func (h *ConsistentHash) Namespaced(prefix string) KeyRouter {
+	return namespacedRouter{ring: h, prefix: appendNamespace(nil, prefix)}
+}
+
+// 命名空间 prefix 下的键 key 实际用于查找的键
+func NamespacedKey(prefix, key string) string {
+	return string(appendNamespace(nil, prefix)) + key
+}
+
+func appendNamespace(buf []byte, prefix string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(prefix)))
+	return append(buf, prefix...)
+}
+
+func (r namespacedRouter) Get(key string) (interface{}, bool) {
+	return r.ring.Get(r.key(key))
+}
+
+func (r namespacedRouter) GetBytes(b []byte) (interface{}, bool) {
+	return r.ring.GetBytes(append(r.prefix[:len(r.prefix):len(r.prefix)], b...))
+}
+
+func (r namespacedRouter) GetCandidates(key string, n int) []Candidate {
+	return r.ring.GetCandidates(r.key(key), n)
+}
+
+func (r namespacedRouter) key(key string) string {
+	return string(r.prefix) + key
+}
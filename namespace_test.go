@@ -0,0 +1,44 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespaced(t *testing.T) {
+	ch := New()
+	for i := 0; i < 5; i++ {
+		ch.Add("node" + strconv.Itoa(i))
+	}
+	a := ch.Namespaced("tenant-a")
+	b := ch.Namespaced("tenant-b")
+
+	var differ int
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		na, ok := a.Get(key)
+		assert.True(t, ok)
+		expect, _ := ch.Get(NamespacedKey("tenant-a", key))
+		assert.Equal(t, expect, na)
+		bytes, _ := a.GetBytes([]byte(key))
+		assert.Equal(t, na, bytes)
+		assert.Equal(t, na, a.GetCandidates(key, 2)[0].Node)
+
+		nb, _ := b.Get(key)
+		if na != nb {
+			differ++
+		}
+	}
+	// 不同租户的相同键分布相互独立
+	assert.Greater(t, differ, 600)
+
+	// 前缀与键的拼接不会产生歧义
+	assert.NotEqual(t, NamespacedKey("a", "bc"), NamespacedKey("ab", "c"))
+
+	// 视图随拓扑变化，固定路由按加上前缀后的键匹配
+	ch.Pin(NamespacedKey("tenant-a", "vip"), "node3")
+	node, _ := a.Get("vip")
+	assert.Equal(t, "node3", node)
+}
@@ -0,0 +1,5 @@
+//go:build !race
+
+package zero
+
+const raceEnabled = false
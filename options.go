@@ -0,0 +1,121 @@
+package zero
+
+import "encoding/binary"
+
+// 构造一致性哈希时的可选配置
+type Option func(h *ConsistentHash)
+
+// 使用指定的哈希函数，nil 表示使用默认的 Hash
+func WithHashFunc(fn Func) Option {
+	return func(h *ConsistentHash) {
+		if fn != nil {
+			h.hashFunc = fn
+		}
+	}
+}
+
+// 使用指定的虚拟节点键生成方法
+func WithReplicaKeyFunc(fn ReplicaKeyFunc) Option {
+	return func(h *ConsistentHash) {
+		if fn != nil {
+			h.replicaKey = fn
+			h.appendReplicaKey = nil
+		}
+	}
+}
+
+// 兼容旧版本的虚拟节点键生成方法，键分布与旧版本一致
+func WithLegacyReplicaKeys() Option {
+	return func(h *ConsistentHash) {
+		h.replicaKey = LegacyReplicaKey
+		h.appendReplicaKey = appendLegacyReplicaKey
+	}
+}
+
+// 为哈希函数设置种子
+// 不同部署使用不同的种子，键到节点的映射随之不同，攻击者无法构造集中落在同一节点的键
+// 需要更强的密钥哈希时可使用 hashes.SipHash
+func WithSeed(seed uint64) Option {
+	return func(h *ConsistentHash) {
+		h.seed = seed
+		h.seeded = true
+	}
+}
+
+// 虚拟节点放大因子，即每个节点的虚拟节点数量，至少为1
+// 默认不设下限，需要下限时配合 WithMinReplicas 使用
+func WithReplicas(replicas int) Option {
+	return func(h *ConsistentHash) {
+		h.replicas = replicas
+	}
+}
+
+// 虚拟节点放大因子的下限，WithReplicas 小于该值时使用该值
+// 防止误配置过少的虚拟节点导致负载严重不均
+func WithMinReplicas(n int) Option {
+	return func(h *ConsistentHash) {
+		h.replicaFloor = n
+	}
+}
+
+// 上报节点变更和查找的指标
+func WithMetrics(metrics Metrics) Option {
+	return func(h *ConsistentHash) {
+		h.metrics = metrics
+	}
+}
+
+// 按可选配置创建一致性哈希
+// 默认使用 Hash 作为哈希函数，每个节点 minReplicas 个虚拟节点
+func New(opts ...Option) *ConsistentHash {
+	h := &ConsistentHash{
+		replicas:         minReplicas,
+		hashFunc:         Hash,
+		replicaKey:       DefaultReplicaKey,
+		appendReplicaKey: appendDefaultReplicaKey,
+		ring:             make(map[uint64][]interface{}),
+		nodes:            make(map[string]int),
+		points:           make(map[string][]uint64),
+		clock:            realClock{},
+		rand:             globalRand{},
+		drainGrace:       defaultDrainGrace,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.replicas = max(h.replicas, h.replicaFloor, 1)
+	if !h.acceptHashFunc(h.hashFunc) {
+		h.hashFunc = Hash
+	}
+	// 所有配置生效后再包装，与 WithHashFunc 的先后顺序无关
+	h.setHashFuncLocked(h.hashFunc)
+	// 外部存储中可能残留上次运行的位置
+	if h.store != nil {
+		h.store.Clear()
+	}
+	return h
+}
+
+// 设置哈希函数，按配置追加种子并重新生成分层的虚拟节点位置函数
+// 调用方需持有写锁
+func (h *ConsistentHash) setHashFuncLocked(fn Func) {
+	if h.seeded {
+		fn = seededHash(fn, h.seed)
+	}
+	h.hashFunc = fn
+	if h.spread {
+		h.pointsFunc = spreadPoints(fn)
+	}
+}
+
+// 在数据前追加种子后再计算哈希，拼接使用复用的缓冲区
+func seededHash(fn Func, seed uint64) Func {
+	return func(data []byte) uint64 {
+		buf := getBuffer()
+		*buf = binary.LittleEndian.AppendUint64((*buf)[:0], seed)
+		*buf = append(*buf, data...)
+		hash := fn(*buf)
+		putBuffer(buf)
+		return hash
+	}
+}
@@ -0,0 +1,113 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSeed(t *testing.T) {
+	create := func(opts ...Option) *ConsistentHash {
+		ch := New(opts...)
+		for i := 0; i < keySize; i++ {
+			ch.Add("localhost:" + strconv.Itoa(i))
+		}
+		return ch
+	}
+
+	plain := create()
+	seeded1 := create(WithSeed(1))
+	seeded1Again := create(WithSeed(1), WithHashFunc(Hash))
+	seeded2 := create(WithSeed(2))
+
+	var diffPlain, diffSeed int
+	for i := 0; i < requestSize; i++ {
+		key := strconv.Itoa(i)
+		v1, _ := seeded1.Get(key)
+		v1Again, _ := seeded1Again.Get(key)
+		v2, _ := seeded2.Get(key)
+		v, _ := plain.Get(key)
+		assert.Equal(t, v1, v1Again)
+		if v1 != v2 {
+			diffSeed++
+		}
+		if v1 != v {
+			diffPlain++
+		}
+	}
+	assert.True(t, diffSeed > requestSize/2)
+	assert.True(t, diffPlain > requestSize/2)
+}
+
+func TestWithReplicaKeyFunc(t *testing.T) {
+	assert.Equal(t, []byte("node\x00\x00\x00\x00\x0b"), DefaultReplicaKey("node", 11))
+	assert.Equal(t, []byte("node11"), LegacyReplicaKey("node", 11))
+	assert.NotEqual(t, DefaultReplicaKey("node1", 1), DefaultReplicaKey("node", 11))
+
+	legacy := New(WithLegacyReplicaKeys())
+	legacy.Add("node1")
+	legacy.Add("node")
+	// 旧方案中 "node1"+"1" 与 "node"+"11" 落在同一个虚拟节点
+	assert.Equal(t, 2, len(legacy.ring[Hash([]byte("node11"))]))
+
+	ch := New()
+	ch.Add("node1")
+	ch.Add("node")
+	for _, nodes := range ch.ring {
+		assert.Equal(t, 1, len(nodes))
+	}
+
+	var calls int
+	custom := New(WithReplicaKeyFunc(func(node string, index int) []byte {
+		calls++
+		return []byte(node + "#" + strconv.Itoa(index))
+	}))
+	custom.Add("node")
+	custom.Remove("node")
+	assert.Equal(t, minReplicas, calls)
+	assert.Empty(t, custom.keys)
+}
+
+func TestAppendReplicaKey(t *testing.T) {
+	for _, node := range []string{"", "node", "localhost:8080"} {
+		for _, index := range []int{0, 11, 1 << 20} {
+			assert.Equal(t, DefaultReplicaKey(node, index), appendDefaultReplicaKey(nil, node, index))
+			assert.Equal(t, LegacyReplicaKey(node, index), appendLegacyReplicaKey(nil, node, index))
+		}
+	}
+
+	// 内置的虚拟节点键只为结果分配内存，竞态检测下分配次数不稳定
+	if raceEnabled {
+		return
+	}
+	for _, ch := range []*ConsistentHash{New(), New(WithLegacyReplicaKeys()), New(WithSeed(1))} {
+		allocs := testing.AllocsPerRun(100, func() {
+			ch.virtualPoints("localhost:8080", 200)
+		})
+		assert.Less(t, allocs, 2.0)
+	}
+}
+
+func TestNewDefaults(t *testing.T) {
+	ch := New()
+	assert.Equal(t, minReplicas, ch.replicas)
+	assert.Equal(t, Hash([]byte(text)), ch.hashFunc([]byte(text)))
+
+	assert.Equal(t, 300, New(WithReplicas(300)).replicas)
+	assert.Equal(t, 300, NewCustomConsistentHash(300, nil).replicas)
+	assert.Equal(t, minReplicas, NewCustomConsistentHash(10, nil).replicas)
+	assert.Equal(t, CollisionError, New(WithCollisionPolicy(CollisionError)).collision)
+}
+
+func TestWithMinReplicas(t *testing.T) {
+	ch := New(WithReplicas(10))
+	assert.Equal(t, 10, ch.replicas)
+	ch.Add("first")
+	assert.Equal(t, 10, len(ch.keys))
+
+	assert.Equal(t, 1, New(WithReplicas(0)).replicas)
+	assert.Equal(t, 50, New(WithReplicas(10), WithMinReplicas(50)).replicas)
+	assert.Equal(t, 50, New(WithMinReplicas(50), WithReplicas(10)).replicas)
+	assert.Equal(t, 80, New(WithReplicas(80), WithMinReplicas(50)).replicas)
+}
@@ -0,0 +1,166 @@
+// 一致性哈希的 OpenTelemetry 埋点
+// 独立为单独的 module，核心包不依赖 OpenTelemetry
+package otel
+
+import (
+	"context"
+	"time"
+
+	"consistenthash"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const scope = "consistenthash/otel"
+
+// 属性名
+const (
+	NodeKey      = attribute.Key("consistenthash.node")
+	RingSizeKey  = attribute.Key("consistenthash.ring.size")
+	CollisionKey = attribute.Key("consistenthash.collision")
+	PinnedKey    = attribute.Key("consistenthash.pinned")
+	FoundKey     = attribute.Key("consistenthash.found")
+	OperationKey = attribute.Key("consistenthash.operation")
+)
+
+type (
+	// 埋点配置
+	Option func(c *config)
+
+	config struct {
+		tracerProvider trace.TracerProvider
+		meterProvider  metric.MeterProvider
+	}
+
+	// 带埋点的一致性哈希
+	// Get、Add、Remove 各产生一个 span，并上报查找次数、耗时和成员变更
+	Ring struct {
+		ring     *zero.ConsistentHash
+		tracer   trace.Tracer
+		lookups  metric.Int64Counter
+		duration metric.Float64Histogram
+		changes  metric.Int64Counter
+	}
+)
+
+// 指定 TracerProvider，默认使用全局的 TracerProvider
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// 指定 MeterProvider，默认使用全局的 MeterProvider
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = provider
+	}
+}
+
+// 为哈希环添加埋点
+func NewRing(ring *zero.ConsistentHash, opts ...Option) (*Ring, error) {
+	c := config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	meter := c.meterProvider.Meter(scope)
+	r := &Ring{
+		ring:   ring,
+		tracer: c.tracerProvider.Tracer(scope),
+	}
+
+	var err error
+	if r.lookups, err = meter.Int64Counter("consistenthash.lookups",
+		metric.WithDescription("Number of key lookups")); err != nil {
+		return nil, err
+	}
+	if r.duration, err = meter.Float64Histogram("consistenthash.lookup.duration",
+		metric.WithDescription("Duration of key lookups"), metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if r.changes, err = meter.Int64Counter("consistenthash.membership.changes",
+		metric.WithDescription("Number of node additions and removals")); err != nil {
+		return nil, err
+	}
+	if _, err = meter.Int64ObservableGauge("consistenthash.ring.size",
+		metric.WithDescription("Number of nodes on the ring"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			o.Observe(int64(ring.Len()))
+			return nil
+		})); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// 被埋点的哈希环
+func (r *Ring) Unwrap() *zero.ConsistentHash {
+	return r.ring
+}
+
+// 查找键所在的节点
+func (r *Ring) Get(ctx context.Context, key string) (interface{}, bool) {
+	ctx, span := r.tracer.Start(ctx, "consistenthash.Get", trace.WithSpanKind(trace.SpanKindInternal))
+	defer span.End()
+
+	start := time.Now()
+	info, ok := r.ring.Lookup(key)
+	elapsed := time.Since(start).Seconds()
+
+	attrs := []attribute.KeyValue{
+		FoundKey.Bool(ok),
+		CollisionKey.Bool(info.Collision),
+		PinnedKey.Bool(info.Pinned),
+	}
+	r.lookups.Add(ctx, 1, metric.WithAttributes(attrs...))
+	r.duration.Record(ctx, elapsed, metric.WithAttributes(FoundKey.Bool(ok)))
+
+	attrs = append(attrs, RingSizeKey.Int(info.RingSize))
+	if node, isString := info.Node.(string); isString {
+		attrs = append(attrs, NodeKey.String(node))
+	}
+	span.SetAttributes(attrs...)
+	if !ok {
+		span.SetStatus(codes.Error, "no node found")
+	}
+	return info.Node, ok
+}
+
+// 增加节点
+func (r *Ring) Add(ctx context.Context, node string) {
+	r.change(ctx, "consistenthash.Add", "add", node, func() {
+		r.ring.Add(node)
+	})
+}
+
+// 按权重增加节点
+func (r *Ring) AddWithWeight(ctx context.Context, node string, weight int) {
+	r.change(ctx, "consistenthash.Add", "add", node, func() {
+		r.ring.AddWithWeight(node, weight)
+	})
+}
+
+// 删除节点
+func (r *Ring) Remove(ctx context.Context, node string) {
+	r.change(ctx, "consistenthash.Remove", "remove", node, func() {
+		r.ring.Remove(node)
+	})
+}
+
+func (r *Ring) change(ctx context.Context, name, op, node string, fn func()) {
+	ctx, span := r.tracer.Start(ctx, name)
+	defer span.End()
+
+	fn()
+	span.SetAttributes(NodeKey.String(node), RingSizeKey.Int(r.ring.Len()))
+	r.changes.Add(ctx, 1, metric.WithAttributes(OperationKey.String(op)))
+}
@@ -0,0 +1,75 @@
+package otel
+
+import (
+	"context"
+	"testing"
+
+	"consistenthash"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRing(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	r, err := NewRing(zero.NewConsistentHash(),
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+	_, ok := r.Get(ctx, "miss")
+	assert.False(t, ok)
+	r.Add(ctx, "first")
+	r.AddWithWeight(ctx, "second", 50)
+	node, ok := r.Get(ctx, "hit")
+	assert.True(t, ok)
+	expect, _ := r.Unwrap().Get("hit")
+	assert.Equal(t, expect, node)
+	r.Remove(ctx, "second")
+
+	ended := spans.Ended()
+	names := make([]string, len(ended))
+	for i, span := range ended {
+		names[i] = span.Name()
+	}
+	assert.Equal(t, []string{
+		"consistenthash.Get",
+		"consistenthash.Add",
+		"consistenthash.Add",
+		"consistenthash.Get",
+		"consistenthash.Remove",
+	}, names)
+
+	attrs := attribute.NewSet(ended[3].Attributes()...)
+	value, _ := attrs.Value(NodeKey)
+	assert.Equal(t, expect, value.AsString())
+	value, _ = attrs.Value(RingSizeKey)
+	assert.Equal(t, int64(2), value.AsInt64())
+	value, _ = attrs.Value(CollisionKey)
+	assert.False(t, value.AsBool())
+
+	var rm metricdata.ResourceMetrics
+	assert.Nil(t, reader.Collect(ctx, &rm))
+	sums := make(map[string]int64)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		switch data := m.Data.(type) {
+		case metricdata.Sum[int64]:
+			for _, point := range data.DataPoints {
+				sums[m.Name] += point.Value
+			}
+		case metricdata.Gauge[int64]:
+			sums[m.Name] = data.DataPoints[0].Value
+		}
+	}
+	assert.Equal(t, map[string]int64{
+		"consistenthash.lookups":            2,
+		"consistenthash.membership.changes": 3,
+		"consistenthash.ring.size":          1,
+	}, sums)
+}
@@ -0,0 +1,89 @@
+package zero
+
+import "math"
+
+// 超出哈希空间占比上限时的处理策略
+type OwnershipPolicy int
+
+const (
+	// 只通过回调告警，不修改哈希环
+	OwnershipWarn OwnershipPolicy = iota
+	// 为未超限的节点成倍增加虚拟节点，压低超限节点的占比，仍无法满足时再告警
+	OwnershipRebalance
+)
+
+// 自动补充虚拟节点的最大轮数
+const ownershipRounds = 8
+
+// 单个节点最多占有 limit（0到1之间）的哈希空间，每次拓扑变更后检查
+// 按 policy 处理超限的节点，仍然超限时对每个超限的节点调用 onViolation，share 为其占比
+// onViolation 在写锁内调用，不能访问哈希环；节点少于两个时不检查
+func WithOwnershipLimit(limit float64, policy OwnershipPolicy, onViolation func(node string, share float64)) Option {
+	return func(h *ConsistentHash) {
+		h.ownershipLimit = limit
+		h.ownershipPolicy = policy
+		h.onOwnershipViolation = onViolation
+	}
+}
+
+// 检查并处理超出占比上限的节点
+// 调用方需持有写锁
+func (h *ConsistentHash) enforceOwnershipLocked() {
+	if h.ownershipLimit <= 0 || len(h.nodes) < 2 {
+		return
+	}
+
+	violations := h.ownershipViolations()
+	if h.ownershipPolicy == OwnershipRebalance {
+		for round := 0; round < ownershipRounds && len(violations) > 0; round++ {
+			if !h.growUnderLimitLocked(violations) {
+				break
+			}
+			violations = h.ownershipViolations()
+		}
+	}
+
+	if h.onOwnershipViolation == nil {
+		return
+	}
+	for _, node := range h.nodesLocked() {
+		if share, ok := violations[node]; ok {
+			h.onOwnershipViolation(node, share)
+		}
+	}
+}
+
+// 占比超出上限的节点及其占比
+// 调用方需持有读锁
+func (h *ConsistentHash) ownershipViolations() map[string]float64 {
+	violations := make(map[string]float64)
+	for node, share := range h.ownership() {
+		if share > h.ownershipLimit {
+			violations[node] = share
+		}
+	}
+	return violations
+}
+
+// 未超限的节点的虚拟节点数量增加一半，没有可以增加的节点时返回 false
+// 调用方需持有写锁
+func (h *ConsistentHash) growUnderLimitLocked(violations map[string]float64) bool {
+	var grown bool
+	for _, node := range h.nodesLocked() {
+		replicas := h.nodes[node]
+		if _, ok := violations[node]; ok || replicas == 0 || replicas >= maxAutoReplicas {
+			continue
+		}
+		next := min(int(math.Ceil(float64(replicas)*1.5)), maxAutoReplicas)
+		h.removeLocked(node)
+		// 不能丢弃已有节点，冲突时退化为共享位置
+		if err := h.addLocked(node, next); err != nil {
+			h.insertLocked(node, next, h.virtualPoints(node, next))
+		}
+		grown = true
+	}
+	if grown {
+		h.sortKeys()
+	}
+	return grown
+}
@@ -0,0 +1,53 @@
+package zero
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOwnershipLimitWarn(t *testing.T) {
+	violations := make(map[string]float64)
+	ch := New(WithOwnershipLimit(0.5, OwnershipWarn, func(node string, share float64) {
+		violations[node] = share
+	}))
+	ch.AddPoint(0, "a")
+	assert.Empty(t, violations)
+	// b 只占有 (0, 2^62]
+	ch.AddPoint(1<<62, "b")
+	assert.Equal(t, map[string]float64{"a": 0.75}, violations)
+	assert.Equal(t, 1, ch.ReplicaCount("b"))
+}
+
+func TestOwnershipLimitRebalance(t *testing.T) {
+	var warned []string
+	ch := New(WithReplicas(1), WithOwnershipLimit(0.4, OwnershipRebalance, func(node string, share float64) {
+		warned = append(warned, node)
+	}))
+	for _, node := range []string{"a", "b", "c"} {
+		ch.Add(node)
+	}
+	// 节点太少时无法满足
+	assert.NotEmpty(t, warned)
+	warned = nil
+	ch.Add("d")
+
+	assert.Empty(t, warned)
+	for node, share := range ch.ownership() {
+		assert.LessOrEqual(t, share, 0.4, node)
+	}
+	var grown bool
+	for _, node := range ch.Nodes() {
+		grown = grown || ch.ReplicaCount(node) > 1
+	}
+	assert.True(t, grown)
+
+	warned = nil
+	ch = New(WithOwnershipLimit(0.3, OwnershipRebalance, func(node string, share float64) {
+		warned = append(warned, node)
+	}))
+	ch.Add("a")
+	ch.Add("b")
+	// 无法满足时告警
+	assert.ElementsMatch(t, []string{"a", "b"}, warned)
+}
@@ -0,0 +1,25 @@
+package zero
+
+// 键的主备节点，用于简单的主备路由
+// primary 与 Get 的结果一致，secondary 为从键的位置顺时针遇到的第一个不同于 primary 的节点
+// secondary 一定不等于 primary，环上只有一个节点时为 nil；环上没有节点时 ok 为 false
+func (h *ConsistentHash) GetPair(key string) (primary, secondary interface{}, ok bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if len(h.ring) == 0 {
+		return nil, nil, false
+	}
+	h.walk(key, func(node string) bool {
+		if primary == nil {
+			primary = node
+			return true
+		}
+		if node != primary {
+			secondary = node
+			return false
+		}
+		return true
+	})
+	return primary, secondary, true
+}
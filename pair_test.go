@@ -0,0 +1,57 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPair(t *testing.T) {
+	ch := New()
+	_, _, ok := ch.GetPair("key")
+	assert.False(t, ok)
+
+	ch.Add("a")
+	primary, secondary, ok := ch.GetPair("key")
+	assert.True(t, ok)
+	assert.Equal(t, "a", primary)
+	assert.Nil(t, secondary)
+
+	for i := 0; i < 5; i++ {
+		ch.Add("node" + strconv.Itoa(i))
+	}
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		primary, secondary, ok := ch.GetPair(key)
+		assert.True(t, ok)
+		assert.NotEqual(t, primary, secondary)
+		expect, _ := ch.Get(key)
+		assert.Equal(t, expect, primary)
+		candidates := ch.GetCandidates(key, 2)
+		assert.Equal(t, candidates[1].Node, secondary)
+	}
+
+	// 主节点下线后由备节点接管
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		primary, secondary, _ := ch.GetPair(key)
+		c := ch.Clone()
+		c.Remove(primary.(string))
+		node, _ := c.Get(key)
+		assert.Equal(t, secondary, node)
+	}
+}
+
+func TestGetPairPinned(t *testing.T) {
+	ch := New()
+	ch.Add("a")
+	ch.Add("b")
+	ch.Add("c")
+	ch.Pin("key", "b")
+
+	primary, secondary, _ := ch.GetPair("key")
+	assert.Equal(t, "b", primary)
+	assert.NotNil(t, secondary)
+	assert.NotEqual(t, "b", secondary)
+}
@@ -0,0 +1,235 @@
+package zero
+
+import (
+	"math/bits"
+	"sort"
+	"sync"
+)
+
+// 默认的分区数量
+const defaultPartitions = 1024
+
+// 固定分区的一致性哈希
+// 哈希空间被等分为固定数量的分区，键先映射到分区，再由分区映射到节点
+// 分区到节点的分配依次沿哈希环选择负载未满的节点（有界负载的一致性哈希），
+// 各节点的分区数按权重比例分配，权重相同的节点至多相差一个，拓扑变化时只迁移必须迁移的分区
+// 数据按分区迁移，便于逐步再平衡
+type PartitionRing struct {
+	// 哈希函数
+	hashFunc Func
+	// 分区数量
+	partitions int
+	// 分配分区用的哈希环
+	ring *ConsistentHash
+	// 节点的权重
+	weights map[string]int
+	// 分区的所属节点
+	owners []string
+	// 读写锁
+	lock sync.RWMutex
+}
+
+func NewPartitionRing(partitions int) *PartitionRing {
+	return NewCustomPartitionRing(partitions, Hash)
+}
+
+func NewCustomPartitionRing(partitions int, fn Func) *PartitionRing {
+	if partitions < 1 {
+		partitions = defaultPartitions
+	}
+	if fn == nil {
+		fn = Hash
+	}
+
+	return &PartitionRing{
+		hashFunc:   fn,
+		partitions: partitions,
+		ring:       New(WithHashFunc(fn)),
+		weights:    make(map[string]int),
+		owners:     make([]string, partitions),
+	}
+}
+
+// 增加物理节点
+func (h *PartitionRing) Add(node string) {
+	h.AddWithWeight(node, TopWeight)
+}
+
+// 按权重增加物理节点，权重决定分到的分区数量，支持重复添加以更新权重
+func (h *PartitionRing) AddWithWeight(node string, weight int) {
+	if weight <= 0 {
+		return
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.weights[node] = weight
+	h.ring.AddWithWeight(node, weight)
+	h.assign()
+}
+
+// 删除物理节点，其分区分配给其他节点
+func (h *PartitionRing) Remove(node string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if _, ok := h.weights[node]; !ok {
+		return
+	}
+	delete(h.weights, node)
+	h.ring.Remove(node)
+	h.assign()
+}
+
+// 分区数量
+func (h *PartitionRing) Partitions() int {
+	return h.partitions
+}
+
+// 键所在的分区
+func (h *PartitionRing) PartitionOf(key string) int {
+	return h.partitionOfHash(h.hashFunc([]byte(key)))
+}
+
+// 哈希值所在的分区，即 hash * partitions / 2^64
+func (h *PartitionRing) partitionOfHash(hash uint64) int {
+	hi, _ := bits.Mul64(hash, uint64(h.partitions))
+	return int(hi)
+}
+
+// 分区所属的节点，分区号越界或没有节点时返回 false
+func (h *PartitionRing) OwnerOfPartition(p int) (string, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if p < 0 || p >= h.partitions || h.owners[p] == "" {
+		return "", false
+	}
+	return h.owners[p], true
+}
+
+// 节点负责的所有分区，从小到大排列
+func (h *PartitionRing) PartitionsOf(node string) []int {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	var partitions []int
+	for p, owner := range h.owners {
+		if owner == node {
+			partitions = append(partitions, p)
+		}
+	}
+	return partitions
+}
+
+// 分区的哈希区间 [start, end]
+func (h *PartitionRing) RangeOfPartition(p int) Range {
+	start := partitionStart(p, h.partitions)
+	end := uint64(1<<64 - 1)
+	if p+1 < h.partitions {
+		end = partitionStart(p+1, h.partitions) - 1
+	}
+	return Range{Start: start, End: end}
+}
+
+// 第一个满足 hash * partitions / 2^64 >= p 的哈希值，即 ceil(p * 2^64 / partitions)
+func partitionStart(p, partitions int) uint64 {
+	quo, rem := bits.Div64(uint64(p), 0, uint64(partitions))
+	if rem > 0 {
+		quo++
+	}
+	return quo
+}
+
+// 键所在的节点
+func (h *PartitionRing) Get(key string) (interface{}, bool) {
+	p := h.PartitionOf(key)
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if h.owners[p] == "" {
+		return nil, false
+	}
+	return h.owners[p], true
+}
+
+// 当前所有物理节点，按字典序排列
+func (h *PartitionRing) Nodes() []string {
+	return h.ring.Nodes()
+}
+
+// 重新分配分区
+// 分区仍由原节点负责，除非原节点已删除或分区数超过上限，超过上限时保留分区号较小的分区；
+// 其余分区从其位置沿哈希环顺时针查找，选择第一个分区数未达上限的节点
+// 因此只有必须迁移的分区才更换节点，分配结果与节点的加入顺序有关
+// 调用方需持有写锁
+func (h *PartitionRing) assign() {
+	if len(h.weights) == 0 {
+		for p := range h.owners {
+			h.owners[p] = ""
+		}
+		return
+	}
+
+	var total int
+	for _, weight := range h.weights {
+		total += weight
+	}
+	// 按最大余数法分配各节点的分区数，总和恰好等于分区数量
+	capacity := make(map[string]int, len(h.weights))
+	names := make([]string, 0, len(h.weights))
+	remain := h.partitions
+	for node, weight := range h.weights {
+		capacity[node] = h.partitions * weight / total
+		remain -= capacity[node]
+		names = append(names, node)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		ri := h.partitions * h.weights[names[i]] % total
+		rj := h.partitions * h.weights[names[j]] % total
+		if ri != rj {
+			return ri > rj
+		}
+		return names[i] < names[j]
+	})
+	for _, node := range names[:remain] {
+		capacity[node]++
+	}
+
+	for p, owner := range h.owners {
+		if capacity[owner] > 0 {
+			capacity[owner]--
+		} else {
+			h.owners[p] = ""
+		}
+	}
+
+	r := h.ring
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	for p := range h.owners {
+		if h.owners[p] != "" {
+			continue
+		}
+		hash := mix64(uint64(p))
+		index := sort.Search(len(r.keys), func(i int) bool {
+			return r.keys[i] >= hash
+		})
+		for i := 0; ; i++ {
+			// 冲突链上的节点依次尝试，与 Get 的选择无关但结果确定
+			for _, node := range r.ring[r.keys[(index+i)%len(r.keys)]] {
+				name := node.(string)
+				if capacity[name] > 0 {
+					capacity[name]--
+					h.owners[p] = name
+					break
+				}
+			}
+			if h.owners[p] != "" {
+				break
+			}
+		}
+	}
+}
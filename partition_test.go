@@ -0,0 +1,116 @@
+package zero
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartitionRing(t *testing.T) {
+	h := NewPartitionRing(1024)
+	_, ok := h.Get("any")
+	assert.False(t, ok)
+
+	for i := 0; i < 10; i++ {
+		h.Add("10.0.0." + strconv.Itoa(i) + ":6379")
+	}
+	// 每个节点的分区数至多相差一个
+	for _, node := range h.Nodes() {
+		count := len(h.PartitionsOf(node))
+		assert.True(t, count == 102 || count == 103, count)
+	}
+
+	for i := 0; i < requestSize; i++ {
+		key := strconv.Itoa(i)
+		p := h.PartitionOf(key)
+		r := h.RangeOfPartition(p)
+		hash := Hash([]byte(key))
+		assert.True(t, r.Start <= hash && hash <= r.End)
+		owner, ok := h.OwnerOfPartition(p)
+		assert.True(t, ok)
+		node, _ := h.Get(key)
+		assert.Equal(t, owner, node)
+	}
+
+	before := make([]string, h.Partitions())
+	for p := range before {
+		before[p], _ = h.OwnerOfPartition(p)
+	}
+	h.Add("10.0.0.10:6379")
+	var moved int
+	for p := range before {
+		owner, _ := h.OwnerOfPartition(p)
+		if owner != before[p] {
+			moved++
+		}
+	}
+	// 只有分给新节点的分区迁移
+	assert.Equal(t, len(h.PartitionsOf("10.0.0.10:6379")), moved)
+
+	// 删除时只有该节点的分区迁移
+	after := make([]string, h.Partitions())
+	for p := range after {
+		after[p], _ = h.OwnerOfPartition(p)
+	}
+	h.Remove("10.0.0.10:6379")
+	for p := range after {
+		owner, _ := h.OwnerOfPartition(p)
+		if after[p] != "10.0.0.10:6379" {
+			assert.Equal(t, after[p], owner)
+		}
+	}
+	for _, node := range h.Nodes() {
+		count := len(h.PartitionsOf(node))
+		assert.True(t, count == 102 || count == 103, count)
+	}
+	_, ok = h.OwnerOfPartition(h.Partitions())
+	assert.False(t, ok)
+}
+
+func TestPartitionRingMovement(t *testing.T) {
+	for _, n := range []int{10, 50} {
+		h := NewPartitionRing(1024)
+		for i := 0; i < n; i++ {
+			h.Add("node" + strconv.Itoa(i))
+		}
+		before := make([]string, h.Partitions())
+		for p := range before {
+			before[p], _ = h.OwnerOfPartition(p)
+		}
+
+		h.Add("node" + strconv.Itoa(n))
+		var moved int
+		for p := range before {
+			if owner, _ := h.OwnerOfPartition(p); owner != before[p] {
+				moved++
+			}
+		}
+		// 理想的迁移量为 1/(n+1)，10个节点时约9.1%，50个节点时约2.0%
+		ideal := float64(h.Partitions()) / float64(n+1)
+		assert.InDelta(t, ideal, float64(moved), 1, n)
+	}
+}
+
+func TestPartitionRingWeights(t *testing.T) {
+	h := NewPartitionRing(300)
+	h.AddWithWeight("heavy", 100)
+	h.AddWithWeight("light", 50)
+	h.AddWithWeight("ignored", 0)
+	assert.Equal(t, 200, len(h.PartitionsOf("heavy")))
+	assert.Equal(t, 100, len(h.PartitionsOf("light")))
+	assert.Equal(t, []string{"heavy", "light"}, h.Nodes())
+}
+
+func TestPartitionRanges(t *testing.T) {
+	h := NewPartitionRing(3)
+	assert.Equal(t, Range{Start: 0, End: 6148914691236517205}, h.RangeOfPartition(0))
+	assert.Equal(t, uint64(math.MaxUint64), h.RangeOfPartition(2).End)
+	for p := 0; p < 3; p++ {
+		r := h.RangeOfPartition(p)
+		assert.Equal(t, p, h.partitionOfHash(r.Start))
+		assert.Equal(t, p, h.partitionOfHash(r.End))
+	}
+	assert.Equal(t, defaultPartitions, NewPartitionRing(0).Partitions())
+}
@@ -0,0 +1,132 @@
+// 基于一致性哈希的消息队列路由
+// Partitioner 实现了 sarama 的分区器，生产者按消息键把消息发往一致性哈希选出的分区；
+// 分区数量变化时只有少量键改变分区，分区可按权重承担不同的流量
+// SubjectRouter 把键映射到 NATS 主题或 NSQ topic，用于把同一个键的消息固定发给某个消费者
+package partitioner
+
+import (
+	"errors"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+
+	"consistenthash"
+	"github.com/IBM/sarama"
+)
+
+// 所有分区的权重都为0，没有可用的分区
+var ErrNoPartition = errors.New("partitioner: no partition available")
+
+// 基于一致性哈希的 sarama.Partitioner
+// 以分区编号作为物理节点，分区数量变化时重建哈希环
+// 没有键的消息随机选择分区
+type Partitioner struct {
+	weights map[int32]int
+	opts    []zero.Option
+
+	lock       sync.Mutex
+	ring       *zero.ConsistentHash
+	partitions int32
+}
+
+var _ sarama.Partitioner = (*Partitioner)(nil)
+
+// 创建每个 topic 独立的一致性哈希分区器，用于 sarama.Config.Producer.Partitioner
+// weights 为各分区的权重，未列出的分区权重为 zero.TopWeight，权重不大于0的分区不接收有键的消息
+// opts 为哈希环的配置，各 topic 使用相同的配置
+func NewPartitioner(weights map[int32]int, opts ...zero.Option) sarama.PartitionerConstructor {
+	copied := make(map[int32]int, len(weights))
+	for partition, weight := range weights {
+		copied[partition] = weight
+	}
+	return func(topic string) sarama.Partitioner {
+		return &Partitioner{weights: copied, opts: opts}
+	}
+}
+
+func (p *Partitioner) Partition(message *sarama.ProducerMessage, numPartitions int32) (int32, error) {
+	if message.Key == nil {
+		return rand.Int32N(numPartitions), nil
+	}
+	key, err := message.Key.Encode()
+	if err != nil {
+		return -1, err
+	}
+
+	node, ok := p.ringFor(numPartitions).GetBytes(key)
+	if !ok {
+		return -1, ErrNoPartition
+	}
+	partition, err := strconv.ParseInt(node.(string), 10, 32)
+	if err != nil {
+		return -1, err
+	}
+	return int32(partition), nil
+}
+
+// 同一个键总是发往同一个分区
+func (p *Partitioner) RequiresConsistency() bool {
+	return true
+}
+
+// 分区数量为 numPartitions 时的哈希环
+func (p *Partitioner) ringFor(numPartitions int32) *zero.ConsistentHash {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.ring != nil && p.partitions == numPartitions {
+		return p.ring
+	}
+
+	ring := zero.New(p.opts...)
+	for partition := int32(0); partition < numPartitions; partition++ {
+		weight, ok := p.weights[partition]
+		if !ok {
+			weight = zero.TopWeight
+		}
+		if weight > 0 {
+			ring.AddWithWeight(strconv.Itoa(int(partition)), weight)
+		}
+	}
+	p.ring, p.partitions = ring, numPartitions
+	return ring
+}
+
+// 把键映射到消费者专属的主题
+// 消费者作为哈希环上的物理节点，由调用方通过 Ring 增删并设置权重
+type SubjectRouter struct {
+	ring   *zero.ConsistentHash
+	prefix string
+}
+
+// 创建主题路由，主题为 prefix + "." + 消费者，prefix 为空时主题即消费者
+// "." 是 NATS 主题的层级分隔符，也是 NSQ topic 允许的字符
+func NewSubjectRouter(ring *zero.ConsistentHash, prefix string) *SubjectRouter {
+	return &SubjectRouter{ring: ring, prefix: prefix}
+}
+
+// 路由使用的哈希环
+func (r *SubjectRouter) Ring() *zero.ConsistentHash {
+	return r.ring
+}
+
+// 负责 key 的消费者，没有消费者时 ok 为 false
+func (r *SubjectRouter) Consumer(key string) (string, bool) {
+	node, ok := r.ring.Get(key)
+	if !ok {
+		return "", false
+	}
+	return node.(string), true
+}
+
+// key 的消息应发往的主题，没有消费者时 ok 为 false
+func (r *SubjectRouter) Subject(key string) (string, bool) {
+	consumer, ok := r.Consumer(key)
+	if !ok {
+		return "", false
+	}
+	if r.prefix == "" {
+		return consumer, true
+	}
+	return r.prefix + "." + consumer, true
+}
@@ -0,0 +1,94 @@
+package partitioner
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"consistenthash"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+)
+
+func partition(t *testing.T, p sarama.Partitioner, key string, n int32) int32 {
+	t.Helper()
+	partition, err := p.Partition(&sarama.ProducerMessage{Key: sarama.StringEncoder(key)}, n)
+	assert.Nil(t, err)
+	return partition
+}
+
+func TestPartitioner(t *testing.T) {
+	p := NewPartitioner(nil)("orders")
+	assert.True(t, p.RequiresConsistency())
+
+	counts := make(map[int32]int)
+	before := make(map[string]int32)
+	for i := 0; i < 10000; i++ {
+		key := strconv.Itoa(i)
+		before[key] = partition(t, p, key, 8)
+		assert.Equal(t, before[key], partition(t, p, key, 8))
+		counts[before[key]]++
+	}
+	assert.Equal(t, 8, len(counts))
+
+	// 增加分区时只有少量键改变分区
+	var moved int
+	for key, old := range before {
+		if partition(t, p, key, 9) != old {
+			moved++
+		}
+	}
+	assert.Less(t, moved, 2000)
+
+	// 不同 topic 相互独立但分配相同
+	q := NewPartitioner(nil)("payments")
+	assert.Equal(t, before["42"], partition(t, q, "42", 8))
+}
+
+func TestPartitionerWeights(t *testing.T) {
+	p := NewPartitioner(map[int32]int{0: 0, 1: 2 * zero.TopWeight})("orders")
+	counts := make(map[int32]int)
+	for i := 0; i < 10000; i++ {
+		counts[partition(t, p, strconv.Itoa(i), 3)]++
+	}
+	assert.Equal(t, 0, counts[0])
+	assert.Greater(t, counts[1], counts[2])
+
+	p = NewPartitioner(map[int32]int{0: 0})("orders")
+	_, err := p.Partition(&sarama.ProducerMessage{Key: sarama.StringEncoder("k")}, 1)
+	assert.Equal(t, ErrNoPartition, err)
+}
+
+func TestPartitionerWithoutKey(t *testing.T) {
+	p := NewPartitioner(nil)("orders")
+	for i := 0; i < 100; i++ {
+		partition, err := p.Partition(&sarama.ProducerMessage{}, 4)
+		assert.Nil(t, err)
+		assert.True(t, partition >= 0 && partition < 4)
+	}
+
+	_, err := p.Partition(&sarama.ProducerMessage{Key: badEncoder{}}, 4)
+	assert.NotNil(t, err)
+}
+
+func TestSubjectRouter(t *testing.T) {
+	ring := zero.New()
+	r := NewSubjectRouter(ring, "jobs")
+	_, ok := r.Subject("k")
+	assert.False(t, ok)
+
+	ring.Add("worker-1")
+	subject, ok := r.Subject("k")
+	assert.True(t, ok)
+	assert.Equal(t, "jobs.worker-1", subject)
+
+	consumer, _ := NewSubjectRouter(ring, "").Subject("k")
+	assert.Equal(t, "worker-1", consumer)
+	assert.Equal(t, ring, r.Ring())
+}
+
+type badEncoder struct{}
+
+func (badEncoder) Encode() ([]byte, error) { return nil, errors.New("encode") }
+
+func (badEncoder) Length() int { return 0 }
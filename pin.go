@@ -0,0 +1,43 @@
+package zero
+
+// 把键固定路由到指定节点，不再按哈希环上的位置查找
+// 固定关系在拓扑变更后依然保留：节点不在环上时按哈希环查找，节点重新加入后恢复固定路由
+func (h *ConsistentHash) Pin(key, node string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.pins == nil {
+		h.pins = make(map[string]string)
+	}
+	h.pins[key] = node
+}
+
+// 取消键的固定路由
+func (h *ConsistentHash) Unpin(key string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	delete(h.pins, key)
+}
+
+// 键的固定路由节点
+func (h *ConsistentHash) Pinned(key string) (string, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	node, ok := h.pins[key]
+	return node, ok
+}
+
+// 查找键的固定路由，节点不在环上时视为未固定
+// 调用方需持有读锁
+func (h *ConsistentHash) pinned(key string) (string, bool) {
+	if len(h.pins) == 0 {
+		return "", false
+	}
+	node, ok := h.pins[key]
+	if !ok || !h.containsNode(node) {
+		return "", false
+	}
+	return node, true
+}
@@ -0,0 +1,44 @@
+package zero
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPin(t *testing.T) {
+	ch := NewConsistentHash()
+	ch.Add("first")
+	ch.Add("second")
+
+	natural, _ := ch.Get("hot")
+	target := "first"
+	if natural == target {
+		target = "second"
+	}
+
+	ch.Pin("hot", target)
+	node, _ := ch.Get("hot")
+	assert.Equal(t, target, node)
+	node, _ = ch.GetBytes([]byte("hot"))
+	assert.Equal(t, target, node)
+	node, _ = ch.GetExisting("hot")
+	assert.Equal(t, target, node)
+	pinned, ok := ch.Pinned("hot")
+	assert.True(t, ok)
+	assert.Equal(t, target, pinned)
+
+	// 节点不在环上时按哈希环查找，重新加入后恢复
+	ch.Remove(target)
+	node, _ = ch.Get("hot")
+	assert.Equal(t, natural, node)
+	ch.Add(target)
+	node, _ = ch.Get("hot")
+	assert.Equal(t, target, node)
+
+	ch.Unpin("hot")
+	node, _ = ch.Get("hot")
+	assert.Equal(t, natural, node)
+	_, ok = ch.Pinned("hot")
+	assert.False(t, ok)
+}
@@ -0,0 +1,201 @@
+package zero
+
+import (
+	"math"
+	"slices"
+	"sort"
+)
+
+// 优化虚拟节点位置的最大轮数
+const placementRounds = 8
+
+// 按代表性的样本键微调虚拟节点位置，使样本键在各节点间的负载尽量与虚拟节点数量成比例
+// 适用于键分布严重倾斜、按哈希空间均分仍然负载不均的场景
+// 每个虚拟节点只在前后两个虚拟节点之间移动，只影响它和后继之间样本键的归属，冲突链上的位置保持不变
+// 返回优化前后样本键负载的不均衡度，即各节点实际负载与期望负载之比的标准差，固定路由的键不参与计算
+// 重建哈希环（自动调优、SetReplicas、SetHashFunc、Restore 等）会按虚拟节点数量重新生成位置，优化的结果随之丢失
+func (h *ConsistentHash) OptimizePlacement(sampleKeys []string) (before, after float64) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	keys := make([]string, 0, len(sampleKeys))
+	for _, key := range sampleKeys {
+		if _, ok := h.pins[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 || len(h.nodes) < 2 {
+		return 0, 0
+	}
+
+	p := newPlacement(h, keys)
+	before = p.imbalance()
+	if !p.optimize() {
+		return before, before
+	}
+
+	// 按移动后的位置重建哈希环，虚拟节点数量保持不变
+	nodes := h.nodesSnapshot()
+	names := make([]string, 0, len(nodes))
+	for node := range nodes {
+		names = append(names, node)
+	}
+	sort.Strings(names)
+	h.resetPoints()
+	h.ring = make(map[uint64][]interface{})
+	h.nodes = make(map[string]int, len(nodes))
+	h.points = make(map[string][]uint64, len(nodes))
+	for _, node := range names {
+		h.insertLocked(node, nodes[node], p.owned[node])
+	}
+	h.sortKeys()
+	h.settleLocked()
+	return before, p.imbalance()
+}
+
+// 虚拟节点位置的优化状态
+type placement struct {
+	// 有序的样本键哈希值
+	hashes []uint64
+	// 有序的虚拟节点位置及其唯一的节点，冲突链上的位置节点为空
+	points []uint64
+	owners []string
+	// 各节点的虚拟节点位置
+	owned map[string][]uint64
+	// 各节点的样本键负载及期望负载
+	loads    map[string]float64
+	expected map[string]float64
+}
+
+// 调用方需持有读锁
+func newPlacement(h *ConsistentHash, keys []string) *placement {
+	p := &placement{
+		hashes:   make([]uint64, len(keys)),
+		points:   h.sortedPoints(),
+		owned:    make(map[string][]uint64, len(h.points)),
+		loads:    make(map[string]float64, len(h.nodes)),
+		expected: make(map[string]float64, len(h.nodes)),
+	}
+	p.owners = make([]string, len(p.points))
+	for i, point := range p.points {
+		if nodes := h.ring[point]; len(nodes) == 1 {
+			p.owners[i] = nodes[0].(string)
+		}
+	}
+	for node, points := range h.points {
+		p.owned[node] = append([]uint64(nil), points...)
+	}
+
+	var total int
+	for _, replicas := range h.nodes {
+		total += replicas
+	}
+	for node, replicas := range h.nodes {
+		p.expected[node] = float64(len(keys)) * float64(replicas) / float64(total)
+	}
+	for i, key := range keys {
+		b := []byte(key)
+		p.hashes[i] = h.hashFunc(b)
+		node, _ := h.locate(p.hashes[i], b)
+		p.loads[node.(string)]++
+	}
+	slices.Sort(p.hashes)
+	return p
+}
+
+// 逐个移动虚拟节点，在它与后继之间重新划分样本键，直到不再改善或达到最大轮数
+// 返回是否移动了虚拟节点
+func (p *placement) optimize() bool {
+	var moved bool
+	for round := 0; round < placementRounds; round++ {
+		var improved bool
+		// 首尾的虚拟节点跨越0点，不参与移动
+		for i := 1; i+1 < len(p.points); i++ {
+			if p.move(i) {
+				improved = true
+			}
+		}
+		if !improved {
+			break
+		}
+		moved = true
+	}
+	return moved
+}
+
+// 在前驱和后继之间为第 i 个虚拟节点选择使不均衡度最小的位置
+func (p *placement) move(i int) bool {
+	a, b := p.owners[i], p.owners[i+1]
+	if a == "" || b == "" || a == b || p.expected[a] == 0 || p.expected[b] == 0 {
+		return false
+	}
+
+	prev, point, next := p.points[i-1], p.points[i], p.points[i+1]
+	lo := p.upperBound(prev)
+	hi := p.upperBound(next)
+	cur := p.upperBound(point) - lo
+	total := hi - lo
+	if total == 0 {
+		return false
+	}
+
+	// 去掉这一段后两个节点的负载，段内的 c 个键归 a，其余归 b
+	ea, eb := p.expected[a], p.expected[b]
+	la := p.loads[a] - float64(cur)
+	lb := p.loads[b] - float64(total-cur)
+	cost := func(c int) float64 {
+		da := (la+float64(c))/ea - 1
+		db := (lb+float64(total-c))/eb - 1
+		return da*da + db*db
+	}
+	// 二次函数的极小值点
+	best := ((lb+float64(total)-eb)/(eb*eb) - (la-ea)/(ea*ea)) / (1/(ea*ea) + 1/(eb*eb))
+	c := min(max(int(math.Round(best)), 0), total)
+
+	// 位置必须严格位于前驱和后继之间
+	var target uint64
+	if c == 0 {
+		target = p.hashes[lo] - 1
+	} else {
+		target = p.hashes[lo+c-1]
+	}
+	if target <= prev || target >= next || target == point {
+		return false
+	}
+	// 哈希值相同的键归属相同
+	c = p.upperBound(target) - lo
+	if cost(c) >= cost(cur) {
+		return false
+	}
+
+	p.points[i] = target
+	p.loads[a] = la + float64(c)
+	p.loads[b] = lb + float64(total-c)
+	owned := p.owned[a]
+	owned[slices.Index(owned, point)] = target
+	return true
+}
+
+// 第一个大于 hash 的样本键下标
+func (p *placement) upperBound(hash uint64) int {
+	return sort.Search(len(p.hashes), func(i int) bool {
+		return p.hashes[i] > hash
+	})
+}
+
+// 各节点样本键负载与期望负载之比的标准差
+func (p *placement) imbalance() float64 {
+	var sum, count float64
+	for node, expected := range p.expected {
+		if expected == 0 {
+			continue
+		}
+		diff := p.loads[node]/expected - 1
+		sum += diff * diff
+		count++
+	}
+	if count == 0 {
+		return 0
+	}
+	return math.Sqrt(sum / count)
+}
@@ -0,0 +1,89 @@
+package zero
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptimizePlacement(t *testing.T) {
+	ch := New()
+	for i := 0; i < 5; i++ {
+		ch.Add("node" + strconv.Itoa(i))
+	}
+	ch.AddWithWeight("big", 200)
+
+	// 少量热点键占据了大部分流量
+	var sample []string
+	for i := 0; i < 10000; i++ {
+		sample = append(sample, "key"+strconv.Itoa(i))
+	}
+	for i := 0; i < 20; i++ {
+		for j := 0; j < 200; j++ {
+			sample = append(sample, "hot"+strconv.Itoa(i))
+		}
+	}
+
+	version := ch.Epoch()
+	before, after := ch.OptimizePlacement(sample)
+	assert.Greater(t, before, 0.0)
+	assert.Less(t, after, before)
+	assert.NotEqual(t, version, ch.Epoch())
+
+	// 返回值与实际查找的结果一致
+	assert.InDelta(t, after, sampleImbalance(ch, sample), 1e-9)
+	// 成员和虚拟节点数量不变
+	assert.Equal(t, 6, len(ch.Nodes()))
+	assert.Equal(t, 200, ch.ReplicaCount("big"))
+
+	// 再次优化不会变差
+	again, last := ch.OptimizePlacement(sample)
+	assert.InDelta(t, after, again, 1e-9)
+	assert.LessOrEqual(t, last, again)
+
+	// 优化后的位置在增删节点后保留
+	ch.Add("extra")
+	ch.Remove("extra")
+	assert.InDelta(t, last, sampleImbalance(ch, sample), 1e-9)
+}
+
+func TestOptimizePlacementNoop(t *testing.T) {
+	ch := New()
+	before, after := ch.OptimizePlacement([]string{"a"})
+	assert.Equal(t, 0.0, before)
+	assert.Equal(t, 0.0, after)
+
+	ch.Add("a")
+	version := ch.Epoch()
+	before, after = ch.OptimizePlacement([]string{"a", "b"})
+	assert.Equal(t, 0.0, before+after)
+	assert.Equal(t, version, ch.Epoch())
+
+	// 固定路由的键不参与计算
+	ch.Add("b")
+	ch.Pin("key", "a")
+	before, after = ch.OptimizePlacement([]string{"key"})
+	assert.Equal(t, 0.0, before+after)
+}
+
+// 样本键在各节点间的负载与期望负载之比的标准差
+func sampleImbalance(ch *ConsistentHash, sample []string) float64 {
+	loads := make(map[interface{}]float64)
+	for _, key := range sample {
+		node, _ := ch.Get(key)
+		loads[node]++
+	}
+	var total int
+	for _, node := range ch.Nodes() {
+		total += ch.ReplicaCount(node)
+	}
+	var sum float64
+	for _, node := range ch.Nodes() {
+		expected := float64(len(sample)) * float64(ch.ReplicaCount(node)) / float64(total)
+		diff := loads[node]/expected - 1
+		sum += diff * diff
+	}
+	return math.Sqrt(sum / float64(len(ch.Nodes())))
+}
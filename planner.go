@@ -0,0 +1,137 @@
+package zero
+
+import (
+	"math"
+	"sort"
+)
+
+type (
+	// 一个数据迁移任务：把哈希区间内的键从 From 迁到 To
+	MigrationTask struct {
+		From  string
+		To    string
+		Range Range
+		// 区间占整个哈希空间的比例
+		Fraction float64
+		// 按总键数估算的迁移键数
+		EstimatedKeys int64
+		// 限流提示：同一批次的任务可以并发执行，批次按顺序执行
+		// 每个节点在同一批次中参与的任务数不超过 Concurrency
+		Wave int
+	}
+
+	// 再平衡规划器，根据新旧拓扑生成迁移任务
+	Planner struct {
+		// 数据的总键数，用于估算每个任务的键数
+		totalKeys int64
+		// 每个节点在同一批次中参与的任务数上限
+		concurrency int
+	}
+)
+
+// totalKeys 为数据的总键数，concurrency 为每个节点同时参与的迁移任务数上限，不大于0时为1
+func NewPlanner(totalKeys int64, concurrency int) *Planner {
+	return &Planner{
+		totalKeys:   totalKeys,
+		concurrency: max(concurrency, 1),
+	}
+}
+
+// 生成从 from 变为 to 所需的迁移任务
+// 任务按批次、再按区间起点排列，相邻且源和目标都相同的区间合并为一个任务
+// 区间的所属节点取冲突链上的第一个节点，与 ForEachSegment 一致
+func (p *Planner) Plan(from, to *ConsistentHash) []MigrationTask {
+	tasks := diffSegments(from, to)
+	for i := range tasks {
+		tasks[i].EstimatedKeys = int64(math.Round(tasks[i].Fraction * float64(p.totalKeys)))
+	}
+	p.schedule(tasks)
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].Wave < tasks[j].Wave
+	})
+	return tasks
+}
+
+// 按区间起点贪心地把任务放入最早的可用批次
+func (p *Planner) schedule(tasks []MigrationTask) {
+	// 节点在各批次中已参与的任务数
+	loads := make(map[string][]int)
+	load := func(node string, wave int) int {
+		if waves := loads[node]; wave < len(waves) {
+			return waves[wave]
+		}
+		return 0
+	}
+	inc := func(node string, wave int) {
+		waves := loads[node]
+		for len(waves) <= wave {
+			waves = append(waves, 0)
+		}
+		waves[wave]++
+		loads[node] = waves
+	}
+
+	for i := range tasks {
+		wave := 0
+		for load(tasks[i].From, wave) >= p.concurrency || load(tasks[i].To, wave) >= p.concurrency {
+			wave++
+		}
+		tasks[i].Wave = wave
+		inc(tasks[i].From, wave)
+		inc(tasks[i].To, wave)
+	}
+}
+
+// 逐段比较两个哈希环，给出所属节点发生变化的区间
+func diffSegments(from, to *ConsistentHash) []MigrationTask {
+	type segment struct {
+		end  uint64
+		node string
+	}
+	segments := func(h *ConsistentHash) []segment {
+		var s []segment
+		h.ForEachSegment(func(_, end uint64, node string) bool {
+			s = append(s, segment{end: end, node: node})
+			return true
+		})
+		return s
+	}
+
+	// 两组区间均按顺序覆盖整个哈希空间，依次取较小的右端点即可对齐
+	var tasks []MigrationTask
+	a, b := segments(from), segments(to)
+	var start uint64
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		end := min(a[i].end, b[j].end)
+		if a[i].node != b[j].node {
+			if n := len(tasks); n > 0 && tasks[n-1].Range.End+1 == start &&
+				tasks[n-1].From == a[i].node && tasks[n-1].To == b[j].node {
+				tasks[n-1].Range.End = end
+				tasks[n-1].Fraction += rangeFraction(start, end)
+			} else {
+				tasks = append(tasks, MigrationTask{
+					From:     a[i].node,
+					To:       b[j].node,
+					Range:    Range{Start: start, End: end},
+					Fraction: rangeFraction(start, end),
+				})
+			}
+		}
+		if end == math.MaxUint64 {
+			break
+		}
+		start = end + 1
+		if a[i].end == end {
+			i++
+		}
+		if b[j].end == end {
+			j++
+		}
+	}
+	return tasks
+}
+
+// 闭区间 [start, end] 占整个哈希空间的比例
+func rangeFraction(start, end uint64) float64 {
+	return (float64(end-start) + 1) / (1 << 64)
+}
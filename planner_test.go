@@ -0,0 +1,55 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanner(t *testing.T) {
+	from := NewConsistentHash()
+	for i := 0; i < 4; i++ {
+		from.Add("10.0.0." + strconv.Itoa(i) + ":6379")
+	}
+	to := from.Prepare(Change{Add: []string{"10.0.0.4:6379"}}).Ring()
+
+	tasks := NewPlanner(1000000, 2).Plan(from, to)
+	assert.NotEmpty(t, tasks)
+
+	var fraction float64
+	var keys int64
+	waves := make(map[int]map[string]int)
+	for i, task := range tasks {
+		assert.Equal(t, "10.0.0.4:6379", task.To)
+		assert.True(t, task.Range.Start <= task.Range.End)
+		fraction += task.Fraction
+		keys += task.EstimatedKeys
+		if i > 0 {
+			assert.True(t, tasks[i-1].Wave <= task.Wave)
+		}
+		if waves[task.Wave] == nil {
+			waves[task.Wave] = make(map[string]int)
+		}
+		waves[task.Wave][task.From]++
+		waves[task.Wave][task.To]++
+
+		// 区间两端的键确实发生了迁移
+		for _, hash := range []uint64{task.Range.Start, task.Range.End} {
+			before, _ := from.GetHash(hash)
+			after, _ := to.GetHash(hash)
+			assert.Equal(t, task.From, before)
+			assert.Equal(t, task.To, after)
+		}
+	}
+	for _, loads := range waves {
+		for _, load := range loads {
+			assert.True(t, load <= 2)
+		}
+	}
+	assert.InDelta(t, 0.2, fraction, 0.08)
+	assert.InDelta(t, fraction*1000000, float64(keys), float64(len(tasks)))
+	assert.Equal(t, (len(tasks)+1)/2, len(waves))
+
+	assert.Empty(t, NewPlanner(0, 0).Plan(from, from))
+}
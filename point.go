@@ -0,0 +1,63 @@
+package zero
+
+import "slices"
+
+// 在 hash 处为节点直接放入一个虚拟节点，节点不存在时随之加入，已有该位置时忽略
+// 用于测试工具和外部计算的虚拟节点方案（如 ketama 的位置）直接构造哈希环
+// 注入的位置不经过冲突策略，与其他节点重合时总是加入冲突链
+// 重建哈希环（自动调优、SetReplicas、SetHashFunc、Restore 等）会按虚拟节点数量重新生成位置，注入的位置随之丢失
+func (h *ConsistentHash) AddPoint(hash uint64, node string) {
+	h.lock.Lock()
+	if slices.Contains(h.points[node], hash) {
+		h.lock.Unlock()
+		return
+	}
+
+	h.clearTTLLocked(node)
+	h.clearDrainLocked(node)
+	h.clearCapacityLocked(node)
+	h.version++
+	h.points[node] = append(slices.Clip(h.points[node]), hash)
+	h.addNode(node, len(h.points[node]))
+	h.insertPoint(hash)
+	h.ring[hash] = insertChain(h.ring[hash], node)
+	h.sortKeys()
+	h.settleLocked()
+	replicas := h.nodes[node]
+	h.lock.Unlock()
+
+	h.nodeAdded(node, replicas)
+}
+
+// 删除节点在 hash 处的虚拟节点，节点没有该位置时忽略
+// 删除节点的最后一个虚拟节点时节点随之离开
+func (h *ConsistentHash) RemovePoint(hash uint64, node string) {
+	h.lock.Lock()
+	index := slices.Index(h.points[node], hash)
+	if index < 0 {
+		h.lock.Unlock()
+		return
+	}
+
+	h.version++
+	h.removeRingNode(hash, node)
+	h.removePoint(hash)
+	points := slices.Delete(slices.Clone(h.points[node]), index, index+1)
+	removed := len(points) == 0
+	if removed {
+		h.removeNode(node)
+		delete(h.points, node)
+	} else {
+		h.addNode(node, len(points))
+		h.points[node] = points
+	}
+	h.settleLocked()
+	replicas := h.nodes[node]
+	h.lock.Unlock()
+
+	if removed {
+		h.nodeRemoved(node)
+	} else {
+		h.nodeAdded(node, replicas)
+	}
+}
@@ -0,0 +1,61 @@
+package zero
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddPoint(t *testing.T) {
+	for _, ch := range []*ConsistentHash{New(), New(WithTreeStore())} {
+		ch.AddPoint(100, "a")
+		ch.AddPoint(200, "b")
+		ch.AddPoint(300, "a")
+		ch.AddPoint(300, "a")
+		assert.Equal(t, 2, ch.ReplicaCount("a"))
+		assert.Equal(t, 1, ch.ReplicaCount("b"))
+
+		for hash, expect := range map[uint64]string{0: "a", 100: "a", 101: "b", 250: "a", 301: "a", math.MaxUint64: "a"} {
+			node, ok := ch.GetHash(hash)
+			assert.True(t, ok)
+			assert.Equal(t, expect, node)
+		}
+		assert.Equal(t, []Range{{Start: 101, End: 200}}, ch.OwnedRanges("b"))
+
+		// 与已有位置重合时加入冲突链
+		ch.AddPoint(200, "c")
+		assert.Equal(t, []interface{}{"b", "c"}, ch.ring[200])
+
+		ch.RemovePoint(200, "c")
+		ch.RemovePoint(200, "unknown")
+		assert.False(t, ch.Contains("c"))
+		node, _ := ch.GetHash(150)
+		assert.Equal(t, "b", node)
+
+		ch.RemovePoint(100, "a")
+		assert.Equal(t, 1, ch.ReplicaCount("a"))
+		node, _ = ch.GetHash(50)
+		assert.Equal(t, "b", node)
+		ch.RemovePoint(300, "a")
+		assert.False(t, ch.Contains("a"))
+		assert.Equal(t, []string{"b"}, ch.Nodes())
+	}
+}
+
+func TestAddPointMixed(t *testing.T) {
+	ch := New()
+	ch.Add("a")
+	before := ch.Clone()
+	ch.AddPoint(12345, "a")
+	assert.Equal(t, minReplicas+1, ch.ReplicaCount("a"))
+	ch.RemovePoint(12345, "a")
+	assert.Equal(t, minReplicas, ch.ReplicaCount("a"))
+	assert.Equal(t, minReplicas, before.ReplicaCount("a"))
+
+	// 删除普通节点的虚拟节点不影响其余位置
+	point := ch.points["a"][0]
+	ch.RemovePoint(point, "a")
+	assert.Equal(t, minReplicas-1, ch.ReplicaCount("a"))
+	assert.NotContains(t, ch.points["a"], point)
+}
@@ -0,0 +1,226 @@
+// 与哈希环成员绑定的连接池
+// 节点加入时为其创建连接池，离开时关闭空闲连接，WithConn 一次完成路由和借还连接
+package pool
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"consistenthash"
+)
+
+var (
+	// 连接池已关闭
+	ErrClosed = errors.New("pool: closed")
+	// 哈希环为空，没有可路由的节点
+	ErrNoNode = errors.New("pool: no node available")
+	// 哈希环上的节点不是字符串，无法建立连接
+	ErrNodeType = errors.New("pool: node is not a string")
+	// WithConn 的回调返回包装了该错误的错误时，连接被丢弃而不是放回连接池
+	ErrBadConn = errors.New("pool: bad connection")
+)
+
+// 默认每个节点保留的空闲连接数量
+const defaultMaxIdle = 2
+
+type (
+	// 建立到节点的连接
+	Dialer[C io.Closer] func(node string) (C, error)
+
+	// 连接池的可选配置
+	Option func(o *options)
+
+	options struct {
+		maxIdle int
+	}
+
+	// 跟随哈希环成员维护各节点的连接池
+	// 连接在首次使用时建立，用完放回所属节点的连接池，节点离开后连接池中的连接被关闭
+	Pool[C io.Closer] struct {
+		ring    *zero.ConsistentHash
+		dial    Dialer[C]
+		maxIdle int
+		cancel  func()
+
+		lock   sync.Mutex
+		nodes  map[string]*nodePool[C]
+		closed bool
+	}
+
+	// 一个节点的空闲连接
+	nodePool[C io.Closer] struct {
+		idle []C
+		// 节点已离开哈希环，归还的连接直接关闭
+		closed bool
+	}
+)
+
+// 每个节点最多保留 n 个空闲连接，多余的连接归还时关闭，默认为2
+func WithMaxIdle(n int) Option {
+	return func(o *options) {
+		o.maxIdle = n
+	}
+}
+
+// 创建与 ring 的成员绑定的连接池，通过 Watch 订阅成员变化
+// 不再使用时调用 Close 取消订阅并关闭所有空闲连接
+func New[C io.Closer](ring *zero.ConsistentHash, dial Dialer[C], opts ...Option) *Pool[C] {
+	o := options{
+		maxIdle: defaultMaxIdle,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	p := &Pool[C]{
+		ring:    ring,
+		dial:    dial,
+		maxIdle: max(o.maxIdle, 0),
+		nodes:   make(map[string]*nodePool[C]),
+	}
+	// 订阅后再读取成员，持有锁直到填充完成，先到的通知等待填充后再处理
+	p.lock.Lock()
+	p.cancel = ring.Watch(p.apply)
+	for _, node := range ring.Nodes() {
+		p.nodes[node] = &nodePool[C]{}
+	}
+	p.lock.Unlock()
+	return p
+}
+
+// 选择 key 所属的节点，借出该节点的一个连接执行 fn，执行后归还连接
+// 没有空闲连接时通过 Dialer 建立新连接，fn 返回包装了 ErrBadConn 的错误时丢弃该连接
+func (p *Pool[C]) WithConn(key string, fn func(conn C) error) error {
+	node, ok := p.ring.Get(key)
+	if !ok {
+		return ErrNoNode
+	}
+	name, ok := node.(string)
+	if !ok {
+		return ErrNodeType
+	}
+
+	conn, err := p.get(name)
+	if err != nil {
+		return err
+	}
+	err = fn(conn)
+	p.put(name, conn, errors.Is(err, ErrBadConn))
+	return err
+}
+
+// 当前维护连接池的节点数量
+func (p *Pool[C]) Len() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return len(p.nodes)
+}
+
+// 取消订阅并关闭所有空闲连接，借出的连接在归还时关闭，返回关闭连接时的错误
+func (p *Pool[C]) Close() error {
+	p.cancel()
+
+	p.lock.Lock()
+	if p.closed {
+		p.lock.Unlock()
+		return nil
+	}
+	p.closed = true
+	var idle []C
+	for node, np := range p.nodes {
+		idle = append(idle, np.close()...)
+		delete(p.nodes, node)
+	}
+	p.lock.Unlock()
+
+	return closeAll(idle)
+}
+
+// 借出节点的一个空闲连接，没有时新建
+func (p *Pool[C]) get(node string) (C, error) {
+	var conn C
+	p.lock.Lock()
+	if p.closed {
+		p.lock.Unlock()
+		return conn, ErrClosed
+	}
+	np, ok := p.nodes[node]
+	if !ok {
+		// 成员变化的通知还未送达，节点仍在环上时先创建连接池
+		// 节点离开的通知在此之后处理，会关闭这里创建的连接池
+		if !p.ring.Contains(node) {
+			p.lock.Unlock()
+			return conn, ErrNoNode
+		}
+		np = &nodePool[C]{}
+		p.nodes[node] = np
+	}
+	if n := len(np.idle); n > 0 {
+		conn = np.idle[n-1]
+		np.idle = np.idle[:n-1]
+		p.lock.Unlock()
+		return conn, nil
+	}
+	p.lock.Unlock()
+
+	return p.dial(node)
+}
+
+// 归还连接，连接损坏、节点已离开或空闲连接已满时关闭
+func (p *Pool[C]) put(node string, conn C, bad bool) {
+	p.lock.Lock()
+	np, ok := p.nodes[node]
+	if !bad && ok && !np.closed && len(np.idle) < p.maxIdle {
+		np.idle = append(np.idle, conn)
+		p.lock.Unlock()
+		return
+	}
+	p.lock.Unlock()
+
+	conn.Close()
+}
+
+// 按成员变化创建和关闭连接池
+func (p *Pool[C]) apply(events []zero.TopologyEvent) {
+	var idle []C
+	p.lock.Lock()
+	if p.closed {
+		p.lock.Unlock()
+		return
+	}
+	for _, event := range events {
+		switch event.Op {
+		case zero.TopologyAdd:
+			if _, ok := p.nodes[event.Node]; !ok {
+				p.nodes[event.Node] = &nodePool[C]{}
+			}
+		case zero.TopologyRemove:
+			if np, ok := p.nodes[event.Node]; ok {
+				idle = append(idle, np.close()...)
+				delete(p.nodes, event.Node)
+			}
+		}
+	}
+	p.lock.Unlock()
+
+	closeAll(idle)
+}
+
+// 标记为关闭并取出所有空闲连接
+func (np *nodePool[C]) close() []C {
+	idle := np.idle
+	np.idle = nil
+	np.closed = true
+	return idle
+}
+
+func closeAll[C io.Closer](conns []C) error {
+	var errs []error
+	for _, conn := range conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,183 @@
+package pool
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"consistenthash"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeConn struct {
+	node   string
+	id     int
+	closed atomic.Bool
+}
+
+func (c *fakeConn) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+// 记录建立的所有连接
+type fakeDialer struct {
+	lock  sync.Mutex
+	conns []*fakeConn
+}
+
+func (d *fakeDialer) dial(node string) (*fakeConn, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if node == "down" {
+		return nil, errors.New("dial " + node)
+	}
+	conn := &fakeConn{node: node, id: len(d.conns)}
+	d.conns = append(d.conns, conn)
+	return conn, nil
+}
+
+func (d *fakeDialer) count() int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return len(d.conns)
+}
+
+func TestWithConn(t *testing.T) {
+	ring := zero.NewConsistentHash()
+	var d fakeDialer
+	p := New(ring, d.dial)
+	defer p.Close()
+	assert.ErrorIs(t, p.WithConn("key", func(*fakeConn) error { return nil }), ErrNoNode)
+
+	ring.Add("a")
+	ring.Add("b")
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		owner, _ := ring.Get(key)
+		assert.NoError(t, p.WithConn(key, func(conn *fakeConn) error {
+			assert.Equal(t, owner, conn.node)
+			return nil
+		}))
+	}
+	// 串行使用时每个节点只需一个连接
+	assert.Equal(t, 2, d.count())
+	assert.Equal(t, 2, p.Len())
+
+	// 回调的错误原样返回
+	err := errors.New("failed")
+	assert.Equal(t, err, p.WithConn("key", func(*fakeConn) error { return err }))
+	assert.Equal(t, 2, d.count())
+}
+
+func TestWithConnBadConn(t *testing.T) {
+	ring := zero.NewConsistentHash()
+	ring.Add("a")
+	var d fakeDialer
+	p := New(ring, d.dial)
+	defer p.Close()
+
+	err := p.WithConn("key", func(*fakeConn) error {
+		return fmt.Errorf("reset: %w", ErrBadConn)
+	})
+	assert.ErrorIs(t, err, ErrBadConn)
+	assert.True(t, d.conns[0].closed.Load())
+
+	assert.NoError(t, p.WithConn("key", func(conn *fakeConn) error {
+		assert.Equal(t, 1, conn.id)
+		return nil
+	}))
+}
+
+func TestWithConnDialError(t *testing.T) {
+	ring := zero.NewConsistentHash()
+	ring.Add("down")
+	var d fakeDialer
+	p := New(ring, d.dial)
+	defer p.Close()
+
+	var called bool
+	assert.EqualError(t, p.WithConn("key", func(*fakeConn) error {
+		called = true
+		return nil
+	}), "dial down")
+	assert.False(t, called)
+}
+
+func TestMaxIdle(t *testing.T) {
+	ring := zero.NewConsistentHash()
+	ring.Add("a")
+	var d fakeDialer
+	p := New(ring, d.dial, WithMaxIdle(1))
+	defer p.Close()
+
+	// 嵌套借出两个连接，归还时只保留一个
+	assert.NoError(t, p.WithConn("key", func(*fakeConn) error {
+		return p.WithConn("key", func(*fakeConn) error { return nil })
+	}))
+	assert.Equal(t, 2, d.count())
+	assert.False(t, d.conns[1].closed.Load())
+	assert.True(t, d.conns[0].closed.Load())
+}
+
+func TestMembership(t *testing.T) {
+	ring := zero.NewConsistentHash()
+	ring.Add("a")
+	var d fakeDialer
+	p := New(ring, d.dial)
+	defer p.Close()
+	assert.Equal(t, 1, p.Len())
+
+	ring.Add("b")
+	assert.Eventually(t, func() bool { return p.Len() == 2 }, time.Second, time.Millisecond)
+
+	assert.NoError(t, p.WithConn("key", func(*fakeConn) error { return nil }))
+	conn := d.conns[0]
+	ring.Remove(conn.node)
+	// 节点离开后空闲连接被关闭
+	assert.Eventually(t, func() bool {
+		return conn.closed.Load() && p.Len() == 1
+	}, time.Second, time.Millisecond)
+}
+
+func TestMembershipBorrowed(t *testing.T) {
+	ring := zero.NewConsistentHash()
+	ring.Add("a")
+	var d fakeDialer
+	p := New(ring, d.dial)
+	defer p.Close()
+
+	assert.NoError(t, p.WithConn("key", func(conn *fakeConn) error {
+		ring.Remove("a")
+		assert.Eventually(t, func() bool { return p.Len() == 0 }, time.Second, time.Millisecond)
+		return nil
+	}))
+	// 借出期间节点离开，归还时关闭
+	assert.True(t, d.conns[0].closed.Load())
+}
+
+func TestClose(t *testing.T) {
+	ring := zero.NewConsistentHash()
+	ring.Add("a")
+	ring.Add("b")
+	var d fakeDialer
+	p := New(ring, d.dial)
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, p.WithConn(strconv.Itoa(i), func(*fakeConn) error { return nil }))
+	}
+
+	assert.NoError(t, p.Close())
+	assert.NoError(t, p.Close())
+	for _, conn := range d.conns {
+		assert.True(t, conn.closed.Load())
+	}
+	assert.ErrorIs(t, p.WithConn("key", func(*fakeConn) error { return nil }), ErrClosed)
+	// 关闭后不再跟随成员变化
+	ring.Add("c")
+	assert.Equal(t, 0, p.Len())
+}
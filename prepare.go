@@ -0,0 +1,173 @@
+package zero
+
+import (
+	"errors"
+	"sort"
+)
+
+var (
+	// 准备变更之后哈希环又发生了变化，变更已失效
+	ErrStaleChange = errors.New("consistenthash: ring changed since prepare")
+	// 变更已经提交或放弃
+	ErrChangeClosed = errors.New("consistenthash: change already committed or aborted")
+)
+
+type (
+	// 一组拓扑变更
+	Change struct {
+		// 加入的节点
+		Add []string
+		// 加入节点的权重，未指定时为 TopWeight
+		Weights map[string]int
+		// 删除的节点
+		Remove []string
+	}
+
+	// 已准备好但尚未生效的拓扑变更
+	PendingChange struct {
+		base    *ConsistentHash
+		ring    *ConsistentHash
+		version uint64
+		closed  bool
+	}
+)
+
+// 两阶段变更的准备阶段
+// 在当前哈希环的副本上应用变更，当前哈希环保持不变
+// 可以先通过 PendingChange 检查变更后的负载，再 Commit 或 Abort
+func (h *ConsistentHash) Prepare(change Change) *PendingChange {
+	ring, version := h.clone()
+	ring.apply(change)
+
+	return &PendingChange{
+		base:    h,
+		ring:    ring,
+		version: version,
+	}
+}
+
+// 先删除再加入
+func (h *ConsistentHash) apply(change Change) {
+	for _, node := range change.Remove {
+		h.Remove(node)
+	}
+	for _, node := range change.Add {
+		weight, ok := change.Weights[node]
+		if !ok {
+			weight = TopWeight
+		}
+		h.AddWithWeight(node, weight)
+	}
+}
+
+// 当前拓扑的版本号，与 Epoch 一致，供 ApplyIfVersion 使用
+func (h *ConsistentHash) Version() uint64 {
+	return h.Epoch()
+}
+
+// 乐观并发控制：哈希环的版本号仍为 version 时按顺序原子地应用 changes，否则返回 ErrStaleChange 且不做任何修改
+// 多个控制器管理同一个哈希环时，各自基于读到的版本号提交，失败后重新读取拓扑再决定变更，避免互相覆盖
+func (h *ConsistentHash) ApplyIfVersion(version uint64, changes []Change) error {
+	ring, current := h.clone()
+	if current != version {
+		return ErrStaleChange
+	}
+	for _, change := range changes {
+		ring.apply(change)
+	}
+
+	p := &PendingChange{
+		base:    h,
+		ring:    ring,
+		version: version,
+	}
+	return p.Commit()
+}
+
+// 变更后的哈希环，提交前只用于查询
+func (p *PendingChange) Ring() *ConsistentHash {
+	return p.ring
+}
+
+// 变更后各节点占有的哈希空间比例
+func (p *PendingChange) Ownership() map[string]float64 {
+	p.ring.lock.RLock()
+	defer p.ring.lock.RUnlock()
+
+	return p.ring.ownership()
+}
+
+// 原子地应用变更
+// 准备之后哈希环被修改过时返回 ErrStaleChange，需要重新准备
+func (p *PendingChange) Commit() error {
+	// 复制一份再应用，提交后 Ring 返回的哈希环与当前环互不影响
+	next, _ := p.ring.clone()
+	h := p.base
+	h.lock.Lock()
+	if p.closed {
+		h.lock.Unlock()
+		return ErrChangeClosed
+	}
+	if h.version != p.version {
+		h.lock.Unlock()
+		return ErrStaleChange
+	}
+	p.closed = true
+
+	nodes := next.nodes
+	var added, removed []string
+	for node, replicas := range nodes {
+		if current, ok := h.nodes[node]; !ok || current != replicas {
+			added = append(added, node)
+		}
+	}
+	for node := range h.nodes {
+		if _, ok := nodes[node]; !ok {
+			removed = append(removed, node)
+		}
+	}
+
+	for _, node := range removed {
+		h.clearTTLLocked(node)
+	}
+	for _, node := range added {
+		h.clearTTLLocked(node)
+		h.clearDrainLocked(node)
+		h.clearCapacityLocked(node)
+	}
+	if h.store != nil {
+		// 保留用户提供的存储，副本的存储可能是其他类型
+		h.store.Clear()
+		for point := range next.ring {
+			h.store.Insert(point)
+		}
+	} else {
+		h.keys = next.keys
+		h.dead = next.dead
+	}
+	h.ring = next.ring
+	h.nodes = nodes
+	h.points = next.points
+	h.replicas = next.replicas
+	h.version++
+	h.settleLocked()
+	h.lock.Unlock()
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	for _, node := range removed {
+		h.nodeRemoved(node)
+	}
+	for _, node := range added {
+		h.nodeAdded(node, nodes[node])
+	}
+	return nil
+}
+
+// 放弃变更
+func (p *PendingChange) Abort() {
+	p.base.lock.Lock()
+	defer p.base.lock.Unlock()
+
+	p.closed = true
+}
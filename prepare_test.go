@@ -0,0 +1,109 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrepareCommit(t *testing.T) {
+	ch := NewConsistentHash()
+	for i := 0; i < 4; i++ {
+		ch.Add("10.0.0." + strconv.Itoa(i) + ":6379")
+	}
+
+	pending := ch.Prepare(Change{
+		Add:     []string{"10.0.0.4:6379", "10.0.0.5:6379"},
+		Weights: map[string]int{"10.0.0.5:6379": 50},
+		Remove:  []string{"10.0.0.0:6379"},
+	})
+	// 提交前当前环保持不变
+	assert.Equal(t, []string{"10.0.0.0:6379", "10.0.0.1:6379", "10.0.0.2:6379", "10.0.0.3:6379"}, ch.Nodes())
+	assert.Equal(t, 5, pending.Ring().Len())
+
+	var total float64
+	ownership := pending.Ownership()
+	for _, share := range ownership {
+		total += share
+	}
+	assert.InDelta(t, 1, total, 1e-9)
+	assert.Less(t, ownership["10.0.0.5:6379"], ownership["10.0.0.4:6379"])
+
+	assert.Nil(t, pending.Commit())
+	assert.Equal(t, pending.Ring().Nodes(), ch.Nodes())
+	assert.Equal(t, minReplicas/2, ch.ReplicaCount("10.0.0.5:6379"))
+	for i := 0; i < requestSize; i++ {
+		key := strconv.Itoa(i)
+		expect, _ := pending.Ring().Get(key)
+		actual, _ := ch.Get(key)
+		assert.Equal(t, expect, actual)
+	}
+	assert.Equal(t, ErrChangeClosed, pending.Commit())
+}
+
+func TestPrepareCommitCustomStore(t *testing.T) {
+	store := &countingStore{RingStore: NewTreeStore()}
+	ch := New(WithStore(store))
+	ch.Add("a")
+	ch.Add("b")
+
+	pending := ch.Prepare(Change{Add: []string{"c"}, Remove: []string{"a"}})
+	assert.Nil(t, pending.Commit())
+	// 提交后仍使用原有的存储，其中的位置与提交的拓扑一致
+	assert.Same(t, store, ch.store)
+	assert.Equal(t, 2*minReplicas, store.Len())
+	for i := 0; i < requestSize; i++ {
+		key := strconv.Itoa(i)
+		expect, _ := pending.Ring().Get(key)
+		actual, _ := ch.Get(key)
+		assert.Equal(t, expect, actual)
+	}
+
+	assert.Nil(t, ch.ApplyIfVersion(ch.Version(), []Change{{Remove: []string{"b"}}}))
+	assert.Same(t, store, ch.store)
+	assert.Equal(t, minReplicas, store.Len())
+}
+
+func TestPrepareStaleAndAbort(t *testing.T) {
+	ch := NewConsistentHash()
+	ch.Add("first")
+
+	pending := ch.Prepare(Change{Add: []string{"second"}})
+	ch.Add("third")
+	assert.Equal(t, ErrStaleChange, pending.Commit())
+	assert.False(t, ch.Contains("second"))
+
+	pending = ch.Prepare(Change{Remove: []string{"first"}})
+	pending.Abort()
+	assert.Equal(t, ErrChangeClosed, pending.Commit())
+	assert.True(t, ch.Contains("first"))
+
+	// 没有实际变化的操作不会使变更失效
+	pending = ch.Prepare(Change{Remove: []string{"third"}})
+	ch.Remove("unknown")
+	assert.Nil(t, pending.Commit())
+	assert.Equal(t, []string{"first"}, ch.Nodes())
+}
+
+func TestApplyIfVersion(t *testing.T) {
+	ch := NewConsistentHash()
+	ch.Add("a")
+	version := ch.Version()
+	assert.Equal(t, ch.Epoch(), version)
+
+	assert.Nil(t, ch.ApplyIfVersion(version, []Change{
+		{Add: []string{"b", "c"}, Weights: map[string]int{"c": 50}},
+		{Remove: []string{"a", "b"}},
+	}))
+	assert.Equal(t, []string{"c"}, ch.Nodes())
+	assert.Equal(t, 50, ch.ReplicaCount("c"))
+
+	// 另一个控制器基于旧版本的变更被拒绝
+	assert.Equal(t, ErrStaleChange, ch.ApplyIfVersion(version, []Change{{Add: []string{"d"}}}))
+	assert.Equal(t, []string{"c"}, ch.Nodes())
+
+	// 重新读取版本号后成功
+	assert.Nil(t, ch.ApplyIfVersion(ch.Version(), []Change{{Add: []string{"d"}}}))
+	assert.Equal(t, []string{"c", "d"}, ch.Nodes())
+}
@@ -0,0 +1,45 @@
+package zero
+
+// 就近选择时默认比较的候选节点数量
+const defaultProximityCandidates = 3
+
+// 按访问代价就近选择节点，如同一可用区的代价为0，跨可用区为1
+// Get 和 GetBytes 在键顺时针方向的前 K 个不同节点中选择代价最低的，代价相同时选择更靠前的，
+// 因此键最多偏离其原本的节点 K-1 个位置，拓扑变化时的迁移仍然有限
+// K 默认为3，可通过 WithProximityCandidates 调整；cost 在持有读锁时调用，不能调用哈希环的方法
+// 开启后 Get 不使用查找缓存，GetHash 等按哈希值的查找不受影响
+func WithProximity(cost func(node string) int) Option {
+	return func(h *ConsistentHash) {
+		h.proximity = cost
+		if h.proximityCandidates <= 0 {
+			h.proximityCandidates = defaultProximityCandidates
+		}
+	}
+}
+
+// 就近选择时比较的候选节点数量，为1时等同于不就近选择
+func WithProximityCandidates(k int) Option {
+	return func(h *ConsistentHash) {
+		h.proximityCandidates = max(k, 1)
+	}
+}
+
+// 键的前 proximityCandidates 个不同节点中代价最低的节点
+// 调用方需持有读锁，且环上至少有一个节点
+func (h *ConsistentHash) nearestLocked(key string) string {
+	var best string
+	var bestCost, seen int
+	visited := make(map[string]struct{}, h.proximityCandidates)
+	h.walkRing(key, func(node string) bool {
+		if _, ok := visited[node]; ok {
+			return true
+		}
+		visited[node] = struct{}{}
+		if cost := h.proximity(node); seen == 0 || cost < bestCost {
+			best, bestCost = node, cost
+		}
+		seen++
+		return seen < h.proximityCandidates
+	})
+	return best
+}
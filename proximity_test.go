@@ -0,0 +1,131 @@
+package zero
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProximity(t *testing.T) {
+	cost := func(node string) int {
+		if strings.HasPrefix(node, "az1/") {
+			return 0
+		}
+		return 1
+	}
+	plain := NewConsistentHash()
+	near := New(WithProximity(cost))
+	for i := 0; i < 9; i++ {
+		node := "az" + strconv.Itoa(i%3) + "/" + strconv.Itoa(i)
+		plain.Add(node)
+		near.Add(node)
+	}
+
+	var local int
+	for i := 0; i < requestSize; i++ {
+		key := strconv.Itoa(i)
+		node, ok := near.Get(key)
+		assert.True(t, ok)
+		bytesNode, _ := near.GetBytes([]byte(key))
+		assert.Equal(t, node, bytesNode)
+
+		// 在前3个候选中选择代价最低且最靠前的节点
+		candidates := plain.GetCandidates(key, 3)
+		expect := candidates[0].Node.(string)
+		for _, c := range candidates {
+			if cost(c.Node.(string)) < cost(expect) {
+				expect = c.Node.(string)
+				break
+			}
+		}
+		assert.Equal(t, expect, node)
+		if cost(node.(string)) == 0 {
+			local++
+		}
+	}
+	// 本地节点只占1/3，就近选择后大部分键落在本地
+	assert.True(t, local > requestSize/2)
+}
+
+func TestProximityCandidates(t *testing.T) {
+	ch := New(WithProximityCandidates(1), WithProximity(func(node string) int {
+		return len(node)
+	}))
+	plain := NewConsistentHash()
+	for _, node := range []string{"a", "bb", "ccc"} {
+		ch.Add(node)
+		plain.Add(node)
+	}
+	ch.Pin("pinned", "ccc")
+
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		expect, _ := plain.Get(key)
+		actual, _ := ch.Get(key)
+		assert.Equal(t, expect, actual)
+	}
+	node, _ := ch.Get("pinned")
+	assert.Equal(t, "ccc", node)
+	assert.Equal(t, 1, ch.Clone().proximityCandidates)
+}
+
+func TestProximityLookup(t *testing.T) {
+	ch := New(WithProximity(func(node string) int {
+		if strings.HasPrefix(node, "az1/") {
+			return 0
+		}
+		return 1
+	}))
+	for i := 0; i < 9; i++ {
+		ch.Add("az" + strconv.Itoa(i%3) + "/" + strconv.Itoa(i))
+	}
+
+	for i := 0; i < requestSize; i++ {
+		key := strconv.Itoa(i)
+		expect, _ := ch.Get(key)
+		info, ok := ch.Lookup(key)
+		assert.True(t, ok)
+		assert.Equal(t, expect, info.Node)
+	}
+}
+
+func TestProximitySharedPaths(t *testing.T) {
+	ch := New(WithProximity(func(node string) int {
+		if strings.HasPrefix(node, "az1/") {
+			return 0
+		}
+		return 1
+	}))
+	for i := 0; i < 9; i++ {
+		ch.Add("az" + strconv.Itoa(i%3) + "/" + strconv.Itoa(i))
+	}
+
+	keys := make([]string, requestSize)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	many := ch.GetMany(keys)
+	frozen := ch.Freeze().GetMany(keys)
+	stream := slices.Collect(ch.GetStream(slices.Values(keys)))
+	var moved int
+	for i, key := range keys {
+		expect, _ := ch.Get(key)
+		assert.Equal(t, expect, many[i].Node)
+		assert.Equal(t, expect, frozen[i].Node)
+		assert.Equal(t, expect, stream[i].Node)
+		existing, _ := ch.GetExisting(key)
+		assert.Equal(t, expect, existing)
+		primary, secondary, ok := ch.GetPair(key)
+		assert.True(t, ok)
+		assert.Equal(t, expect, primary)
+		assert.NotEqual(t, expect, secondary)
+		if plain, _ := ch.GetHash(ch.hashFunc([]byte(key))); plain != expect {
+			moved++
+		}
+	}
+	// 确认就近选择确实改变了部分键的节点
+	assert.True(t, moved > 0)
+}
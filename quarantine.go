@@ -0,0 +1,205 @@
+package zero
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFlapThreshold = 3
+	defaultFlapWindow    = time.Minute
+	defaultQuarantine    = 30 * time.Second
+	defaultMaxQuarantine = 10 * time.Minute
+)
+
+type (
+	// 隔离抖动节点的配置
+	QuarantineConfig struct {
+		// 窗口内允许的加入和离开次数，超过时隔离，不大于0时为3
+		Threshold int
+		// 统计抖动的时间窗口，不大于0时为1分钟
+		Window time.Duration
+		// 第一次隔离的时长，之后每次连续隔离翻倍，不大于0时为30秒
+		Backoff time.Duration
+		// 隔离时长的上限，不大于0时为10分钟；解除隔离后稳定超过该时长时重新从 Backoff 开始
+		MaxBackoff time.Duration
+		// 节点被隔离时调用，until 为解除隔离的时间
+		OnQuarantine func(node string, until time.Time)
+		// 节点解除隔离时调用，joined 表示节点按成员发现的最新状态重新加入了哈希环
+		OnRelease func(node string, joined bool)
+	}
+
+	// 位于成员发现和哈希环之间，隔离频繁加入和离开的节点
+	// 节点在窗口内的状态变化超过阈值时从哈希环上删除，隔离期间成员发现的加入和离开只被记录，
+	// 到期后按最新状态决定是否重新加入，隔离时长按连续隔离的次数指数增长
+	Quarantine struct {
+		ring *ConsistentHash
+		cfg  QuarantineConfig
+
+		lock   sync.Mutex
+		nodes  map[string]*flapState
+		closed bool
+	}
+
+	flapState struct {
+		// 窗口内状态变化的时间
+		flaps []time.Time
+		// 成员发现的最新状态
+		present bool
+		weight  int
+		// 连续隔离的次数，及最近一次解除隔离的时间
+		strikes  int
+		released time.Time
+		// 隔离中的定时任务，为 nil 时未隔离
+		timer Timer
+	}
+)
+
+// 为哈希环创建抖动隔离，时间来源沿用哈希环的配置
+func NewQuarantine(ring *ConsistentHash, cfg QuarantineConfig) *Quarantine {
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = defaultFlapThreshold
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = defaultFlapWindow
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = defaultQuarantine
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxQuarantine
+	}
+	return &Quarantine{
+		ring:  ring,
+		cfg:   cfg,
+		nodes: make(map[string]*flapState),
+	}
+}
+
+// 成员发现加入节点，隔离中的节点不会加入
+func (q *Quarantine) Add(node string) {
+	q.AddWithWeight(node, TopWeight)
+}
+
+// 成员发现按权重加入节点，权重含义同 AddWithWeight
+func (q *Quarantine) AddWithWeight(node string, weight int) {
+	q.update(node, true, weight)
+}
+
+// 成员发现删除节点
+func (q *Quarantine) Remove(node string) {
+	q.update(node, false, 0)
+}
+
+// 隔离中的节点，按字典序排列
+func (q *Quarantine) Quarantined() []string {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	var nodes []string
+	for node, state := range q.nodes {
+		if state.timer != nil {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// 停止所有隔离的定时任务，隔离中的节点不再自动加入
+func (q *Quarantine) Close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.closed = true
+	for _, state := range q.nodes {
+		if state.timer != nil {
+			state.timer.Stop()
+			state.timer = nil
+		}
+	}
+}
+
+func (q *Quarantine) update(node string, present bool, weight int) {
+	q.lock.Lock()
+	if q.closed {
+		q.lock.Unlock()
+		return
+	}
+	state, ok := q.nodes[node]
+	if !ok {
+		state = &flapState{}
+		q.nodes[node] = state
+	}
+	changed := state.present != present || (present && state.weight != weight)
+	state.present, state.weight = present, weight
+
+	// 隔离期间只记录最新状态
+	if state.timer != nil {
+		q.lock.Unlock()
+		return
+	}
+	if present {
+		q.ring.AddWithWeight(node, weight)
+	} else {
+		q.ring.Remove(node)
+	}
+	if !changed {
+		q.lock.Unlock()
+		return
+	}
+
+	now := q.ring.clock.Now()
+	i := 0
+	for i < len(state.flaps) && !state.flaps[i].Add(q.cfg.Window).After(now) {
+		i++
+	}
+	state.flaps = append(state.flaps[i:], now)
+	if len(state.flaps) <= q.cfg.Threshold {
+		q.lock.Unlock()
+		return
+	}
+
+	// 稳定了足够长时间后重新计算连续隔离的次数
+	if state.strikes > 0 && now.Sub(state.released) > q.cfg.MaxBackoff {
+		state.strikes = 0
+	}
+	backoff := q.cfg.Backoff << min(state.strikes, 30)
+	if backoff <= 0 || backoff > q.cfg.MaxBackoff {
+		backoff = q.cfg.MaxBackoff
+	}
+	state.strikes++
+	state.flaps = nil
+	until := now.Add(backoff)
+	state.timer = q.ring.clock.AfterFunc(backoff, func() {
+		q.release(node)
+	})
+	q.ring.Remove(node)
+	q.lock.Unlock()
+
+	if q.cfg.OnQuarantine != nil {
+		q.cfg.OnQuarantine(node, until)
+	}
+}
+
+func (q *Quarantine) release(node string) {
+	q.lock.Lock()
+	state, ok := q.nodes[node]
+	if !ok || state.timer == nil || q.closed {
+		q.lock.Unlock()
+		return
+	}
+	state.timer = nil
+	state.released = q.ring.clock.Now()
+	// 离开的节点也保留连续隔离的次数，直到稳定超过 MaxBackoff
+	joined := state.present
+	if joined {
+		q.ring.AddWithWeight(node, state.weight)
+	}
+	q.lock.Unlock()
+
+	if q.cfg.OnRelease != nil {
+		q.cfg.OnRelease(node, joined)
+	}
+}
@@ -0,0 +1,89 @@
+package zero
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuarantine(t *testing.T) {
+	ch, clock := newRebalancerRing()
+	var quarantined []time.Time
+	var released []bool
+	q := NewQuarantine(ch, QuarantineConfig{
+		Threshold: 3,
+		Window:    time.Minute,
+		Backoff:   10 * time.Second,
+		OnQuarantine: func(node string, until time.Time) {
+			assert.Equal(t, "flappy", node)
+			quarantined = append(quarantined, until)
+		},
+		OnRelease: func(node string, joined bool) {
+			released = append(released, joined)
+		},
+	})
+
+	q.Add("stable")
+	// 重复加入不算抖动
+	q.Add("stable")
+	q.Add("stable")
+	q.Add("stable")
+	q.Add("stable")
+	assert.Empty(t, q.Quarantined())
+
+	q.Add("flappy")
+	q.Remove("flappy")
+	q.Add("flappy")
+	assert.True(t, ch.Contains("flappy"))
+	q.Remove("flappy")
+	assert.Equal(t, []string{"flappy"}, q.Quarantined())
+	assert.Equal(t, []time.Time{time.Unix(10, 0)}, quarantined)
+
+	// 隔离期间成员发现的加入被记录但不生效
+	q.AddWithWeight("flappy", 50)
+	assert.False(t, ch.Contains("flappy"))
+	clock.Advance(10 * time.Second)
+	assert.Empty(t, q.Quarantined())
+	assert.Equal(t, []bool{true}, released)
+	assert.Equal(t, 50, ch.ReplicaCount("flappy"))
+	assert.Equal(t, []string{"flappy", "stable"}, ch.Nodes())
+
+	// 连续隔离时隔离时长翻倍
+	q.Remove("flappy")
+	q.Add("flappy")
+	q.Remove("flappy")
+	q.Add("flappy")
+	assert.Equal(t, time.Unix(30, 0), quarantined[1])
+	assert.False(t, ch.Contains("flappy"))
+	q.Remove("flappy")
+	clock.Advance(20 * time.Second)
+	assert.Equal(t, []bool{true, false}, released)
+	assert.False(t, ch.Contains("flappy"))
+}
+
+func TestQuarantineWindow(t *testing.T) {
+	ch, clock := newRebalancerRing()
+	q := NewQuarantine(ch, QuarantineConfig{Threshold: 2, Window: time.Minute})
+
+	// 窗口外的变化不计入
+	for i := 0; i < 5; i++ {
+		q.Add("a")
+		clock.Advance(time.Minute)
+		q.Remove("a")
+		clock.Advance(time.Minute)
+	}
+	assert.Empty(t, q.Quarantined())
+
+	q.Add("a")
+	q.Remove("a")
+	q.Add("a")
+	assert.Equal(t, []string{"a"}, q.Quarantined())
+
+	// 关闭后不再自动加入
+	q.Close()
+	clock.Advance(time.Hour)
+	assert.False(t, ch.Contains("a"))
+	q.Add("b")
+	assert.False(t, ch.Contains("b"))
+}
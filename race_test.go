@@ -0,0 +1,6 @@
+//go:build race
+
+package zero
+
+// 竞态检测下 sync.Pool 会随机丢弃对象，分配次数不可预测
+const raceEnabled = true
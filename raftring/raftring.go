@@ -0,0 +1,186 @@
+// 基于 Raft 复制的哈希环
+// 拓扑变更作为日志条目经过共识后，由每个成员按相同的顺序应用到本地的哈希环，
+// 所有成员因此得到完全一致的哈希环，适合无法部署 etcd 等外部协调服务的场景
+// 各成员的哈希环需使用相同的配置（哈希函数、虚拟节点放大因子等）
+package raftring
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"consistenthash"
+	"github.com/hashicorp/raft"
+)
+
+// 提交拓扑变更的默认超时
+const defaultTimeout = 10 * time.Second
+
+const (
+	opAdd byte = iota + 1
+	opAddWithWeight
+	opRemove
+	opRestore
+)
+
+// 日志条目格式错误
+var errBadCommand = errors.New("raftring: malformed command")
+
+type (
+	// 把拓扑日志应用到哈希环的状态机，作为 raft.NewRaft 的 FSM 参数
+	FSM struct {
+		ring *zero.ConsistentHash
+	}
+
+	// 通过 Raft 修改的哈希环
+	// 拓扑变更只能在 Leader 上提交，在其他成员上调用返回 raft.ErrNotLeader
+	Ring struct {
+		fsm     *FSM
+		raft    *raft.Raft
+		timeout time.Duration
+	}
+
+	// 某一时刻哈希环的编码，raft 在后台持久化
+	fsmSnapshot struct {
+		state []byte
+	}
+)
+
+// 创建状态机，日志会应用到 ring 上
+func NewFSM(ring *zero.ConsistentHash) *FSM {
+	return &FSM{ring: ring}
+}
+
+// 应用一条拓扑日志，返回值为 nil 或 error
+func (f *FSM) Apply(l *raft.Log) interface{} {
+	if l.Type != raft.LogCommand {
+		return nil
+	}
+	return f.apply(l.Data)
+}
+
+func (f *FSM) apply(data []byte) error {
+	if len(data) == 0 {
+		return errBadCommand
+	}
+
+	op, body := data[0], data[1:]
+	switch op {
+	case opAdd:
+		f.ring.Add(string(body))
+	case opAddWithWeight:
+		weight, n := binary.Uvarint(body)
+		if n <= 0 {
+			return errBadCommand
+		}
+		f.ring.AddWithWeight(string(body[n:]), int(weight))
+	case opRemove:
+		f.ring.Remove(string(body))
+	case opRestore:
+		var s zero.Snapshot
+		if err := json.Unmarshal(body, &s); err != nil {
+			return errBadCommand
+		}
+		f.ring.Restore(s)
+	default:
+		return fmt.Errorf("raftring: unknown command %d", op)
+	}
+	return nil
+}
+
+// 导出哈希环的当前状态，raft 保证调用期间不会并发 Apply
+func (f *FSM) Snapshot() (raft.FSMSnapshot, error) {
+	return &fsmSnapshot{state: f.ring.EncodeBinary()}, nil
+}
+
+// 用 raft 快照替换哈希环的成员
+func (f *FSM) Restore(rc io.ReadCloser) error {
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+	return f.ring.DecodeBinary(data)
+}
+
+func (s *fsmSnapshot) Persist(sink raft.SnapshotSink) error {
+	if _, err := sink.Write(s.state); err != nil {
+		sink.Cancel()
+		return err
+	}
+	return sink.Close()
+}
+
+func (s *fsmSnapshot) Release() {}
+
+// 创建哈希环，r 需使用 fsm 作为状态机
+func New(fsm *FSM, r *raft.Raft) *Ring {
+	return &Ring{
+		fsm:     fsm,
+		raft:    r,
+		timeout: defaultTimeout,
+	}
+}
+
+// 设置提交拓扑变更的超时，默认为10秒
+func (r *Ring) SetTimeout(timeout time.Duration) {
+	r.timeout = timeout
+}
+
+// 本地的哈希环，只应用于查找，直接修改会使成员之间不一致
+func (r *Ring) Ring() *zero.ConsistentHash {
+	return r.fsm.ring
+}
+
+// 底层的 raft 实例，用于管理集群成员等操作
+func (r *Ring) Raft() *raft.Raft {
+	return r.raft
+}
+
+// 添加节点，返回时变更已提交并应用到本地的哈希环
+func (r *Ring) Add(node string) error {
+	return r.apply(append([]byte{opAdd}, node...))
+}
+
+// 按权重添加节点
+func (r *Ring) AddWithWeight(node string, weight int) error {
+	if weight < 0 {
+		return fmt.Errorf("raftring: negative weight %d", weight)
+	}
+	cmd := binary.AppendUvarint([]byte{opAddWithWeight}, uint64(weight))
+	return r.apply(append(cmd, node...))
+}
+
+// 删除节点
+func (r *Ring) Remove(node string) error {
+	return r.apply(append([]byte{opRemove}, node...))
+}
+
+// 用快照替换所有成员的哈希环
+func (r *Ring) Restore(s zero.Snapshot) error {
+	body, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return r.apply(append([]byte{opRestore}, body...))
+}
+
+// 等待此前提交的变更都应用到本地的哈希环，只能在 Leader 上调用
+func (r *Ring) Barrier() error {
+	return r.raft.Barrier(r.timeout).Error()
+}
+
+func (r *Ring) apply(cmd []byte) error {
+	future := r.raft.Apply(cmd, r.timeout)
+	if err := future.Error(); err != nil {
+		return err
+	}
+	if err, ok := future.Response().(error); ok {
+		return err
+	}
+	return nil
+}
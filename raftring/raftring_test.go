@@ -0,0 +1,153 @@
+package raftring
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"consistenthash"
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/assert"
+)
+
+type member struct {
+	ring *Ring
+	raft *raft.Raft
+}
+
+// 启动一个全部使用内存存储和内存传输的集群
+func cluster(t *testing.T, size int) []*member {
+	transports := make([]*raft.InmemTransport, size)
+	var servers []raft.Server
+	for i := range transports {
+		addr, trans := raft.NewInmemTransport(raft.ServerAddress("node" + strconv.Itoa(i)))
+		transports[i] = trans
+		servers = append(servers, raft.Server{ID: raft.ServerID(addr), Address: addr})
+	}
+	for _, a := range transports {
+		for _, b := range transports {
+			a.Connect(b.LocalAddr(), b)
+		}
+	}
+
+	members := make([]*member, size)
+	for i, trans := range transports {
+		conf := raft.DefaultConfig()
+		conf.LocalID = raft.ServerID(trans.LocalAddr())
+		conf.HeartbeatTimeout = 50 * time.Millisecond
+		conf.ElectionTimeout = 50 * time.Millisecond
+		conf.LeaderLeaseTimeout = 50 * time.Millisecond
+		conf.CommitTimeout = 5 * time.Millisecond
+		conf.LogOutput = io.Discard
+
+		store := raft.NewInmemStore()
+		snaps := raft.NewInmemSnapshotStore()
+		assert.Nil(t, raft.BootstrapCluster(conf, store, store, snaps, trans,
+			raft.Configuration{Servers: servers}))
+		fsm := NewFSM(zero.NewConsistentHash())
+		r, err := raft.NewRaft(conf, fsm, store, store, snaps, trans)
+		assert.Nil(t, err)
+		t.Cleanup(func() { r.Shutdown().Error() })
+		members[i] = &member{ring: New(fsm, r), raft: r}
+	}
+	return members
+}
+
+func leader(t *testing.T, members []*member) *member {
+	t.Helper()
+	var found *member
+	assert.Eventually(t, func() bool {
+		for _, m := range members {
+			if m.raft.State() == raft.Leader {
+				found = m
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+	return found
+}
+
+func assertConsistent(t *testing.T, members []*member) {
+	t.Helper()
+	expect := leader(t, members).ring.Ring().EncodeBinary()
+	for _, m := range members {
+		assert.Eventually(t, func() bool {
+			return bytes.Equal(expect, m.ring.Ring().EncodeBinary())
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+}
+
+func TestReplicatedTopology(t *testing.T) {
+	members := cluster(t, 3)
+	l := leader(t, members)
+
+	assert.Nil(t, l.ring.Add("10.0.0.1:6379"))
+	assert.Nil(t, l.ring.AddWithWeight("10.0.0.2:6379", 50))
+	assert.Nil(t, l.ring.Add("10.0.0.3:6379"))
+	assert.Nil(t, l.ring.Remove("10.0.0.1:6379"))
+	assert.ElementsMatch(t, []string{"10.0.0.2:6379", "10.0.0.3:6379"}, l.ring.Ring().Nodes())
+	assertConsistent(t, members)
+
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		expect, _ := l.ring.Ring().Get(key)
+		for _, m := range members {
+			actual, _ := m.ring.Ring().Get(key)
+			assert.Equal(t, expect, actual)
+		}
+	}
+
+	assert.Nil(t, l.ring.Restore(zero.Snapshot{Nodes: map[string]int{"a": 100, "b": 50}}))
+	assert.ElementsMatch(t, []string{"a", "b"}, l.ring.Ring().Nodes())
+	assertConsistent(t, members)
+}
+
+func TestNotLeader(t *testing.T) {
+	members := cluster(t, 3)
+	l := leader(t, members)
+
+	for _, m := range members {
+		if m != l {
+			assert.Equal(t, raft.ErrNotLeader, m.ring.Add("10.0.0.1:6379"))
+		}
+	}
+	assert.Nil(t, l.ring.Barrier())
+	assert.Empty(t, l.ring.Ring().Nodes())
+}
+
+func TestFSMSnapshot(t *testing.T) {
+	ring := zero.NewConsistentHash()
+	fsm := NewFSM(ring)
+	assert.Nil(t, fsm.apply(append([]byte{opAdd}, "a"...)))
+	assert.Nil(t, fsm.apply(append([]byte{opAdd}, "b"...)))
+
+	snap, err := fsm.Snapshot()
+	assert.Nil(t, err)
+	sink := &memorySink{}
+	assert.Nil(t, snap.Persist(sink))
+	snap.Release()
+
+	restored := NewFSM(zero.NewConsistentHash())
+	assert.Nil(t, restored.Restore(io.NopCloser(&sink.Buffer)))
+	assert.Equal(t, ring.EncodeBinary(), restored.ring.EncodeBinary())
+}
+
+func TestFSMBadCommand(t *testing.T) {
+	fsm := NewFSM(zero.NewConsistentHash())
+	assert.Equal(t, errBadCommand, fsm.apply(nil))
+	assert.Equal(t, errBadCommand, fsm.apply([]byte{opAddWithWeight}))
+	assert.Equal(t, errBadCommand, fsm.apply([]byte{opRestore, '{'}))
+	assert.NotNil(t, fsm.apply([]byte{0xff}))
+	assert.Empty(t, fsm.ring.Nodes())
+}
+
+type memorySink struct {
+	bytes.Buffer
+}
+
+func (s *memorySink) ID() string    { return "memory" }
+func (s *memorySink) Cancel() error { return nil }
+func (s *memorySink) Close() error  { return nil }
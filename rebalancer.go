@@ -0,0 +1,258 @@
+package zero
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// 两次重建之间的默认最小间隔
+	defaultRebalanceInterval = 500 * time.Millisecond
+	// 限制迁移量的时间窗口
+	churnWindow = time.Minute
+)
+
+type (
+	// 后台再平衡的配置
+	RebalancerConfig struct {
+		// 两次应用变更之间的最小间隔，不大于0时为 500ms
+		Interval time.Duration
+		// 每分钟允许迁移的哈希空间比例上限，如 0.2 表示20%，不大于0时不限制
+		// 单个变更超出上限时仍会在窗口空闲时单独应用，避免永远无法生效
+		MaxChurn float64
+	}
+
+	// 缓冲拓扑变更，按间隔和迁移量上限分批应用到哈希环，平滑服务发现抖动带来的频繁重建
+	// 同一节点在应用前的多次变更只保留最后一次，与哈希环现状相同的变更被丢弃
+	Rebalancer struct {
+		ring *ConsistentHash
+		cfg  RebalancerConfig
+
+		lock    sync.Mutex
+		pending map[string]rebalanceOp
+		// 最近一个窗口内每批变更的迁移量
+		churns []churnRecord
+		last   time.Time
+		timer  Timer
+		closed bool
+	}
+
+	// 排队中的变更，remove 为 false 时按 weight 加入
+	rebalanceOp struct {
+		remove bool
+		weight int
+	}
+
+	churnRecord struct {
+		at    time.Time
+		churn float64
+	}
+)
+
+// 为哈希环创建后台再平衡器，时间来源沿用哈希环的配置
+func NewRebalancer(ring *ConsistentHash, cfg RebalancerConfig) *Rebalancer {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultRebalanceInterval
+	}
+	return &Rebalancer{
+		ring:    ring,
+		cfg:     cfg,
+		pending: make(map[string]rebalanceOp),
+	}
+}
+
+// 排队加入节点
+func (r *Rebalancer) Add(node string) {
+	r.AddWithWeight(node, TopWeight)
+}
+
+// 排队按权重加入节点，权重含义同 AddWithWeight
+func (r *Rebalancer) AddWithWeight(node string, weight int) {
+	r.enqueue(node, rebalanceOp{weight: weight})
+}
+
+// 排队删除节点
+func (r *Rebalancer) Remove(node string) {
+	r.enqueue(node, rebalanceOp{remove: true})
+}
+
+// 排队中的变更数量
+func (r *Rebalancer) Pending() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return len(r.pending)
+}
+
+// 忽略间隔和迁移量上限，立即应用所有排队中的变更
+func (r *Rebalancer) Flush() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.applyLocked(math.Inf(1))
+}
+
+// 停止后台应用，排队中的变更被丢弃，需要保留时先调用 Flush
+func (r *Rebalancer) Close() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.closed = true
+	r.pending = make(map[string]rebalanceOp)
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+}
+
+func (r *Rebalancer) enqueue(node string, op rebalanceOp) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.closed {
+		return
+	}
+	r.pending[node] = op
+	r.scheduleLocked(r.last.Add(r.cfg.Interval))
+}
+
+// 在 at 时刻应用下一批变更，已有计划时不重复安排
+// 调用方需持有 r.lock
+func (r *Rebalancer) scheduleLocked(at time.Time) {
+	if r.timer != nil || r.closed || len(r.pending) == 0 {
+		return
+	}
+	delay := max(at.Sub(r.ring.clock.Now()), 0)
+	r.timer = r.ring.clock.AfterFunc(delay, r.tick)
+}
+
+func (r *Rebalancer) tick() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.timer = nil
+	if r.closed {
+		return
+	}
+
+	now := r.ring.clock.Now()
+	budget := math.Inf(1)
+	if r.cfg.MaxChurn > 0 {
+		budget = r.cfg.MaxChurn
+		for _, record := range r.churns {
+			budget -= record.churn
+		}
+	}
+	r.applyLocked(budget)
+
+	// 上限用尽时等到最早一批移出窗口
+	next := now.Add(r.cfg.Interval)
+	if len(r.pending) > 0 && len(r.churns) > 0 && r.cfg.MaxChurn > 0 {
+		if expire := r.churns[0].at.Add(churnWindow); expire.After(next) {
+			next = expire
+		}
+	}
+	r.scheduleLocked(next)
+}
+
+// 在迁移量不超过 budget 的前提下应用尽量多的排队变更
+// 窗口内没有其他变更时至少应用一个
+// 调用方需持有 r.lock
+func (r *Rebalancer) applyLocked(budget float64) {
+	now := r.ring.clock.Now()
+	// 丢弃移出窗口的记录
+	i := 0
+	for i < len(r.churns) && !r.churns[i].at.Add(churnWindow).After(now) {
+		i++
+	}
+	r.churns = r.churns[i:]
+
+	for {
+		change, churn, ok := r.batchLocked(budget, len(r.churns) == 0)
+		if !ok {
+			return
+		}
+		// 准备之后哈希环被直接修改时重新计算
+		if err := r.ring.Prepare(change).Commit(); errors.Is(err, ErrStaleChange) {
+			continue
+		}
+
+		for _, node := range change.Remove {
+			delete(r.pending, node)
+		}
+		for _, node := range change.Add {
+			delete(r.pending, node)
+		}
+		r.churns = append(r.churns, churnRecord{at: now, churn: churn})
+		r.last = now
+		return
+	}
+}
+
+// 按估算的迁移量挑选一批变更，先删除后加入，同类按节点名排列
+// 删除的迁移量为节点当前的占比，加入和权重变化按虚拟节点数量的变化占总数的比例估算
+// 调用方需持有 r.lock
+func (r *Rebalancer) batchLocked(budget float64, force bool) (Change, float64, bool) {
+	h := r.ring
+	h.lock.RLock()
+	owned := h.ownership()
+	total := 0
+	for _, replicas := range h.nodes {
+		total += replicas
+	}
+	type candidate struct {
+		node     string
+		op       rebalanceOp
+		replicas int
+	}
+	var removes, adds []candidate
+	for node, op := range r.pending {
+		current, exists := h.nodes[node]
+		if op.remove {
+			if exists {
+				removes = append(removes, candidate{node: node, op: op, replicas: current})
+			} else {
+				delete(r.pending, node)
+			}
+			continue
+		}
+		replicas := h.replicas * op.weight / TopWeight
+		if exists && current == replicas {
+			delete(r.pending, node)
+			continue
+		}
+		adds = append(adds, candidate{node: node, op: op, replicas: replicas - current})
+	}
+	h.lock.RUnlock()
+
+	sort.Slice(removes, func(i, j int) bool { return removes[i].node < removes[j].node })
+	sort.Slice(adds, func(i, j int) bool { return adds[i].node < adds[j].node })
+
+	var change Change
+	var churn float64
+	for _, c := range append(removes, adds...) {
+		var cost float64
+		if c.op.remove {
+			cost = owned[c.node]
+		} else {
+			cost = math.Abs(float64(c.replicas)) / float64(max(total+max(c.replicas, 0), 1))
+		}
+		empty := len(change.Add) == 0 && len(change.Remove) == 0
+		if churn+cost > budget && !(force && empty) {
+			break
+		}
+		churn += cost
+		if c.op.remove {
+			change.Remove = append(change.Remove, c.node)
+		} else {
+			if change.Weights == nil {
+				change.Weights = make(map[string]int)
+			}
+			change.Add = append(change.Add, c.node)
+			change.Weights[c.node] = c.op.weight
+		}
+	}
+	return change, churn, len(change.Add) > 0 || len(change.Remove) > 0
+}
@@ -0,0 +1,92 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newRebalancerRing() (*ConsistentHash, *fakeClock) {
+	clock := newFakeClock()
+	ch := New()
+	ch.clock = clock
+	return ch, clock
+}
+
+func TestRebalancer(t *testing.T) {
+	ch, clock := newRebalancerRing()
+	r := NewRebalancer(ch, RebalancerConfig{})
+
+	// 应用前的多次变更只保留最后一次
+	r.Add("a")
+	r.Remove("a")
+	r.Add("a")
+	r.AddWithWeight("b", 50)
+	assert.Equal(t, 2, r.Pending())
+	assert.Equal(t, 0, ch.Len())
+	clock.Advance(0)
+	assert.Equal(t, []string{"a", "b"}, ch.Nodes())
+	assert.Equal(t, 50, ch.ReplicaCount("b"))
+	assert.Equal(t, 0, r.Pending())
+
+	// 两次应用之间至少间隔 Interval
+	r.Add("c")
+	clock.Advance(100 * time.Millisecond)
+	assert.False(t, ch.Contains("c"))
+	clock.Advance(400 * time.Millisecond)
+	assert.True(t, ch.Contains("c"))
+
+	// 抖动后与现状相同的变更被丢弃
+	epoch := ch.Epoch()
+	r.Remove("c")
+	r.Add("c")
+	clock.Advance(time.Second)
+	assert.Equal(t, epoch, ch.Epoch())
+	assert.Equal(t, 0, r.Pending())
+}
+
+func TestRebalancerMaxChurn(t *testing.T) {
+	ch, clock := newRebalancerRing()
+	for i := 0; i < 10; i++ {
+		ch.Add("node" + strconv.Itoa(i))
+	}
+	r := NewRebalancer(ch, RebalancerConfig{MaxChurn: 0.15})
+
+	r.Remove("node0")
+	r.Remove("node1")
+	r.Remove("node2")
+	clock.Advance(0)
+	// 每个节点约占10%，一分钟内只能删除一个
+	assert.Equal(t, 9, ch.Len())
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, 9, ch.Len())
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, 8, ch.Len())
+
+	r.Flush()
+	assert.Equal(t, 7, ch.Len())
+	assert.Equal(t, 0, r.Pending())
+}
+
+func TestRebalancerClose(t *testing.T) {
+	ch, clock := newRebalancerRing()
+	r := NewRebalancer(ch, RebalancerConfig{})
+	r.Add("a")
+	r.Close()
+	r.Add("b")
+	clock.Advance(time.Second)
+	assert.Equal(t, 0, ch.Len())
+	assert.Equal(t, 0, r.Pending())
+}
+
+func TestRebalancerConcurrentChange(t *testing.T) {
+	ch, _ := newRebalancerRing()
+	r := NewRebalancer(ch, RebalancerConfig{})
+	r.Add("a")
+	// 哈希环被直接修改不影响排队的变更
+	ch.Add("b")
+	r.Flush()
+	assert.Equal(t, []string{"a", "b"}, ch.Nodes())
+}
@@ -0,0 +1,96 @@
+package zero
+
+import (
+	"strconv"
+	"time"
+)
+
+// 估算重建前后键迁移比例的样本键数量
+const reconfigSampleKeys = 10000
+
+// 更换哈希函数，并按现有节点及其虚拟节点数量重建哈希环
+// 沿用 WithSeed 和 WithReplicaSpreading 的配置，返回用样本键估算的迁移比例
+// 开启 WithHashValidation 时拒绝分布不均的哈希函数，此时不做修改并返回0
+// 更换哈希函数几乎会迁移所有的键，应在数据迁移方案就绪后进行
+func (h *ConsistentHash) SetHashFunc(fn Func) float64 {
+	if fn == nil {
+		return 0
+	}
+
+	// 检查的配置在创建后不再变化，不需要持有锁
+	if !h.acceptHashFunc(fn) {
+		return 0
+	}
+
+	start := time.Now()
+	h.lock.Lock()
+	before := h.sampleOwnersLocked()
+	h.setHashFuncLocked(fn)
+	h.rebuild(h.nodesSnapshot())
+	h.version++
+	h.settleLocked()
+	moved := h.movedFractionLocked(before)
+	h.lock.Unlock()
+
+	h.rebuildDone("hash_func", time.Since(start), moved)
+	return moved
+}
+
+// 修改虚拟节点放大因子，所有节点的虚拟节点数量按比例缩放后重建哈希环
+// 不低于 WithMinReplicas 设置的下限，返回用样本键估算的迁移比例
+func (h *ConsistentHash) SetReplicas(replicas int) float64 {
+	start := time.Now()
+	h.lock.Lock()
+	replicas = max(replicas, h.replicaFloor, 1)
+	if replicas == h.replicas {
+		h.lock.Unlock()
+		return 0
+	}
+
+	before := h.sampleOwnersLocked()
+	h.rescale(replicas)
+	h.version++
+	h.settleLocked()
+	moved := h.movedFractionLocked(before)
+	h.lock.Unlock()
+
+	h.rebuildDone("replicas", time.Since(start), moved)
+	return moved
+}
+
+// 当前各节点的虚拟节点数量
+// 调用方需持有读锁
+func (h *ConsistentHash) nodesSnapshot() map[string]int {
+	nodes := make(map[string]int, len(h.nodes))
+	for node, replicas := range h.nodes {
+		nodes[node] = replicas
+	}
+	return nodes
+}
+
+// 样本键当前所属的节点，不经过固定路由和查找缓存
+// 调用方需持有读锁
+func (h *ConsistentHash) sampleOwnersLocked() []interface{} {
+	owners := make([]interface{}, reconfigSampleKeys)
+	if len(h.ring) == 0 {
+		return owners
+	}
+	for i := range owners {
+		key := []byte("sample:" + strconv.Itoa(i))
+		owners[i], _ = h.locate(h.hashFunc(key), key)
+	}
+	return owners
+}
+
+// 与 before 相比改变所属节点的样本键比例
+// 调用方需持有读锁
+func (h *ConsistentHash) movedFractionLocked(before []interface{}) float64 {
+	after := h.sampleOwnersLocked()
+	var moved int
+	for i := range before {
+		if before[i] != after[i] {
+			moved++
+		}
+	}
+	return float64(moved) / float64(len(before))
+}
@@ -0,0 +1,71 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"consistenthash/hashes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetHashFunc(t *testing.T) {
+	ch := New()
+	ch.Add("a")
+	ch.AddWithWeight("b", 50)
+	ch.Add("c")
+	epoch := ch.Epoch()
+
+	expect := New(WithHashFunc(hashes.XXHash64))
+	expect.Add("a")
+	expect.AddWithWeight("b", 50)
+	expect.Add("c")
+
+	moved := ch.SetHashFunc(hashes.XXHash64)
+	assert.InDelta(t, 0.6, moved, 0.1)
+	assert.Greater(t, ch.Epoch(), epoch)
+	assert.Equal(t, 50, ch.ReplicaCount("b"))
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		want, _ := expect.Get(key)
+		got, _ := ch.Get(key)
+		assert.Equal(t, want, got)
+	}
+
+	assert.Equal(t, 0., ch.SetHashFunc(hashes.XXHash64))
+	assert.Equal(t, 0., ch.SetHashFunc(nil))
+	assert.Equal(t, 0., New().SetHashFunc(hashes.XXHash64))
+}
+
+func TestSetHashFuncSeeded(t *testing.T) {
+	ch := New(WithSeed(7))
+	ch.SetHashFunc(hashes.XXHash64)
+	ch.Add("a")
+	ch.Add("b")
+
+	expect := New(WithSeed(7), WithHashFunc(hashes.XXHash64))
+	expect.Add("a")
+	expect.Add("b")
+	assert.Equal(t, 0., MovedFraction(expect, ch, 1000))
+}
+
+func TestSetReplicas(t *testing.T) {
+	ch := New()
+	for i := 0; i < 10; i++ {
+		ch.Add("node" + strconv.Itoa(i))
+	}
+	ch.AddWithWeight("half", 50)
+
+	moved := ch.SetReplicas(200)
+	assert.Less(t, moved, 0.5)
+	assert.Equal(t, 200, ch.ReplicaCount("node0"))
+	assert.Equal(t, 100, ch.ReplicaCount("half"))
+	// 之后添加的节点使用新的放大因子
+	ch.Add("new")
+	assert.Equal(t, 200, ch.ReplicaCount("new"))
+
+	assert.Equal(t, 0., ch.SetReplicas(200))
+	ch = New(WithMinReplicas(150))
+	ch.Add("a")
+	ch.SetReplicas(10)
+	assert.Equal(t, 150, ch.ReplicaCount("a"))
+}
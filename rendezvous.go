@@ -0,0 +1,129 @@
+package zero
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+type (
+	// 带权重的最高随机权重（rendezvous）哈希
+	// 每个键对所有节点打分，取分数最高的节点，不需要虚拟节点
+	// 打分采用对数方法 score = -weight / ln(h)，节点分到的键与权重成正比
+	RendezvousHash struct {
+		// 哈希函数
+		hashFunc Func
+		// 按名称排序的节点列表，分数相同时取靠前的节点
+		list []rendezvousNode
+		// 物理节点到权重的映射
+		nodes map[string]int
+		// 读写锁
+		lock sync.RWMutex
+	}
+
+	rendezvousNode struct {
+		name   string
+		hash   uint64
+		weight float64
+	}
+)
+
+func NewRendezvousHash() *RendezvousHash {
+	return NewCustomRendezvousHash(Hash)
+}
+
+func NewCustomRendezvousHash(fn Func) *RendezvousHash {
+	if fn == nil {
+		fn = Hash
+	}
+
+	return &RendezvousHash{
+		hashFunc: fn,
+		nodes:    make(map[string]int),
+	}
+}
+
+// 增加物理节点
+func (h *RendezvousHash) Add(node string) {
+	h.AddWithWeight(node, TopWeight)
+}
+
+// 按权重增加物理节点，重复添加会更新权重
+// 权重不为正数时忽略
+func (h *RendezvousHash) AddWithWeight(node string, weight int) {
+	if weight <= 0 {
+		return
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.removeLocked(node)
+	h.nodes[node] = weight
+	h.list = append(h.list, rendezvousNode{
+		name:   node,
+		hash:   h.hashFunc([]byte(node)),
+		weight: float64(weight),
+	})
+	sort.Slice(h.list, func(i, j int) bool {
+		return h.list[i].name < h.list[j].name
+	})
+}
+
+// 删除物理节点
+func (h *RendezvousHash) Remove(node string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.removeLocked(node)
+}
+
+// 返回分数最高的节点
+func (h *RendezvousHash) Get(v string) (interface{}, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if len(h.list) == 0 {
+		return nil, false
+	}
+
+	hash := h.hashFunc([]byte(v))
+	best := -1
+	bestScore := math.Inf(-1)
+	for i, node := range h.list {
+		// 取53位映射到 (0, 1) 开区间，避免 ln(0) 与 ln(1)
+		u := (float64(mix64(hash^node.hash)>>11) + .5) / (1 << 53)
+		if score := -node.weight / math.Log(u); score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+
+	return h.list[best].name, true
+}
+
+// 当前所有物理节点，按字典序排列
+func (h *RendezvousHash) Nodes() []string {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	nodes := make([]string, len(h.list))
+	for i, node := range h.list {
+		nodes[i] = node.name
+	}
+	return nodes
+}
+
+// 调用方需持有写锁
+func (h *RendezvousHash) removeLocked(node string) {
+	if _, ok := h.nodes[node]; !ok {
+		return
+	}
+
+	delete(h.nodes, node)
+	for i, x := range h.list {
+		if x.name == node {
+			h.list = append(h.list[:i], h.list[i+1:]...)
+			return
+		}
+	}
+}
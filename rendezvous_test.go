@@ -0,0 +1,57 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRendezvousHashWeights(t *testing.T) {
+	h := NewRendezvousHash()
+	_, ok := h.Get("any")
+	assert.False(t, ok)
+
+	h.AddWithWeight("heavy", 2*TopWeight)
+	h.Add("light")
+	h.AddWithWeight("ignored", 0)
+
+	const total = requestSize * 20
+	loads := make(map[interface{}]int)
+	for i := 0; i < total; i++ {
+		node, ok := h.Get(strconv.Itoa(i))
+		assert.True(t, ok)
+		loads[node]++
+	}
+	assert.Equal(t, 2, len(loads))
+	assert.InDelta(t, 2, float64(loads["heavy"])/float64(loads["light"]), .2)
+}
+
+func TestRendezvousHashLeastTransfer(t *testing.T) {
+	h := NewRendezvousHash()
+	for i := 0; i < keySize; i++ {
+		h.Add("localhost:" + strconv.Itoa(i))
+	}
+
+	keys := make(map[int]interface{}, requestSize)
+	for i := 0; i < requestSize; i++ {
+		keys[i], _ = h.Get(strconv.Itoa(i))
+	}
+
+	const removed = "localhost:7"
+	h.Remove(removed)
+	for i := 0; i < requestSize; i++ {
+		node, ok := h.Get(strconv.Itoa(i))
+		assert.True(t, ok)
+		assert.NotEqual(t, removed, node)
+		if keys[i] != removed {
+			assert.Equal(t, keys[i], node)
+		}
+	}
+
+	h.Add(removed)
+	for i := 0; i < requestSize; i++ {
+		node, _ := h.Get(strconv.Itoa(i))
+		assert.Equal(t, keys[i], node)
+	}
+}
@@ -0,0 +1,146 @@
+package zero
+
+import "sort"
+
+type (
+	// 带权重的节点，权重含义同 AddWithWeight，不大于0时为 TopWeight
+	WeightedNode struct {
+		Node   string
+		Weight int
+	}
+
+	// ReplaceAll 实际生效的变更，均按字典序排列
+	ReplaceSummary struct {
+		// 新加入的节点
+		Added []string
+		// 离开的节点
+		Removed []string
+		// 权重变化的节点
+		Reweighted []string
+	}
+)
+
+// 没有任何变更
+func (s ReplaceSummary) Empty() bool {
+	return len(s.Added) == 0 && len(s.Removed) == 0 && len(s.Reweighted) == 0
+}
+
+// 用服务发现给出的完整成员列表替换当前成员
+// 在一次写锁内计算差异并应用全部增删，读取方不会看到只更新了一半的哈希环
+// 列表中名称为空的节点被忽略，重复的节点以最后一次为准
+// 加入或权重变化的节点视为以普通方式添加，不再过期、摘除或按容量计算权重
+// 按 CollisionError 策略被拒绝的节点不计入结果，已有节点保持原有的虚拟节点
+// 变更涉及大量虚拟节点时并行计算虚拟节点位置
+func (h *ConsistentHash) ReplaceAll(nodes []WeightedNode) ReplaceSummary {
+	desired := make(map[string]int, len(nodes))
+	for _, n := range nodes {
+		if n.Node == "" {
+			continue
+		}
+		weight := n.Weight
+		if weight <= 0 {
+			weight = TopWeight
+		}
+		desired[n.Node] = weight
+	}
+
+	h.lock.Lock()
+	var summary ReplaceSummary
+	for node := range h.nodes {
+		if _, ok := desired[node]; !ok {
+			summary.Removed = append(summary.Removed, node)
+		}
+	}
+	sort.Strings(summary.Removed)
+	for _, node := range summary.Removed {
+		h.clearTTLLocked(node)
+		h.clearDrainLocked(node)
+		h.removeLocked(node)
+	}
+
+	// 固定处理顺序，使冲突链和顺延的位置可复现
+	names := make([]string, 0, len(desired))
+	for node := range desired {
+		names = append(names, node)
+	}
+	sort.Strings(names)
+	pending := make(map[string]int)
+	for _, node := range names {
+		replicas := h.replicas * desired[node] / TopWeight
+		if old, ok := h.nodes[node]; !ok || old != replicas {
+			pending[node] = replicas
+		}
+	}
+	precomputed := h.precomputePoints(pending)
+	for _, node := range names {
+		replicas, ok := pending[node]
+		if !ok {
+			continue
+		}
+		oldReplicas, existed := h.nodes[node]
+		h.clearTTLLocked(node)
+		h.clearDrainLocked(node)
+		h.clearCapacityLocked(node)
+		oldPoints := h.points[node]
+		h.removeLocked(node)
+		if err := h.addPointsLocked(node, replicas, h.pointsFor(precomputed, node, replicas)); err != nil {
+			if existed {
+				h.insertLocked(node, oldReplicas, oldPoints)
+			}
+			continue
+		}
+		if existed {
+			summary.Reweighted = append(summary.Reweighted, node)
+		} else {
+			summary.Added = append(summary.Added, node)
+		}
+	}
+	if !summary.Empty() {
+		h.sortKeys()
+		h.settleLocked()
+	}
+	added := make(map[string]int, len(summary.Added)+len(summary.Reweighted))
+	for _, list := range [][]string{summary.Added, summary.Reweighted} {
+		for _, node := range list {
+			added[node] = h.nodes[node]
+		}
+	}
+	h.lock.Unlock()
+
+	for _, node := range summary.Removed {
+		h.nodeRemoved(node)
+	}
+	for _, node := range names {
+		if replicas, ok := added[node]; ok {
+			h.nodeAdded(node, replicas)
+		}
+	}
+	return summary
+}
+
+// 删除所有满足 pred 的节点，在一次写锁内完成并只收尾一次，返回删除的数量
+// 如下线整个网段的节点；pred 在写锁内调用，不能访问哈希环
+func (h *ConsistentHash) RemoveIf(pred func(node string) bool) int {
+	h.lock.Lock()
+	var removed []string
+	for node := range h.nodes {
+		if pred(node) {
+			removed = append(removed, node)
+		}
+	}
+	sort.Strings(removed)
+	for _, node := range removed {
+		h.clearTTLLocked(node)
+		h.clearDrainLocked(node)
+		h.removeLocked(node)
+	}
+	if len(removed) > 0 {
+		h.settleLocked()
+	}
+	h.lock.Unlock()
+
+	for _, node := range removed {
+		h.nodeRemoved(node)
+	}
+	return len(removed)
+}
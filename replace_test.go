@@ -0,0 +1,106 @@
+package zero
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplaceAll(t *testing.T) {
+	metrics := &countingMetrics{t: t, added: make(map[string]int)}
+	ch := New(WithHistory(10), WithMetrics(metrics))
+	metrics.ring = ch
+	ch.Add("a")
+	ch.Add("b")
+	ch.AddWithWeight("c", 50)
+	events := len(ch.History())
+	metrics.added, metrics.removed = make(map[string]int), nil
+
+	summary := ch.ReplaceAll([]WeightedNode{
+		{Node: "b"},
+		{Node: "c", Weight: TopWeight},
+		{Node: "d", Weight: 200},
+		{Node: ""},
+		{Node: "e", Weight: 10},
+		{Node: "e", Weight: 50},
+	})
+	assert.Equal(t, ReplaceSummary{
+		Added:      []string{"d", "e"},
+		Removed:    []string{"a"},
+		Reweighted: []string{"c"},
+	}, summary)
+	assert.Equal(t, []string{"b", "c", "d", "e"}, ch.Nodes())
+	assert.Equal(t, minReplicas, ch.ReplicaCount("c"))
+	assert.Equal(t, 2*minReplicas, ch.ReplicaCount("d"))
+	assert.Equal(t, minReplicas/2, ch.ReplicaCount("e"))
+	assert.Equal(t, map[string]int{"c": 100, "d": 200, "e": 50}, metrics.added)
+	assert.Equal(t, []string{"a"}, metrics.removed)
+
+	// 所有变更记在同一个版本上
+	history := ch.History()[events:]
+	assert.Equal(t, 4, len(history))
+	for _, event := range history {
+		assert.Equal(t, ch.Epoch(), event.Version)
+	}
+
+	// 结果与逐个添加一致
+	expect := New()
+	expect.Add("b")
+	expect.Add("c")
+	expect.AddWithWeight("d", 200)
+	expect.AddWithWeight("e", 50)
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		want, _ := expect.Get(key)
+		got, _ := ch.Get(key)
+		assert.Equal(t, want, got)
+	}
+}
+
+func TestReplaceAllNoChange(t *testing.T) {
+	ch := New()
+	ch.Add("a")
+	epoch := ch.Epoch()
+	assert.True(t, ch.ReplaceAll([]WeightedNode{{Node: "a"}}).Empty())
+	assert.Equal(t, epoch, ch.Epoch())
+
+	summary := ch.ReplaceAll(nil)
+	assert.Equal(t, []string{"a"}, summary.Removed)
+	assert.Equal(t, 0, ch.Len())
+}
+
+func TestReplaceAllCollision(t *testing.T) {
+	ch := newOverlappingHash(CollisionError)
+	ch.Add("a")
+	summary := ch.ReplaceAll([]WeightedNode{{Node: "a"}, {Node: "b"}})
+	assert.Empty(t, summary.Added)
+	assert.Equal(t, []string{"a"}, ch.Nodes())
+}
+
+func TestRemoveIf(t *testing.T) {
+	ch := New(WithHistory(10))
+	for i := 0; i < 5; i++ {
+		ch.Add("10.0.1." + strconv.Itoa(i))
+		ch.Add("10.0.2." + strconv.Itoa(i))
+	}
+	events := len(ch.History())
+
+	n := ch.RemoveIf(func(node string) bool {
+		return strings.HasPrefix(node, "10.0.1.")
+	})
+	assert.Equal(t, 5, n)
+	assert.Equal(t, 5, ch.Len())
+	for _, node := range ch.Nodes() {
+		assert.True(t, strings.HasPrefix(node, "10.0.2."))
+	}
+	// 所有删除记在同一个版本上
+	for _, event := range ch.History()[events:] {
+		assert.Equal(t, ch.Epoch(), event.Version)
+	}
+
+	epoch := ch.Epoch()
+	assert.Equal(t, 0, ch.RemoveIf(func(string) bool { return false }))
+	assert.Equal(t, epoch, ch.Epoch())
+}
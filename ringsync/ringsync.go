@@ -0,0 +1,227 @@
+// 基于 gRPC 的哈希环分发
+// Leader 持有权威的哈希环，每次拓扑变化后把完整的二进制状态推送给所有 Follower，
+// Follower 在本地应用后得到与 Leader 完全一致的哈希环
+// 消息使用自定义的 gRPC 编解码器，不依赖 protoc 生成的代码
+package ringsync
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"consistenthash"
+	"google.golang.org/grpc"
+)
+
+const (
+	// Follower 断线重连的退避时间范围
+	minBackoff = 100 * time.Millisecond
+	maxBackoff = 30 * time.Second
+)
+
+type (
+	// 权威哈希环的持有者，通过 Register 注册到 gRPC 服务上
+	// 拓扑变更需通过 Leader 的方法进行，直接修改哈希环后需调用 Publish
+	Leader struct {
+		ring *zero.ConsistentHash
+		// 进程级标识，Leader 重启后 Follower 据此丢弃旧的版本号
+		epoch uint64
+		lock  sync.Mutex
+		// 已发布状态的版本号和编码
+		version uint64
+		state   []byte
+		// 发布新状态时关闭并替换
+		changed chan struct{}
+	}
+
+	// 从 Leader 同步哈希环的一方
+	Follower struct {
+		ring  *zero.ConsistentHash
+		lock  sync.RWMutex
+		epoch uint64
+		// 已应用的版本号，0 表示尚未同步
+		version uint64
+	}
+)
+
+// 创建 Leader，ring 的当前状态作为第一个版本
+func NewLeader(ring *zero.ConsistentHash) *Leader {
+	return &Leader{
+		ring:    ring,
+		epoch:   uint64(time.Now().UnixNano()),
+		version: 1,
+		state:   ring.EncodeBinary(),
+		changed: make(chan struct{}),
+	}
+}
+
+// 在 gRPC 服务上注册同步服务
+func (l *Leader) Register(s grpc.ServiceRegistrar) {
+	s.RegisterService(&serviceDesc, l)
+}
+
+// 权威的哈希环
+func (l *Leader) Ring() *zero.ConsistentHash {
+	return l.ring
+}
+
+// 添加节点并推送
+func (l *Leader) Add(node string) {
+	l.ring.Add(node)
+	l.Publish()
+}
+
+// 按权重添加节点并推送
+func (l *Leader) AddWithWeight(node string, weight int) {
+	l.ring.AddWithWeight(node, weight)
+	l.Publish()
+}
+
+// 删除节点并推送
+func (l *Leader) Remove(node string) {
+	l.ring.Remove(node)
+	l.Publish()
+}
+
+// 用快照替换成员并推送
+func (l *Leader) Restore(s zero.Snapshot) {
+	l.ring.Restore(s)
+	l.Publish()
+}
+
+// 把哈希环的当前状态推送给所有 Follower，状态未变化时不产生新版本
+// 返回已发布的版本号
+func (l *Leader) Publish() uint64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	// 持有锁时编码，并发发布时较早的状态不会覆盖较新的状态
+	state := l.ring.EncodeBinary()
+	if bytes.Equal(state, l.state) {
+		return l.version
+	}
+	l.version++
+	l.state = state
+	close(l.changed)
+	l.changed = make(chan struct{})
+	return l.version
+}
+
+// 已发布的版本号
+func (l *Leader) Version() uint64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.version
+}
+
+// 当前状态及其变化通知
+func (l *Leader) current() (update, <-chan struct{}) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return update{Epoch: l.epoch, Version: l.version, Ring: l.state}, l.changed
+}
+
+// 向一个 Follower 推送状态，连续的变化只推送最新的状态
+func (l *Leader) watch(req *watchRequest, stream grpc.ServerStream) error {
+	ctx := stream.Context()
+	for {
+		u, changed := l.current()
+		if req.Epoch != u.Epoch || req.Version < u.Version {
+			if err := stream.SendMsg(&u); err != nil {
+				return err
+			}
+			req.Epoch, req.Version = u.Epoch, u.Version
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// 创建 Follower，收到的状态会替换 ring 的成员
+func NewFollower(ring *zero.ConsistentHash) *Follower {
+	return &Follower{ring: ring}
+}
+
+// 本地的哈希环
+func (f *Follower) Ring() *zero.ConsistentHash {
+	return f.ring
+}
+
+// 已应用的版本号，0 表示尚未同步
+func (f *Follower) Version() uint64 {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	return f.version
+}
+
+// 持续从 Leader 同步，断线后按指数退避重连，直到 ctx 结束
+func (f *Follower) Run(ctx context.Context, cc grpc.ClientConnInterface) error {
+	backoff := minBackoff
+	for {
+		// 流中断的原因不影响重连策略
+		if applied, _ := f.watch(ctx, cc); applied {
+			backoff = minBackoff
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// 建立一次推送流并应用收到的状态，applied 表示至少应用了一个版本
+func (f *Follower) watch(ctx context.Context, cc grpc.ClientConnInterface) (applied bool, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := cc.NewStream(ctx, &serviceDesc.Streams[0], watchMethod, grpc.CallContentSubtype(codecName))
+	if err != nil {
+		return false, err
+	}
+	f.lock.RLock()
+	req := watchRequest{Epoch: f.epoch, Version: f.version}
+	f.lock.RUnlock()
+	if err := stream.SendMsg(&req); err != nil {
+		return false, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return false, err
+	}
+
+	for {
+		var u update
+		if err := stream.RecvMsg(&u); err != nil {
+			return applied, err
+		}
+		if err := f.apply(u); err != nil {
+			return applied, err
+		}
+		applied = true
+	}
+}
+
+func (f *Follower) apply(u update) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if u.Epoch == f.epoch && u.Version <= f.version {
+		return nil
+	}
+	if err := f.ring.DecodeBinary(u.Ring); err != nil {
+		return err
+	}
+	f.epoch, f.version = u.Epoch, u.Version
+	return nil
+}
@@ -0,0 +1,176 @@
+package ringsync
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"consistenthash"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// 客户端总是连接到最近一次启动的服务
+type listener struct {
+	current atomic.Pointer[bufconn.Listener]
+}
+
+func (l *listener) serve(t *testing.T, leader *Leader) *grpc.Server {
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	leader.Register(server)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	l.current.Store(lis)
+	return server
+}
+
+func (l *listener) dial(t *testing.T) *grpc.ClientConn {
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return l.current.Load().DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.Nil(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func follow(t *testing.T, conn *grpc.ClientConn) *Follower {
+	f := NewFollower(zero.NewConsistentHash())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- f.Run(ctx, conn) }()
+	t.Cleanup(func() {
+		cancel()
+		assert.Equal(t, context.Canceled, <-done)
+	})
+	return f
+}
+
+func assertSynced(t *testing.T, leader *Leader, followers ...*Follower) {
+	t.Helper()
+	for _, f := range followers {
+		assert.Eventually(t, func() bool {
+			return f.Version() == leader.Version()
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(t, leader.Ring().EncodeBinary(), f.Ring().EncodeBinary())
+		for i := 0; i < 100; i++ {
+			key := strconv.Itoa(i)
+			expect, _ := leader.Ring().Get(key)
+			actual, _ := f.Ring().Get(key)
+			assert.Equal(t, expect, actual)
+		}
+	}
+}
+
+func TestLeaderFollower(t *testing.T) {
+	ring := zero.NewConsistentHash()
+	ring.Add("10.0.0.1:6379")
+	leader := NewLeader(ring)
+	var lis listener
+	lis.serve(t, leader)
+	conn := lis.dial(t)
+
+	first, second := follow(t, conn), follow(t, conn)
+	assertSynced(t, leader, first, second)
+
+	leader.Add("10.0.0.2:6379")
+	leader.AddWithWeight("10.0.0.3:6379", 50)
+	leader.Remove("10.0.0.1:6379")
+	assertSynced(t, leader, first, second)
+	assert.Equal(t, []string{"10.0.0.2:6379", "10.0.0.3:6379"}, first.Ring().Nodes())
+
+	// 状态未变化时不产生新版本
+	version := leader.Version()
+	assert.Equal(t, version, leader.Publish())
+
+	// 直接修改哈希环后需要手动发布
+	ring.Pin("hot", "10.0.0.2:6379")
+	assert.Equal(t, version+1, leader.Publish())
+	assertSynced(t, leader, first, second)
+	node, _ := second.Ring().Get("hot")
+	assert.Equal(t, "10.0.0.2:6379", node)
+}
+
+func TestLeaderConcurrentPublish(t *testing.T) {
+	ring := zero.NewConsistentHash()
+	ring.Add("10.0.0.1:6379")
+	leader := NewLeader(ring)
+
+	// 发布等待锁期间哈希环发生变化，发布的必须是变化后的状态
+	leader.lock.Lock()
+	done := make(chan uint64)
+	go func() { done <- leader.Publish() }()
+	time.Sleep(10 * time.Millisecond)
+	ring.Add("10.0.0.2:6379")
+	leader.lock.Unlock()
+	assert.Equal(t, uint64(2), <-done)
+	u, _ := leader.current()
+	assert.Equal(t, ring.EncodeBinary(), u.Ring)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			leader.Add("10.0.1." + strconv.Itoa(i) + ":6379")
+		}()
+	}
+	wg.Wait()
+	u, _ = leader.current()
+	assert.Equal(t, ring.EncodeBinary(), u.Ring)
+	assert.Equal(t, 18, len(ring.Nodes()))
+}
+
+func TestFollowerReconnect(t *testing.T) {
+	ring := zero.NewConsistentHash()
+	ring.Add("10.0.0.1:6379")
+	leader := NewLeader(ring)
+	var lis listener
+	server := lis.serve(t, leader)
+	conn := lis.dial(t)
+	f := follow(t, conn)
+	leader.Add("10.0.0.2:6379")
+	leader.Add("10.0.0.3:6379")
+	assertSynced(t, leader, f)
+
+	// Leader 重启后版本号从头开始，Follower 仍然接受新的状态
+	server.Stop()
+	restarted := NewLeader(zero.NewConsistentHash())
+	restarted.Add("10.0.0.7:6379")
+	for restarted.epoch == leader.epoch {
+		restarted.epoch++
+	}
+	assert.Less(t, restarted.Version(), f.Version())
+	lis.serve(t, restarted)
+
+	assertSynced(t, restarted, f)
+	assert.Equal(t, []string{"10.0.0.7:6379"}, f.Ring().Nodes())
+}
+
+func TestCodec(t *testing.T) {
+	c := codec{}
+	data, err := c.Marshal(&update{Epoch: 7, Version: 3, Ring: []byte("ring")})
+	assert.Nil(t, err)
+	var u update
+	assert.Nil(t, c.Unmarshal(data, &u))
+	assert.Equal(t, update{Epoch: 7, Version: 3, Ring: []byte("ring")}, u)
+
+	data, err = c.Marshal(&watchRequest{Epoch: 1, Version: 2})
+	assert.Nil(t, err)
+	var req watchRequest
+	assert.Nil(t, c.Unmarshal(data, &req))
+	assert.Equal(t, watchRequest{Epoch: 1, Version: 2}, req)
+
+	assert.Equal(t, errBadMessage, c.Unmarshal(append(data, 0), &req))
+	assert.Equal(t, errBadMessage, c.Unmarshal(nil, &u))
+	_, err = c.Marshal("other")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,111 @@
+package ringsync
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+const (
+	serviceName = "consistenthash.ringsync.RingSync"
+	watchMethod = "/" + serviceName + "/Watch"
+	// 客户端通过 content-subtype 选择编解码器，服务端按名称自动匹配
+	codecName = "ringsync"
+)
+
+// 消息格式错误
+var errBadMessage = errors.New("ringsync: malformed message")
+
+type (
+	// Follower 已有的状态，Leader 只推送更新的版本
+	watchRequest struct {
+		Epoch   uint64
+		Version uint64
+	}
+
+	// 一个版本的完整状态，Ring 为 EncodeBinary 的结果
+	update struct {
+		Epoch   uint64
+		Version uint64
+		Ring    []byte
+	}
+
+	// 注册服务时校验实现的类型
+	syncServer interface {
+		watch(*watchRequest, grpc.ServerStream) error
+	}
+
+	// 两个 uvarint 之后跟随可选的哈希环状态
+	codec struct{}
+)
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*syncServer)(nil),
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Watch",
+		Handler:       watchHandler,
+		ServerStreams: true,
+	}},
+}
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+func watchHandler(srv any, stream grpc.ServerStream) error {
+	var req watchRequest
+	if err := stream.RecvMsg(&req); err != nil {
+		return err
+	}
+	return srv.(syncServer).watch(&req, stream)
+}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	switch m := v.(type) {
+	case *watchRequest:
+		buf := binary.AppendUvarint(nil, m.Epoch)
+		return binary.AppendUvarint(buf, m.Version), nil
+	case *update:
+		buf := binary.AppendUvarint(make([]byte, 0, len(m.Ring)+20), m.Epoch)
+		buf = binary.AppendUvarint(buf, m.Version)
+		return append(buf, m.Ring...), nil
+	default:
+		return nil, fmt.Errorf("ringsync: unexpected message type %T", v)
+	}
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	epoch, n := binary.Uvarint(data)
+	if n <= 0 {
+		return errBadMessage
+	}
+	data = data[n:]
+	version, n := binary.Uvarint(data)
+	if n <= 0 {
+		return errBadMessage
+	}
+	data = data[n:]
+
+	switch m := v.(type) {
+	case *watchRequest:
+		if len(data) > 0 {
+			return errBadMessage
+		}
+		m.Epoch, m.Version = epoch, version
+	case *update:
+		m.Epoch, m.Version = epoch, version
+		// data 属于 gRPC 的缓冲区，需要复制
+		m.Ring = append([]byte(nil), data...)
+	default:
+		return fmt.Errorf("ringsync: unexpected message type %T", v)
+	}
+	return nil
+}
+
+func (codec) Name() string {
+	return codecName
+}
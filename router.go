@@ -0,0 +1,129 @@
+package zero
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// 未注册的算法名称
+var ErrUnknownRouter = errors.New("consistenthash: unknown router")
+
+type (
+	// 各种一致性哈希算法的公共接口，应用可以通过配置选择算法而不修改代码
+	Router interface {
+		Get(key string) (interface{}, bool)
+		Add(node string)
+		Remove(node string)
+		// 当前所有物理节点，按字典序排列
+		Nodes() []string
+	}
+
+	// 创建 Router 的参数，各算法只使用与自己相关的字段，为零值时使用算法的默认值
+	RouterOptions struct {
+		// 哈希函数
+		HashFunc Func
+		// 虚拟节点放大因子（ring），小于默认值时使用默认值
+		Replicas int
+		// 查找表大小（maglev）
+		TableSize int
+		// 探针数量（multiprobe）
+		Probes int
+		// 桶的容量（anchor），为0时为1024
+		Capacity int
+		// 分区数量（partition），为0时为1024
+		Partitions int
+		// 子环数量（sharded），为0时为16
+		Shards int
+	}
+
+	// 按参数创建 Router
+	RouterFactory func(opts RouterOptions) Router
+)
+
+const (
+	defaultRouterCapacity   = 1 << 10
+	defaultRouterPartitions = 1 << 10
+	defaultRouterShards     = 16
+)
+
+var (
+	_ Router = (*ConsistentHash)(nil)
+	_ Router = (*MaglevHash)(nil)
+	_ Router = (*RendezvousHash)(nil)
+	_ Router = (*MultiProbeHash)(nil)
+	_ Router = (*AnchorHash)(nil)
+	_ Router = (*JumpHash)(nil)
+	_ Router = (*PartitionRing)(nil)
+	_ Router = (*ShardedConsistentHash)(nil)
+
+	routersLock sync.RWMutex
+	routers     = map[string]RouterFactory{
+		"ring": func(opts RouterOptions) Router {
+			return NewCustomConsistentHash(opts.Replicas, opts.HashFunc)
+		},
+		"ketama": func(RouterOptions) Router {
+			return NewKetamaHash()
+		},
+		"maglev": func(opts RouterOptions) Router {
+			return NewCustomMaglevHash(opts.TableSize, opts.HashFunc)
+		},
+		"rendezvous": func(opts RouterOptions) Router {
+			return NewCustomRendezvousHash(opts.HashFunc)
+		},
+		"multiprobe": func(opts RouterOptions) Router {
+			return NewCustomMultiProbeHash(opts.Probes, opts.HashFunc)
+		},
+		"anchor": func(opts RouterOptions) Router {
+			return NewCustomAnchorHash(orDefault(opts.Capacity, defaultRouterCapacity), opts.HashFunc)
+		},
+		"jump": func(opts RouterOptions) Router {
+			return NewCustomJumpHash(opts.HashFunc)
+		},
+		"partition": func(opts RouterOptions) Router {
+			return NewCustomPartitionRing(orDefault(opts.Partitions, defaultRouterPartitions), opts.HashFunc)
+		},
+		"sharded": func(opts RouterOptions) Router {
+			return NewShardedConsistentHash(orDefault(opts.Shards, defaultRouterShards))
+		},
+	}
+)
+
+// 按名称创建 Router，内置的算法有 ring、ketama、maglev、rendezvous、multiprobe、anchor、jump、partition 和 sharded
+// 名称未注册时返回 ErrUnknownRouter
+func NewRouter(name string, opts RouterOptions) (Router, error) {
+	routersLock.RLock()
+	factory, ok := routers[name]
+	routersLock.RUnlock()
+	if !ok {
+		return nil, ErrUnknownRouter
+	}
+	return factory(opts), nil
+}
+
+// 注册自定义算法，同名时覆盖原有的算法
+func RegisterRouter(name string, factory RouterFactory) {
+	routersLock.Lock()
+	defer routersLock.Unlock()
+	routers[name] = factory
+}
+
+// 已注册的算法名称，按字典序排列
+func Routers() []string {
+	routersLock.RLock()
+	defer routersLock.RUnlock()
+
+	names := make([]string, 0, len(routers))
+	for name := range routers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func orDefault(v, def int) int {
+	if v > 0 {
+		return v
+	}
+	return def
+}
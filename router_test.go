@@ -0,0 +1,53 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRouter(t *testing.T) {
+	for _, name := range Routers() {
+		t.Run(name, func(t *testing.T) {
+			r, err := NewRouter(name, RouterOptions{})
+			assert.Nil(t, err)
+			r.Add("a")
+			r.Add("b")
+			r.Add("c")
+			r.Remove("b")
+			assert.Equal(t, []string{"a", "c"}, r.Nodes())
+			for i := 0; i < 100; i++ {
+				node, ok := r.Get(strconv.Itoa(i))
+				assert.True(t, ok)
+				assert.Contains(t, []interface{}{"a", "c"}, node)
+			}
+		})
+	}
+
+	_, err := NewRouter("missing", RouterOptions{})
+	assert.Equal(t, ErrUnknownRouter, err)
+}
+
+func TestNewRouterOptions(t *testing.T) {
+	r, _ := NewRouter("maglev", RouterOptions{TableSize: 100})
+	assert.Equal(t, uint64(101), r.(*MaglevHash).size)
+	r, _ = NewRouter("ring", RouterOptions{Replicas: 200})
+	assert.Equal(t, 200, r.(*ConsistentHash).replicas)
+}
+
+func TestRegisterRouter(t *testing.T) {
+	RegisterRouter("custom", func(opts RouterOptions) Router {
+		return NewCustomRendezvousHash(opts.HashFunc)
+	})
+	defer func() {
+		routersLock.Lock()
+		delete(routers, "custom")
+		routersLock.Unlock()
+	}()
+
+	assert.Contains(t, Routers(), "custom")
+	r, err := NewRouter("custom", RouterOptions{})
+	assert.Nil(t, err)
+	assert.IsType(t, &RendezvousHash{}, r)
+}
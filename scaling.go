@@ -0,0 +1,32 @@
+package zero
+
+// 放大因子偏离目标超过该比例时才重新缩放，避免每次成员变化都重建整个环
+const scaleTolerance = 4
+
+// 按集群规模缩放虚拟节点：放大因子保持在 max(minPoints, targetTotalPoints/节点数) 附近，
+// 集群扩大时自动减少每个节点的虚拟节点，使内存和查找开销不随节点数线性增长
+// 放大因子偏离目标超过 1/scaleTolerance 时才按新的放大因子等比例重建，各节点之间的权重比例保持不变
+// targetTotalPoints 不大于0时关闭，minPoints 至少为1
+func WithReplicaScaling(minPoints, targetTotalPoints int) Option {
+	return func(h *ConsistentHash) {
+		h.targetPoints = max(targetTotalPoints, 0)
+		h.minPoints = max(minPoints, 1)
+	}
+}
+
+// 成员变化后按集群规模调整放大因子
+// 调用方需持有写锁
+func (h *ConsistentHash) scaleReplicasLocked() {
+	if h.targetPoints <= 0 || len(h.nodes) == 0 {
+		return
+	}
+
+	target := max(h.minPoints, h.targetPoints/len(h.nodes))
+	diff := h.replicas - target
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff*scaleTolerance > target {
+		h.rescale(target)
+	}
+}
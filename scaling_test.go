@@ -0,0 +1,51 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplicaScaling(t *testing.T) {
+	ch := New(WithReplicaScaling(10, 1000))
+	total := func() int {
+		var n int
+		for _, node := range ch.Nodes() {
+			n += ch.ReplicaCount(node)
+		}
+		return n
+	}
+
+	ch.Add("node0")
+	assert.Equal(t, 1000, ch.ReplicaCount("node0"))
+	for i := 1; i < 100; i++ {
+		ch.Add("node" + strconv.Itoa(i))
+		// 总数保持在目标附近
+		assert.InDelta(t, 1000, total(), 1000/scaleTolerance+float64(ch.Len()))
+	}
+	for _, node := range ch.Nodes() {
+		assert.Equal(t, ch.replicas, ch.ReplicaCount(node))
+	}
+
+	// 不低于每个节点的下限
+	for i := 100; i < 200; i++ {
+		ch.Add("node" + strconv.Itoa(i))
+	}
+	assert.InDelta(t, 10, ch.ReplicaCount("node0"), 10/scaleTolerance)
+
+	// 集群缩小时恢复
+	for i := 3; i < 200; i++ {
+		ch.Remove("node" + strconv.Itoa(i))
+	}
+	assert.InDelta(t, 333, ch.ReplicaCount("node0"), 333/scaleTolerance)
+}
+
+func TestReplicaScalingWeights(t *testing.T) {
+	ch := New(WithReplicaScaling(1, 1000))
+	ch.Add("a")
+	ch.AddWithWeight("half", 50)
+	// 缩放后保持权重比例
+	assert.Equal(t, ch.ReplicaCount("a")/2, ch.ReplicaCount("half"))
+	assert.Equal(t, 1000, ch.Clone().targetPoints)
+}
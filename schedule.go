@@ -0,0 +1,120 @@
+package zero
+
+import (
+	"sort"
+	"time"
+)
+
+type (
+	// 计划在指定时刻生效的拓扑变更
+	ScheduledChange struct {
+		Node string
+		// TopologyAdd 或 TopologyRemove
+		Op TopologyOp
+		At time.Time
+	}
+
+	scheduleEntry struct {
+		change ScheduledChange
+		timer  Timer
+	}
+)
+
+// 计划在 at 时刻添加节点，at 不晚于当前时间时立即添加
+// 各进程的时钟同步时，同样的计划使所有进程的哈希环在同一时刻切换，可用于编排维护窗口
+// 每个节点只保留最后一次计划，计划不属于拓扑，不随 Clone、Snapshot 复制
+func (h *ConsistentHash) ScheduleAdd(node string, at time.Time) {
+	h.schedule(ScheduledChange{Node: node, Op: TopologyAdd, At: at})
+}
+
+// 计划在 at 时刻删除节点，at 不晚于当前时间时立即删除
+func (h *ConsistentHash) ScheduleRemove(node string, at time.Time) {
+	h.schedule(ScheduledChange{Node: node, Op: TopologyRemove, At: at})
+}
+
+// 取消节点尚未生效的计划，没有计划时返回 false
+func (h *ConsistentHash) CancelScheduled(node string) bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	entry, ok := h.schedules[node]
+	if ok {
+		entry.timer.Stop()
+		delete(h.schedules, node)
+	}
+	return ok
+}
+
+// 尚未生效的计划，按生效时间升序排列
+func (h *ConsistentHash) Scheduled() []ScheduledChange {
+	h.lock.RLock()
+	changes := make([]ScheduledChange, 0, len(h.schedules))
+	for _, entry := range h.schedules {
+		changes = append(changes, entry.change)
+	}
+	h.lock.RUnlock()
+
+	sort.Slice(changes, func(i, j int) bool {
+		if !changes[i].At.Equal(changes[j].At) {
+			return changes[i].At.Before(changes[j].At)
+		}
+		return changes[i].Node < changes[j].Node
+	})
+	return changes
+}
+
+func (h *ConsistentHash) schedule(change ScheduledChange) {
+	h.lock.Lock()
+	if entry, ok := h.schedules[change.Node]; ok {
+		entry.timer.Stop()
+		delete(h.schedules, change.Node)
+	}
+	wait := change.At.Sub(h.clock.Now())
+	if wait <= 0 {
+		h.lock.Unlock()
+		h.applyScheduled(change)
+		return
+	}
+
+	entry := &scheduleEntry{change: change}
+	h.scheduleChangeLocked(entry, wait)
+	if h.schedules == nil {
+		h.schedules = make(map[string]*scheduleEntry)
+	}
+	h.schedules[change.Node] = entry
+	h.lock.Unlock()
+}
+
+// 调用方需持有写锁
+func (h *ConsistentHash) scheduleChangeLocked(entry *scheduleEntry, wait time.Duration) {
+	entry.timer = h.clock.AfterFunc(wait, func() {
+		h.fireScheduled(entry)
+	})
+}
+
+// 到期检查，定时器早于计划时刻触发时重新计时
+func (h *ConsistentHash) fireScheduled(entry *scheduleEntry) {
+	h.lock.Lock()
+	// 计划已取消或被替换
+	if h.schedules[entry.change.Node] != entry {
+		h.lock.Unlock()
+		return
+	}
+	if wait := entry.change.At.Sub(h.clock.Now()); wait > 0 {
+		h.scheduleChangeLocked(entry, wait)
+		h.lock.Unlock()
+		return
+	}
+	delete(h.schedules, entry.change.Node)
+	h.lock.Unlock()
+
+	h.applyScheduled(entry.change)
+}
+
+func (h *ConsistentHash) applyScheduled(change ScheduledChange) {
+	if change.Op == TopologyRemove {
+		h.Remove(change.Node)
+	} else {
+		h.Add(change.Node)
+	}
+}
@@ -0,0 +1,62 @@
+package zero
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedule(t *testing.T) {
+	clock := newFakeClock()
+	ch := New()
+	ch.clock = clock
+	ch.Add("a")
+
+	start := clock.Now()
+	ch.ScheduleAdd("b", start.Add(time.Minute))
+	ch.ScheduleRemove("a", start.Add(2*time.Minute))
+	assert.Equal(t, []ScheduledChange{
+		{Node: "b", Op: TopologyAdd, At: start.Add(time.Minute)},
+		{Node: "a", Op: TopologyRemove, At: start.Add(2 * time.Minute)},
+	}, ch.Scheduled())
+
+	clock.Advance(time.Minute - time.Second)
+	assert.ElementsMatch(t, []string{"a"}, ch.Nodes())
+	clock.Advance(time.Second)
+	assert.ElementsMatch(t, []string{"a", "b"}, ch.Nodes())
+	clock.Advance(time.Minute)
+	assert.ElementsMatch(t, []string{"b"}, ch.Nodes())
+	assert.Empty(t, ch.Scheduled())
+}
+
+func TestScheduleReplaceAndCancel(t *testing.T) {
+	clock := newFakeClock()
+	ch := New()
+	ch.clock = clock
+	start := clock.Now()
+
+	// 同一节点只保留最后一次计划
+	ch.ScheduleAdd("a", start.Add(time.Minute))
+	ch.ScheduleAdd("a", start.Add(time.Hour))
+	assert.Equal(t, 1, len(ch.Scheduled()))
+	clock.Advance(time.Minute)
+	assert.Empty(t, ch.Nodes())
+
+	assert.True(t, ch.CancelScheduled("a"))
+	assert.False(t, ch.CancelScheduled("a"))
+	clock.Advance(time.Hour)
+	assert.Empty(t, ch.Nodes())
+
+	// 已经到达的时刻立即生效
+	ch.ScheduleAdd("b", start)
+	assert.ElementsMatch(t, []string{"b"}, ch.Nodes())
+	assert.Empty(t, ch.Scheduled())
+
+	// 计划不随副本复制
+	ch.ScheduleRemove("b", clock.Now().Add(time.Minute))
+	c := ch.Clone()
+	clock.Advance(time.Minute)
+	assert.Empty(t, ch.Nodes())
+	assert.ElementsMatch(t, []string{"b"}, c.Nodes())
+}
@@ -0,0 +1,108 @@
+package zero
+
+import "math"
+
+// 哈希空间上的闭区间 [Start, End]
+type Range struct {
+	Start uint64
+	End   uint64
+}
+
+// 在读锁下按哈希值从小到大遍历连续的哈希区间及其所属节点
+// 区间为闭区间 [start, end]，相邻且属于同一节点的区间会被合并，跨越0点的区间拆成首尾两段
+// 冲突链上的多个节点按键分摊同一区间，这里只给出链上的第一个节点
+// fn 返回 false 时停止遍历
+func (h *ConsistentHash) ForEachSegment(fn func(start, end uint64, node string) bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	h.forEachSegment(fn)
+}
+
+// 调用方需持有读锁
+func (h *ConsistentHash) forEachSegment(fn func(start, end uint64, node string) bool) {
+	if len(h.ring) == 0 {
+		return
+	}
+
+	first := h.ring[h.successorPoint(0)][0].(string)
+	var start, end uint64
+	owner := first
+	started := false
+	if !h.ascend(0, func(hash uint64) bool {
+		// 重复的虚拟节点不产生新的区间
+		if started && hash == end {
+			return true
+		}
+
+		node := h.ring[hash][0].(string)
+		if !started {
+			started = true
+			end = hash
+			return true
+		}
+		if node == owner {
+			end = hash
+			return true
+		}
+		if !fn(start, end, owner) {
+			return false
+		}
+		start, end, owner = end+1, hash, node
+		return true
+	}) {
+		return
+	}
+
+	// 最后一个虚拟节点之后的区间属于第一个虚拟节点
+	if end == math.MaxUint64 {
+		fn(start, end, owner)
+		return
+	}
+	if owner == first {
+		fn(start, math.MaxUint64, owner)
+		return
+	}
+	if fn(start, end, owner) {
+		fn(end+1, math.MaxUint64, first)
+	}
+}
+
+// 哈希值顺时针方向的第一个虚拟节点（含自身位置）所属的节点
+func (h *ConsistentHash) Successor(hash uint64) (string, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if len(h.ring) == 0 {
+		return "", false
+	}
+
+	return h.ring[h.successorPoint(hash)][0].(string), true
+}
+
+// 哈希值逆时针方向的第一个虚拟节点（不含自身位置）所属的节点
+func (h *ConsistentHash) Predecessor(hash uint64) (string, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if len(h.ring) == 0 {
+		return "", false
+	}
+
+	return h.ring[h.predecessorPoint(hash)][0].(string), true
+}
+
+// 节点负责的所有哈希区间，按起点排序
+func (h *ConsistentHash) OwnedRanges(node string) []Range {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	var ranges []Range
+	h.forEachSegment(func(start, end uint64, owner string) bool {
+		if owner == node {
+			ranges = append(ranges, Range{Start: start, End: end})
+		}
+		return true
+	})
+	return ranges
+}
@@ -0,0 +1,111 @@
+package zero
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsistentHash_ForEachSegment(t *testing.T) {
+	ch := NewConsistentHash()
+	ch.ForEachSegment(func(start, end uint64, node string) bool {
+		t.Fatal("unexpected segment")
+		return true
+	})
+
+	for i := 0; i < keySize; i++ {
+		ch.Add("10.0.0." + strconv.Itoa(i) + ":6379")
+	}
+
+	var next uint64
+	var last string
+	var count int
+	ch.ForEachSegment(func(start, end uint64, node string) bool {
+		// 区间首尾相接，覆盖整个哈希空间
+		assert.Equal(t, next, start)
+		assert.True(t, start <= end)
+		if count > 0 {
+			assert.NotEqual(t, last, node)
+		}
+
+		// 区间内的哈希值都属于该节点
+		for _, hash := range []uint64{start, end, start + (end-start)/2} {
+			owner, ok := ch.GetHash(hash)
+			assert.True(t, ok)
+			assert.Equal(t, node, owner)
+		}
+
+		next, last = end+1, node
+		count++
+		return end != math.MaxUint64
+	})
+	assert.Equal(t, uint64(0), next)
+	assert.True(t, count > keySize)
+
+	count = 0
+	ch.ForEachSegment(func(start, end uint64, node string) bool {
+		count++
+		return count < 3
+	})
+	assert.Equal(t, 3, count)
+}
+
+func TestConsistentHash_ForEachSegmentSingleNode(t *testing.T) {
+	ch := NewConsistentHash()
+	ch.Add("first")
+
+	var segments [][2]uint64
+	ch.ForEachSegment(func(start, end uint64, node string) bool {
+		assert.Equal(t, "first", node)
+		segments = append(segments, [2]uint64{start, end})
+		return true
+	})
+	assert.Equal(t, [][2]uint64{{0, math.MaxUint64}}, segments)
+}
+
+func TestConsistentHash_SuccessorPredecessor(t *testing.T) {
+	ch := NewConsistentHash()
+	_, ok := ch.Successor(0)
+	assert.False(t, ok)
+	_, ok = ch.Predecessor(0)
+	assert.False(t, ok)
+
+	ch.Add("first")
+	ch.Add("second")
+
+	first, last := ch.keys[0], ch.keys[len(ch.keys)-1]
+	node, ok := ch.Successor(first)
+	assert.True(t, ok)
+	assert.Equal(t, ch.ring[first][0], node)
+	node, _ = ch.Successor(last + 1)
+	assert.Equal(t, ch.ring[first][0], node)
+
+	node, ok = ch.Predecessor(first)
+	assert.True(t, ok)
+	assert.Equal(t, ch.ring[last][0], node)
+	node, _ = ch.Predecessor(ch.keys[1])
+	assert.Equal(t, ch.ring[first][0], node)
+}
+
+func TestConsistentHash_OwnedRanges(t *testing.T) {
+	ch := NewConsistentHash()
+	assert.Empty(t, ch.OwnedRanges("first"))
+
+	ch.Add("first")
+	ch.Add("second")
+
+	var total float64
+	for _, node := range []string{"first", "second"} {
+		for _, r := range ch.OwnedRanges(node) {
+			owner, _ := ch.GetHash(r.Start)
+			assert.Equal(t, node, owner)
+			owner, _ = ch.GetHash(r.End)
+			assert.Equal(t, node, owner)
+			total += float64(r.End-r.Start) + 1
+		}
+	}
+	assert.InDelta(t, 1, total/(1<<64), 1e-9)
+	assert.Empty(t, ch.OwnedRanges("unknown"))
+}
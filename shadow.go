@@ -0,0 +1,70 @@
+package zero
+
+import "sync/atomic"
+
+type (
+	// 可选的影子对比指标，Metrics 同时实现该接口时上报
+	ShadowMetrics interface {
+		// 一次采样对比，diverged 表示影子环选出了不同的节点
+		ShadowLookup(diverged bool)
+	}
+
+	// 影子对比的累计结果
+	ShadowStats struct {
+		// 采样对比的次数
+		Sampled uint64
+		// 结果不一致的次数
+		Diverged uint64
+	}
+
+	// 影子环及其累计结果
+	shadowState struct {
+		ring     *ConsistentHash
+		fraction float64
+		sampled  atomic.Uint64
+		diverged atomic.Uint64
+	}
+)
+
+// 开启影子对比：Get 按 sampleFraction 的比例抽样，在 other 上再查找一次并统计结果不一致的比例
+// 用于在更换哈希函数或算法之前，用线上流量评估迁移量；other 为 nil 或比例不为正数时关闭
+// 抽样使用 WithRand 指定的随机数来源
+// 影子查找在主查找之后进行，不影响 Get 的返回值，也不触发 other 的指标和跟踪回调
+// 重新开启时累计结果清零
+func (h *ConsistentHash) ShadowCompare(other *ConsistentHash, sampleFraction float64) {
+	if other == nil || !(sampleFraction > 0) {
+		h.shadow.Store(nil)
+		return
+	}
+	h.shadow.Store(&shadowState{ring: other, fraction: min(sampleFraction, 1)})
+}
+
+// 当前影子对比的累计结果，未开启时为零值
+func (h *ConsistentHash) ShadowStats() ShadowStats {
+	s := h.shadow.Load()
+	if s == nil {
+		return ShadowStats{}
+	}
+	return ShadowStats{
+		Sampled:  s.sampled.Load(),
+		Diverged: s.diverged.Load(),
+	}
+}
+
+// 抽样在影子环上查找 v，并与主查找的结果 node 比较
+func (h *ConsistentHash) compareShadow(v string, node interface{}) {
+	s := h.shadow.Load()
+	if s == nil || (s.fraction < 1 && h.rand.Float64() >= s.fraction) {
+		return
+	}
+
+	shadow, _ := s.ring.get(v)
+	diverged := shadow != node
+	s.sampled.Add(1)
+	if diverged {
+		s.diverged.Add(1)
+	}
+	if m, ok := h.metrics.(ShadowMetrics); ok {
+		m.ShadowLookup(diverged)
+	}
+}
@@ -0,0 +1,57 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"consistenthash/hashes"
+	"github.com/stretchr/testify/assert"
+)
+
+type shadowMetrics struct {
+	countingMetrics
+	diverged int
+}
+
+func (m *shadowMetrics) ShadowLookup(diverged bool) {
+	if diverged {
+		m.diverged++
+	}
+}
+
+func TestShadowCompare(t *testing.T) {
+	metrics := &shadowMetrics{countingMetrics: countingMetrics{t: t, added: make(map[string]int)}}
+	ch := New(WithMetrics(metrics))
+	metrics.ring = ch
+	shadow := New(WithHashFunc(hashes.XXHash64))
+	for i := 0; i < 5; i++ {
+		node := "node" + strconv.Itoa(i)
+		ch.Add(node)
+		shadow.Add(node)
+	}
+
+	ch.ShadowCompare(shadow, 1)
+	for i := 0; i < 10000; i++ {
+		ch.Get(strconv.Itoa(i))
+	}
+	stats := ch.ShadowStats()
+	assert.Equal(t, uint64(10000), stats.Sampled)
+	// 更换哈希函数后约 4/5 的键改变节点
+	assert.InDelta(t, 0.8, float64(stats.Diverged)/float64(stats.Sampled), 0.05)
+	assert.Equal(t, int(stats.Diverged), metrics.diverged)
+	// 影子环不受影响
+	assert.Equal(t, ShadowStats{}, shadow.ShadowStats())
+
+	// 相同的环没有分歧
+	ch.ShadowCompare(ch.Clone(), 0.5)
+	for i := 0; i < 10000; i++ {
+		ch.Get(strconv.Itoa(i))
+	}
+	stats = ch.ShadowStats()
+	assert.InDelta(t, 5000, float64(stats.Sampled), 500)
+	assert.Equal(t, uint64(0), stats.Diverged)
+
+	ch.ShadowCompare(nil, 1)
+	ch.Get("key")
+	assert.Equal(t, ShadowStats{}, ch.ShadowStats())
+}
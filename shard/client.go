@@ -0,0 +1,208 @@
+// 基于一致性哈希的连接池分片
+package shard
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"consistenthash"
+)
+
+// 没有可用的连接池
+var ErrNoPool = errors.New("shard: no pool available")
+
+type (
+	// 分片客户端的可选配置
+	Option func(o *options)
+
+	options struct {
+		window  time.Duration
+		newRing func() *zero.ConsistentHash
+	}
+
+	// 将一组连接池挂在哈希环上，按键选择连接池
+	ShardedClient[C any] struct {
+		lock    sync.RWMutex
+		options options
+		ring    *zero.ConsistentHash
+		// 仍在再平衡窗口内的各次变更之前的拓扑，按变更顺序排列
+		previous []snapshot
+		pools    map[string]C
+		weights  map[string]int
+	}
+
+	// 一次变更之前的拓扑及其双写窗口的截止时间
+	snapshot struct {
+		ring     *zero.ConsistentHash
+		deadline time.Time
+	}
+)
+
+// 拓扑变更后的窗口期内，DoWrite 同时写入新旧归属节点，窗口内的多次变更各自计时
+// 计时使用哈希环的时间来源
+func WithDoubleWrite(window time.Duration) Option {
+	return func(o *options) {
+		o.window = window
+	}
+}
+
+// 指定创建哈希环的方法，默认 zero.NewConsistentHash
+func WithRing(fn func() *zero.ConsistentHash) Option {
+	return func(o *options) {
+		o.newRing = fn
+	}
+}
+
+func NewShardedClient[C any](opts ...Option) *ShardedClient[C] {
+	o := options{
+		newRing: zero.NewConsistentHash,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &ShardedClient[C]{
+		options: o,
+		ring:    o.newRing(),
+		pools:   make(map[string]C),
+		weights: make(map[string]int),
+	}
+}
+
+// 添加连接池，重复添加会替换原有的连接池，返回被替换的连接池由调用方负责关闭
+func (c *ShardedClient[C]) AddPool(node string, pool C) (C, bool) {
+	return c.AddPoolWithWeight(node, zero.TopWeight, pool)
+}
+
+// 按权重添加连接池，返回被替换的连接池由调用方负责关闭
+func (c *ShardedClient[C]) AddPoolWithWeight(node string, weight int, pool C) (C, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.beginRebalance()
+	old, replaced := c.pools[node]
+	c.pools[node] = pool
+	c.weights[node] = weight
+	c.ring.AddWithWeight(node, weight)
+	return old, replaced
+}
+
+// 移除连接池，返回被移除的连接池由调用方负责关闭
+func (c *ShardedClient[C]) RemovePool(node string) (C, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	pool, ok := c.pools[node]
+	if !ok {
+		return pool, false
+	}
+
+	c.beginRebalance()
+	delete(c.pools, node)
+	delete(c.weights, node)
+	c.ring.Remove(node)
+	return pool, true
+}
+
+// 当前所有连接池对应的节点，按字典序排列
+func (c *ShardedClient[C]) Nodes() []string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	nodes := make([]string, 0, len(c.pools))
+	for node := range c.pools {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// 选择 key 所属的连接池并执行 fn
+func (c *ShardedClient[C]) Do(key string, fn func(conn C) error) error {
+	c.lock.RLock()
+	pool, ok := c.lookup(c.ring, key)
+	c.lock.RUnlock()
+
+	if !ok {
+		return ErrNoPool
+	}
+	return fn(pool)
+}
+
+// 写操作
+// 再平衡窗口内如果 key 的旧归属节点仍然存在且与新节点不同，会先写新节点再按变更从新到旧写各个旧节点
+func (c *ShardedClient[C]) DoWrite(key string, fn func(conn C) error) error {
+	c.lock.RLock()
+	var (
+		pool C
+		olds []C
+	)
+	// 只查找一次，新节点与写入的连接池保持一致
+	node, ok := c.ring.Get(key)
+	if ok {
+		pool, ok = c.pools[node.(string)]
+	}
+	if ok {
+		now := c.ring.Clock().Now()
+		written := map[interface{}]struct{}{node: {}}
+		for i := len(c.previous) - 1; i >= 0; i-- {
+			prev := c.previous[i]
+			if !now.Before(prev.deadline) {
+				continue
+			}
+			oldNode, found := prev.ring.Get(key)
+			if _, ok := written[oldNode]; !found || ok {
+				continue
+			}
+			written[oldNode] = struct{}{}
+			if old, ok := c.pools[oldNode.(string)]; ok {
+				olds = append(olds, old)
+			}
+		}
+	}
+	c.lock.RUnlock()
+
+	if !ok {
+		return ErrNoPool
+	}
+	errs := []error{fn(pool)}
+	for _, old := range olds {
+		errs = append(errs, fn(old))
+	}
+	return errors.Join(errs...)
+}
+
+func (c *ShardedClient[C]) lookup(ring *zero.ConsistentHash, key string) (C, bool) {
+	var pool C
+	node, ok := ring.Get(key)
+	if !ok {
+		return pool, false
+	}
+	pool, ok = c.pools[node.(string)]
+	return pool, ok
+}
+
+// 记录变更前的拓扑，开启双写窗口，同时丢弃窗口已结束的拓扑
+// 调用方需持有写锁
+func (c *ShardedClient[C]) beginRebalance() {
+	if c.options.window <= 0 {
+		return
+	}
+
+	now := c.ring.Clock().Now()
+	live := c.previous[:0]
+	for _, prev := range c.previous {
+		if now.Before(prev.deadline) {
+			live = append(live, prev)
+		}
+	}
+	clear(c.previous[len(live):])
+
+	previous := c.options.newRing()
+	for node, weight := range c.weights {
+		previous.AddWithWeight(node, weight)
+	}
+	c.previous = append(live, snapshot{ring: previous, deadline: now.Add(c.options.window)})
+}
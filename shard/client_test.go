@@ -0,0 +1,145 @@
+package shard
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"consistenthash"
+	"consistenthash/chashtest"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePool struct {
+	name   string
+	writes []string
+}
+
+func TestShardedClientDo(t *testing.T) {
+	c := NewShardedClient[*fakePool]()
+	assert.ErrorIs(t, c.Do("any", func(*fakePool) error { return nil }), ErrNoPool)
+
+	c.AddPool("a", &fakePool{name: "a"})
+	c.AddPool("b", &fakePool{name: "b"})
+	assert.Equal(t, []string{"a", "b"}, c.Nodes())
+
+	owners := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, c.Do(strconv.Itoa(i), func(p *fakePool) error {
+			owners[p.name]++
+			return nil
+		}))
+	}
+	assert.Equal(t, 2, len(owners))
+
+	pool, ok := c.RemovePool("a")
+	assert.True(t, ok)
+	assert.Equal(t, "a", pool.name)
+	_, ok = c.RemovePool("a")
+	assert.False(t, ok)
+
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, c.Do(strconv.Itoa(i), func(p *fakePool) error {
+			assert.Equal(t, "b", p.name)
+			return nil
+		}))
+	}
+}
+
+func TestShardedClientDoubleWrite(t *testing.T) {
+	c := NewShardedClient[*fakePool](WithDoubleWrite(time.Hour))
+	a := &fakePool{name: "a"}
+	c.AddPool("a", a)
+	b := &fakePool{name: "b"}
+	c.AddPool("b", b)
+
+	var doubled int
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		var targets []string
+		assert.NoError(t, c.DoWrite(key, func(p *fakePool) error {
+			targets = append(targets, p.name)
+			return nil
+		}))
+		// 只有迁移到 b 的键需要双写，且新归属节点先写
+		if len(targets) == 2 {
+			assert.Equal(t, []string{"b", "a"}, targets)
+			doubled++
+		} else {
+			assert.Equal(t, []string{"a"}, targets)
+		}
+	}
+	assert.True(t, doubled > 0)
+}
+
+func TestShardedClientDoubleWriteChain(t *testing.T) {
+	clock := chashtest.NewFakeClock(time.Unix(0, 0))
+	c := NewShardedClient[*fakePool](WithDoubleWrite(time.Minute), WithRing(func() *zero.ConsistentHash {
+		return zero.New(zero.WithClock(clock))
+	}))
+	c.AddPool("a", &fakePool{name: "a"})
+	c.AddPool("b", &fakePool{name: "b"})
+	first := zero.NewConsistentHash()
+	first.Add("a")
+	first.Add("b")
+	second := first.Clone()
+	second.Add("c")
+
+	// 窗口内的第二次变更不影响第一次变更的双写
+	clock.Advance(time.Minute)
+	c.AddPool("c", &fakePool{name: "c"})
+	clock.Advance(20 * time.Second)
+	c.AddPool("d", &fakePool{name: "d"})
+
+	var chained int
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		var expect []string
+		for _, ring := range []*zero.ConsistentHash{c.ring, second, first} {
+			node, _ := ring.Get(key)
+			if !slices.Contains(expect, node.(string)) {
+				expect = append(expect, node.(string))
+			}
+		}
+		if len(expect) == 3 {
+			chained++
+		}
+		assert.Equal(t, expect, writeTargets(t, c, key))
+	}
+	assert.True(t, chained > 0)
+
+	// 第一次变更的窗口先结束
+	clock.Advance(40 * time.Second)
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		node, _ := c.ring.Get(key)
+		expect := []string{node.(string)}
+		if old, _ := second.Get(key); old != node {
+			expect = append(expect, old.(string))
+		}
+		assert.Equal(t, expect, writeTargets(t, c, key))
+	}
+	clock.Advance(time.Minute)
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, 1, len(writeTargets(t, c, strconv.Itoa(i))))
+	}
+}
+
+func TestShardedClientReplacePool(t *testing.T) {
+	c := NewShardedClient[*fakePool]()
+	_, replaced := c.AddPool("a", &fakePool{name: "old"})
+	assert.False(t, replaced)
+	old, replaced := c.AddPool("a", &fakePool{name: "new"})
+	assert.True(t, replaced)
+	assert.Equal(t, "old", old.name)
+}
+
+func writeTargets(t *testing.T, c *ShardedClient[*fakePool], key string) []string {
+	var targets []string
+	assert.NoError(t, c.DoWrite(key, func(p *fakePool) error {
+		targets = append(targets, p.name)
+		return nil
+	}))
+	return targets
+}
@@ -0,0 +1,208 @@
+package zero
+
+import (
+	"math/bits"
+	"sort"
+	"sync"
+)
+
+type (
+	// 分片的一致性哈希
+	// 按哈希值的高位把哈希空间划分为多个区间，每个区间的虚拟节点由独立的子环和锁维护
+	// 增删节点时逐个子环加写锁，只阻塞落在该子环上的查找
+	// 变更过程中不同子环可能短暂处于新旧两种拓扑
+	ShardedConsistentHash struct {
+		// 哈希函数
+		hashFunc Func
+		// 虚拟节点放大因子
+		replicas int
+		// 哈希值右移的位数，得到子环编号
+		shift uint
+		// 子环列表
+		shards []*ringShard
+		// 物理节点实际占用的虚拟节点位置
+		nodes map[string][]uint64
+		// 串行化写操作，查找不需要
+		lock sync.Mutex
+	}
+
+	ringShard struct {
+		keys []uint64
+		ring map[uint64][]interface{}
+		lock sync.RWMutex
+	}
+)
+
+// 创建分片的一致性哈希，shards 会向上取整到2的幂
+func NewShardedConsistentHash(shards int) *ShardedConsistentHash {
+	if shards < 1 {
+		shards = 1
+	}
+	width := bits.Len(uint(shards - 1))
+
+	h := &ShardedConsistentHash{
+		hashFunc: Hash,
+		replicas: minReplicas,
+		shift:    uint(64 - width),
+		shards:   make([]*ringShard, 1<<width),
+		nodes:    make(map[string][]uint64),
+	}
+	for i := range h.shards {
+		h.shards[i] = &ringShard{
+			ring: make(map[uint64][]interface{}),
+		}
+	}
+	return h
+}
+
+// 扩容操作，增加物理节点
+func (h *ShardedConsistentHash) Add(node string) {
+	h.AddWithReplicas(node, h.replicas)
+}
+
+// 按权重添加节点，权重可以超过 TopWeight
+func (h *ShardedConsistentHash) AddWithWeight(node string, weight int) {
+	h.addWithReplicas(node, h.replicas*weight/TopWeight)
+}
+
+// 扩容操作，增加物理节点，支持重复添加
+// 虚拟节点数量不超过放大因子
+func (h *ShardedConsistentHash) AddWithReplicas(node string, replicas int) {
+	if replicas > h.replicas {
+		replicas = h.replicas
+	}
+	h.addWithReplicas(node, replicas)
+}
+
+func (h *ShardedConsistentHash) addWithReplicas(node string, replicas int) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.removeLocked(node)
+	points := make([]uint64, replicas)
+	for i := range points {
+		points[i] = h.hashFunc(DefaultReplicaKey(node, i))
+	}
+	h.nodes[node] = points
+
+	for index, hashes := range h.group(points) {
+		shard := h.shards[index]
+		shard.lock.Lock()
+		for _, hash := range hashes {
+			if _, ok := shard.ring[hash]; !ok {
+				shard.keys = append(shard.keys, hash)
+			}
+			shard.ring[hash] = insertChain(shard.ring[hash], node)
+		}
+		sort.Slice(shard.keys, func(i, j int) bool {
+			return shard.keys[i] < shard.keys[j]
+		})
+		shard.lock.Unlock()
+	}
+}
+
+// 删除物理节点
+func (h *ShardedConsistentHash) Remove(node string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.removeLocked(node)
+}
+
+// 根据V顺时针找到最近的虚拟节点
+// 所在子环中没有更大的虚拟节点时，继续查找后续子环的第一个虚拟节点
+// 最多绕环一周回到起始子环
+func (h *ShardedConsistentHash) Get(v string) (interface{}, bool) {
+	hash := h.hashFunc([]byte(v))
+	start := int(hash >> h.shift)
+	for i := 0; i <= len(h.shards); i++ {
+		shard := h.shards[(start+i)%len(h.shards)]
+		shard.lock.RLock()
+		index := 0
+		if i == 0 {
+			index = sort.Search(len(shard.keys), func(i int) bool {
+				return shard.keys[i] >= hash
+			})
+		}
+		if index < len(shard.keys) {
+			// 冲突链会被原地修改，需在释放锁之前选出节点
+			nodes := shard.ring[shard.keys[index]]
+			node := nodes[0]
+			if len(nodes) > 1 {
+				innerIndex := h.hashFunc([]byte(innerRepr(v)))
+				node = nodes[int(innerIndex%uint64(len(nodes)))]
+			}
+			shard.lock.RUnlock()
+			return node, true
+		}
+		shard.lock.RUnlock()
+	}
+
+	return nil, false
+}
+
+// 物理节点数量
+func (h *ShardedConsistentHash) Len() int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return len(h.nodes)
+}
+
+// 当前所有物理节点，按字典序排列
+func (h *ShardedConsistentHash) Nodes() []string {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	nodes := make([]string, 0, len(h.nodes))
+	for node := range h.nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// 调用方需持有 h.lock
+func (h *ShardedConsistentHash) removeLocked(node string) {
+	points, ok := h.nodes[node]
+	if !ok {
+		return
+	}
+	delete(h.nodes, node)
+
+	for index, hashes := range h.group(points) {
+		shard := h.shards[index]
+		shard.lock.Lock()
+		for _, hash := range hashes {
+			nodes, ok := shard.ring[hash]
+			if !ok {
+				continue
+			}
+			newNodes := nodes[:0]
+			for _, x := range nodes {
+				if x != node {
+					newNodes = append(newNodes, x)
+				}
+			}
+			if len(newNodes) > 0 {
+				shard.ring[hash] = newNodes
+				continue
+			}
+			delete(shard.ring, hash)
+			i := sort.Search(len(shard.keys), func(i int) bool {
+				return shard.keys[i] >= hash
+			})
+			shard.keys = append(shard.keys[:i], shard.keys[i+1:]...)
+		}
+		shard.lock.Unlock()
+	}
+}
+
+// 按所属子环对虚拟节点分组
+func (h *ShardedConsistentHash) group(points []uint64) map[int][]uint64 {
+	groups := make(map[int][]uint64)
+	for _, hash := range points {
+		index := int(hash >> h.shift)
+		groups[index] = append(groups[index], hash)
+	}
+	return groups
+}
@@ -0,0 +1,86 @@
+package zero
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardedConsistentHash(t *testing.T) {
+	for _, shards := range []int{0, 1, 5, 16} {
+		sharded := NewShardedConsistentHash(shards)
+		_, ok := sharded.Get("any")
+		assert.False(t, ok)
+
+		plain := NewConsistentHash()
+		for i := 0; i < keySize; i++ {
+			node := "localhost:" + strconv.Itoa(i)
+			sharded.AddWithWeight(node, 50+i)
+			plain.AddWithWeight(node, 50+i)
+		}
+		sharded.Remove("localhost:3")
+		plain.Remove("localhost:3")
+		assert.Equal(t, keySize-1, sharded.Len())
+
+		// 与不分片的一致性哈希结果一致
+		for i := 0; i < requestSize; i++ {
+			expect, _ := plain.Get(strconv.Itoa(i))
+			val, ok := sharded.Get(strconv.Itoa(i))
+			assert.True(t, ok)
+			assert.Equal(t, expect, val)
+		}
+	}
+}
+
+func TestShardedConsistentHashConcurrent(t *testing.T) {
+	h := NewShardedConsistentHash(8)
+	h.Add("stable")
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			h.Add("flapping")
+			h.Remove("flapping")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < requestSize; i++ {
+			node, ok := h.Get(strconv.Itoa(i))
+			assert.True(t, ok)
+			assert.Contains(t, []interface{}{"stable", "flapping"}, node)
+		}
+	}()
+	wg.Wait()
+}
+
+func BenchmarkShardedConsistentHashGet(b *testing.B) {
+	h := NewShardedConsistentHash(16)
+	for i := 0; i < keySize; i++ {
+		h.Add("localhost:" + strconv.Itoa(i))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.Get(strconv.Itoa(i))
+	}
+}
+
+func TestShardedConsistentHashWeightAboveTop(t *testing.T) {
+	sharded := NewShardedConsistentHash(4)
+	plain := NewConsistentHash()
+	for i, weight := range []int{TopWeight, 2 * TopWeight, 4 * TopWeight} {
+		node := "localhost:" + strconv.Itoa(i)
+		sharded.AddWithWeight(node, weight)
+		plain.AddWithWeight(node, weight)
+	}
+	for i := 0; i < requestSize; i++ {
+		expect, _ := plain.Get(strconv.Itoa(i))
+		val, _ := sharded.Get(strconv.Itoa(i))
+		assert.Equal(t, expect, val)
+	}
+}
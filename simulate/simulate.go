@@ -0,0 +1,237 @@
+// 一致性哈希的负载与迁移模拟，用于容量规划和算法选型
+package simulate
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"consistenthash"
+)
+
+type (
+	// 被模拟的哈希环
+	Ring interface {
+		Add(node string)
+		Remove(node string)
+		Get(v string) (interface{}, bool)
+	}
+
+	// 参与比较的算法
+	Algorithm struct {
+		Name string
+		New  func() Ring
+	}
+
+	// 单个节点的负载
+	Load struct {
+		Node string
+		Keys int
+	}
+
+	// 负载分布报告
+	Report struct {
+		Algorithm string
+		Nodes     int
+		Keys      int
+		// 各节点的负载，按节点名称排序
+		Loads []Load
+		Min   int
+		Max   int
+		Mean  float64
+		// 负载的标准差
+		StdDev float64
+		// 峰值与均值之比
+		PeakToMean float64
+	}
+
+	// 负载直方图的一个区间 [Lo, Hi)
+	Bucket struct {
+		Lo    int
+		Hi    int
+		Count int
+	}
+
+	// 算法比较结果
+	Comparison struct {
+		Report
+		// 新增一个节点时迁移的键比例
+		MovedOnAdd float64
+		// 删除一个节点时迁移的键比例
+		MovedOnRemove float64
+	}
+)
+
+// 内置的几种算法
+func DefaultAlgorithms() []Algorithm {
+	return []Algorithm{
+		{Name: "ring", New: func() Ring { return zero.NewConsistentHash() }},
+		{Name: "maglev", New: func() Ring { return zero.NewMaglevHash() }},
+		{Name: "rendezvous", New: func() Ring { return zero.NewRendezvousHash() }},
+		{Name: "multiprobe", New: func() Ring { return zero.NewMultiProbeHash() }},
+		{Name: "anchor", New: func() Ring { return zero.NewAnchorHash(1 << 16) }},
+	}
+}
+
+// 模拟用的节点名称
+func Nodes(n int) []string {
+	nodes := make([]string, n)
+	for i := range nodes {
+		nodes[i] = "node-" + strconv.Itoa(i)
+	}
+	return nodes
+}
+
+// 模拟用的键
+func Keys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = "key-" + strconv.Itoa(i)
+	}
+	return keys
+}
+
+// 在已添加节点的环上模拟 keys 的负载分布
+func Simulate(ring Ring, keys []string) Report {
+	counts := make(map[string]int)
+	for _, key := range keys {
+		if node, ok := ring.Get(key); ok {
+			counts[node.(string)]++
+		}
+	}
+
+	loads := make([]Load, 0, len(counts))
+	for node, count := range counts {
+		loads = append(loads, Load{Node: node, Keys: count})
+	}
+	sort.Slice(loads, func(i, j int) bool {
+		return loads[i].Node < loads[j].Node
+	})
+	return newReport(loads, len(loads), len(keys))
+}
+
+// 用 N 个键模拟 M 个节点上的负载分布
+// 没有分到键的节点同样计入统计
+func Distribution(algo Algorithm, nodes, keys int) Report {
+	ring := algo.New()
+	names := Nodes(nodes)
+	for _, node := range names {
+		ring.Add(node)
+	}
+
+	report := Simulate(ring, Keys(keys))
+	counts := make(map[string]int, len(report.Loads))
+	for _, load := range report.Loads {
+		counts[load.Node] = load.Keys
+	}
+	loads := make([]Load, len(names))
+	for i, node := range names {
+		loads[i] = Load{Node: node, Keys: counts[node]}
+	}
+	sort.Slice(loads, func(i, j int) bool {
+		return loads[i].Node < loads[j].Node
+	})
+
+	report = newReport(loads, nodes, keys)
+	report.Algorithm = algo.Name
+	return report
+}
+
+// 在 M 个节点上新增一个节点时迁移的键比例
+func MovedOnAdd(algo Algorithm, nodes, keys int) float64 {
+	names := Nodes(nodes + 1)
+	return moved(algo, names[:nodes], Keys(keys), func(ring Ring) {
+		ring.Add(names[nodes])
+	})
+}
+
+// 在 M 个节点上删除一个节点时迁移的键比例
+func MovedOnRemove(algo Algorithm, nodes, keys int) float64 {
+	names := Nodes(nodes)
+	return moved(algo, names, Keys(keys), func(ring Ring) {
+		ring.Remove(names[len(names)/2])
+	})
+}
+
+// 比较多种算法的负载分布与迁移比例
+func Compare(algos []Algorithm, nodes, keys int) []Comparison {
+	comparisons := make([]Comparison, len(algos))
+	for i, algo := range algos {
+		comparisons[i] = Comparison{
+			Report:        Distribution(algo, nodes, keys),
+			MovedOnAdd:    MovedOnAdd(algo, nodes, keys),
+			MovedOnRemove: MovedOnRemove(algo, nodes, keys),
+		}
+	}
+	return comparisons
+}
+
+// 节点负载的直方图，将 [Min, Max] 等分为 buckets 个区间
+func (r Report) Histogram(buckets int) []Bucket {
+	if buckets < 1 || len(r.Loads) == 0 {
+		return nil
+	}
+
+	width := (r.Max-r.Min)/buckets + 1
+	histogram := make([]Bucket, buckets)
+	for i := range histogram {
+		histogram[i].Lo = r.Min + i*width
+		histogram[i].Hi = r.Min + (i+1)*width
+	}
+	for _, load := range r.Loads {
+		histogram[(load.Keys-r.Min)/width].Count++
+	}
+	return histogram
+}
+
+func moved(algo Algorithm, nodes, keys []string, change func(ring Ring)) float64 {
+	if len(keys) == 0 {
+		return 0
+	}
+
+	ring := algo.New()
+	for _, node := range nodes {
+		ring.Add(node)
+	}
+
+	before := make([]interface{}, len(keys))
+	for i, key := range keys {
+		before[i], _ = ring.Get(key)
+	}
+
+	change(ring)
+	var count int
+	for i, key := range keys {
+		if node, _ := ring.Get(key); node != before[i] {
+			count++
+		}
+	}
+	return float64(count) / float64(len(keys))
+}
+
+func newReport(loads []Load, nodes, keys int) Report {
+	report := Report{
+		Nodes: nodes,
+		Keys:  keys,
+		Loads: loads,
+	}
+	if len(loads) == 0 {
+		return report
+	}
+
+	report.Min = math.MaxInt
+	for _, load := range loads {
+		report.Min = min(report.Min, load.Keys)
+		report.Max = max(report.Max, load.Keys)
+	}
+	report.Mean = float64(keys) / float64(len(loads))
+
+	var sum float64
+	for _, load := range loads {
+		diff := float64(load.Keys) - report.Mean
+		sum += diff * diff
+	}
+	report.StdDev = math.Sqrt(sum / float64(len(loads)))
+	report.PeakToMean = float64(report.Max) / report.Mean
+	return report
+}
@@ -0,0 +1,50 @@
+package simulate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	const (
+		nodes = 10
+		keys  = 10000
+	)
+
+	comparisons := Compare(DefaultAlgorithms(), nodes, keys)
+	assert.Equal(t, len(DefaultAlgorithms()), len(comparisons))
+	for _, c := range comparisons {
+		assert.Equal(t, nodes, len(c.Loads), c.Algorithm)
+		assert.Equal(t, nodes, c.Nodes)
+		assert.InDelta(t, keys/nodes, c.Mean, 1e-9)
+		assert.True(t, c.PeakToMean < 1.5, c.Algorithm)
+
+		// 新增节点时理想的迁移比例为 1/(M+1)，删除时为 1/M
+		assert.InDelta(t, 1./(nodes+1), c.MovedOnAdd, .05, c.Algorithm)
+		assert.InDelta(t, 1./nodes, c.MovedOnRemove, .05, c.Algorithm)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	report := newReport([]Load{
+		{Node: "a", Keys: 10},
+		{Node: "b", Keys: 12},
+		{Node: "c", Keys: 20},
+	}, 3, 42)
+	assert.Equal(t, 10, report.Min)
+	assert.Equal(t, 20, report.Max)
+	assert.InDelta(t, 20/14., report.PeakToMean, 1e-9)
+
+	assert.Equal(t, []Bucket{
+		{Lo: 10, Hi: 16, Count: 2},
+		{Lo: 16, Hi: 22, Count: 1},
+	}, report.Histogram(2))
+	assert.Nil(t, report.Histogram(0))
+}
+
+func TestSimulateEmptyRing(t *testing.T) {
+	report := Simulate(DefaultAlgorithms()[0].New(), Keys(10))
+	assert.Empty(t, report.Loads)
+	assert.Equal(t, 0., MovedOnAdd(DefaultAlgorithms()[0], 1, 0))
+}
@@ -0,0 +1,77 @@
+package zero
+
+// 哈希环成员的快照，可序列化后在其他进程中恢复
+// 哈希函数等配置不在快照中，恢复时沿用目标哈希环的配置
+type Snapshot struct {
+	// 虚拟节点放大因子
+	Replicas int `json:"replicas"`
+	// 节点及其虚拟节点数量
+	Nodes map[string]int `json:"nodes"`
+	// 键的固定路由
+	Pins map[string]string `json:"pins,omitempty"`
+	// 按逻辑 ID 添加的节点的地址
+	Addrs map[string]string `json:"addrs,omitempty"`
+}
+
+// 导出当前成员、节点地址和固定路由的快照
+func (h *ConsistentHash) Snapshot() Snapshot {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	s := Snapshot{
+		Replicas: h.replicas,
+		Nodes:    make(map[string]int, len(h.nodes)),
+	}
+	for node, replicas := range h.nodes {
+		s.Nodes[node] = replicas
+	}
+	if len(h.pins) > 0 {
+		s.Pins = make(map[string]string, len(h.pins))
+		for key, node := range h.pins {
+			s.Pins[key] = node
+		}
+	}
+	if len(h.addrs) > 0 {
+		s.Addrs = make(map[string]string, len(h.addrs))
+		for id, addr := range h.addrs {
+			s.Addrs[id] = addr
+		}
+	}
+	return s
+}
+
+// 用快照替换当前的成员、节点地址和固定路由
+// 临时节点、摘除中的节点和节点容量一并清除，虚拟节点很多时并行计算虚拟节点位置
+func (h *ConsistentHash) Restore(s Snapshot) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for node := range h.ttls {
+		h.clearTTLLocked(node)
+	}
+	for node := range h.drains {
+		h.clearDrainLocked(node)
+	}
+	h.capacities = nil
+	if s.Replicas > 0 {
+		h.replicas = max(s.Replicas, h.replicaFloor)
+	}
+
+	h.pins = nil
+	if len(s.Pins) > 0 {
+		h.pins = make(map[string]string, len(s.Pins))
+		for key, node := range s.Pins {
+			h.pins[key] = node
+		}
+	}
+	h.addrs = nil
+	if len(s.Addrs) > 0 {
+		h.addrs = make(map[string]string, len(s.Addrs))
+		for id, addr := range s.Addrs {
+			h.addrs[id] = addr
+		}
+	}
+	h.rebuild(s.Nodes)
+	h.version++
+	h.settleLocked()
+}
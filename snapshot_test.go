@@ -0,0 +1,45 @@
+package zero
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	ch := New(WithReplicas(200))
+	for i := 0; i < 5; i++ {
+		ch.Add("10.0.0." + strconv.Itoa(i) + ":6379")
+	}
+	ch.AddWithWeight("10.0.0.5:6379", 50)
+	ch.Pin("hot", "10.0.0.1:6379")
+
+	data, err := json.Marshal(ch.Snapshot())
+	assert.Nil(t, err)
+
+	var s Snapshot
+	assert.Nil(t, json.Unmarshal(data, &s))
+	restored := New()
+	restored.AddWithTTL("stale", time.Minute)
+	restored.Restore(s)
+
+	assert.Equal(t, ch.Nodes(), restored.Nodes())
+	assert.Equal(t, 100, restored.ReplicaCount("10.0.0.5:6379"))
+	assert.Empty(t, restored.ttls)
+	for i := 0; i < requestSize; i++ {
+		key := strconv.Itoa(i)
+		expect, _ := ch.Get(key)
+		actual, _ := restored.Get(key)
+		assert.Equal(t, expect, actual)
+	}
+	node, _ := restored.Get("hot")
+	assert.Equal(t, "10.0.0.1:6379", node)
+
+	restored.Restore(Snapshot{})
+	assert.Equal(t, 0, restored.Len())
+	_, ok := restored.Pinned("hot")
+	assert.False(t, ok)
+}
@@ -0,0 +1,204 @@
+package zero
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// 每个节点跟踪的热点键数量为溢出上限的倍数
+const hotKeysFactor = 16
+
+type (
+	// 过载保护：把过载节点的热点键临时转移给后继节点
+	spillState struct {
+		limit   int
+		maxKeys int
+		ttl     time.Duration
+
+		lock sync.Mutex
+		// 各节点上报的连接数
+		loads map[string]int
+		// 各节点近期被查找的键及次数，容量有限，满时替换次数最少的键
+		hot map[string]map[string]uint64
+		// 被转移的键
+		spilled map[string]spillEntry
+	}
+
+	spillEntry struct {
+		from, to string
+		expires  time.Time
+	}
+)
+
+// 开启过载保护：ReportLoad 上报的连接数超过 limit 时，把该节点最热的至多 maxKeys 个键临时转移给
+// 顺时针方向第一个未过载的节点，ttl 之后自动回到原节点
+// 热点按 Get、GetBytes、GetMany 和 GetExisting 的近似查找次数统计，每个节点只跟踪有限个键；固定路由的键不转移
+// limit 或 maxKeys 不大于0时关闭
+func WithSpill(limit, maxKeys int, ttl time.Duration) Option {
+	return func(h *ConsistentHash) {
+		if limit > 0 && maxKeys > 0 {
+			h.spill = &spillState{
+				limit:   limit,
+				maxKeys: maxKeys,
+				ttl:     ttl,
+				loads:   make(map[string]int),
+				hot:     make(map[string]map[string]uint64),
+				spilled: make(map[string]spillEntry),
+			}
+		} else {
+			h.spill = nil
+		}
+	}
+}
+
+// 上报节点当前的连接数，超过 WithSpill 的上限时转移其热点键，未开启过载保护时忽略
+func (h *ConsistentHash) ReportLoad(node string, n int) {
+	s := h.spill
+	if s == nil {
+		return
+	}
+
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.loads[node] = n
+	if n <= s.limit || !h.containsNode(node) {
+		return
+	}
+
+	now := h.clock.Now()
+	budget := s.maxKeys
+	for _, entry := range s.spilled {
+		if entry.from == node && now.Before(entry.expires) {
+			budget--
+		}
+	}
+	for _, key := range s.hottest(node) {
+		if budget <= 0 {
+			break
+		}
+		if _, ok := s.spilled[key]; ok {
+			continue
+		}
+		if to, ok := h.spillTargetLocked(key, node); ok {
+			s.spilled[key] = spillEntry{from: node, to: to, expires: now.Add(s.ttl)}
+			budget--
+		}
+	}
+	// 重新开始统计，转移后的热点不再计入原节点
+	delete(s.hot, node)
+}
+
+// 当前被转移的键及其临时节点
+func (h *ConsistentHash) Spilled() map[string]string {
+	s := h.spill
+	if s == nil {
+		return nil
+	}
+
+	now := h.clock.Now()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	spilled := make(map[string]string, len(s.spilled))
+	for key, entry := range s.spilled {
+		if now.Before(entry.expires) {
+			spilled[key] = entry.to
+		}
+	}
+	return spilled
+}
+
+// 键被转移时返回临时节点，过期的转移在此时清除；否则记录一次对原节点的查找
+// 调用方需持有读锁
+func (h *ConsistentHash) spillLocked(key string, node interface{}) interface{} {
+	s := h.spill
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if entry, ok := s.spilled[key]; ok {
+		if h.clock.Now().Before(entry.expires) && h.containsNode(entry.to) {
+			return entry.to
+		}
+		delete(s.spilled, key)
+	}
+	if name, ok := node.(string); ok {
+		s.record(name, key)
+	}
+	return node
+}
+
+// 键当前被转移到的临时节点，不清除过期的转移也不记录查找，用于诊断
+// 调用方需持有读锁
+func (h *ConsistentHash) spilledToLocked(key string) (string, bool) {
+	s := h.spill
+	if s == nil {
+		return "", false
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	entry, ok := s.spilled[key]
+	if !ok || !h.clock.Now().Before(entry.expires) || !h.containsNode(entry.to) {
+		return "", false
+	}
+	return entry.to, true
+}
+
+// 顺时针方向第一个不是 from 且未过载的节点
+// 调用方需持有读锁和 s.lock
+func (h *ConsistentHash) spillTargetLocked(key, from string) (string, bool) {
+	var target string
+	h.walkRing(key, func(node string) bool {
+		if node == from || h.spill.loads[node] > h.spill.limit {
+			return true
+		}
+		target = node
+		return false
+	})
+	return target, target != ""
+}
+
+// 调用方需持有 s.lock
+func (s *spillState) record(node, key string) {
+	counts := s.hot[node]
+	if counts == nil {
+		counts = make(map[string]uint64)
+		s.hot[node] = counts
+	}
+	if _, ok := counts[key]; ok || len(counts) < s.maxKeys*hotKeysFactor {
+		counts[key]++
+		return
+	}
+
+	// 替换次数最少的键，新键继承其次数，高估而不会漏掉真正的热点
+	var victim string
+	var least uint64
+	for k, n := range counts {
+		if victim == "" || n < least {
+			victim, least = k, n
+		}
+	}
+	delete(counts, victim)
+	counts[key] = least + 1
+}
+
+// 节点的热点键，按查找次数从多到少排列
+// 调用方需持有 s.lock
+func (s *spillState) hottest(node string) []string {
+	counts := s.hot[node]
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
@@ -0,0 +1,158 @@
+package zero
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpill(t *testing.T) {
+	clock := newFakeClock()
+	ch := New(WithSpill(100, 2, time.Minute))
+	ch.clock = clock
+	for i := 0; i < 5; i++ {
+		ch.Add("node" + strconv.Itoa(i))
+	}
+
+	// 找到同一节点上的三个键，按不同的热度查找
+	var keys []string
+	owner, _ := ch.Get("0")
+	for i := 0; len(keys) < 3; i++ {
+		key := strconv.Itoa(i)
+		if node, _ := ch.Get(key); node == owner {
+			keys = append(keys, key)
+		}
+	}
+	for i, key := range keys {
+		for j := 0; j < 10*(3-i); j++ {
+			ch.Get(key)
+		}
+	}
+
+	// 未超过上限时不转移
+	ch.ReportLoad(owner.(string), 100)
+	assert.Empty(t, ch.Spilled())
+
+	ch.ReportLoad(owner.(string), 101)
+	spilled := ch.Spilled()
+	assert.Equal(t, 2, len(spilled))
+	for _, key := range keys[:2] {
+		node, _ := ch.Get(key)
+		assert.NotEqual(t, owner, node)
+		assert.Equal(t, spilled[key], node)
+		bytesNode, _ := ch.GetBytes([]byte(key))
+		assert.Equal(t, node, bytesNode)
+		// 转移给顺时针方向的下一个节点，副本不含过载保护
+		assert.Equal(t, ch.Clone().GetCandidates(key, 2)[1].Node, node)
+		// 候选节点与 Get 一致，原节点排在其后
+		candidates := ch.GetCandidates(key, 2)
+		assert.Equal(t, node, candidates[0].Node)
+		assert.Equal(t, owner, candidates[1].Node)
+	}
+	node, _ := ch.Get(keys[2])
+	assert.Equal(t, owner, node)
+
+	// 到期后回到原节点
+	clock.Advance(time.Minute)
+	assert.Empty(t, ch.Spilled())
+	node, _ = ch.Get(keys[0])
+	assert.Equal(t, owner, node)
+}
+
+func TestSpillAvoidsOverloaded(t *testing.T) {
+	ch := New(WithSpill(10, 1, time.Minute))
+	ch.Add("a")
+	ch.Add("b")
+	ch.Add("c")
+
+	owner, _ := ch.Get("key")
+	next := ch.GetCandidates("key", 3)
+	ch.ReportLoad(next[1].Node.(string), 50)
+	ch.Get("key")
+	ch.ReportLoad(owner.(string), 50)
+
+	// 跳过同样过载的后继
+	node, _ := ch.Get("key")
+	assert.Equal(t, next[2].Node, node)
+
+	// 未开启时忽略
+	plain := NewConsistentHash()
+	plain.ReportLoad("a", 100)
+	assert.Nil(t, plain.Spilled())
+}
+
+func TestSpillDiagnostics(t *testing.T) {
+	ch := New(WithSpill(100, 1, time.Minute))
+	for i := 0; i < 5; i++ {
+		ch.Add("node" + strconv.Itoa(i))
+	}
+	owner, _ := ch.Get("0")
+	for i := 0; i < 10; i++ {
+		ch.Get("0")
+	}
+	ch.ReportLoad(owner.(string), 101)
+	assert.Equal(t, 1, len(ch.Spilled()))
+
+	for i := 0; i < requestSize; i++ {
+		key := strconv.Itoa(i)
+		expect, _ := ch.Get(key)
+		info, _ := ch.Lookup(key)
+		assert.Equal(t, expect, info.Node)
+		e := ch.Explain(key)
+		assert.Equal(t, expect, e.Node)
+		if key == "0" {
+			assert.Equal(t, "spill", e.Override)
+		} else {
+			assert.Empty(t, e.Override)
+		}
+	}
+
+	// 诊断不计入热点统计
+	ch.spill.hot = make(map[string]map[string]uint64)
+	for i := 0; i < 10; i++ {
+		ch.Lookup("1")
+		ch.Explain("1")
+	}
+	assert.Empty(t, ch.spill.hot)
+}
+
+func TestSpillSharedPaths(t *testing.T) {
+	ch := New(WithSpill(100, 1, time.Minute))
+	for i := 0; i < 5; i++ {
+		ch.Add("node" + strconv.Itoa(i))
+	}
+	owner, _ := ch.Get("0")
+	for i := 0; i < 10; i++ {
+		ch.Get("0")
+	}
+	ch.ReportLoad(owner.(string), 101)
+	to := ch.Spilled()["0"]
+	assert.NotEmpty(t, to)
+	assert.NotEqual(t, owner, to)
+
+	keys := []string{"0", "1", "2"}
+	many := ch.GetMany(keys)
+	frozen := ch.Freeze()
+	frozenMany := frozen.GetMany(keys)
+	stream := slices.Collect(ch.GetStream(slices.Values(keys)))
+	for i, key := range keys {
+		expect, _ := ch.Get(key)
+		assert.Equal(t, expect, many[i].Node)
+		// 冻结的视图不复制过载转移，与冻结视图的 Get 一致
+		frozenNode, _ := frozen.Get(key)
+		assert.Equal(t, frozenNode, frozenMany[i].Node)
+		assert.Equal(t, expect, stream[i].Node)
+		existing, _ := ch.GetExisting(key)
+		assert.Equal(t, expect, existing)
+		primary, secondary, _ := ch.GetPair(key)
+		assert.Equal(t, expect, primary)
+		assert.NotEqual(t, expect, secondary)
+		assert.Equal(t, expect, ch.GetCandidates(key, 2)[0].Node)
+		assert.Equal(t, expect, ch.GetWithAntiAffinity([]string{key})[key])
+	}
+	primary, _, _ := ch.GetPair("0")
+	assert.Equal(t, to, primary)
+}
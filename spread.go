@@ -0,0 +1,35 @@
+package zero
+
+// 黄金分割比对应的64位增量，splitmix64 用它生成互不相关的序列
+const goldenGamma = 0x9e3779b97f4a7c15
+
+// 由节点的基础哈希派生虚拟节点位置，保证同一节点的虚拟节点在环上均匀分布
+// 哈希空间等分为 replicas 段，节点在每一段中恰好放置一个虚拟节点，段内的偏移由 splitmix64 序列决定，
+// 不同节点的虚拟节点在段内随机交错；相似的节点名在较弱的哈希函数下不再扎堆，
+// 虚拟节点较少时负载明显更均衡，分布良好的哈希函数下与默认方式相当
+// 节点的虚拟节点数量变化时其所有虚拟节点都会移动，频繁调整权重的场景不宜使用
+// 与 WithReplicaKeyFunc 互斥，开启后不再使用虚拟节点键
+func WithReplicaSpreading() Option {
+	return func(h *ConsistentHash) {
+		h.spread = true
+	}
+}
+
+// 生成均匀分段的虚拟节点位置，hash 为最终生效的哈希函数
+func spreadPoints(hash Func) func(node string, replicas int) []uint64 {
+	return func(node string, replicas int) []uint64 {
+		if replicas <= 0 {
+			return nil
+		}
+
+		base := hash([]byte(node))
+		stride := ^uint64(0) / uint64(replicas)
+		points := make([]uint64, replicas)
+		state := base
+		for i := range points {
+			state += goldenGamma
+			points[i] = uint64(i)*stride + mix64(state)%stride
+		}
+		return points
+	}
+}
@@ -0,0 +1,61 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"consistenthash/hashes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplicaSpreadingStrata(t *testing.T) {
+	ch := New(WithReplicaSpreading(), WithReplicas(16))
+	ch.Add("node")
+
+	// 每一段中恰好有一个虚拟节点
+	stride := ^uint64(0) / 16
+	seen := make(map[uint64]bool)
+	for _, point := range ch.points["node"] {
+		seen[point/stride] = true
+	}
+	assert.Equal(t, 16, len(seen))
+
+	// 相同的配置得到相同的位置
+	other := New(WithReplicaSpreading(), WithReplicas(16))
+	other.Add("node")
+	assert.Equal(t, ch.points["node"], other.points["node"])
+}
+
+func TestReplicaSpreadingBalance(t *testing.T) {
+	build := func(opts ...Option) *ConsistentHash {
+		ch := New(append(opts, WithReplicas(10), WithHashFunc(hashes.CRC32))...)
+		for i := 0; i < 20; i++ {
+			ch.Add("10.0.0." + strconv.Itoa(i) + ":6379")
+		}
+		return ch
+	}
+
+	plain, spread := build(), build(WithReplicaSpreading())
+	plain.lock.RLock()
+	spread.lock.RLock()
+	defer plain.lock.RUnlock()
+	defer spread.lock.RUnlock()
+	assert.Less(t, spread.imbalance(), plain.imbalance())
+}
+
+func TestReplicaSpreadingLookup(t *testing.T) {
+	ch := New(WithReplicaSpreading(), WithSeed(7))
+	ch.Add("a")
+	ch.AddWithWeight("b", 50)
+	ch.Remove("a")
+	for i := 0; i < 100; i++ {
+		node, ok := ch.Get(strconv.Itoa(i))
+		assert.True(t, ok)
+		assert.Equal(t, "b", node)
+	}
+
+	// 副本沿用相同的位置
+	ch.Add("a")
+	clone := ch.Clone()
+	assert.Equal(t, ch.points["a"], clone.points["a"])
+}
@@ -0,0 +1,147 @@
+// 基于一致性哈希的 database/sql 分片路由
+package sqlshard
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"sync"
+
+	"consistenthash"
+)
+
+// 没有可用的分片
+var ErrNoShard = errors.New("sqlshard: no shard available")
+
+type (
+	// 分片路由的可选配置
+	Option func(o *options)
+
+	options struct {
+		newRing func() *zero.ConsistentHash
+	}
+
+	// 一个分片的数据库连接
+	Shard struct {
+		// 主库，承担写入和默认的读取
+		Primary *sql.DB
+		// 只读副本，主库查询失败时依次尝试，可以为空
+		Replicas []*sql.DB
+	}
+
+	// 将一组分片挂在哈希环上，按键选择数据库
+	Router struct {
+		lock   sync.RWMutex
+		ring   *zero.ConsistentHash
+		shards map[string]Shard
+	}
+)
+
+// 指定创建哈希环的方法，默认 zero.NewConsistentHash
+func WithRing(fn func() *zero.ConsistentHash) Option {
+	return func(o *options) {
+		o.newRing = fn
+	}
+}
+
+func NewRouter(opts ...Option) *Router {
+	o := options{
+		newRing: zero.NewConsistentHash,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &Router{
+		ring:   o.newRing(),
+		shards: make(map[string]Shard),
+	}
+}
+
+// 添加分片，重复添加会替换原有的分片
+func (r *Router) Add(node string, shard Shard) {
+	r.AddWithWeight(node, zero.TopWeight, shard)
+}
+
+// 按权重添加分片
+func (r *Router) AddWithWeight(node string, weight int, shard Shard) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.shards[node] = shard
+	r.ring.AddWithWeight(node, weight)
+}
+
+// 移除分片，返回被移除的分片由调用方负责关闭
+func (r *Router) Remove(node string) (Shard, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	shard, ok := r.shards[node]
+	if !ok {
+		return shard, false
+	}
+
+	delete(r.shards, node)
+	r.ring.Remove(node)
+	return shard, true
+}
+
+// 当前所有分片对应的节点，按字典序排列
+func (r *Router) Nodes() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	nodes := make([]string, 0, len(r.shards))
+	for node := range r.shards {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// key 所属分片的主库，没有分片时返回 nil
+func (r *Router) DBFor(key string) *sql.DB {
+	shard, ok := r.shardFor(key)
+	if !ok {
+		return nil
+	}
+	return shard.Primary
+}
+
+// 在 key 所属的分片上查询一行
+func (r *Router) QueryRowSharded(key, query string, args ...any) (*sql.Row, error) {
+	return r.QueryRowShardedContext(context.Background(), key, query, args...)
+}
+
+// 在 key 所属的分片上查询一行
+// 主库查询失败时依次尝试该分片的只读副本，全部失败时返回最后一次查询的结果
+// 查询结果为空不算失败，sql.ErrNoRows 仍由 Scan 返回
+func (r *Router) QueryRowShardedContext(ctx context.Context, key, query string, args ...any) (*sql.Row, error) {
+	shard, ok := r.shardFor(key)
+	if !ok {
+		return nil, ErrNoShard
+	}
+
+	row := shard.Primary.QueryRowContext(ctx, query, args...)
+	for _, replica := range shard.Replicas {
+		if row.Err() == nil || ctx.Err() != nil {
+			break
+		}
+		row = replica.QueryRowContext(ctx, query, args...)
+	}
+	return row, nil
+}
+
+func (r *Router) shardFor(key string) (Shard, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	node, ok := r.ring.Get(key)
+	if !ok {
+		return Shard{}, false
+	}
+	shard, ok := r.shards[node.(string)]
+	return shard, ok
+}
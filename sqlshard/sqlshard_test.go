@@ -0,0 +1,128 @@
+package sqlshard
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	sql.Register("sqlshardtest", fakeDriver{})
+}
+
+func open(t *testing.T, name string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlshardtest", name)
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestRouter(t *testing.T) {
+	r := NewRouter()
+	assert.Nil(t, r.DBFor("any"))
+	_, err := r.QueryRowSharded("any", "select")
+	assert.ErrorIs(t, err, ErrNoShard)
+
+	a, b := open(t, "a"), open(t, "b")
+	r.Add("a", Shard{Primary: a})
+	r.Add("b", Shard{Primary: b})
+	assert.Equal(t, []string{"a", "b"}, r.Nodes())
+
+	owners := make(map[string]int)
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		row, err := r.QueryRowSharded(key, "select")
+		assert.NoError(t, err)
+		var name string
+		assert.NoError(t, row.Scan(&name))
+		owners[name]++
+		if name == "a" {
+			assert.Equal(t, a, r.DBFor(key))
+		} else {
+			assert.Equal(t, b, r.DBFor(key))
+		}
+	}
+	assert.Equal(t, 2, len(owners))
+
+	shard, ok := r.Remove("a")
+	assert.True(t, ok)
+	assert.Equal(t, a, shard.Primary)
+	_, ok = r.Remove("a")
+	assert.False(t, ok)
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, b, r.DBFor(strconv.Itoa(i)))
+	}
+}
+
+func TestRouterReplicaFallback(t *testing.T) {
+	r := NewRouter()
+	r.Add("a", Shard{Primary: open(t, "down"), Replicas: []*sql.DB{open(t, "down"), open(t, "replica")}})
+
+	row, err := r.QueryRowSharded("key", "select")
+	assert.NoError(t, err)
+	var name string
+	assert.NoError(t, row.Scan(&name))
+	assert.Equal(t, "replica", name)
+
+	// 没有副本时返回主库的错误
+	r.Add("a", Shard{Primary: open(t, "down")})
+	row, err = r.QueryRowSharded("key", "select")
+	assert.NoError(t, err)
+	assert.EqualError(t, row.Scan(&name), "down")
+}
+
+// 返回一行数据源名称的驱动，名称为 down 时无法连接
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	if name == "down" {
+		return nil, errors.New("down")
+	}
+	return fakeConn(name), nil
+}
+
+type fakeConn string
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt(c), nil }
+
+func (c fakeConn) Close() error { return nil }
+
+func (c fakeConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+type fakeStmt string
+
+func (s fakeStmt) Close() error { return nil }
+
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{name: string(s)}, nil
+}
+
+type fakeRows struct {
+	name string
+	done bool
+}
+
+func (r *fakeRows) Columns() []string { return []string{"name"} }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.name
+	return nil
+}
@@ -0,0 +1,69 @@
+package zero
+
+import "time"
+
+// 估算 map 占用时，每个元素在键值之外的开销，包括 tophash 和装载因子留出的空位
+const mapOverhead = 1.25
+
+// 哈希环的规模和内存占用，用于容量看板跟踪路由层随集群增长的开销
+// 内存按 Go 运行时的数据布局估算，不含分配器的对齐和碎片，只用于观察趋势
+type Stats struct {
+	// 有效的虚拟节点数量
+	Points int
+	// 物理节点数量
+	Nodes int
+	// 有序位置（切片或 RingStore）、虚拟节点映射、节点映射和编译查找表的估算字节数，及其合计
+	PointsBytes   int64
+	RingBytes     int64
+	NodesBytes    int64
+	CompiledBytes int64
+	HeapBytes     int64
+	// 最近一次整体重建的耗时，如更换哈希函数、缩放虚拟节点、恢复快照或回滚，没有重建过时为0
+	LastRebuild time.Duration
+}
+
+// 哈希环当前的规模和估算的内存占用
+func (h *ConsistentHash) Stats() Stats {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	s := Stats{
+		Nodes:       len(h.nodes),
+		LastRebuild: h.lastRebuild,
+	}
+	if h.store != nil {
+		// 树节点中的位置及其指针开销
+		s.PointsBytes = int64(h.store.Len()) * 16
+	} else {
+		s.PointsBytes = int64(cap(h.keys)) * 8
+	}
+
+	// 键8字节，值为切片头24字节，冲突链中的每个节点为16字节的接口
+	s.RingBytes = mapBytes(len(h.ring), 8+24)
+	for _, chain := range h.ring {
+		if len(chain) > 0 {
+			s.Points++
+		}
+		s.RingBytes += int64(cap(chain)) * 16
+	}
+
+	// 节点名为16字节的字符串头，节点映射的值为8字节，虚拟节点位置映射的值为24字节的切片头
+	s.NodesBytes = mapBytes(len(h.nodes), 16+8) + mapBytes(len(h.points), 16+24)
+	for node := range h.nodes {
+		s.NodesBytes += int64(len(node))
+	}
+	for _, points := range h.points {
+		s.NodesBytes += int64(cap(points)) * 8
+	}
+
+	if t := h.compiled; t != nil {
+		// 有序位置与 keys 共享，不重复计算
+		s.CompiledBytes = int64(len(t.buckets))*12 + int64(len(t.owners))*4 + int64(len(t.nodes))*16
+	}
+	s.HeapBytes = s.PointsBytes + s.RingBytes + s.NodesBytes + s.CompiledBytes
+	return s
+}
+
+func mapBytes(entries, size int) int64 {
+	return int64(float64(entries) * float64(size+1) * mapOverhead)
+}
@@ -0,0 +1,39 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	ch := NewConsistentHash()
+	assert.Equal(t, Stats{}, ch.Stats())
+
+	for i := 0; i < 10; i++ {
+		ch.Add("node" + strconv.Itoa(i))
+	}
+	s := ch.Stats()
+	assert.Equal(t, 10*minReplicas, s.Points)
+	assert.Equal(t, 10, s.Nodes)
+	assert.True(t, s.PointsBytes >= int64(s.Points)*8)
+	assert.True(t, s.RingBytes > int64(s.Points)*(8+24+16))
+	assert.True(t, s.NodesBytes > int64(s.Points)*8)
+	assert.Zero(t, s.CompiledBytes)
+	assert.Zero(t, s.LastRebuild)
+	assert.Equal(t, s.PointsBytes+s.RingBytes+s.NodesBytes, s.HeapBytes)
+
+	// 规模翻倍时占用大致翻倍
+	for i := 10; i < 20; i++ {
+		ch.Add("node" + strconv.Itoa(i))
+	}
+	assert.InDelta(t, 2, float64(ch.Stats().HeapBytes)/float64(s.HeapBytes), 0.3)
+
+	ch.Compile()
+	ch.SetReplicas(2 * minReplicas)
+	s = ch.Stats()
+	assert.Equal(t, 40*minReplicas, s.Points)
+	assert.True(t, s.CompiledBytes > 0)
+	assert.True(t, s.LastRebuild > 0)
+}
@@ -0,0 +1,99 @@
+package zero
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// 会话粘滞
+	// 会话第一次分配的节点会被记住，拓扑变化时只要该节点仍在环上，会话就保持在原节点
+	// 只有新会话、过期的会话和节点已下线的会话才按哈希环重新分配
+	StickySessions struct {
+		ring *ConsistentHash
+		// 会话闲置超过 ttl 后过期
+		ttl      time.Duration
+		sessions map[string]*session
+		// 上次清理过期会话的时间
+		lastSweep time.Time
+		clock     Clock
+		lock      sync.Mutex
+	}
+
+	session struct {
+		node    string
+		expires time.Time
+	}
+)
+
+// ttl 为会话的闲置过期时间，每次 Assign 都会续期，时间来源沿用哈希环的配置
+func NewStickySessions(ring *ConsistentHash, ttl time.Duration) *StickySessions {
+	return &StickySessions{
+		ring:     ring,
+		ttl:      ttl,
+		sessions: make(map[string]*session),
+		clock:    ring.clock,
+	}
+}
+
+// 会话所在的节点
+func (s *StickySessions) Assign(sessionID string) (interface{}, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.clock.Now()
+	s.sweep(now)
+	if sess, ok := s.sessions[sessionID]; ok && now.Before(sess.expires) && s.ring.Contains(sess.node) {
+		sess.expires = now.Add(s.ttl)
+		return sess.node, true
+	}
+
+	node, ok := s.ring.Get(sessionID)
+	if !ok {
+		delete(s.sessions, sessionID)
+		return nil, false
+	}
+	s.sessions[sessionID] = &session{
+		node:    node.(string),
+		expires: now.Add(s.ttl),
+	}
+	return node, true
+}
+
+// 忘记会话的分配，下次 Assign 时按哈希环重新分配
+func (s *StickySessions) Forget(sessionID string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.sessions, sessionID)
+}
+
+// 未过期的会话数量
+func (s *StickySessions) Len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.clock.Now()
+	var n int
+	for _, sess := range s.sessions {
+		if now.Before(sess.expires) {
+			n++
+		}
+	}
+	return n
+}
+
+// 每隔一个 ttl 清理一次过期的会话，清理的开销分摊到各次 Assign
+// 调用方需持有锁
+func (s *StickySessions) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.ttl {
+		return
+	}
+
+	s.lastSweep = now
+	for id, sess := range s.sessions {
+		if !now.Before(sess.expires) {
+			delete(s.sessions, id)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStickySessions(t *testing.T) {
+	ring := NewConsistentHash()
+	s := NewStickySessions(ring, time.Minute)
+	clock := newFakeClock()
+	s.clock = clock
+
+	_, ok := s.Assign("session")
+	assert.False(t, ok)
+
+	for i := 0; i < 3; i++ {
+		ring.Add("10.0.0." + strconv.Itoa(i) + ":6379")
+	}
+	assigned := make(map[string]interface{})
+	for i := 0; i < 100; i++ {
+		id := strconv.Itoa(i)
+		assigned[id], _ = s.Assign(id)
+	}
+	assert.Equal(t, 100, s.Len())
+
+	// 新增节点不影响已有会话
+	ring.Add("10.0.0.3:6379")
+	var moved int
+	for id, node := range assigned {
+		current, _ := s.Assign(id)
+		assert.Equal(t, node, current)
+		if natural, _ := ring.Get(id); natural != node {
+			moved++
+		}
+	}
+	assert.True(t, moved > 0)
+
+	// 节点下线后会话重新分配
+	ring.Remove("10.0.0.0:6379")
+	for id, node := range assigned {
+		current, _ := s.Assign(id)
+		if node == "10.0.0.0:6379" {
+			natural, _ := ring.Get(id)
+			assert.Equal(t, natural, current)
+		} else {
+			assert.Equal(t, node, current)
+		}
+	}
+
+	// 闲置过期
+	clock.Advance(30 * time.Second)
+	s.Assign("0")
+	clock.Advance(40 * time.Second)
+	assert.Equal(t, 1, s.Len())
+	s.Assign("1")
+	assert.Equal(t, 2, len(s.sessions))
+
+	s.Forget("0")
+	assert.Equal(t, 1, s.Len())
+}
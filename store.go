@@ -0,0 +1,256 @@
+package zero
+
+import "github.com/google/btree"
+
+// B 树的度
+const treeDegree = 32
+
+// 虚拟节点位置的有序存储，可用于把超大规模哈希环的索引放到堆外或磁盘上
+// 每个位置只保存一次，冲突链由映射维护
+// 读取类方法会在读锁下并发调用，不能修改内部状态；写入类方法在写锁下调用
+// 存储不负责持久化哈希环，创建哈希环时会被清空
+type RingStore interface {
+	// 放入一个位置，已存在时忽略
+	Insert(point uint64)
+	// 删除一个位置，不存在时忽略
+	Delete(point uint64)
+	// 清空所有位置
+	Clear()
+	// 位置的数量
+	Len() int
+	// 最小的位置，为空时 ok 为 false
+	Min() (point uint64, ok bool)
+	// 最大的位置，为空时 ok 为 false
+	Max() (point uint64, ok bool)
+	// 从小到大访问不小于 from 的位置，fn 返回 false 时停止
+	AscendGreaterOrEqual(from uint64, fn func(point uint64) bool)
+	// 从大到小访问不大于 from 的位置，fn 返回 false 时停止
+	DescendLessOrEqual(from uint64, fn func(point uint64) bool)
+	// 独立的副本，用于 Clone 和 Prepare，副本可以是其他类型的存储
+	Clone() RingStore
+}
+
+// 用 B 树保存虚拟节点，增删节点均为 O(replicas * log n)
+// 默认的有序切片在查找时对缓存更友好，但每次增加节点都要整体排序；
+// 虚拟节点数以十万计且拓扑频繁变化时，B 树的写入开销更可控，查找仍为 O(log n)
+// B 树中每个位置只保存一次，冲突链由映射维护，查找表模式同样可用
+func WithTreeStore() Option {
+	return func(h *ConsistentHash) {
+		h.store = NewTreeStore()
+	}
+}
+
+// 用 store 保存虚拟节点，为 nil 时使用默认的有序切片
+// 同一个 store 不能被多个哈希环共用
+func WithStore(store RingStore) Option {
+	return func(h *ConsistentHash) {
+		h.store = store
+	}
+}
+
+// 基于 B 树的内存存储
+type treeStore struct {
+	tree *btree.BTreeG[uint64]
+}
+
+// 创建基于 B 树的内存存储
+func NewTreeStore() RingStore {
+	return &treeStore{tree: btree.NewOrderedG[uint64](treeDegree)}
+}
+
+func (s *treeStore) Insert(point uint64) {
+	s.tree.ReplaceOrInsert(point)
+}
+
+func (s *treeStore) Delete(point uint64) {
+	s.tree.Delete(point)
+}
+
+func (s *treeStore) Clear() {
+	s.tree.Clear(false)
+}
+
+func (s *treeStore) Len() int {
+	return s.tree.Len()
+}
+
+func (s *treeStore) Min() (uint64, bool) {
+	return s.tree.Min()
+}
+
+func (s *treeStore) Max() (uint64, bool) {
+	return s.tree.Max()
+}
+
+func (s *treeStore) AscendGreaterOrEqual(from uint64, fn func(point uint64) bool) {
+	s.tree.AscendGreaterOrEqual(from, fn)
+}
+
+func (s *treeStore) DescendLessOrEqual(from uint64, fn func(point uint64) bool) {
+	s.tree.DescendLessOrEqual(from, fn)
+}
+
+// 逐个复制 B 树中的位置
+// 不使用 btree 的写时复制，它会修改原树，不能在读锁下进行
+func (s *treeStore) Clone() RingStore {
+	c := NewTreeStore().(*treeStore)
+	s.tree.Ascend(func(p uint64) bool {
+		c.tree.ReplaceOrInsert(p)
+		return true
+	})
+	return c
+}
+
+// 以下方法屏蔽了虚拟节点的两种存储方式：
+// 有序切片 keys（含重复位置和墓碑）与存储 store（位置唯一）
+// 调用方需持有相应的锁，读取类方法要求环上至少有一个物理节点
+
+// 放入一个虚拟节点，切片模式下需随后调用 sortKeys
+func (h *ConsistentHash) insertPoint(hash uint64) {
+	if h.store != nil {
+		h.store.Insert(hash)
+		return
+	}
+	h.keys = append(h.keys, hash)
+}
+
+// 删除一个虚拟节点，调用方已从映射中摘除了对应的物理节点
+func (h *ConsistentHash) removePoint(hash uint64) {
+	if h.store != nil {
+		// 冲突链上还有其他节点时保留该位置
+		if len(h.ring[hash]) == 0 {
+			h.store.Delete(hash)
+		}
+		return
+	}
+	// 切片中留下墓碑
+	h.dead++
+}
+
+// 清空所有虚拟节点
+func (h *ConsistentHash) resetPoints() {
+	if h.store != nil {
+		h.store.Clear()
+		return
+	}
+	h.keys = h.keys[:0]
+	h.dead = 0
+}
+
+// 有效虚拟节点的数量，切片模式下冲突的位置按冲突链长度计数
+func (h *ConsistentHash) pointCount() int {
+	if h.store != nil {
+		return h.store.Len()
+	}
+	return len(h.keys) - h.dead
+}
+
+// 顺时针方向第一个不小于 hash 的虚拟节点位置
+func (h *ConsistentHash) successorPoint(hash uint64) uint64 {
+	if h.store != nil {
+		point, found := hash, false
+		h.store.AscendGreaterOrEqual(hash, func(p uint64) bool {
+			point, found = p, true
+			return false
+		})
+		if !found {
+			point, _ = h.store.Min()
+		}
+		return point
+	}
+	return h.keys[h.search(hash)]
+}
+
+// 逆时针方向第一个小于 hash 的虚拟节点位置
+func (h *ConsistentHash) predecessorPoint(hash uint64) uint64 {
+	if h.store != nil {
+		point, found := hash, false
+		h.store.DescendLessOrEqual(hash, func(p uint64) bool {
+			if p < hash {
+				point, found = p, true
+				return false
+			}
+			return true
+		})
+		if !found {
+			point, _ = h.store.Max()
+		}
+		return point
+	}
+
+	index := h.lowerBound(hash)
+	// 逆时针跳过墓碑
+	for {
+		index = (index + len(h.keys) - 1) % len(h.keys)
+		if len(h.ring[h.keys[index]]) > 0 {
+			return h.keys[index]
+		}
+	}
+}
+
+// 按从小到大的顺序访问 [from, 2^64) 中的有效虚拟节点，fn 返回 false 时停止
+// 切片模式下冲突的位置会被访问多次
+func (h *ConsistentHash) ascend(from uint64, fn func(hash uint64) bool) bool {
+	if h.store != nil {
+		stopped := false
+		h.store.AscendGreaterOrEqual(from, func(p uint64) bool {
+			stopped = !fn(p)
+			return !stopped
+		})
+		return !stopped
+	}
+
+	for _, hash := range h.keys[h.lowerBound(from):] {
+		if len(h.ring[hash]) > 0 && !fn(hash) {
+			return false
+		}
+	}
+	return true
+}
+
+// 从 hash 开始顺时针访问一整圈虚拟节点，fn 返回 false 时停止
+func (h *ConsistentHash) ascendRing(hash uint64, fn func(hash uint64) bool) {
+	if !h.ascend(hash, fn) {
+		return
+	}
+	h.ascend(0, func(p uint64) bool {
+		return p < hash && fn(p)
+	})
+}
+
+// 最后一个有效虚拟节点的位置
+func (h *ConsistentHash) lastPoint() uint64 {
+	if h.store != nil {
+		point, _ := h.store.Max()
+		return point
+	}
+	for i := len(h.keys) - 1; ; i-- {
+		if len(h.ring[h.keys[i]]) > 0 {
+			return h.keys[i]
+		}
+	}
+}
+
+// 有序且不含墓碑的虚拟节点位置
+// 切片模式下先压缩再直接返回 keys，调用方不能修改
+// 调用方需持有写锁
+func (h *ConsistentHash) sortedPoints() []uint64 {
+	if h.store != nil {
+		points := make([]uint64, 0, h.store.Len())
+		h.store.AscendGreaterOrEqual(0, func(p uint64) bool {
+			points = append(points, p)
+			return true
+		})
+		return points
+	}
+	h.compactLocked()
+	return h.keys
+}
+
+// 复制存储中的位置，切片模式下返回 nil
+func (h *ConsistentHash) cloneStore() RingStore {
+	if h.store == nil {
+		return nil
+	}
+	return h.store.Clone()
+}
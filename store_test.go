@@ -0,0 +1,185 @@
+package zero
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 两种存储方式下的查找、区间和占比必须完全一致
+func assertSameRing(t *testing.T, expect, actual *ConsistentHash) {
+	t.Helper()
+
+	r := rand.New(rand.NewSource(1))
+	hashes := []uint64{0, 1, math.MaxUint64}
+	for _, hash := range expect.keys {
+		hashes = append(hashes, hash-1, hash, hash+1)
+	}
+	for i := 0; i < requestSize; i++ {
+		hashes = append(hashes, r.Uint64())
+	}
+	for _, hash := range hashes {
+		e, _ := expect.GetHash(hash)
+		a, _ := actual.GetHash(hash)
+		assert.Equal(t, e, a, hash)
+		e, _ = expect.Predecessor(hash)
+		a, _ = actual.Predecessor(hash)
+		assert.Equal(t, e, a, hash)
+	}
+	for i := 0; i < requestSize; i++ {
+		key := strconv.Itoa(i)
+		e, _ := expect.Get(key)
+		a, _ := actual.Get(key)
+		assert.Equal(t, e, a, key)
+		assert.Equal(t, expect.GetCandidates(key, 3), actual.GetCandidates(key, 3), key)
+	}
+
+	type segment struct {
+		start, end uint64
+		node       string
+	}
+	collect := func(h *ConsistentHash) []segment {
+		var segments []segment
+		h.ForEachSegment(func(start, end uint64, node string) bool {
+			segments = append(segments, segment{start, end, node})
+			return true
+		})
+		return segments
+	}
+	assert.Equal(t, collect(expect), collect(actual))
+
+	expectOwned, actualOwned := expect.ownership(), actual.ownership()
+	assert.Equal(t, len(expectOwned), len(actualOwned))
+	for node, share := range expectOwned {
+		assert.InDelta(t, share, actualOwned[node], 1e-9, node)
+	}
+}
+
+func TestTreeStore(t *testing.T) {
+	slice := NewConsistentHash()
+	tree := New(WithTreeStore())
+
+	for i := 0; i < 20; i++ {
+		node := "10.0.0." + strconv.Itoa(i) + ":6379"
+		slice.AddWithWeight(node, 50+i*2)
+		tree.AddWithWeight(node, 50+i*2)
+	}
+	assert.Equal(t, len(slice.keys), tree.store.Len())
+	assert.Empty(t, tree.keys)
+	assertSameRing(t, slice, tree)
+
+	for i := 0; i < 20; i += 3 {
+		node := "10.0.0." + strconv.Itoa(i) + ":6379"
+		slice.Remove(node)
+		tree.Remove(node)
+	}
+	assert.Equal(t, 0, tree.dead)
+	assertSameRing(t, slice, tree)
+
+	// 副本与原有的环互不影响
+	clone := tree.Clone()
+	before := tree.store.Len()
+	tree.Remove("10.0.0.1:6379")
+	slice.Remove("10.0.0.1:6379")
+	assertSameRing(t, slice, tree)
+	assert.True(t, clone.Contains("10.0.0.1:6379"))
+	assert.Equal(t, before, clone.store.Len())
+	assert.Less(t, tree.store.Len(), before)
+
+	tree.Compile()
+	slice.Compile()
+	assertSameRing(t, slice, tree)
+
+	for _, node := range tree.Nodes() {
+		tree.Remove(node)
+	}
+	assert.Equal(t, 0, tree.store.Len())
+	_, ok := tree.Get("any")
+	assert.False(t, ok)
+	_, ok = tree.Predecessor(1)
+	assert.False(t, ok)
+}
+
+func TestTreeStoreWithCollisions(t *testing.T) {
+	slice := newOverlappingHash(CollisionChain)
+	tree := newOverlappingHash(CollisionChain)
+	WithTreeStore()(tree)
+
+	for _, node := range []string{"a", "b", "c"} {
+		slice.Add(node)
+		tree.Add(node)
+	}
+	// 冲突的位置只保存一次
+	assert.Equal(t, 250, tree.store.Len())
+	assertSameRing(t, slice, tree)
+
+	// 冲突链上还有 b 时保留该位置
+	slice.Remove("a")
+	tree.Remove("a")
+	assert.Equal(t, 200, tree.store.Len())
+	assert.True(t, tree.store.(*treeStore).tree.Has(60))
+	assertSameRing(t, slice, tree)
+}
+
+func TestCustomStore(t *testing.T) {
+	store := &countingStore{RingStore: NewTreeStore()}
+	// 残留的位置在创建时清空
+	store.RingStore.Insert(42)
+	slice := New()
+	custom := New(WithStore(store))
+	assert.Equal(t, 0, store.Len())
+
+	for i := 0; i < 20; i++ {
+		node := "10.0.0." + strconv.Itoa(i) + ":6379"
+		slice.Add(node)
+		custom.Add(node)
+	}
+	assert.Equal(t, len(slice.keys), store.Len())
+	assert.Empty(t, custom.keys)
+	assert.Equal(t, 20*minReplicas, store.inserts)
+	assertSameRing(t, slice, custom)
+
+	// 副本独立于原有的存储
+	clone := custom.Clone()
+	slice.Remove("10.0.0.1:6379")
+	custom.Remove("10.0.0.1:6379")
+	assertSameRing(t, slice, custom)
+	assert.Equal(t, 20*minReplicas, clone.store.Len())
+
+	assert.Nil(t, New(WithTreeStore(), WithStore(nil)).store)
+}
+
+// 统计写入次数的存储
+type countingStore struct {
+	RingStore
+	inserts int
+}
+
+func (s *countingStore) Insert(point uint64) {
+	s.inserts++
+	s.RingStore.Insert(point)
+}
+
+func BenchmarkConsistentHashAddTree(b *testing.B) {
+	benchmarkAdd(b, New(WithTreeStore()))
+}
+
+func BenchmarkConsistentHashAddSlice(b *testing.B) {
+	benchmarkAdd(b, New())
+}
+
+func benchmarkAdd(b *testing.B, ch *ConsistentHash) {
+	for i := 0; i < keySize; i++ {
+		ch.Add("localhost:" + strconv.Itoa(i))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		node := "localhost:" + strconv.Itoa(keySize+i%keySize)
+		ch.Add(node)
+		ch.Remove(node)
+	}
+}
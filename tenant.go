@@ -0,0 +1,89 @@
+package zero
+
+import (
+	"maps"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// 沿用全局节点、按租户覆盖权重的视图
+	tenantView struct {
+		ring      *ConsistentHash
+		overrides map[string]int
+		// 重建子环时加锁，查找不加锁
+		lock sync.Mutex
+		sub  atomic.Pointer[tenantRing]
+	}
+
+	// 按某个版本的全局成员构建的子环
+	tenantRing struct {
+		ring    *ConsistentHash
+		version uint64
+	}
+)
+
+// 租户 tenant 的路由视图，节点与哈希环相同，overrides 中的节点按给定权重计算虚拟节点数量
+// 权重含义同 AddWithWeight，可以大于 TopWeight 以把租户偏向专属节点，不大于0时该租户不使用此节点；
+// 不在环上的节点被忽略
+// 子环在第一次查找时构建，之后在拓扑变化后的第一次查找时重建
+// 同一租户以相同的 overrides 再次调用时返回缓存的视图，overrides 改变时替换原有的视图
+func (h *ConsistentHash) TenantView(tenant string, overrides map[string]int) KeyRouter {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if view, ok := h.tenants[tenant]; ok && maps.Equal(view.overrides, overrides) {
+		return view
+	}
+	if h.tenants == nil {
+		h.tenants = make(map[string]*tenantView)
+	}
+	view := &tenantView{ring: h, overrides: maps.Clone(overrides)}
+	h.tenants[tenant] = view
+	return view
+}
+
+func (v *tenantView) Get(key string) (interface{}, bool) {
+	return v.current().Get(key)
+}
+
+func (v *tenantView) GetBytes(b []byte) (interface{}, bool) {
+	return v.current().GetBytes(b)
+}
+
+func (v *tenantView) GetCandidates(key string, n int) []Candidate {
+	return v.current().GetCandidates(key, n)
+}
+
+// 与全局成员一致的子环，拓扑变化后重建
+func (v *tenantView) current() *ConsistentHash {
+	h := v.ring
+	h.lock.RLock()
+	version := h.version
+	h.lock.RUnlock()
+	if sub := v.sub.Load(); sub != nil && sub.version == version {
+		return sub.ring
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if sub := v.sub.Load(); sub != nil && sub.version == version {
+		return sub.ring
+	}
+
+	// 沿用哈希环的配置和固定路由，按覆盖后的成员重建
+	c, version := h.clone()
+	nodes := make(map[string]int, len(c.nodes))
+	for node, replicas := range c.nodes {
+		if weight, ok := v.overrides[node]; ok {
+			replicas = c.replicas * weight / TopWeight
+		}
+		if replicas > 0 {
+			nodes[node] = replicas
+		}
+	}
+	c.rebuild(nodes)
+	c.recompileLocked()
+	v.sub.Store(&tenantRing{ring: c, version: version})
+	return c
+}
@@ -0,0 +1,62 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantView(t *testing.T) {
+	ch := NewConsistentHash()
+	for _, node := range []string{"a", "b", "c", "dedicated"} {
+		ch.Add(node)
+	}
+	ch.AddWithWeight("dedicated", 10)
+	premium := ch.TenantView("premium", map[string]int{"dedicated": 400, "c": 0, "unknown": 100})
+	standard := ch.TenantView("standard", nil)
+
+	counts := make(map[interface{}]int)
+	for i := 0; i < requestSize; i++ {
+		key := strconv.Itoa(i)
+		node, ok := premium.Get(key)
+		assert.True(t, ok)
+		counts[node]++
+
+		expect, _ := ch.Get(key)
+		actual, _ := standard.Get(key)
+		assert.Equal(t, expect, actual)
+	}
+	// 专属节点的权重是其他节点的4倍，且不使用 c
+	assert.Equal(t, 0, counts["c"])
+	assert.True(t, counts["dedicated"] > requestSize/2)
+
+	// 相同的配置复用缓存的视图
+	assert.Same(t, premium, ch.TenantView("premium", map[string]int{"dedicated": 400, "c": 0, "unknown": 100}))
+	assert.NotSame(t, premium, ch.TenantView("premium", map[string]int{"dedicated": 200}))
+}
+
+func TestTenantViewTopology(t *testing.T) {
+	ch := NewConsistentHash()
+	ch.Add("a")
+	view := ch.TenantView("tenant", map[string]int{"b": 50}).(*tenantView)
+
+	node, _ := view.Get("key")
+	assert.Equal(t, "a", node)
+	sub := view.sub.Load()
+
+	// 没有拓扑变化时不重建
+	view.GetBytes([]byte("key"))
+	assert.Same(t, sub, view.sub.Load())
+
+	ch.Remove("a")
+	ch.Add("b")
+	node, _ = view.Get("key")
+	assert.Equal(t, "b", node)
+	assert.Equal(t, 50, view.sub.Load().ring.ReplicaCount("b"))
+	assert.Equal(t, 1, len(view.GetCandidates("key", 2)))
+
+	ch.Remove("b")
+	_, ok := view.Get("key")
+	assert.False(t, ok)
+}
@@ -0,0 +1,69 @@
+package zero
+
+// 在冲突链中为键选择节点，返回 nodes 中的下标
+// nodes 按字典序排列，weights 为对应节点的虚拟节点数量，inner 为键在冲突链内的二次哈希
+// 同样的输入必须返回同样的结果，否则同一个键会在不同节点间跳动
+type TieBreaker func(nodes []string, weights []int, inner uint64) int
+
+// 冲突链中各节点等概率选中，与不指定 TieBreaker 时相同
+func UniformTieBreaker(nodes []string, weights []int, inner uint64) int {
+	return int(inner % uint64(len(nodes)))
+}
+
+// 冲突链中各节点按虚拟节点数量成比例选中，权重越大的节点分得越多的键
+func WeightedTieBreaker(nodes []string, weights []int, inner uint64) int {
+	var total uint64
+	for _, w := range weights {
+		total += uint64(max(w, 0))
+	}
+	if total == 0 {
+		return UniformTieBreaker(nodes, weights, inner)
+	}
+
+	r := inner % total
+	for i, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		if r < uint64(w) {
+			return i
+		}
+		r -= uint64(w)
+	}
+	return len(nodes) - 1
+}
+
+// 指定冲突链中选择节点的策略，nil 表示等概率选择
+// 只在 CollisionChain 策略下产生冲突链时生效，改变策略会改变冲突位置上键的归属
+func WithTieBreaker(tb TieBreaker) Option {
+	return func(h *ConsistentHash) {
+		h.tieBreaker = tb
+	}
+}
+
+// 按 TieBreaker 在冲突链中选择节点
+// 调用方需持有读锁
+func (h *ConsistentHash) breakTie(chain []interface{}, inner uint64) interface{} {
+	nodes := make([]string, len(chain))
+	weights := make([]int, len(chain))
+	for i, node := range chain {
+		nodes[i] = node.(string)
+		weights[i] = h.chainWeight(nodes[i])
+	}
+	pos := h.tieBreaker(nodes, weights, inner)
+	if pos < 0 || pos >= len(chain) {
+		pos = UniformTieBreaker(nodes, weights, inner)
+	}
+	return chain[pos]
+}
+
+// 节点在冲突链中的权重，摘除中的节点按其保留的虚拟节点数量计算
+func (h *ConsistentHash) chainWeight(node string) int {
+	if replicas, ok := h.nodes[node]; ok {
+		return replicas
+	}
+	if entry := h.drains[node]; entry != nil {
+		return len(entry.points)
+	}
+	return 1
+}
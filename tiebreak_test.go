@@ -0,0 +1,90 @@
+package zero
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 所有节点的虚拟节点从同一位置开始，键都落在位置0的冲突链上
+func newSharedPointHash(opts ...Option) *ConsistentHash {
+	return New(append([]Option{
+		WithReplicaKeyFunc(func(node string, index int) []byte {
+			return []byte(strconv.Itoa(index))
+		}),
+		WithHashFunc(func(data []byte) uint64 {
+			v, err := strconv.Atoi(string(data))
+			if err != nil {
+				return Hash(data) | 1<<63
+			}
+			return uint64(v)
+		}),
+	}, opts...)...)
+}
+
+func TestWeightedTieBreaker(t *testing.T) {
+	ch := newSharedPointHash(WithTieBreaker(WeightedTieBreaker))
+	ch.Add("a")
+	ch.AddWithWeight("b", 300)
+
+	counts := make(map[interface{}]int)
+	for i := 0; i < 10000; i++ {
+		node, ok := ch.Get("key" + strconv.Itoa(i))
+		assert.True(t, ok)
+		counts[node]++
+	}
+	// 虚拟节点数量为 1:3
+	assert.InDelta(t, 2500, counts["a"], 300)
+	assert.InDelta(t, 7500, counts["b"], 300)
+
+	// 默认等概率选择
+	ch = newSharedPointHash()
+	ch.Add("a")
+	ch.AddWithWeight("b", 300)
+	counts = make(map[interface{}]int)
+	for i := 0; i < 10000; i++ {
+		node, _ := ch.Get("key" + strconv.Itoa(i))
+		counts[node]++
+	}
+	assert.InDelta(t, 5000, counts["a"], 300)
+}
+
+func TestCustomTieBreaker(t *testing.T) {
+	last := func(nodes []string, weights []int, inner uint64) int {
+		assert.Equal(t, []string{"a", "b"}, nodes)
+		assert.Equal(t, []int{100, 100}, weights)
+		return len(nodes) - 1
+	}
+	ch := newSharedPointHash(WithTieBreaker(last))
+	ch.Add("a")
+	ch.Add("b")
+	for i := 0; i < 100; i++ {
+		node, _ := ch.Get("key" + strconv.Itoa(i))
+		assert.Equal(t, "b", node)
+	}
+	// 副本沿用策略
+	node, _ := ch.Clone().Get("key")
+	assert.Equal(t, "b", node)
+
+	// 越界的结果退化为等概率选择
+	ch = newSharedPointHash(WithTieBreaker(func([]string, []int, uint64) int { return -1 }))
+	ch.Add("a")
+	ch.Add("b")
+	expect := newSharedPointHash()
+	expect.Add("a")
+	expect.Add("b")
+	for i := 0; i < 100; i++ {
+		key := "key" + strconv.Itoa(i)
+		actual, _ := ch.Get(key)
+		want, _ := expect.Get(key)
+		assert.Equal(t, want, actual)
+	}
+}
+
+func TestWeightedTieBreakerZeroWeights(t *testing.T) {
+	nodes := []string{"a", "b", "c"}
+	assert.Equal(t, 1, WeightedTieBreaker(nodes, []int{0, 0, 0}, 4))
+	assert.Equal(t, 2, WeightedTieBreaker(nodes, []int{1, 0, 2}, 2))
+	assert.Equal(t, 0, WeightedTieBreaker(nodes, []int{1, 0, 2}, 3))
+}
@@ -0,0 +1,78 @@
+package zero
+
+import "sort"
+
+// 墓碑占全部虚拟节点的比例超过 1/compactRatio 时压缩
+const compactRatio = 4
+
+// 切片模式下删除节点时只从映射中摘掉虚拟节点，keys 中留下墓碑，写锁内的开销为 O(replicas)
+// 墓碑积累到一定比例后一次性线性压缩，避免每个虚拟节点一次二分查找和切片拷贝
+// keys 中映射为空的位置即为墓碑，查找时跳过
+
+// 第一个不小于 hash 的有效虚拟节点下标，越过末尾时绕回开头
+// 调用方需持有读锁，且环上至少有一个物理节点
+func (h *ConsistentHash) search(hash uint64) int {
+	return h.skipDead(h.lowerBound(hash) % len(h.keys))
+}
+
+// 第一个不小于 hash 的虚拟节点下标，可能为 len(keys)
+// 调用方需持有读锁
+func (h *ConsistentHash) lowerBound(hash uint64) int {
+	return sort.Search(len(h.keys), func(i int) bool {
+		return h.keys[i] >= hash
+	})
+}
+
+// 从 index 开始顺时针跳过墓碑
+// 调用方需持有读锁
+func (h *ConsistentHash) skipDead(index int) int {
+	for h.dead > 0 && len(h.ring[h.keys[index]]) == 0 {
+		index = (index + 1) % len(h.keys)
+	}
+	return index
+}
+
+// 立即清除所有墓碑
+// 删除操作会自动压缩，只有希望在空闲时提前压缩时才需要调用
+func (h *ConsistentHash) Compact() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.compactLocked()
+	h.recompileLocked()
+}
+
+// 墓碑比例过高时压缩
+// 调用方需持有写锁
+func (h *ConsistentHash) maybeCompactLocked() {
+	if h.dead > 0 && h.dead*compactRatio >= len(h.keys) {
+		h.compactLocked()
+	}
+}
+
+// 调用方需持有写锁
+func (h *ConsistentHash) compactLocked() {
+	if h.dead == 0 {
+		return
+	}
+	h.keys = h.appendLiveKeys(h.keys[:0], h.keys)
+	h.dead = 0
+}
+
+// 把 keys 中的有效虚拟节点追加到 dst，dst 可以与 keys 共用底层数组
+// 同一位置保留的个数与冲突链的长度一致
+// 调用方需持有读锁
+func (h *ConsistentHash) appendLiveKeys(dst, keys []uint64) []uint64 {
+	for i := 0; i < len(keys); {
+		hash := keys[i]
+		run := 1
+		for i+run < len(keys) && keys[i+run] == hash {
+			run++
+		}
+		for n := min(run, len(h.ring[hash])); n > 0; n-- {
+			dst = append(dst, hash)
+		}
+		i += run
+	}
+	return dst
+}
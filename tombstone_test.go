@@ -0,0 +1,111 @@
+package zero
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoveLeavesTombstones(t *testing.T) {
+	ch := NewConsistentHash()
+	for i := 0; i < 10; i++ {
+		ch.Add("10.0.0." + strconv.Itoa(i) + ":6379")
+	}
+
+	// 墓碑比例未达到阈值时不压缩
+	ch.Remove("10.0.0.0:6379")
+	ch.Remove("10.0.0.1:6379")
+	assert.Equal(t, 2*minReplicas, ch.dead)
+	assert.Equal(t, 10*minReplicas, len(ch.keys))
+
+	fresh := NewConsistentHash()
+	for _, node := range ch.Nodes() {
+		fresh.Add(node)
+	}
+	for i := 0; i < requestSize; i++ {
+		key := strconv.Itoa(i)
+		expect, _ := fresh.Get(key)
+		actual, _ := ch.Get(key)
+		assert.Equal(t, expect, actual)
+	}
+	for _, hash := range append([]uint64{0, math.MaxUint64}, fresh.keys...) {
+		expect, _ := fresh.Successor(hash)
+		actual, _ := ch.Successor(hash)
+		assert.Equal(t, expect, actual)
+		expect, _ = fresh.Predecessor(hash)
+		actual, _ = ch.Predecessor(hash)
+		assert.Equal(t, expect, actual)
+	}
+
+	var expect, actual []Range
+	fresh.ForEachSegment(func(start, end uint64, node string) bool {
+		expect = append(expect, Range{Start: start, End: end})
+		return true
+	})
+	ch.ForEachSegment(func(start, end uint64, node string) bool {
+		actual = append(actual, Range{Start: start, End: end})
+		return true
+	})
+	assert.Equal(t, expect, actual)
+	owned := ch.ownership()
+	for node, share := range fresh.ownership() {
+		assert.InDelta(t, share, owned[node], 1e-12)
+	}
+	assert.Equal(t, fresh.keys, ch.Clone().keys)
+
+	// 达到阈值后自动压缩
+	ch.Remove("10.0.0.2:6379")
+	assert.Equal(t, 0, ch.dead)
+	assert.Equal(t, 7*minReplicas, len(ch.keys))
+}
+
+func TestCompact(t *testing.T) {
+	ch := NewConsistentHash()
+	ch.Add("first")
+	ch.Add("second")
+	ch.Add("third")
+	ch.Add("fourth")
+	ch.Add("fifth")
+	ch.Remove("first")
+	assert.Equal(t, minReplicas, ch.dead)
+
+	ch.Compact()
+	assert.Equal(t, 0, ch.dead)
+	assert.Equal(t, 4*minReplicas, len(ch.keys))
+
+	// 重新添加的节点复用墓碑所在的位置
+	ch.Remove("second")
+	ch.Add("second")
+	ch.Compact()
+	assert.Equal(t, 4*minReplicas, len(ch.keys))
+}
+
+func TestCompactKeepsCollisionChains(t *testing.T) {
+	ch := NewCustomConsistentHash(minReplicas, func([]byte) uint64 { return 1 })
+	ch.Add("first")
+	ch.Add("second")
+	ch.Add("third")
+	ch.Remove("second")
+	ch.Compact()
+	assert.Equal(t, []uint64{1, 1}, ch.keys[:2])
+	assert.Equal(t, 2*minReplicas, len(ch.keys))
+	owned := ch.ownership()
+	assert.InDelta(t, .5, owned["first"], 1e-9)
+	assert.InDelta(t, .5, owned["third"], 1e-9)
+}
+
+func BenchmarkConsistentHashRemove(b *testing.B) {
+	ch := NewConsistentHash()
+	for i := 0; i < 1000; i++ {
+		ch.Add("10.0.0." + strconv.Itoa(i) + ":6379")
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		node := "10.0.0." + strconv.Itoa(i%1000) + ":6379"
+		ch.Remove(node)
+		ch.Add(node)
+	}
+}
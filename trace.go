@@ -0,0 +1,26 @@
+package zero
+
+import "time"
+
+// 查找的跟踪回调
+// key 为查找的键，hash 为键的哈希值，node 为选中的节点，未找到时为 nil
+// durationNs 为查找耗时（纳秒），不含回调本身
+type TraceHook func(key string, hash uint64, node interface{}, durationNs int64)
+
+// 每次 Get、GetBytes 和 GetWithEpoch 后调用 hook，可用于接入链路追踪或采样调试
+// GetHash 拿不到原始键，不触发回调
+// 回调在释放锁之后执行，跟踪开启时键的哈希值会额外计算一次
+func WithTraceHook(hook TraceHook) Option {
+	return func(h *ConsistentHash) {
+		h.traceHook = hook
+	}
+}
+
+// 计时执行一次查找并调用跟踪回调
+func (h *ConsistentHash) trace(key string, get func() (interface{}, bool)) (interface{}, bool) {
+	start := time.Now()
+	node, ok := get()
+	duration := time.Since(start).Nanoseconds()
+	h.traceHook(key, h.hashFunc([]byte(key)), node, duration)
+	return node, ok
+}
@@ -0,0 +1,35 @@
+package zero
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTraceHook(t *testing.T) {
+	type call struct {
+		key  string
+		hash uint64
+		node interface{}
+	}
+	var calls []call
+	var ch *ConsistentHash
+	ch = New(WithTraceHook(func(key string, hash uint64, node interface{}, durationNs int64) {
+		assert.True(t, durationNs >= 0)
+		// 回调时已释放锁
+		assert.Equal(t, node != nil, ch.Len() > 0)
+		calls = append(calls, call{key: key, hash: hash, node: node})
+	}))
+
+	ch.Get("miss")
+	ch.Add("first")
+	ch.Get("hit")
+	ch.GetBytes([]byte("bytes"))
+	ch.GetHash(1)
+
+	assert.Equal(t, []call{
+		{key: "miss", hash: Hash([]byte("miss"))},
+		{key: "hit", hash: Hash([]byte("hit")), node: "first"},
+		{key: "bytes", hash: Hash([]byte("bytes")), node: "first"},
+	}, calls)
+}
@@ -0,0 +1,90 @@
+package zero
+
+import "time"
+
+// 临时节点的存活信息
+type ttlEntry struct {
+	ttl      time.Duration
+	deadline time.Time
+	timer    Timer
+}
+
+// 添加临时节点，节点在 ttl 内没有通过 Touch 续期时自动删除
+// 对已存在的节点调用时更新其 ttl 并重新计时
+func (h *ConsistentHash) AddWithTTL(node string, ttl time.Duration) {
+	h.lock.Lock()
+	// 在收尾之前清除原有的状态，收尾时按容量计算的权重不再包含该节点
+	h.clearTTLLocked(node)
+	h.clearDrainLocked(node)
+	h.clearCapacityLocked(node)
+	err := h.addWithReplicasLocked(node, h.replicas)
+	if err == nil {
+		entry := &ttlEntry{
+			ttl:      ttl,
+			deadline: h.clock.Now().Add(ttl),
+		}
+		h.scheduleLocked(node, entry, ttl)
+		if h.ttls == nil {
+			h.ttls = make(map[string]*ttlEntry)
+		}
+		h.ttls[node] = entry
+	}
+	h.lock.Unlock()
+
+	if err == nil {
+		h.nodeAdded(node, h.replicas)
+	}
+}
+
+// 临时节点的心跳，把过期时间顺延一个 ttl
+// 节点不存在或不是临时节点时返回 false
+func (h *ConsistentHash) Touch(node string) bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	entry, ok := h.ttls[node]
+	if !ok {
+		return false
+	}
+	// 不重置定时器，到期时再根据最新的过期时间决定是否删除
+	entry.deadline = h.clock.Now().Add(entry.ttl)
+	return true
+}
+
+// 调用方需持有写锁
+func (h *ConsistentHash) scheduleLocked(node string, entry *ttlEntry, wait time.Duration) {
+	entry.timer = h.clock.AfterFunc(wait, func() {
+		h.expire(node, entry)
+	})
+}
+
+// 到期检查，期间续期过则重新计时，否则删除节点
+func (h *ConsistentHash) expire(node string, entry *ttlEntry) {
+	h.lock.Lock()
+	// 节点已被删除或重新添加
+	if h.ttls[node] != entry {
+		h.lock.Unlock()
+		return
+	}
+	if wait := entry.deadline.Sub(h.clock.Now()); wait > 0 {
+		h.scheduleLocked(node, entry, wait)
+		h.lock.Unlock()
+		return
+	}
+
+	delete(h.ttls, node)
+	h.removeLocked(node)
+	h.settleLocked()
+	h.lock.Unlock()
+
+	h.nodeRemoved(node)
+}
+
+// 取消节点的过期删除
+// 调用方需持有写锁
+func (h *ConsistentHash) clearTTLLocked(node string) {
+	if entry, ok := h.ttls[node]; ok {
+		entry.timer.Stop()
+		delete(h.ttls, node)
+	}
+}
@@ -0,0 +1,127 @@
+package zero
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	lock   sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock   *fakeClock
+	when    time.Time
+	f       func()
+	stopped bool
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(0, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	t := &fakeTimer{clock: c, when: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// 推进时间并同步执行到期的定时任务
+func (c *fakeClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	c.now = c.now.Add(d)
+	var due, pending []*fakeTimer
+	for _, t := range c.timers {
+		if !t.stopped && !t.when.After(c.now) {
+			due = append(due, t)
+		} else if !t.stopped {
+			pending = append(pending, t)
+		}
+	}
+	c.timers = pending
+	c.lock.Unlock()
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].when.Before(due[j].when)
+	})
+	for _, t := range due {
+		t.f()
+	}
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.lock.Lock()
+	defer t.clock.lock.Unlock()
+	stopped := t.stopped
+	t.stopped = true
+	return !stopped
+}
+
+func TestAddWithTTL(t *testing.T) {
+	clock := newFakeClock()
+	ch := New()
+	ch.clock = clock
+
+	ch.Add("static")
+	ch.AddWithTTL("ephemeral", 10*time.Second)
+	assert.True(t, ch.Contains("ephemeral"))
+
+	clock.Advance(6 * time.Second)
+	assert.True(t, ch.Touch("ephemeral"))
+	clock.Advance(6 * time.Second)
+	assert.True(t, ch.Contains("ephemeral"))
+
+	clock.Advance(4 * time.Second)
+	assert.False(t, ch.Contains("ephemeral"))
+	assert.False(t, ch.Touch("ephemeral"))
+	assert.Equal(t, []string{"static"}, ch.Nodes())
+	assert.Equal(t, minReplicas, len(ch.keys))
+
+	assert.False(t, ch.Touch("static"))
+	clock.Advance(time.Hour)
+	assert.True(t, ch.Contains("static"))
+}
+
+func TestAddWithTTLOverridden(t *testing.T) {
+	clock := newFakeClock()
+	ch := New()
+	ch.clock = clock
+
+	// 以普通方式重新添加后不再过期
+	ch.AddWithTTL("first", time.Second)
+	ch.Add("first")
+	clock.Advance(time.Minute)
+	assert.True(t, ch.Contains("first"))
+
+	// 删除后重新添加，旧的定时器不影响新的节点
+	ch.AddWithTTL("second", time.Second)
+	ch.Remove("second")
+	ch.AddWithTTL("second", time.Minute)
+	clock.Advance(2 * time.Second)
+	assert.True(t, ch.Contains("second"))
+	clock.Advance(time.Minute)
+	assert.False(t, ch.Contains("second"))
+	assert.Empty(t, ch.ttls)
+}
+
+func TestAddWithTTLRealClock(t *testing.T) {
+	ch := NewConsistentHash()
+	ch.AddWithTTL("first", 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return !ch.Contains("first")
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,240 @@
+package zero
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// 预写日志中间的记录无法解析
+var ErrCorruptWAL = errors.New("consistenthash: corrupt wal")
+
+type (
+	// 预写日志中的一条成员变更
+	WALEntry struct {
+		Time time.Time
+		Op   TopologyOp
+		Node string
+		// 变更后的虚拟节点数量，节点离开时为0
+		Replicas int
+		// 执行变更的主机和进程，用于审计
+		Host string
+		PID  int
+	}
+
+	// 追加写入的成员变更日志
+	// 每次拓扑变更收尾时在写锁内把成员的变化写入文件并同步到磁盘，重启后通过 RecoverFromWAL 恢复
+	// 一个日志只能供一个哈希环使用
+	WAL struct {
+		lock sync.Mutex
+		file *os.File
+		host string
+		pid  int
+		// 日志中记录的最新成员
+		last map[string]int
+		err  error
+	}
+
+	walRecord struct {
+		Time     time.Time `json:"time"`
+		Op       string    `json:"op"`
+		Node     string    `json:"node"`
+		Replicas int       `json:"replicas,omitempty"`
+		Host     string    `json:"host,omitempty"`
+		PID      int       `json:"pid,omitempty"`
+	}
+)
+
+const (
+	walOpAdd    = "add"
+	walOpRemove = "remove"
+)
+
+// 打开或创建预写日志，已有的记录决定日志中的最新成员
+// 崩溃时写了一半的最后一条记录被截掉，避免之后的记录接在残缺的记录后面
+func OpenWAL(path string) (*WAL, error) {
+	entries, size, err := readWAL(path)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Truncate(size); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	host, _ := os.Hostname()
+	return &WAL{
+		file: file,
+		host: host,
+		pid:  os.Getpid(),
+		last: replayWAL(entries),
+	}, nil
+}
+
+// 把成员变更写入 w
+// 打开日志后应先调用 RecoverFromWAL 恢复成员，否则第一次变更会把日志中其余的成员记为离开
+func WithWAL(w *WAL) Option {
+	return func(h *ConsistentHash) {
+		h.wal = w
+	}
+}
+
+// 最近一次写入失败的错误，写入失败后不再写入
+func (w *WAL) Err() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.err
+}
+
+// 关闭日志文件
+func (w *WAL) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.file.Close()
+}
+
+// 把当前成员相对日志的变化写入日志
+func (w *WAL) append(nodes map[string]int, now time.Time) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.err != nil {
+		return
+	}
+	added, removed := diffNodes(w.last, nodes)
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, node := range removed {
+		enc.Encode(walRecord{Time: now, Op: walOpRemove, Node: node, Host: w.host, PID: w.pid})
+	}
+	for _, node := range added {
+		enc.Encode(walRecord{Time: now, Op: walOpAdd, Node: node, Replicas: nodes[node], Host: w.host, PID: w.pid})
+	}
+	if _, err := w.file.Write(buf.Bytes()); err != nil {
+		w.err = err
+		return
+	}
+	if err := w.file.Sync(); err != nil {
+		w.err = err
+		return
+	}
+
+	for _, node := range removed {
+		delete(w.last, node)
+	}
+	for _, node := range added {
+		w.last[node] = nodes[node]
+	}
+}
+
+// 读取预写日志中的全部记录，可用于审计拓扑的变更，文件不存在时返回空
+// 崩溃时写了一半的最后一条记录被忽略
+func ReadWAL(path string) ([]WALEntry, error) {
+	entries, _, err := readWAL(path)
+	return entries, err
+}
+
+// 同 ReadWAL，同时返回完整记录占用的字节数
+func readWAL(path string) ([]WALEntry, int64, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	var entries []WALEntry
+	var size int64
+	r := bufio.NewReader(file)
+	for line := 1; ; line++ {
+		data, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			// 没有换行结尾的记录没有写完
+			return entries, size, nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		size += int64(len(data))
+
+		var record walRecord
+		if err := json.Unmarshal(data, &record); err != nil || record.Node == "" {
+			return nil, 0, fmt.Errorf("%w: line %d", ErrCorruptWAL, line)
+		}
+		entry := WALEntry{
+			Time:     record.Time,
+			Node:     record.Node,
+			Replicas: record.Replicas,
+			Host:     record.Host,
+			PID:      record.PID,
+		}
+		switch record.Op {
+		case walOpAdd:
+			entry.Op = TopologyAdd
+		case walOpRemove:
+			entry.Op = TopologyRemove
+		default:
+			return nil, 0, fmt.Errorf("%w: line %d", ErrCorruptWAL, line)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// 依次应用日志记录得到的成员
+func replayWAL(entries []WALEntry) map[string]int {
+	nodes := make(map[string]int)
+	for _, entry := range entries {
+		if entry.Op == TopologyRemove {
+			delete(nodes, entry.Node)
+		} else {
+			nodes[entry.Node] = entry.Replicas
+		}
+	}
+	return nodes
+}
+
+// 重放预写日志，用日志中的最新成员替换当前成员，文件不存在时成员清空
+// 临时节点、摘除中的节点和节点容量一并清除，固定路由保持不变
+func (h *ConsistentHash) RecoverFromWAL(path string) error {
+	entries, err := ReadWAL(path)
+	if err != nil {
+		return err
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for node := range h.ttls {
+		h.clearTTLLocked(node)
+	}
+	for node := range h.drains {
+		h.clearDrainLocked(node)
+	}
+	h.capacities = nil
+	h.rebuild(replayWAL(entries))
+	h.version++
+	h.settleLocked()
+	return nil
+}
+
+// 调用方需持有写锁
+func (h *ConsistentHash) appendWALLocked() {
+	if h.wal != nil {
+		h.wal.append(h.nodes, h.clock.Now())
+	}
+}
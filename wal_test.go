@@ -0,0 +1,104 @@
+package zero
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWAL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring.wal")
+	w, err := OpenWAL(path)
+	assert.Nil(t, err)
+	clock := newFakeClock()
+	ch := New(WithWAL(w))
+	ch.clock = clock
+	ch.Add("a")
+	clock.Advance(time.Minute)
+	ch.AddWithWeight("b", 50)
+	ch.Remove("a")
+	// 成员没有变化时不写入
+	ch.Pin("key", "b")
+	assert.Nil(t, w.Err())
+	assert.Nil(t, w.Close())
+
+	entries, err := ReadWAL(path)
+	assert.Nil(t, err)
+	host, _ := os.Hostname()
+	assert.Equal(t, []WALEntry{
+		{Time: time.Unix(0, 0), Op: TopologyAdd, Node: "a", Replicas: 100, Host: host, PID: os.Getpid()},
+		{Time: time.Unix(60, 0), Op: TopologyAdd, Node: "b", Replicas: 50, Host: host, PID: os.Getpid()},
+		{Time: time.Unix(60, 0), Op: TopologyRemove, Node: "a", Host: host, PID: os.Getpid()},
+	}, normalizeWAL(entries))
+
+	// 重启后恢复，恢复本身不写入日志
+	w, err = OpenWAL(path)
+	assert.Nil(t, err)
+	restarted := New(WithWAL(w))
+	assert.Nil(t, restarted.RecoverFromWAL(path))
+	assert.Equal(t, []string{"b"}, restarted.Nodes())
+	assert.Equal(t, 50, restarted.ReplicaCount("b"))
+	entries, _ = ReadWAL(path)
+	assert.Equal(t, 3, len(entries))
+	restarted.Add("c")
+	assert.Nil(t, w.Close())
+
+	// 恢复时替换原有的成员
+	recovered := New()
+	recovered.Add("stale")
+	assert.Nil(t, recovered.RecoverFromWAL(path))
+	assert.ElementsMatch(t, []string{"b", "c"}, recovered.Nodes())
+}
+
+func TestWALCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	ch := New()
+	ch.Add("a")
+	assert.Nil(t, ch.RecoverFromWAL(filepath.Join(dir, "missing.wal")))
+	assert.Empty(t, ch.Nodes())
+
+	// 崩溃时没有写完的最后一条记录被忽略
+	path := filepath.Join(dir, "torn.wal")
+	assert.Nil(t, os.WriteFile(path, []byte(`{"op":"add","node":"a","replicas":100}`+"\n"+`{"op":"add","no`), 0o644))
+	assert.Nil(t, ch.RecoverFromWAL(path))
+	assert.Equal(t, []string{"a"}, ch.Nodes())
+
+	path = filepath.Join(dir, "corrupt.wal")
+	assert.Nil(t, os.WriteFile(path, []byte("garbage\n"+`{"op":"add","node":"a"}`+"\n"), 0o644))
+	assert.ErrorIs(t, ch.RecoverFromWAL(path), ErrCorruptWAL)
+	_, err := OpenWAL(path)
+	assert.ErrorIs(t, err, ErrCorruptWAL)
+	assert.Equal(t, []string{"a"}, ch.Nodes())
+}
+
+func TestWALTornAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "torn.wal")
+	assert.Nil(t, os.WriteFile(path, []byte(`{"op":"add","node":"a","replicas":100}`+"\n"+`{"op":"add","no`), 0o644))
+
+	// 重新打开时截掉残缺的记录，之后的记录从完整的一行开始
+	w, err := OpenWAL(path)
+	assert.Nil(t, err)
+	ch := New(WithWAL(w))
+	assert.Nil(t, ch.RecoverFromWAL(path))
+	ch.Add("b")
+	assert.Nil(t, w.Err())
+	assert.Nil(t, w.Close())
+
+	entries, err := ReadWAL(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(entries))
+	recovered := New()
+	assert.Nil(t, recovered.RecoverFromWAL(path))
+	assert.ElementsMatch(t, []string{"a", "b"}, recovered.Nodes())
+}
+
+// 去掉时间的时区信息，便于比较
+func normalizeWAL(entries []WALEntry) []WALEntry {
+	for i := range entries {
+		entries[i].Time = time.Unix(0, entries[i].Time.UnixNano())
+	}
+	return entries
+}
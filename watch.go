@@ -0,0 +1,104 @@
+package zero
+
+import "sync"
+
+// 成员变化的订阅者，事件在独立的协程中按顺序投递
+type watcher struct {
+	fn     func(events []TopologyEvent)
+	lock   sync.Mutex
+	queue  [][]TopologyEvent
+	signal chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+// 订阅成员变化，每次拓扑变更收尾后以一批事件回调 fn，同一批事件的版本号相同
+// 事件的含义与 History 相同，回调在独立的协程中按变更顺序执行，可以安全地访问哈希环
+// 返回的 cancel 取消订阅，尚未投递的事件被丢弃
+func (h *ConsistentHash) Watch(fn func(events []TopologyEvent)) (cancel func()) {
+	w := &watcher{
+		fn:     fn,
+		signal: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	go w.run()
+
+	h.lock.Lock()
+	if h.watchers == nil {
+		h.watchers = make(map[*watcher]struct{})
+		h.watchLast = h.nodesSnapshot()
+	}
+	h.watchers[w] = struct{}{}
+	h.lock.Unlock()
+
+	return func() {
+		h.lock.Lock()
+		delete(h.watchers, w)
+		if len(h.watchers) == 0 {
+			h.watchers = nil
+			h.watchLast = nil
+		}
+		h.lock.Unlock()
+		w.once.Do(func() { close(w.done) })
+	}
+}
+
+// 对比上一次通知时的成员，把变化投递给订阅者
+// 调用方需持有写锁
+func (h *ConsistentHash) notifyWatchersLocked() {
+	if len(h.watchers) == 0 {
+		return
+	}
+	added, removed := diffNodes(h.watchLast, h.nodes)
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+
+	now := h.clock.Now()
+	events := make([]TopologyEvent, 0, len(added)+len(removed))
+	for _, node := range removed {
+		events = append(events, TopologyEvent{Version: h.version, Time: now, Op: TopologyRemove, Node: node})
+		delete(h.watchLast, node)
+	}
+	for _, node := range added {
+		events = append(events, TopologyEvent{Version: h.version, Time: now, Op: TopologyAdd, Node: node, Replicas: h.nodes[node]})
+		h.watchLast[node] = h.nodes[node]
+	}
+	for w := range h.watchers {
+		w.push(events)
+	}
+}
+
+func (w *watcher) push(events []TopologyEvent) {
+	w.lock.Lock()
+	w.queue = append(w.queue, events)
+	w.lock.Unlock()
+
+	select {
+	case w.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (w *watcher) run() {
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-w.signal:
+		}
+
+		w.lock.Lock()
+		queue := w.queue
+		w.queue = nil
+		w.lock.Unlock()
+		for _, events := range queue {
+			select {
+			case <-w.done:
+				return
+			default:
+			}
+			w.fn(events)
+		}
+	}
+}
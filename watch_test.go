@@ -0,0 +1,43 @@
+package zero
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatch(t *testing.T) {
+	ch := New()
+	ch.Add("a")
+	batches := make(chan []TopologyEvent, 10)
+	cancel := ch.Watch(func(events []TopologyEvent) {
+		// 回调中可以访问哈希环
+		ch.Nodes()
+		batches <- events
+	})
+
+	ch.AddWithWeight("b", 50)
+	events := <-batches
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, TopologyEvent{Version: ch.Epoch(), Time: events[0].Time, Op: TopologyAdd, Node: "b", Replicas: 50}, events[0])
+
+	ch.ReplaceAll([]WeightedNode{{Node: "c"}})
+	events = <-batches
+	assert.Equal(t, 3, len(events))
+	assert.Equal(t, events[0].Version, events[2].Version)
+	assert.Equal(t, []string{"a", "b", "c"}, []string{events[0].Node, events[1].Node, events[2].Node})
+	assert.Equal(t, TopologyAdd, events[2].Op)
+
+	// 成员没有变化时不通知
+	ch.Pin("key", "c")
+	cancel()
+	cancel()
+	ch.Add("d")
+	select {
+	case events := <-batches:
+		t.Fatalf("unexpected events %v", events)
+	case <-time.After(10 * time.Millisecond):
+	}
+	assert.Nil(t, ch.watchers)
+}